@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 
 	"go.spiff.io/skim/lisp/interp"
 	"go.spiff.io/skim/lisp/skim"
@@ -14,113 +15,126 @@ import (
 type binopFunc func(l, r skim.Numeric) (skim.Numeric, error)
 
 func sum(l, r skim.Numeric) (skim.Numeric, error) {
-	float := l.IsFloat() || r.IsFloat()
-	if float {
-		l, ok := l.Float64()
-		if !ok {
-			fmt.Errorf("+: unable to convert argument [1] to Float")
-		}
-		r, ok := r.Float64()
-		if !ok {
-			fmt.Errorf("+: unable to convert argument [2] to Float")
+	l, r, kind := skim.NumericPair(l, r)
+	switch kind {
+	case skim.IntKind:
+		if l, r := int64(l.(skim.Int)), int64(r.(skim.Int)); (l+r > l) == (r > 0) {
+			return skim.Int(l + r), nil
 		}
-		return skim.Float(l + r), nil
-	}
-	{
-		l, ok := l.Int64()
-		if !ok {
-			fmt.Errorf("+: unable to convert argument [1] to Int")
-		}
-		r, ok := r.Int64()
-		if !ok {
-			fmt.Errorf("+: unable to convert argument [2] to Int")
-		}
-		return skim.Int(l + r), nil
+		fallthrough
+	case skim.BigIntKind:
+		return bigBinop(l, r, (*big.Int).Add), nil
+	case skim.RationalKind:
+		return ratBinop(l, r, (*big.Rat).Add), nil
+	case skim.FloatKind:
+		return l.(skim.Float) + r.(skim.Float), nil
 	}
+	return nil, pairError("+", l, r)
 }
 
 func sub(l, r skim.Numeric) (skim.Numeric, error) {
-	float := l.IsFloat() || r.IsFloat()
-	if float {
-		l, ok := l.Float64()
-		if !ok {
-			fmt.Errorf("-: unable to convert argument [1] to Float")
-		}
-		r, ok := r.Float64()
-		if !ok {
-			fmt.Errorf("-: unable to convert argument [2] to Float")
-		}
-		return skim.Float(l - r), nil
-	}
-	{
-		l, ok := l.Int64()
-		if !ok {
-			fmt.Errorf("-: unable to convert argument [1] to Int")
-		}
-		r, ok := r.Int64()
-		if !ok {
-			fmt.Errorf("-: unable to convert argument [2] to Int")
+	l, r, kind := skim.NumericPair(l, r)
+	switch kind {
+	case skim.IntKind:
+		if l, r := int64(l.(skim.Int)), int64(r.(skim.Int)); (l-r < l) == (r > 0) {
+			return skim.Int(l - r), nil
 		}
-		return skim.Int(l - r), nil
+		fallthrough
+	case skim.BigIntKind:
+		return bigBinop(l, r, (*big.Int).Sub), nil
+	case skim.RationalKind:
+		return ratBinop(l, r, (*big.Rat).Sub), nil
+	case skim.FloatKind:
+		return l.(skim.Float) - r.(skim.Float), nil
 	}
+	return nil, pairError("-", l, r)
 }
 
 func mul(l, r skim.Numeric) (skim.Numeric, error) {
-	float := l.IsFloat() || r.IsFloat()
-	if float {
-		l, ok := l.Float64()
-		if !ok {
-			fmt.Errorf("*: unable to convert argument [1] to Float")
+	l, r, kind := skim.NumericPair(l, r)
+	switch kind {
+	case skim.IntKind:
+		l, r := int64(l.(skim.Int)), int64(r.(skim.Int))
+		if prod := l * r; l == 0 || (prod/l == r && !(l == -1 && r == math.MinInt64)) {
+			return skim.Int(prod), nil
 		}
-		r, ok := r.Float64()
-		if !ok {
-			fmt.Errorf("*: unable to convert argument [2] to Float")
-		}
-		return skim.Float(l * r), nil
-	}
-	{
-		l, ok := l.Int64()
-		if !ok {
-			fmt.Errorf("*: unable to convert argument [1] to Int")
-		}
-		r, ok := r.Int64()
-		if !ok {
-			fmt.Errorf("*: unable to convert argument [2] to Int")
-		}
-		return skim.Int(l * r), nil
+		return bigBinop(skim.Int(l), skim.Int(r), (*big.Int).Mul), nil
+	case skim.BigIntKind:
+		return bigBinop(l, r, (*big.Int).Mul), nil
+	case skim.RationalKind:
+		return ratBinop(l, r, (*big.Rat).Mul), nil
+	case skim.FloatKind:
+		return l.(skim.Float) * r.(skim.Float), nil
 	}
+	return nil, pairError("*", l, r)
 }
 
+// div divides l by r. Integers are divided by truncating their quotients toward zero, so that only
+// the division of a Rational is exact.
 func div(l, r skim.Numeric) (skim.Numeric, error) {
-	float := l.IsFloat() || r.IsFloat()
-	if float {
-		l, ok := l.Float64()
-		if !ok {
-			fmt.Errorf("/: unable to convert argument [1] to Float")
-		}
-		r, ok := r.Float64()
-		if !ok {
-			fmt.Errorf("/: unable to convert argument [2] to Float")
-		}
+	l, r, kind := skim.NumericPair(l, r)
+	switch kind {
+	case skim.IntKind:
+		l, r := int64(l.(skim.Int)), int64(r.(skim.Int))
 		if r == 0 {
 			return nil, errors.New("attempt to divide by zero")
+		} else if !(l == math.MinInt64 && r == -1) {
+			return skim.Int(l / r), nil
 		}
-		return skim.Float(l / r), nil
-	}
-	{
-		l, ok := l.Int64()
-		if !ok {
-			fmt.Errorf("/: unable to convert argument [1] to Int")
+		return bigBinop(skim.Int(l), skim.Int(r), (*big.Int).Quo), nil
+	case skim.BigIntKind:
+		if bigInt(r).Sign() == 0 {
+			return nil, errors.New("attempt to divide by zero")
 		}
-		r, ok := r.Int64()
-		if !ok {
-			fmt.Errorf("/: unable to convert argument [2] to Int")
+		return bigBinop(l, r, (*big.Int).Quo), nil
+	case skim.RationalKind:
+		if r.(skim.Rational).Big().Sign() == 0 {
+			return nil, errors.New("attempt to divide by zero")
 		}
-		if r == 0 {
+		return ratBinop(l, r, (*big.Rat).Quo), nil
+	case skim.FloatKind:
+		if r.(skim.Float) == 0 {
 			return nil, errors.New("attempt to divide by zero")
 		}
-		return skim.Int(l / r), nil
+		return l.(skim.Float) / r.(skim.Float), nil
+	}
+	return nil, pairError("/", l, r)
+}
+
+// pairError returns the error for operands of the operator name that skim.NumericPair cannot
+// convert to a common kind.
+func pairError(name string, l, r skim.Numeric) error {
+	if l.IsFloat() || r.IsFloat() {
+		if _, ok := l.Float64(); !ok {
+			return fmt.Errorf("%s: unable to convert argument [1] to Float", name)
+		} else if _, ok := r.Float64(); !ok {
+			return fmt.Errorf("%s: unable to convert argument [2] to Float", name)
+		}
+	}
+	return fmt.Errorf("%s: unable to convert %T and %T to a common type", name, l, r)
+}
+
+// bigInt returns n, an Int or BigInt, as a *big.Int. The result may be modified by the caller.
+func bigInt(n skim.Numeric) *big.Int {
+	if b, ok := n.(skim.BigInt); ok {
+		return b.Big()
 	}
+	i, _ := n.Int64()
+	return big.NewInt(i)
+}
+
+// bigBinop applies op to l and r, each an Int or BigInt, as big integers, returning an Int if the
+// result fits in one. It is used for BigInts and when the result of an operation on Int values
+// would otherwise overflow.
+func bigBinop(l, r skim.Numeric, op func(z, x, y *big.Int) *big.Int) skim.Numeric {
+	lv, rv := bigInt(l), bigInt(r)
+	return skim.NewInteger(op(lv, lv, rv))
+}
+
+// ratBinop applies op to the Rationals l and r, returning an integer if the result is one.
+func ratBinop(l, r skim.Numeric, op func(z, x, y *big.Rat) *big.Rat) skim.Numeric {
+	lv, rv := l.(skim.Rational).Big(), r.(skim.Rational).Big()
+	return skim.NewRational(op(lv, lv, rv))
 }
 
 func binopReduce(name, verb string, opfn binopFunc, nargs int) interp.Proc {
@@ -128,16 +142,16 @@ func binopReduce(name, verb string, opfn binopFunc, nargs int) interp.Proc {
 		if argv == nil {
 			return nil, fmt.Errorf("%s: expected >=%d arguments; got 0", name, nargs)
 		}
-		memo, _ := argv.Car.(skim.Numeric)
-		if memo == nil {
-			return nil, fmt.Errorf("%s: cannot %s a %T atom", name, verb, argv.Car)
-		}
-		argc := 1
-		err = skim.Walk(argv.Cdr, func(a skim.Atom) error {
+		var memo skim.Numeric
+		argc := 0
+		err = skim.WalkIndexed(argv, func(i int, a skim.Atom) error {
 			argc++
 			n, _ := a.(skim.Numeric)
 			if n == nil {
-				return fmt.Errorf("%s: cannot %s a %T atom", name, verb, a)
+				return fmt.Errorf("%s: argument %d: cannot %s a %T atom", name, i+1, verb, a)
+			} else if i == 0 {
+				memo = n
+				return nil
 			}
 			memo, err = opfn(memo, n)
 			return err
@@ -231,24 +245,35 @@ func Mod(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err error) {
 	}
 
 	if lhs.IsFloat() || rhs.IsFloat() {
-		lhs, ok := lhs.Float64()
-		if !ok {
-			return nil, fmt.Errorf("modulo: [1] cannot convert to Float")
-		}
-		rhs, ok := rhs.Float64()
-		if !ok {
-			return nil, fmt.Errorf("modulo: [2] cannot convert to Float")
+		// Floats too large for an Int are too imprecise for their remainders to mean anything.
+		if _, ok := lhs.Int64(); !ok {
+			return nil, fmt.Errorf("modulo: [1] %v is out of range", lhs)
+		} else if _, ok := rhs.Int64(); !ok {
+			return nil, fmt.Errorf("modulo: [2] %v is out of range", rhs)
 		}
-		return skim.Float(math.Mod(lhs, rhs)), nil
 	}
 
-	if lhs, ok := lhs.Int64(); !ok {
-		return nil, fmt.Errorf("modulo: [1] cannot convert to Int")
-	} else if rhs, ok := rhs.Int64(); !ok {
-		return nil, fmt.Errorf("modulo: [2] cannot convert to Int")
-	} else {
-		return skim.Int(lhs % rhs), nil
+	ln, rn, kind := skim.NumericPair(lhs, rhs)
+	switch kind {
+	case skim.IntKind:
+		if rn.(skim.Int) == 0 {
+			return nil, errors.New("modulo: attempt to divide by zero")
+		}
+		return ln.(skim.Int) % rn.(skim.Int), nil
+	case skim.BigIntKind:
+		if bigInt(rn).Sign() == 0 {
+			return nil, errors.New("modulo: attempt to divide by zero")
+		}
+		return bigBinop(ln, rn, (*big.Int).Rem), nil
+	case skim.RationalKind:
+		if skim.KindOf(lhs) != skim.RationalKind {
+			lhs = rhs
+		}
+		return nil, fmt.Errorf("modulo: unable to convert %T to an integer", lhs)
+	case skim.FloatKind:
+		return skim.Float(math.Mod(float64(ln.(skim.Float)), float64(rn.(skim.Float)))), nil
 	}
+	return nil, pairError("modulo", lhs, rhs)
 }
 
 func BindArithmetic(ctx *interp.Context) {
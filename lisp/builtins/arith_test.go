@@ -0,0 +1,151 @@
+package builtins
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"go.spiff.io/skim/lisp/interp"
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func bigint(s string) skim.Numeric {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid big.Int: " + s)
+	}
+	return skim.NewInteger(i)
+}
+
+func TestArithmeticBigInt(t *testing.T) {
+	type testcase struct {
+		form skim.Atom
+		want skim.Atom
+	}
+
+	call := func(sym string, args ...skim.Atom) skim.Atom {
+		return skim.List(append([]skim.Atom{skim.Symbol(sym)}, args...)...)
+	}
+
+	maxInt, minInt := skim.Int(math.MaxInt64), skim.Int(math.MinInt64)
+	cases := map[string]testcase{
+		"sum/overflow":       {call("+", maxInt, skim.Int(1)), bigint("9223372036854775808")},
+		"sum/demote":         {call("+", bigint("9223372036854775808"), skim.Int(-1)), maxInt},
+		"sub/overflow":       {call("-", minInt, skim.Int(1)), bigint("-9223372036854775809")},
+		"sub/negate-min":     {call("-", minInt), bigint("9223372036854775808")},
+		"mul/overflow":       {call("*", maxInt, skim.Int(2)), bigint("18446744073709551614")},
+		"mul/neg-min":        {call("*", skim.Int(-1), minInt), bigint("9223372036854775808")},
+		"div/min":            {call("/", minInt, skim.Int(-1)), bigint("9223372036854775808")},
+		"div/big":            {call("/", bigint("18446744073709551614"), skim.Int(2)), maxInt},
+		"modulo/big":         {call("modulo", bigint("18446744073709551617"), skim.Int(10)), skim.Int(7)},
+		"sum/big-float":      {call("+", bigint("9223372036854775808"), skim.Float(0.5)), skim.Float(9223372036854775808.5)},
+		"sum/no-overflow":    {call("+", skim.Int(1), skim.Int(2), skim.Int(-4)), skim.Int(-1)},
+		"mul/no-overflow":    {call("*", skim.Int(-3), skim.Int(3)), skim.Int(-9)},
+		"sub/no-overflow":    {call("-", skim.Int(-3), skim.Int(-3)), skim.Int(0)},
+		"div/no-overflow":    {call("/", skim.Int(-9), skim.Int(3)), skim.Int(-3)},
+		"modulo/no-overflow": {call("modulo", skim.Int(-9), skim.Int(4)), skim.Int(-1)},
+	}
+
+	ctx := interp.NewContext()
+	BindArithmetic(ctx)
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			got, err := ctx.Eval(c.form)
+			if err != nil {
+				t.Fatalf("Eval(%v) err = %v; want nil", c.form, err)
+			} else if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("Eval(%v) = (%T) %v; want (%T) %v", c.form, got, got, c.want, c.want)
+			}
+		})
+	}
+
+	for _, form := range []skim.Atom{
+		call("/", bigint("9223372036854775808"), skim.Int(0)),
+		call("modulo", bigint("9223372036854775808"), skim.Int(0)),
+	} {
+		if got, err := ctx.Eval(form); err == nil {
+			t.Errorf("Eval(%v) = %v; want error", form, got)
+		}
+	}
+}
+
+func TestArithmeticPromotion(t *testing.T) {
+	rat := func(a, b int64) skim.Numeric { return skim.NewRational(big.NewRat(a, b)) }
+	ratstr := func(s string) skim.Numeric {
+		r, _ := new(big.Rat).SetString(s)
+		return skim.NewRational(r)
+	}
+	huge := skim.NewBigInt(new(big.Int).Lsh(big.NewInt(1), 1100)) // too large for a Float
+	type testcase struct {
+		op   binopFunc
+		l, r skim.Numeric
+		want skim.Atom
+		err  string
+	}
+	cases := map[string]testcase{
+		"sum/int-float":        {op: sum, l: skim.Int(1), r: skim.Float(0.5), want: skim.Float(1.5)},
+		"sum/float-int":        {op: sum, l: skim.Float(0.5), r: skim.Int(1), want: skim.Float(1.5)},
+		"sum/rational-int":     {op: sum, l: rat(1, 2), r: skim.Int(1), want: rat(3, 2)},
+		"sum/rational-integer": {op: sum, l: rat(1, 2), r: rat(1, 2), want: skim.Int(1)},
+		"sum/rational-big":     {op: sum, l: rat(1, 2), r: bigint("9223372036854775808"), want: ratstr("18446744073709551617/2")},
+		"sum/rational-float":   {op: sum, l: rat(1, 4), r: skim.Float(0.5), want: skim.Float(0.75)},
+		"sub/rational":         {op: sub, l: skim.Int(1), r: rat(1, 3), want: rat(2, 3)},
+		"mul/rational":         {op: mul, l: rat(2, 3), r: skim.Int(3), want: skim.Int(2)},
+		"mul/big-float":        {op: mul, l: bigint("9223372036854775808"), r: skim.Float(2), want: skim.Float(18446744073709551616)},
+		"div/int":              {op: div, l: skim.Int(7), r: skim.Int(2), want: skim.Int(3)},
+		"div/rational":         {op: div, l: rat(1, 2), r: skim.Int(2), want: rat(1, 4)},
+		"div/int-float":        {op: div, l: skim.Int(1), r: skim.Float(4), want: skim.Float(0.25)},
+		"div/rational-zero":    {op: div, l: rat(1, 2), r: skim.Int(0), err: "attempt to divide by zero"},
+		"div/big-zero":         {op: div, l: huge, r: skim.NewBigInt(new(big.Int)), err: "attempt to divide by zero"},
+		"div/float-zero":       {op: div, l: skim.Int(1), r: skim.Float(0), err: "attempt to divide by zero"},
+		"sum/huge-float":       {op: sum, l: huge, r: skim.Float(1), err: "+: unable to convert argument [1] to Float"},
+		"mul/float-huge":       {op: mul, l: skim.Float(1), r: huge, err: "*: unable to convert argument [2] to Float"},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			got, err := c.op(c.l, c.r)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("%s(%v, %v) = %v, %v; want error %q", name, c.l, c.r, got, err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s(%v, %v) err = %v; want nil", name, c.l, c.r, err)
+			} else if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("%s(%v, %v) = (%T) %v; want (%T) %v", name, c.l, c.r, got, got, c.want, c.want)
+			}
+		})
+	}
+}
+
+func TestModuloFloatRange(t *testing.T) {
+	ctx := interp.NewContext()
+	BindArithmetic(ctx)
+	eval := func(in string) (skim.Atom, error) {
+		t.Helper()
+		data, err := parser.ReadString(in)
+		if err != nil || len(data) != 1 {
+			t.Fatalf("Read(%q) = %v, %v; want one form", in, data, err)
+		}
+		return ctx.Eval(data[0])
+	}
+
+	if got, err := eval("(modulo 5.5 2)"); err != nil || got != skim.Float(1.5) {
+		t.Errorf("(modulo 5.5 2) = %v, %v; want 1.5", got, err)
+	}
+	for in, want := range map[string]string{
+		"(modulo 1e300 7)":  "modulo: [1] 1e+300 is out of range",
+		"(modulo 7 -1e300)": "modulo: [2] -1e+300 is out of range",
+		"(modulo +nan.0 7)": "modulo: [1] +nan.0 is out of range",
+		"(modulo 7 +inf.0)": "modulo: [2] +inf.0 is out of range",
+	} {
+		if got, err := eval(in); err == nil || err.Error() != want {
+			t.Errorf("%s = %v, %v; want error %q", in, got, err, want)
+		}
+	}
+}
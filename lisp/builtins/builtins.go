@@ -1,14 +1,20 @@
 package builtins
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"go.spiff.io/skim/lisp/interp"
+	"go.spiff.io/skim/lisp/printer"
 	"go.spiff.io/skim/lisp/skim"
 )
 
+// stdout is the writer that display, write, and newline write to.
+var stdout io.Writer = os.Stdout
+
 // Expand expands the values by evaluating each value in the scope of the interpreter context, ctx.
 // It returns a new list with the expanded values.
 //
@@ -24,6 +30,17 @@ func Expand(ctx *interp.Context, list *skim.Cons) (*skim.Cons, error) {
 	return m.(*skim.Cons), nil
 }
 
+// argError returns err, raised while evaluating argument i, from zero, of the builtin name, with
+// the argument's position. An error that already has a source position is returned as is, since
+// its position is more precise.
+func argError(name string, i int, err error) error {
+	var pe *interp.PosError
+	if errors.As(err, &pe) {
+		return err
+	}
+	return fmt.Errorf("%s: argument %d: %w", name, i+1, err)
+}
+
 // Expanded returns a new Proc that will invoke fn with expanded values of its form when called.
 // This is useful as a convenience when dealing with regular functions that do not receive anything
 // other than normal arguments as a list. For special procs, such as let, let*, begin, cond, and, or
@@ -47,27 +64,36 @@ func BeginBlock(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err err
 }
 
 func letform(eval, bind *interp.Context, form *skim.Cons) (result skim.Atom, err error) {
-	err = skim.Walk(form.Car, func(a skim.Atom) error {
-		l, r, err := skim.Pair(a)
+	// All bindings are checked before any is evaluated.
+	var (
+		syms  []skim.Symbol
+		exprs []skim.Atom
+	)
+	err = skim.CheckList(form.Car, func(i int, a skim.Atom) error {
+		// Bindings may also be dotted pairs, (x . 1), if their values are not lists.
+		l, r, err := skim.DottedPair(a)
 		if err != nil {
-			return err
+			return fmt.Errorf("binding %d: %w", i+1, err)
 		}
 		sym, ok := l.(skim.Symbol)
 		if !ok {
-			return fmt.Errorf("expected symbol, got %T", l)
+			return fmt.Errorf("binding %d: expected symbol, got %T", i+1, l)
 		}
-
-		r, err = eval.Fork().Eval(r)
-		if err != nil {
-			return err
-		}
-		bind.Bind(sym, r)
+		syms, exprs = append(syms, sym), append(exprs, r)
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	for i, sym := range syms {
+		r, err := eval.Fork().Eval(exprs[i])
+		if err != nil {
+			return nil, err
+		}
+		bind.Bind(sym, r)
+	}
+
 	err = skim.Walk(form.Cdr, func(a skim.Atom) (err error) {
 		result, err = bind.Eval(a)
 		return err
@@ -79,11 +105,18 @@ func letform(eval, bind *interp.Context, form *skim.Cons) (result skim.Atom, err
 	return result, nil
 }
 
+// LogAnd evaluates its arguments in order until one is false, returning the last value evaluated.
+// Under a Loose Truthiness, a false and returns nil; under SchemeStrict, it returns #f, and an and
+// of no arguments returns #t.
 func LogAnd(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err error) {
+	strict := ctx.Truthiness() == interp.SchemeStrict
 	if form == nil {
+		if strict {
+			return skim.Bool(true), nil
+		}
 		return nil, nil
 	}
-	for a := skim.Atom(form); a != nil && err == nil; a, err = skim.Cdr(a) {
+	for a := skim.Atom(form); !skim.IsNil(a) && err == nil; a, err = skim.Cdr(a) {
 		result, err = skim.Car(a)
 		if err == nil {
 			result, err = ctx.Eval(result)
@@ -92,7 +125,10 @@ func LogAnd(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err error)
 			return nil, err
 		}
 
-		if !skim.IsTrue(result) {
+		if !ctx.IsTrue(result) {
+			if strict {
+				return result, nil
+			}
 			return nil, nil
 		}
 	}
@@ -102,11 +138,17 @@ func LogAnd(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err error)
 	return
 }
 
+// LogOr evaluates its arguments in order until one is true, returning it. If none is, it returns
+// nil under a Loose Truthiness and #f under SchemeStrict.
 func LogOr(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err error) {
+	var none skim.Atom
+	if ctx.Truthiness() == interp.SchemeStrict {
+		none = skim.Bool(false)
+	}
 	if form == nil {
-		return nil, nil
+		return none, nil
 	}
-	for a := skim.Atom(form); a != nil && err == nil; a, err = skim.Cdr(a) {
+	for a := skim.Atom(form); !skim.IsNil(a) && err == nil; a, err = skim.Cdr(a) {
 		result, err = skim.Car(a)
 		if err == nil {
 			result, err = ctx.Eval(result)
@@ -115,11 +157,14 @@ func LogOr(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err error) {
 			return nil, err
 		}
 
-		if skim.IsTrue(result) {
+		if ctx.IsTrue(result) {
 			return result, nil
 		}
 	}
-	return nil, err
+	if err != nil {
+		return nil, err
+	}
+	return none, nil
 }
 
 func Cond(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err error) {
@@ -128,7 +173,7 @@ func Cond(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err error) {
 	}
 
 	var a skim.Atom = form
-	for ; a != nil; a, err = skim.Cdr(a) {
+	for ; !skim.IsNil(a); a, err = skim.Cdr(a) {
 		var clause, test, conseq skim.Atom
 		clause, err = skim.Car(a)
 		if err != nil {
@@ -148,7 +193,7 @@ func Cond(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err error) {
 		test, err = ctx.Eval(test)
 		if err != nil {
 			return nil, err
-		} else if !skim.IsTrue(test) {
+		} else if !ctx.IsTrue(test) {
 			continue
 		}
 
@@ -174,21 +219,24 @@ func Newline(c *interp.Context, v *skim.Cons) (skim.Atom, error) {
 	if v != nil {
 		return nil, fmt.Errorf("expected no arguments; got %v", v)
 	}
-	_, err := io.WriteString(os.Stdout, "\n")
+	_, err := io.WriteString(stdout, "\n")
 	return nil, err
 }
 
+// Display writes the display representation of each of its arguments, in which strings and
+// characters are written as raw text. Arguments are separated by spaces unless either is a string.
 func Display(c *interp.Context, v *skim.Cons) (_ skim.Atom, err error) {
 	var args []interface{}
-	err = skim.Walk(v, func(a skim.Atom) error {
+	err = skim.WalkIndexed(v, func(i int, a skim.Atom) error {
 		a, err := c.Eval(a)
 		if err != nil {
+			return argError("display", i, err)
 		} else if str, ok := a.(skim.String); ok {
 			args = append(args, string(str))
 		} else {
-			args = append(args, a)
+			args = append(args, displayed{a})
 		}
-		return err
+		return nil
 	})
 	if err != nil {
 		return nil, err
@@ -197,14 +245,42 @@ func Display(c *interp.Context, v *skim.Cons) (_ skim.Atom, err error) {
 	if len(args) == 0 {
 		return nil, nil
 	}
-	_, err = fmt.Print(args...)
+	_, err = fmt.Fprint(stdout, args...)
 	return nil, err
 }
 
+// displayed formats an atom using its display representation when printed by the fmt package.
+type displayed struct{ a skim.Atom }
+
+func (d displayed) String() string {
+	var b strings.Builder
+	printer.Display(&b, d.a)
+	return b.String()
+}
+
+// Write writes the external representation of each of its arguments, separated by spaces. Unlike
+// display, strings and characters are written as they would be read.
+func Write(c *interp.Context, v *skim.Cons) (_ skim.Atom, err error) {
+	sep := false
+	return nil, skim.WalkIndexed(v, func(i int, a skim.Atom) error {
+		a, err := c.Eval(a)
+		if err != nil {
+			return argError("write", i, err)
+		}
+		if sep {
+			if _, err = io.WriteString(stdout, " "); err != nil {
+				return err
+			}
+		}
+		sep = true
+		return printer.Write(stdout, a)
+	})
+}
+
 func Cons(ctx *interp.Context, form *skim.Cons) (cons skim.Atom, err error) {
 	car, cdr, err := skim.Pair(form)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("cons: %w", err)
 	}
 
 	car, err = ctx.Eval(car)
@@ -219,21 +295,20 @@ func Cons(ctx *interp.Context, form *skim.Cons) (cons skim.Atom, err error) {
 
 func List(ctx *interp.Context, form *skim.Cons) (list skim.Atom, err error) {
 	if form == nil {
-		return &skim.Cons{}, nil
+		return skim.Nil, nil
 	}
 	var pred *skim.Atom = &list
-	for a := skim.Atom(form); a != nil && err == nil; a, err = skim.Cdr(a) {
-		var car skim.Atom
-		car, err = skim.Car(a)
-		if err == nil {
-			car, err = ctx.Eval(car)
-		}
+	err = skim.WalkIndexed(form, func(i int, a skim.Atom) error {
+		car, err := ctx.Eval(a)
 		if err != nil {
-			return nil, err
+			return argError("list", i, err)
 		}
-
 		next := &skim.Cons{Car: car}
 		*pred, pred = next, &next.Cdr
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return list, nil
 }
@@ -242,8 +317,117 @@ func QuoteFn(c *interp.Context, v *skim.Cons) (skim.Atom, error) {
 	return v.Car, nil
 }
 
+// QuasiquoteFn returns its argument with each unquoted form, ,x, replaced by its value and each
+// unquote-splicing form, ,@x, replaced by the elements of its value. Lists and vectors are both
+// descended into. Unquotes inside nested quasiquotes are left in place until the nesting they
+// belong to is evaluated.
 func QuasiquoteFn(c *interp.Context, v *skim.Cons) (skim.Atom, error) {
-	return c.Fork().BindProc("unquote", UnquoteFn).Eval(v.Car)
+	if n, proper := skim.LengthOK(v); n != 1 || !proper {
+		return nil, fmt.Errorf("quasiquote: expected 1 argument; got %d", max(n, 0))
+	}
+	return quasiquote(c, v.Car, 1)
+}
+
+// quasiquote expands the unquotes in a at the given quasiquote depth.
+func quasiquote(ctx *interp.Context, a skim.Atom, depth int) (skim.Atom, error) {
+	switch a := a.(type) {
+	case skim.Vector:
+		out := make(skim.Vector, 0, len(a))
+		for _, elem := range a {
+			elems, err := quasiquoteElem(ctx, elem, depth)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, elems...)
+		}
+		return out, nil
+	case *skim.Cons:
+		if skim.IsNil(a) {
+			return a, nil
+		}
+		switch a.Car {
+		case skim.Unquote:
+			if depth == 1 {
+				arg, err := skim.Cadr(a)
+				if err != nil {
+					return nil, err
+				}
+				return ctx.Eval(arg)
+			}
+			return quasiquoteForm(ctx, a, depth-1)
+		case skim.Quasiquote:
+			return quasiquoteForm(ctx, a, depth+1)
+		}
+
+		var (
+			list skim.Atom
+			pred = &list
+		)
+		for tail := skim.Atom(a); !skim.IsNil(tail); {
+			cons, ok := tail.(*skim.Cons)
+			if !ok || cons.Car == skim.Unquote {
+				// Dotted tail, such as (a . b) or (a . ,b).
+				v, err := quasiquote(ctx, tail, depth)
+				if err != nil {
+					return nil, err
+				}
+				*pred = v
+				break
+			}
+			elems, err := quasiquoteElem(ctx, cons.Car, depth)
+			if err != nil {
+				return nil, err
+			}
+			for _, elem := range elems {
+				next := &skim.Cons{Car: elem}
+				*pred, pred = next, &next.Cdr
+			}
+			tail = cons.Cdr
+		}
+		if list == nil {
+			return skim.Nil, nil
+		}
+		return list, nil
+	}
+	return a, nil
+}
+
+// quasiquoteForm expands the arguments of a nested quasiquote, unquote, or unquote-splicing form
+// at the given depth, keeping the form itself.
+func quasiquoteForm(ctx *interp.Context, form *skim.Cons, depth int) (skim.Atom, error) {
+	args, err := quasiquote(ctx, form.Cdr, depth)
+	if err != nil {
+		return nil, err
+	}
+	return &skim.Cons{Car: form.Car, Cdr: args}, nil
+}
+
+// quasiquoteElem expands an element of a list or vector, returning the elements it expands to. An
+// unquote-splicing form at depth 1 expands to the elements of its value, which must be a list or
+// vector.
+func quasiquoteElem(ctx *interp.Context, elem skim.Atom, depth int) ([]skim.Atom, error) {
+	if form, ok := elem.(*skim.Cons); ok && form != nil && form.Car == skim.UnquoteSplicing {
+		if depth > 1 {
+			v, err := quasiquoteForm(ctx, form, depth-1)
+			return []skim.Atom{v}, err
+		}
+		arg, err := skim.Cadr(form)
+		if err != nil {
+			return nil, err
+		}
+		v, err := ctx.Eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		var elems []skim.Atom
+		err = skim.Walk(v, func(a skim.Atom) error {
+			elems = append(elems, a)
+			return nil
+		})
+		return elems, err
+	}
+	v, err := quasiquote(ctx, elem, depth)
+	return []skim.Atom{v}, err
 }
 
 func UnquoteFn(c *interp.Context, v *skim.Cons) (skim.Atom, error) {
@@ -257,6 +441,7 @@ func BindCore(ctx *interp.Context) {
 	ctx.BindProc("cons", Cons)
 	ctx.BindProc("list", List)
 	ctx.BindProc("quote", QuoteFn)
+	ctx.BindProc("quasiquote", QuasiquoteFn)
 	ctx.BindProc("cond", Cond)
 	ctx.BindProc("and", LogAnd)
 	ctx.BindProc("or", LogOr)
@@ -266,4 +451,5 @@ func BindCore(ctx *interp.Context) {
 func BindDisplay(ctx *interp.Context) {
 	ctx.BindProc("newline", Newline)
 	ctx.BindProc("display", Display)
+	ctx.BindProc("write", Write)
 }
@@ -0,0 +1,345 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/interp"
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestQuasiquote(t *testing.T) {
+	cases := map[string]string{
+		"`a":                       "a",
+		"`(a b)":                   "(a b)",
+		"`(a ,x)":                  "(a 2)",
+		"`[a ,x]":                  "[a 2]",
+		"`(a [b ,x] ,@xs)":         "(a [b 2] 3 4)",
+		"`[,@xs ,@[5 6] ,@'()]":    "[3 4 5 6]",
+		"`(a . ,x)":                "(a . 2)",
+		"`(a ,@xs . b)":            "(a 3 4 . b)",
+		"`'[,x]":                   "'[2]",
+		"`(a `(b ,(c ,x)))":        "(a `(b ,(c 2)))",
+		"`[`[,,x ,@,@(list 'xs)]]": "[`[,2 ,@xs]]",
+		"`()":                      "()",
+		"`[]":                      "[]",
+	}
+
+	ctx := interp.NewContext()
+	BindCore(ctx)
+	ctx.Bind("x", skim.Int(2)).Bind("xs", skim.List(skim.Int(3), skim.Int(4)))
+
+	for in, want := range cases {
+		data, err := parser.ReadString(in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", in, err)
+		}
+		got, err := ctx.Eval(data[0])
+		if err != nil {
+			t.Errorf("Eval(%s) err = %v; want nil", in, err)
+		} else if got.String() != want {
+			t.Errorf("Eval(%s) = %v; want %s", in, got, want)
+		}
+	}
+
+	for in, want := range map[string]string{
+		"(quasiquote)":     "quasiquote: expected 1 argument; got 0",
+		"(quasiquote 1 2)": "quasiquote: expected 1 argument; got 2",
+	} {
+		data, err := parser.ReadString(in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", in, err)
+		}
+		if got, err := ctx.Eval(data[0]); err == nil || err.Error() != want {
+			t.Errorf("Eval(%s) = %v, %v; want error %q", in, got, err, want)
+		}
+	}
+}
+
+func TestBracketsAsLists(t *testing.T) {
+	const (
+		parens   = "(let ((x 1) (f (lambda (y) (+ x y)))) (f 2))"
+		brackets = "(let ([x 1] [f (lambda [y] (+ x y))]) (f 2))"
+	)
+
+	opts := parser.Options{BracketsAsLists: true}
+	want, err := opts.ReadString(parens)
+	if err != nil {
+		t.Fatalf("Read(%q) err = %v; want nil", parens, err)
+	}
+	got, err := opts.ReadString(brackets)
+	if err != nil {
+		t.Fatalf("Read(%q) err = %v; want nil", brackets, err)
+	} else if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Read(%q) = %v; want %v", brackets, got, want)
+	}
+
+	ctx := interp.NewContext().SetListArguments(true)
+	BindCore(ctx)
+	BindArithmetic(ctx)
+	if v, err := ctx.Eval(got[0]); err != nil || v != skim.Int(3) {
+		t.Fatalf("Eval(%v) = %v, %v; want 3, nil", got[0], v, err)
+	}
+}
+
+func TestDisplayWrite(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{`(display "hi")`, `hi`},
+		{`(display "a" 1 2 "b")`, `a1 2b`},
+		{`(display #\a '("x" #\y))`, `a (x y)`},
+		{`(write "hi")`, `"hi"`},
+		{`(write "a" 1 #\a '("x" #\y))`, `"a" 1 #\a ("x" #\y)`},
+	}
+
+	ctx := interp.NewContext()
+	BindCore(ctx)
+	BindDisplay(ctx)
+
+	defer func(w io.Writer) { stdout = w }(stdout)
+	for _, c := range cases {
+		var out strings.Builder
+		stdout = &out
+		data, err := parser.ReadString(c.in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", c.in, err)
+		}
+		if _, err := ctx.Eval(data[0]); err != nil {
+			t.Errorf("Eval(%s) err = %v; want nil", c.in, err)
+		} else if got := out.String(); got != c.want {
+			t.Errorf("Eval(%s) wrote %q; want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestTraceDeterministic(t *testing.T) {
+	const program = `
+		+
+		(setq f (lambda [x] (+ x 1)))
+		f
+		(f 1)
+		(list cons f)
+		(quote (a b))
+		(undefined)
+		undefined
+	`
+
+	// trace evaluates program as main does, writing each result's debug representation.
+	trace := func() string {
+		src := skim.NewSourceMap()
+		data, err := parser.Options{SourceMap: src}.ReadString(program)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", program, err)
+		}
+		ctx := interp.NewContext().SetSourceMap(src)
+		BindCore(ctx)
+		BindArithmetic(ctx)
+		BindMutative(ctx)
+		var b strings.Builder
+		for _, a := range data {
+			v, err := ctx.Eval(a)
+			var next interface{} = v
+			if _, ok := err.(fmt.GoStringer); ok {
+				next = err
+			} else if err != nil {
+				next = err.Error()
+			}
+			fmt.Fprintf(&b, "; [D] => %#v\n", next)
+		}
+		return b.String()
+	}
+
+	first, second := trace(), trace()
+	if first != second {
+		t.Errorf("trace differs between runs:\n%s\nthen\n%s", first, second)
+	}
+	for _, want := range []string{"#<procedure +>", "#<procedure f>", "(#<procedure cons>", "&interp.PosError{"} {
+		if !strings.Contains(first, want) {
+			t.Errorf("trace =\n%s\nwant it to contain %s", first, want)
+		}
+	}
+}
+
+func TestTruthiness(t *testing.T) {
+	cases := []struct {
+		in, loose, strict string
+	}{
+		{"(and)", "#nil", "#t"},
+		{"(and 1 2)", "2", "2"},
+		{"(and 1 #f 2)", "#nil", "#f"},
+		{"(and 1 '() 2)", "#nil", "2"},
+		{"(and 0 \"\")", `""`, `""`},
+		{"(and #nil 1)", "#nil", "1"},
+		{"(or)", "#nil", "#f"},
+		{"(or #f 1)", "1", "1"},
+		{"(or #f #f)", "#nil", "#f"},
+		{"(or '() 1)", "1", "()"},
+		{"(or #nil #f)", "#nil", "#nil"},
+		{"(cond (#f 1) (else 2))", "2", "2"},
+		{"(cond ('() 1) (else 2))", "2", "1"},
+		{"(cond (#nil 1) (#t 2))", "2", "1"},
+		{"(cond ((list) 1))", "#nil", "1"},
+		{"(cond (#f 1))", "#nil", "#nil"},
+		{"(cond ((or #f '()) 'empty) (else 'none))", "none", "empty"},
+	}
+
+	root := interp.NewContext()
+	BindCore(root)
+	root.Bind("else", skim.Bool(true))
+	modes := map[string]*interp.Context{
+		"loose":   root.Fork(),
+		"strict":  root.Fork().SetTruthiness(interp.SchemeStrict),
+		"inherit": root.Fork().SetTruthiness(interp.SchemeStrict).Fork().Dup().Overlay(root),
+	}
+	for _, c := range cases {
+		data, err := parser.ReadString(c.in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", c.in, err)
+		}
+		for mode, ctx := range modes {
+			want := c.strict
+			if mode == "loose" {
+				want = c.loose
+			}
+			got, err := ctx.Eval(data[0])
+			str := "#nil"
+			if got != nil {
+				str = got.String()
+			}
+			if err != nil {
+				t.Errorf("%s: Eval(%s) err = %v; want nil", mode, c.in, err)
+			} else if str != want {
+				t.Errorf("%s: Eval(%s) = %v; want %s", mode, c.in, got, want)
+			}
+		}
+	}
+}
+
+func TestNilPair(t *testing.T) {
+	cases := map[string]string{
+		"(cons #nil #nil)":                  "(#nil)",
+		"(cons 1 '())":                      "(1)",
+		"(cons '() '())":                    "(())",
+		"(list #nil)":                       "(#nil)",
+		"(and (cons #nil #nil) 'pair)":      "pair",
+		"(or '() (cons #nil #nil))":         "(#nil)",
+		"(cond ((cons #nil #nil) 'pair))":   "pair",
+		"`(a ,@(cons #nil #nil) b)":         "(a #nil b)",
+		"`(a ,@(cons 1 '()))":               "(a 1)",
+		"((lambda [x] x) (cons #nil #nil))": "(#nil)",
+	}
+
+	ctx := interp.NewContext()
+	BindCore(ctx)
+	for in, want := range cases {
+		data, err := parser.ReadString(in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", in, err)
+		}
+		got, err := ctx.Eval(data[0])
+		if err != nil {
+			t.Errorf("Eval(%s) err = %v; want nil", in, err)
+		} else if got.String() != want {
+			t.Errorf("Eval(%s) = %v; want %s", in, got, want)
+		}
+	}
+
+	if got, err := ctx.Eval(skim.List(skim.Symbol("list"))); err != nil || got != skim.Nil {
+		t.Errorf("(list) = %#v, %v; want skim.Nil", got, err)
+	}
+}
+
+func TestLetDottedBindings(t *testing.T) {
+	cases := map[string]string{
+		"(let ((x 1) (y 2)) (list x y))":    "(1 2)",
+		"(let ((x . 1) (y 2)) (list x y))":  "(1 2)",
+		"(let* ((x . 1) (y x)) (list x y))": "(1 1)",
+	}
+	errs := map[string]string{
+		"(let ((x)) x)":       "binding 1: skim: pair: (x) has no second element",
+		"(let ((x 1 2)) x)":   "binding 1: skim: pair: (x 1 2) has more than two elements",
+		"(cons 1)":            "cons: skim: pair: (1) has no second element",
+		"(cons 1 2 3)":        "cons: skim: pair: (1 2 3) has more than two elements",
+		"(let ((x 1 . 2)) x)": "binding 1: skim: pair: (x 1 . 2) has a dotted tail after its second element",
+		// The cdr of this binding is a list, so it is not read as a dotted pair.
+		"(let ((x . (list 1))) x)": "binding 1: skim: pair: (x list 1) has more than two elements",
+		"(let ((x 1) (2 3)) x)":    "binding 2: expected symbol, got skim.Int",
+		// Every binding is checked before (f) is evaluated.
+		"(let ((x (f)) (2 3)) x)": "binding 2: expected symbol, got skim.Int",
+		"(let ((x 1) . y) x)":     "skim: improper list ends in y at index 1",
+		"(let 1 x)":               "skim: expected a list, got skim.Int",
+	}
+
+	ctx := interp.NewContext()
+	BindCore(ctx)
+	eval := func(in string) (skim.Atom, error) {
+		t.Helper()
+		data, err := parser.ReadString(in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", in, err)
+		}
+		return ctx.Eval(data[0])
+	}
+	for in, want := range cases {
+		if got, err := eval(in); err != nil {
+			t.Errorf("Eval(%s) err = %v; want nil", in, err)
+		} else if got.String() != want {
+			t.Errorf("Eval(%s) = %v; want %s", in, got, want)
+		}
+	}
+	for in, want := range errs {
+		if got, err := eval(in); err == nil || err.Error() != want {
+			t.Errorf("Eval(%s) = %v, %v; want error %q", in, got, err, want)
+		}
+	}
+}
+
+func TestArgumentErrors(t *testing.T) {
+	defer func(w io.Writer) { stdout = w }(stdout)
+	stdout = io.Discard
+
+	errs := map[string]string{
+		`(+ 1 "x" 3)`:            "+: argument 2: cannot sum a skim.String atom",
+		`(* 'a 2)`:               "*: argument 1: cannot multiply a skim.Symbol atom",
+		`(- 1 2 [3])`:            "-: argument 3: cannot subtract a skim.Vector atom",
+		`(/ 1 #t)`:               "/: argument 2: cannot divide a skim.Bool atom",
+		`(list 1 (+ 1 "x"))`:     "list: argument 2: +: argument 2: cannot sum a skim.String atom",
+		`(list (list 1 y))`:      "list: argument 1: list: argument 2: skim: undefined symbol: y",
+		`(display "a" y)`:        "display: argument 2: skim: undefined symbol: y",
+		`(write y)`:              "write: argument 1: skim: undefined symbol: y",
+		`((lambda [x y] x) 1 y)`: "skim: error evaluating argument #2: skim: undefined symbol: y",
+		`((lambda [x] x) 1 2)`:   "skim: too many arguments to lambda",
+		`((lambda [x y] x) 1)`:   "skim: too few arguments to lambda; got 1, expected 2",
+	}
+
+	ctx := interp.NewContext()
+	BindCore(ctx)
+	BindArithmetic(ctx)
+	BindDisplay(ctx)
+	for in, want := range errs {
+		data, err := parser.ReadString(in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", in, err)
+		}
+		if got, err := ctx.Eval(data[0]); err == nil || err.Error() != want {
+			t.Errorf("Eval(%s) = %v, %v; want error %q", in, got, err, want)
+		}
+	}
+
+	// An argument's source position is more precise than its index, so it is kept as is.
+	src := skim.NewSourceMap()
+	ctx.SetSourceMap(src)
+	data, err := parser.Options{SourceMap: src}.ReadString("(list 1\n  (+ 1 \"x\"))")
+	if err != nil {
+		t.Fatalf("Read err = %v; want nil", err)
+	}
+	const want = "2:3: +: argument 2: cannot sum a skim.String atom"
+	if _, err := ctx.Eval(data[0]); err == nil || err.Error() != want {
+		t.Errorf("Eval(list with source map) err = %v; want %q", err, want)
+	}
+}
@@ -0,0 +1,57 @@
+package builtins
+
+import (
+	"fmt"
+
+	"go.spiff.io/skim/lisp/interp"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// ErrorFn implements (error message irritant ...). It evaluates its arguments and raises a
+// *skim.Error holding the message, which must be a string, and the irritants. Go callers can recover
+// the Error from the error returned by evaluation with errors.As.
+func ErrorFn(ctx *interp.Context, form *skim.Cons) (skim.Atom, error) {
+	args, err := Expand(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	argv, err := skim.ToSlice(args)
+	if err != nil {
+		return nil, err
+	} else if len(argv) == 0 {
+		return nil, fmt.Errorf("error: expected a message")
+	}
+	msg, ok := argv[0].(skim.String)
+	if !ok {
+		return nil, fmt.Errorf("error: message is a %T, not a string", argv[0])
+	}
+	return nil, &skim.Error{Msg: string(msg), Irritants: skim.Vector(argv[1:])}
+}
+
+// Raise implements (raise obj). It evaluates obj and raises it as an error: a *skim.Error is
+// raised as it is, and any other atom is raised as an Error with the message "raise" and obj as
+// its only irritant.
+func Raise(ctx *interp.Context, form *skim.Cons) (skim.Atom, error) {
+	obj, err := singleArg(ctx, "raise", form)
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := obj.(*skim.Error); ok && e != nil {
+		return nil, e
+	}
+	return nil, &skim.Error{Msg: "raise", Irritants: skim.Vector{obj}}
+}
+
+// singleArg evaluates the only argument of the proc name.
+func singleArg(ctx *interp.Context, name string, form *skim.Cons) (skim.Atom, error) {
+	if form == nil || skim.IsNil(form) || form.Cdr != nil {
+		return nil, fmt.Errorf("%s: expected 1 argument; got %v", name, form)
+	}
+	return ctx.Eval(form.Car)
+}
+
+// BindErrors binds error and raise in ctx.
+func BindErrors(ctx *interp.Context) {
+	ctx.BindProc("error", ErrorFn)
+	ctx.BindProc("raise", Raise)
+}
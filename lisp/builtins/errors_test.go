@@ -0,0 +1,58 @@
+package builtins
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/interp"
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestErrorRaise(t *testing.T) {
+	cases := []struct {
+		in   string
+		want *skim.Error
+	}{
+		{`(error "bad value" x 'y)`, &skim.Error{Msg: "bad value", Irritants: skim.Vector{skim.Int(1), skim.Symbol("y")}}},
+		{`(error "no irritants")`, &skim.Error{Msg: "no irritants"}},
+		{`(raise 'oops)`, &skim.Error{Msg: "raise", Irritants: skim.Vector{skim.Symbol("oops")}}},
+		{`(list 1 (raise e))`, &skim.Error{Msg: "from go", Irritants: skim.Vector{skim.Int(2)}}},
+	}
+
+	src := skim.NewSourceMap()
+	ctx := interp.NewContext().SetSourceMap(src)
+	BindCore(ctx)
+	BindErrors(ctx)
+	ctx.Bind("x", skim.Int(1)).Bind("e", &skim.Error{Msg: "from go", Irritants: skim.Vector{skim.Int(2)}})
+
+	for _, c := range cases {
+		data, err := parser.Options{SourceMap: src}.ReadString(c.in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", c.in, err)
+		}
+		_, err = ctx.Eval(data[0])
+		var got *skim.Error
+		var pe *interp.PosError
+		if !errors.As(err, &got) {
+			t.Errorf("Eval(%s) err = (%T) %v; want *skim.Error", c.in, err, err)
+		} else if !skim.Equal(got, c.want) {
+			t.Errorf("Eval(%s) err = %v; want %v", c.in, got, c.want)
+		} else if !errors.As(err, &pe) {
+			t.Errorf("Eval(%s) err = (%T) %v; want it at a position", c.in, err, err)
+		}
+	}
+
+	for _, in := range []string{`(error)`, `(error 'sym)`, `(raise)`, `(raise 1 2)`} {
+		data, err := parser.ReadString(in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", in, err)
+		}
+		_, err = ctx.Eval(data[0])
+		var se *skim.Error
+		if err == nil || errors.As(err, &se) || !strings.HasPrefix(err.Error(), strings.Trim(strings.Fields(in)[0], "()")) {
+			t.Errorf("Eval(%s) err = %v; want a usage error", in, err)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 )
 
 type Lambda struct {
+	name     skim.Symbol // the symbol the lambda was first assigned to, if any
 	ctx      *interp.Context
 	args     []skim.Symbol
 	defaults []skim.Atom
@@ -23,7 +24,7 @@ func NewLambda(ctx *interp.Context, args []skim.Symbol, body *skim.Cons) (*Lambd
 	return &Lambda{
 		ctx:  ctx,
 		args: append([]skim.Symbol(nil), args...),
-		body: skim.Dup(body).(*skim.Cons),
+		body: skim.DupShallow(body).(*skim.Cons),
 	}, nil
 }
 
@@ -53,36 +54,59 @@ func (l *Lambda) String() string {
 	return buf.String()
 }
 
+// Name returns the symbol the lambda was first assigned to by set or setq, or the empty symbol if
+// it is anonymous.
+func (l *Lambda) Name() skim.Symbol {
+	if l == nil {
+		return ""
+	}
+	return l.name
+}
+
+// GoString returns #<procedure name> for a named lambda, or the lambda's address and source if it is
+// anonymous.
 func (l *Lambda) GoString() string {
+	if name := l.Name(); name != "" {
+		return "#<procedure " + name.String() + ">"
+	}
 	return fmt.Sprintf("#<procedure %p %v>", l, l)
 }
 
+// named returns a, or a copy of a named name if a is an anonymous lambda.
+func named(name skim.Symbol, a skim.Atom) skim.Atom {
+	l, ok := a.(*Lambda)
+	if !ok || l == nil || l.name != "" {
+		return a
+	}
+	dup := *l
+	dup.name = name
+	return &dup
+}
+
 func (l *Lambda) Eval(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err error) {
 	var (
 		args  = l.args
 		nargs = len(args)
 		argi  = 0
-		ok    bool
 		call  = l.ctx.Overlay(ctx)
 	)
 
-	for ; form != nil; argi++ {
-		if argi >= nargs {
-			return nil, errors.New("skim: too many arguments to lambda")
+	err = skim.WalkIndexed(form, func(i int, a skim.Atom) error {
+		if i >= nargs {
+			return errors.New("skim: too many arguments to lambda")
 		}
 
-		arg, err := ctx.Fork().Eval(form.Car)
+		arg, err := ctx.Fork().Eval(a)
 		if err != nil {
-			return nil, fmt.Errorf("skim: error evaluating argument #%d: %v", argi+1, err)
+			return fmt.Errorf("skim: error evaluating argument #%d: %w", i+1, err)
 		}
 
-		call.Bind(args[argi], arg)
-		if form.Cdr == nil {
-			argi++
-			break
-		} else if form, ok = form.Cdr.(*skim.Cons); !ok {
-			return nil, errors.New("skim: arguments do not form a list")
-		}
+		call.Bind(args[i], arg)
+		argi++
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	if argi != nargs {
 		return nil, fmt.Errorf("skim: too few arguments to lambda; got %d, expected %d", argi, nargs)
@@ -110,8 +134,14 @@ func newLambda(ctx *interp.Context, form *skim.Cons) (skim.Atom, error) {
 	var (
 		argsym []skim.Symbol
 		syms   map[skim.Symbol]struct{}
+		err    error
 	)
 	args, ok := form.Car.(skim.Vector)
+	if !ok && bodyok && ctx.ListArguments() {
+		// A list of symbols followed by a body is also an argument list, as in (lambda (x) x).
+		// Otherwise, (lambda (f x) (g)) is a lambda taking no arguments whose body calls f.
+		args, ok = symbolList(form.Car)
+	}
 	if !ok {
 		body = form
 		goto construct
@@ -123,18 +153,33 @@ func newLambda(ctx *interp.Context, form *skim.Cons) (skim.Atom, error) {
 
 	syms = make(map[skim.Symbol]struct{}, len(args))
 	argsym = make([]skim.Symbol, len(args))
-	for i, v := range args {
-		if sym, ok := v.(skim.Symbol); ok {
-			if _, ok = syms[sym]; ok {
-				return nil, fmt.Errorf("skim: duplicate argument symbol %q", sym)
-			}
-			argsym[i] = sym
-		} else {
-			argsym, body = nil, form
-			break
+	err = skim.CheckList(args, func(i int, a skim.Atom) error {
+		sym, ok := a.(skim.Symbol)
+		if !ok {
+			return fmt.Errorf("skim: lambda: argument %d: expected symbol, got %T", i+1, a)
+		} else if _, ok = syms[sym]; ok {
+			return fmt.Errorf("skim: duplicate argument symbol %q", sym)
 		}
+		syms[sym] = struct{}{}
+		argsym[i] = sym
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 construct:
 	return NewLambda(ctx, argsym, body)
 }
+
+// symbolList returns the elements of a, if it is a proper list of only symbols, as a Vector.
+func symbolList(a skim.Atom) (skim.Vector, bool) {
+	if _, ok := a.(*skim.Cons); !ok {
+		return nil, false
+	}
+	if skim.CheckList(a, skim.IsSymbolElem) != nil {
+		return nil, false
+	}
+	args, _ := skim.ToSlice(a)
+	return skim.Vector(args), true
+}
@@ -0,0 +1,90 @@
+package builtins
+
+import (
+	"testing"
+
+	"go.spiff.io/skim/lisp/interp"
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestLambdaUnbindVisibility(t *testing.T) {
+	const x = skim.Symbol("x")
+
+	root := interp.NewContext().Bind(x, skim.Int(1))
+	child := root.Fork()
+	child.Unbind(x)
+
+	fn, err := NewLambda(child, nil, skim.List(x).(*skim.Cons))
+	if err != nil {
+		t.Fatalf("NewLambda(..) err = %v; want nil", err)
+	}
+
+	for name, ctx := range map[string]*interp.Context{"root": root, "child": child} {
+		_, direct := child.Resolve(x)
+		_, err := fn.Eval(ctx, nil)
+		if called := err == nil; called != direct {
+			t.Errorf("%s: lambda resolved x = %t (%v); direct Resolve = %t", name, called, err, direct)
+		}
+	}
+}
+
+func TestLambdaArguments(t *testing.T) {
+	cases := map[string]string{
+		"((lambda [x y] (list x y)) 1 2)": "(1 2)",
+		"((lambda (x y) (list x y)) 1 2)": "(1 2)",
+		"((lambda (list 1 2)))":           "(1 2)", // not an argument list, so a body
+	}
+	errs := map[string]string{
+		"(lambda [x 1] x)":   "skim: lambda: argument 2: expected symbol, got skim.Int",
+		"(lambda [x y x] x)": `skim: duplicate argument symbol "x"`,
+		"(lambda (x x) x)":   `skim: duplicate argument symbol "x"`,
+	}
+
+	// The setting is inherited by forks and their duplicates.
+	ctx := interp.NewContext().SetListArguments(true).Fork().Dup()
+	BindCore(ctx)
+	eval := func(in string) (skim.Atom, error) {
+		t.Helper()
+		data, err := parser.ReadString(in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", in, err)
+		}
+		return ctx.Eval(data[0])
+	}
+	for in, want := range cases {
+		if got, err := eval(in); err != nil {
+			t.Errorf("Eval(%s) err = %v; want nil", in, err)
+		} else if got.String() != want {
+			t.Errorf("Eval(%s) = %v; want %s", in, got, want)
+		}
+	}
+	for in, want := range errs {
+		if got, err := eval(in); err == nil || err.Error() != want {
+			t.Errorf("Eval(%s) = %v, %v; want error %q", in, got, err, want)
+		}
+	}
+}
+
+func TestLambdaListBody(t *testing.T) {
+	// Unless list arguments are enabled, a list following lambda is the first form of its body.
+	const in = "((lambda (f x) (g)))"
+	data, err := parser.ReadString(in)
+	if err != nil {
+		t.Fatalf("Read(%q) err = %v; want nil", in, err)
+	}
+
+	ctx := interp.NewContext()
+	BindCore(ctx)
+	calls := 0
+	ctx.BindProc("f", func(*interp.Context, *skim.Cons) (skim.Atom, error) {
+		calls++
+		return nil, nil
+	})
+	ctx.BindProc("g", func(*interp.Context, *skim.Cons) (skim.Atom, error) { return skim.Int(2), nil })
+	if got, err := ctx.Eval(data[0]); err != nil || got != skim.Int(2) {
+		t.Fatalf("Eval(%s) = %v, %v; want 2, nil", in, got, err)
+	} else if calls != 1 {
+		t.Fatalf("Eval(%s) called f %d times; want 1", in, calls)
+	}
+}
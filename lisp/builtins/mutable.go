@@ -17,7 +17,7 @@ func SetQuoted(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err erro
 		sym  skim.Symbol
 		ok   bool
 	)
-	for ; err == nil && a != nil; a, err = skim.Cddr(a) {
+	for ; err == nil && !skim.IsNil(a); a, err = skim.Cddr(a) {
 		name, err = skim.Car(a)
 		if err != nil {
 			return nil, err
@@ -32,6 +32,7 @@ func SetQuoted(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err erro
 		} else if result, err = ctx.Eval(result); err != nil {
 			return nil, err
 		}
+		result = named(sym, result)
 		ctx.Bind(sym, result)
 	}
 	if err != nil {
@@ -50,7 +51,7 @@ func SetUnquoted(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err er
 		sym  skim.Symbol
 		ok   bool
 	)
-	for ; err == nil && a != nil; a, err = skim.Cddr(a) {
+	for ; err == nil && !skim.IsNil(a); a, err = skim.Cddr(a) {
 		name, err = skim.Car(a)
 		if err != nil {
 			return nil, err
@@ -68,6 +69,7 @@ func SetUnquoted(ctx *interp.Context, form *skim.Cons) (result skim.Atom, err er
 		} else if result, err = ctx.Eval(result); err != nil {
 			return nil, err
 		}
+		result = named(sym, result)
 		ctx.Bind(sym, result)
 	}
 	if err != nil {
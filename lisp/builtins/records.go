@@ -0,0 +1,196 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+
+	"go.spiff.io/skim/lisp/interp"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+var errRecordForm = errors.New("skim: define-record-type must be of the form " +
+	"(define-record-type name (constructor field...) predicate (field accessor [modifier])...)")
+
+// DefineRecordType implements (define-record-type name (constructor field ...) predicate (field
+// accessor [modifier]) ...), as in R7RS. It binds name to a new *skim.RecordType holding the fields
+// given, in order, and binds procedures for the type in ctx:
+//
+//   - (constructor value ...) returns a new record with the fields listed after the constructor set
+//     to the values given, and any other fields set to nil. If the constructor is given as a symbol,
+//     rather than a list, it takes a value for every field. If it is #f, no constructor is bound.
+//   - (predicate obj) returns whether obj is a record of the type. If it is #f, no predicate is
+//     bound.
+//   - (accessor record) returns the value of the field, and (modifier record value) sets it and
+//     returns value.
+//
+// It returns the new record type.
+func DefineRecordType(ctx *interp.Context, form *skim.Cons) (skim.Atom, error) {
+	argv, err := skim.ToSlice(form)
+	if err != nil || len(argv) < 3 {
+		return nil, errRecordForm
+	}
+	name, ok := argv[0].(skim.Symbol)
+	if !ok {
+		return nil, errRecordForm
+	}
+
+	type fieldSpec struct {
+		name, accessor, modifier skim.Symbol
+	}
+	specs := make([]fieldSpec, len(argv)-3)
+	fields := make([]skim.Symbol, len(specs))
+	for i, a := range argv[3:] {
+		syms, ok := symbolList(a)
+		if !ok || len(syms) < 2 || len(syms) > 3 {
+			return nil, fmt.Errorf("define-record-type: field must be (field accessor [modifier]); got %v", a)
+		}
+		spec := fieldSpec{name: syms[0].(skim.Symbol), accessor: syms[1].(skim.Symbol)}
+		if len(syms) == 3 {
+			spec.modifier = syms[2].(skim.Symbol)
+		}
+		specs[i], fields[i] = spec, spec.name
+	}
+	rt, err := skim.NewRecordType(name, fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ctor      skim.Symbol
+		ctorArgs  []int // indices of the fields set by the constructor's arguments
+		predicate skim.Symbol
+	)
+	switch spec := argv[1].(type) {
+	case skim.Bool:
+		if spec {
+			return nil, errRecordForm
+		}
+	case skim.Symbol:
+		ctor = spec
+		for i := range fields {
+			ctorArgs = append(ctorArgs, i)
+		}
+	default:
+		syms, ok := symbolList(spec)
+		if !ok || len(syms) == 0 {
+			return nil, errRecordForm
+		}
+		ctor = syms[0].(skim.Symbol)
+		for _, field := range syms[1:] {
+			i, ok := rt.FieldIndex(field.(skim.Symbol))
+			if !ok {
+				return nil, fmt.Errorf("define-record-type: %v has no field %v", name, field)
+			}
+			ctorArgs = append(ctorArgs, i)
+		}
+	}
+	switch spec := argv[2].(type) {
+	case skim.Bool:
+		if spec {
+			return nil, errRecordForm
+		}
+	case skim.Symbol:
+		predicate = spec
+	default:
+		return nil, errRecordForm
+	}
+
+	ctx.Bind(name, rt)
+	if ctor != "" {
+		ctx.BindProc(ctor, recordConstructor(rt, ctor, ctorArgs))
+	}
+	if predicate != "" {
+		ctx.BindProc(predicate, func(ctx *interp.Context, form *skim.Cons) (skim.Atom, error) {
+			obj, err := singleArg(ctx, string(predicate), form)
+			if err != nil {
+				return nil, err
+			}
+			r, ok := obj.(*skim.Record)
+			return skim.Bool(ok && r != nil && r.Type == rt), nil
+		})
+	}
+	for i, spec := range specs {
+		ctx.BindProc(spec.accessor, recordAccessor(rt, spec.accessor, i))
+		if spec.modifier != "" {
+			ctx.BindProc(spec.modifier, recordModifier(rt, spec.modifier, i))
+		}
+	}
+	return rt, nil
+}
+
+// recordConstructor returns the constructor name of records of type rt, whose arguments are the
+// values of the fields at the indices given.
+func recordConstructor(rt *skim.RecordType, name skim.Symbol, fields []int) interp.Proc {
+	return func(ctx *interp.Context, form *skim.Cons) (skim.Atom, error) {
+		argv, err := recordArgs(ctx, name, form, len(fields))
+		if err != nil {
+			return nil, err
+		}
+		values := make([]skim.Atom, len(rt.Fields))
+		for i, field := range fields {
+			values[field] = argv[i]
+		}
+		return rt.New(values...)
+	}
+}
+
+// recordAccessor returns the accessor name of the field at index i of records of type rt.
+func recordAccessor(rt *skim.RecordType, name skim.Symbol, i int) interp.Proc {
+	return func(ctx *interp.Context, form *skim.Cons) (skim.Atom, error) {
+		argv, err := recordArgs(ctx, name, form, 1)
+		if err != nil {
+			return nil, err
+		}
+		r, err := recordOf(rt, name, argv[0])
+		if err != nil {
+			return nil, err
+		}
+		return r.Values[i], nil
+	}
+}
+
+// recordModifier returns the modifier name of the field at index i of records of type rt.
+func recordModifier(rt *skim.RecordType, name skim.Symbol, i int) interp.Proc {
+	return func(ctx *interp.Context, form *skim.Cons) (skim.Atom, error) {
+		argv, err := recordArgs(ctx, name, form, 2)
+		if err != nil {
+			return nil, err
+		}
+		r, err := recordOf(rt, name, argv[0])
+		if err != nil {
+			return nil, err
+		}
+		r.Values[i] = argv[1]
+		return argv[1], nil
+	}
+}
+
+// recordArgs evaluates the n arguments of the record procedure name.
+func recordArgs(ctx *interp.Context, name skim.Symbol, form *skim.Cons, n int) ([]skim.Atom, error) {
+	args, err := Expand(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	argv, err := skim.ToSlice(args)
+	if err != nil {
+		return nil, err
+	} else if len(argv) != n {
+		return nil, fmt.Errorf("%v: expected %d arguments; got %d", name, n, len(argv))
+	}
+	return argv, nil
+}
+
+// recordOf returns a as a record of type rt, or an error naming the record procedure name if it is
+// not one.
+func recordOf(rt *skim.RecordType, name skim.Symbol, a skim.Atom) (*skim.Record, error) {
+	r, ok := a.(*skim.Record)
+	if !ok || r == nil || r.Type != rt {
+		return nil, fmt.Errorf("%v: expected a %v record; got %v", name, rt.Name, a)
+	}
+	return r, nil
+}
+
+// BindRecords binds define-record-type in ctx.
+func BindRecords(ctx *interp.Context) {
+	ctx.BindProc("define-record-type", DefineRecordType)
+}
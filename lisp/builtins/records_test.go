@@ -0,0 +1,93 @@
+package builtins
+
+import (
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/interp"
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestDefineRecordType(t *testing.T) {
+	ctx := interp.NewContext()
+	BindCore(ctx)
+	BindRecords(ctx)
+
+	eval := func(in string) (skim.Atom, error) {
+		t.Helper()
+		data, err := parser.ReadString(in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", in, err)
+		}
+		var result skim.Atom
+		for _, a := range data {
+			if result, err = ctx.Eval(a); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	rt, err := eval(`
+		(define-record-type <point> (make-point x y) point? (x point-x set-point-x!) (y point-y))
+		(define-record-type <pair> (make-pair b) pair? (a pair-a) (b pair-b))`)
+	if err != nil {
+		t.Fatalf("define-record-type err = %v; want nil", err)
+	} else if got, want := rt.String(), "#<record-type <pair>>"; got != want {
+		t.Errorf("define-record-type = %s; want %s", got, want)
+	}
+
+	cases := []struct {
+		in, want string
+	}{
+		{"(make-point 1 2)", "#<point x: 1 y: 2>"},
+		{"(point-x (make-point 1 2))", "1"},
+		{"(point-y (make-point 1 (list 2 3)))", "(2 3)"},
+		{"(let ((p (make-point 1 2))) (set-point-x! p 'moved) p)", "#<point x: moved y: 2>"},
+		{"(point? (make-point 1 2))", "#t"},
+		{"(point? (make-pair 1))", "#f"},
+		{"(point? '(1 2))", "#f"},
+		{"(pair? (make-pair 1))", "#t"},
+		{"(make-pair 1)", "#<pair a: #nil b: 1>"},
+		{"make-point", "#<procedure make-point>"},
+		{"<point>", "#<record-type <point>>"},
+	}
+	for _, c := range cases {
+		got, err := eval(c.in)
+		if err != nil {
+			t.Errorf("Eval(%s) err = %v; want nil", c.in, err)
+		} else if got.String() != c.want {
+			t.Errorf("Eval(%s) = %v; want %s", c.in, got, c.want)
+		}
+	}
+
+	errs := []struct {
+		in, want string
+	}{
+		{"(point-x (make-pair 1))", "point-x: expected a <point> record"},
+		{"(set-point-x! 1 2)", "set-point-x!: expected a <point> record"},
+		{"(make-point 1)", "make-point: expected 2 arguments"},
+		{"(point-x)", "point-x: expected 1 arguments"},
+		{"(define-record-type <bad> (make-bad z) bad? (x bad-x))", "define-record-type: <bad> has no field z"},
+		{"(define-record-type <bad> make-bad bad? (x bad-x) (x bad-x2))", "duplicate field x"},
+		{"(define-record-type <bad> make-bad bad? (x))", "define-record-type: field must be"},
+		{"(define-record-type <bad>)", "define-record-type must be"},
+	}
+	for _, c := range errs {
+		if _, err := eval(c.in); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("Eval(%s) err = %v; want %q", c.in, err, c.want)
+		}
+	}
+
+	// A symbol constructor takes every field, and #f binds no predicate.
+	if _, err := eval("(define-record-type <two> make-two #f (a two-a) (b two-b))"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := eval("(two-b (make-two 1 2))"); err != nil || got != skim.Int(2) {
+		t.Errorf("(two-b (make-two 1 2)) = %v, %v; want 2", got, err)
+	}
+	if _, ok := ctx.Resolve("#f"); ok {
+		t.Error("#f was bound as a predicate")
+	}
+}
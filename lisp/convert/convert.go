@@ -0,0 +1,323 @@
+// Package convert converts between skim atoms and the values used by encoding/json: nil, bool,
+// float64, json.Number, string, []interface{}, and map[string]interface{}. It also converts atoms
+// to and from YAML documents using gopkg.in/yaml.v3.
+//
+// Unlike the MarshalJSON methods of atoms, which encode any atom in a form that FromJSON can decode
+// exactly, this package maps skim documents onto plain JSON: symbols become strings and
+// association lists or property lists become objects, as configured by Options. This makes it
+// possible to write configuration in skim for code that consumes JSON.
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// ObjectMode is the kind of atom that JSON objects are converted to and from.
+type ObjectMode int
+
+const (
+	// Alist converts objects to and from association lists, ((key . value) ...). When converting
+	// an atom, a non-empty list whose elements are all cons pairs beginning with a symbol, string,
+	// or keyword is an object. Keys of converted objects are symbols.
+	Alist ObjectMode = iota
+
+	// Plist converts objects to and from property lists, (:key value ...). When converting an
+	// atom, a non-empty list of even length whose elements alternate between keywords and values
+	// is an object. Keys of converted objects are keywords, which are written quoted by pipes if
+	// they are empty or hold whitespace or delimiters, as in :|first name|.
+	Plist
+)
+
+func (m ObjectMode) String() string {
+	switch m {
+	case Alist:
+		return "alist"
+	case Plist:
+		return "plist"
+	}
+	return fmt.Sprintf("ObjectMode(%d)", int(m))
+}
+
+// Options configures the conversion of atoms to and from JSON values. The zero Options converts
+// objects to association lists and arrays to lists.
+type Options struct {
+	// Objects is the kind of atom that JSON objects are converted to and from.
+	Objects ObjectMode
+
+	// Vectors, if true, converts JSON arrays to vectors rather than lists. Both vectors and lists
+	// that are not objects are converted to arrays regardless.
+	Vectors bool
+}
+
+// ToJSONValue converts a to a JSON value using the zero Options.
+func ToJSONValue(a skim.Atom) (interface{}, error) {
+	return Options{}.ToJSONValue(a)
+}
+
+// FromJSONValue converts v to an atom using the zero Options.
+func FromJSONValue(v interface{}) (skim.Atom, error) {
+	return Options{}.FromJSONValue(v)
+}
+
+// ToJSONValue converts a to a JSON value that encoding/json can marshal.
+//
+// Int is converted to int64, Float to float64, BigInt to json.Number, Bool to bool, and nil to nil.
+// Strings, symbols, and keywords are converted to strings, keywords without their colon. Vectors
+// and proper lists are converted to []interface{}, unless the list is an object as described by
+// o.Objects, in which case it is converted to map[string]interface{}; if a key occurs more than
+// once, its first value is kept. Infinite and NaN floats, improper lists, cyclic atoms, and any
+// other atom are errors.
+func (o Options) ToJSONValue(a skim.Atom) (interface{}, error) {
+	if skim.Cyclic(a) {
+		return nil, fmt.Errorf("skim: cannot convert cyclic %T to JSON", a)
+	}
+	return o.toJSON(a)
+}
+
+func (o Options) toJSON(a skim.Atom) (interface{}, error) {
+	switch a := a.(type) {
+	case nil:
+		return nil, nil
+	case skim.Int:
+		return int64(a), nil
+	case skim.BigInt:
+		return json.Number(a.String()), nil
+	case skim.Float:
+		f := float64(a)
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("skim: cannot convert %v to JSON", a)
+		}
+		return f, nil
+	case skim.Bool:
+		return bool(a), nil
+	case skim.String:
+		return string(a), nil
+	case skim.Symbol:
+		return string(a), nil
+	case skim.Keyword:
+		return string(a), nil
+	case skim.Vector:
+		return o.array(a)
+	case *skim.Cons:
+		if skim.IsNil(a) {
+			return []interface{}{}, nil
+		}
+		elems, err := listElems(a)
+		if err != nil {
+			return nil, err
+		}
+		if keys, values, ok := o.members(elems); ok {
+			return o.jsonObject(keys, values)
+		}
+		return o.array(elems)
+	}
+	return nil, fmt.Errorf("skim: cannot convert %T to JSON", a)
+}
+
+func (o Options) array(elems []skim.Atom) ([]interface{}, error) {
+	arr := make([]interface{}, len(elems))
+	for i, elem := range elems {
+		v, err := o.toJSON(elem)
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func (o Options) jsonObject(keys []string, values []skim.Atom) (map[string]interface{}, error) {
+	obj := make(map[string]interface{}, len(keys))
+	for i, key := range keys {
+		v, err := o.toJSON(values[i])
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+	}
+	return obj, nil
+}
+
+// members returns the keys and values of the object that the list elements elems form, as
+// described by o.Objects. If a key occurs more than once, only its first value is returned. If
+// elems do not form an object, ok is false.
+func (o Options) members(elems []skim.Atom) (keys []string, values []skim.Atom, ok bool) {
+	seen := make(map[string]bool)
+	add := func(key string, value skim.Atom) {
+		if !seen[key] {
+			seen[key] = true
+			keys, values = append(keys, key), append(values, value)
+		}
+	}
+	switch {
+	case o.Objects == Alist && isAlist(elems):
+		for _, elem := range elems {
+			pair := elem.(*skim.Cons)
+			key, _ := keyString(pair.Car)
+			add(key, pair.Cdr)
+		}
+	case o.Objects == Plist && isPlist(elems):
+		for i := 0; i < len(elems); i += 2 {
+			add(string(elems[i].(skim.Keyword)), elems[i+1])
+		}
+	default:
+		return nil, nil, false
+	}
+	return keys, values, true
+}
+
+// object returns the list that the object with the given keys and values is converted to, as
+// described by o.Objects.
+func (o Options) object(keys []string, values []skim.Atom) (skim.Atom, error) {
+	elems := make([]skim.Atom, 0, len(keys)*2)
+	for i, key := range keys {
+		switch o.Objects {
+		case Alist:
+			elems = append(elems, &skim.Cons{Car: skim.Symbol(key), Cdr: values[i]})
+		case Plist:
+			elems = append(elems, skim.Keyword(key), values[i])
+		default:
+			return nil, fmt.Errorf("skim: invalid object mode %v", o.Objects)
+		}
+	}
+	return skim.List(elems...), nil
+}
+
+// listElems returns the elements of list, which must be a proper list.
+func listElems(list *skim.Cons) (elems []skim.Atom, err error) {
+	for c := list; !skim.IsNil(c); {
+		elems = append(elems, c.Car)
+		switch cdr := c.Cdr.(type) {
+		case nil:
+			return elems, nil
+		case *skim.Cons:
+			c = cdr
+		default:
+			return nil, fmt.Errorf("skim: cannot convert improper list %v", list)
+		}
+	}
+	return elems, nil
+}
+
+// isAlist returns whether elems are all cons pairs whose car is a symbol, string, or keyword.
+func isAlist(elems []skim.Atom) bool {
+	for _, elem := range elems {
+		pair, ok := elem.(*skim.Cons)
+		if !ok || pair == nil {
+			return false
+		} else if _, ok := keyString(pair.Car); !ok {
+			return false
+		}
+	}
+	return len(elems) > 0
+}
+
+// isPlist returns whether elems alternate between keywords and values.
+func isPlist(elems []skim.Atom) bool {
+	if len(elems) == 0 || len(elems)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(elems); i += 2 {
+		if _, ok := elems[i].(skim.Keyword); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// keyString returns the object key named by a, if a is a symbol, string, or keyword.
+func keyString(a skim.Atom) (string, bool) {
+	switch a := a.(type) {
+	case skim.Symbol:
+		return string(a), true
+	case skim.String:
+		return string(a), true
+	case skim.Keyword:
+		return string(a), true
+	}
+	return "", false
+}
+
+// FromJSONValue converts v, a value such as encoding/json unmarshals into an interface{}, to an
+// atom. Numbers keep their type: float64 is converted to Float and int and int64 to Int. A
+// json.Number is converted to Int if it is an integer, to BigInt if it is an integer too large
+// for an Int, and otherwise to Float. Strings are converted to String, bool to Bool, and nil to
+// nil.
+//
+// Arrays are converted to lists, or vectors if o.Vectors is set, and objects as described by
+// o.Objects, with their keys in sorted order.
+func (o Options) FromJSONValue(v interface{}) (skim.Atom, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return skim.Bool(v), nil
+	case float64:
+		return skim.Float(v), nil
+	case int:
+		return skim.Int(v), nil
+	case int64:
+		return skim.Int(v), nil
+	case json.Number:
+		return number(v)
+	case string:
+		return skim.String(v), nil
+	case []interface{}:
+		elems := make([]skim.Atom, len(v))
+		for i, elem := range v {
+			a, err := o.FromJSONValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = a
+		}
+		if o.Vectors {
+			return skim.Vector(elems), nil
+		}
+		return skim.List(elems...), nil
+	case map[string]interface{}:
+		return o.fromJSONObject(v)
+	}
+	return nil, fmt.Errorf("skim: cannot convert %T to an atom", v)
+}
+
+func (o Options) fromJSONObject(obj map[string]interface{}) (skim.Atom, error) {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := make([]skim.Atom, len(keys))
+	for i, key := range keys {
+		a, err := o.FromJSONValue(obj[key])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = a
+	}
+	return o.object(keys, values)
+}
+
+// number converts the JSON number n to an atom.
+func number(n json.Number) (skim.Atom, error) {
+	if !strings.ContainsAny(string(n), ".eE") {
+		if i, err := n.Int64(); err == nil {
+			return skim.Int(i), nil
+		} else if b, ok := new(big.Int).SetString(string(n), 10); ok {
+			return skim.NewBigInt(b), nil
+		}
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("skim: cannot convert number %s to an atom: %v", n, err)
+	}
+	return skim.Float(f), nil
+}
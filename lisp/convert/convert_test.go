@@ -0,0 +1,205 @@
+package convert
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func pair(car, cdr skim.Atom) *skim.Cons { return &skim.Cons{Car: car, Cdr: cdr} }
+
+func TestToJSONValue(t *testing.T) {
+	cases := []struct {
+		name string
+		opts Options
+		in   skim.Atom
+		want string
+	}{
+		{"nil", Options{}, nil, `null`},
+		{"scalars", Options{}, skim.Vector{skim.Int(1), skim.Float(1.5), skim.Bool(true), skim.String("s"), skim.Symbol("sym"), skim.Keyword("kw")},
+			`[1,1.5,true,"s","sym","kw"]`},
+		{"empty", Options{}, skim.Nil, `[]`},
+		{"list with nil", Options{}, skim.List(skim.Int(1), nil), `[1,null]`},
+		{"alist", Options{},
+			skim.List(pair(skim.Symbol("host"), skim.String("x")), pair(skim.String("port"), skim.Int(80)), pair(skim.Symbol("host"), skim.String("y"))),
+			`{"host":"x","port":80}`},
+		{"alist with list values", Options{},
+			skim.List(skim.List(skim.Symbol("ports"), skim.Int(80), skim.Int(443))),
+			`{"ports":[80,443]}`},
+		{"alist in plist mode", Options{Objects: Plist},
+			skim.List(skim.List(skim.Symbol("a"), skim.Int(1))),
+			`[["a",1]]`},
+		{"plist", Options{Objects: Plist},
+			skim.List(skim.Keyword("a"), skim.Int(1), skim.Keyword("b"), skim.List(skim.Keyword("c"), nil)),
+			`{"a":1,"b":{"c":null}}`},
+		{"plist in alist mode", Options{},
+			skim.List(skim.Keyword("a"), skim.Int(1)),
+			`["a",1]`},
+		{"odd plist", Options{Objects: Plist},
+			skim.List(skim.Keyword("a"), skim.Int(1), skim.Keyword("b")),
+			`["a",1,"b"]`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := c.opts.ToJSONValue(c.in)
+			if err != nil {
+				t.Fatalf("ToJSONValue(%v) err = %v; want nil", c.in, err)
+			}
+			got, err := json.Marshal(v)
+			if err != nil {
+				t.Fatalf("Marshal(%#v) err = %v; want nil", v, err)
+			} else if string(got) != c.want {
+				t.Fatalf("ToJSONValue(%v) = %s; want %s", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestToJSONValueError(t *testing.T) {
+	cyclic := pair(skim.Int(1), nil)
+	cyclic.Cdr = cyclic
+	cases := []skim.Atom{
+		skim.Float(math.NaN()),
+		skim.Float(math.Inf(1)),
+		pair(skim.Int(1), skim.Int(2)),
+		skim.List(skim.Int(1), pair(skim.Int(1), skim.Int(2))),
+		skim.Char('x'),
+		cyclic,
+	}
+	for _, a := range cases {
+		if got, err := ToJSONValue(a); err == nil {
+			t.Errorf("ToJSONValue(%#v) = %#v; want error", a, got)
+		}
+	}
+}
+
+const nested = `{
+	"name": "svc",
+	"replicas": 3,
+	"ratio": 0.5,
+	"weight": 2.0,
+	"big": 9223372036854775807,
+	"bigger": 92233720368547758070,
+	"tags": ["a", 1, null, [true, {"k": "v"}], {}],
+	"limits": {"cpu": 1.5, "memory": null}
+}`
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("Decode(%s) err = %v", s, err)
+	}
+	return v
+}
+
+func TestFromJSONValue(t *testing.T) {
+	cases := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"alist", Options{},
+			`((big . 9223372036854775807) (bigger . 92233720368547758070) (limits (cpu . 1.5) (memory)) ` +
+				`(name . "svc") (ratio . 0.5) (replicas . 3) (tags "a" 1 #nil (#t {k "v"}) ()) (weight . 2.0))`},
+		{"plist", Options{Objects: Plist},
+			`(:big 9223372036854775807 :bigger 92233720368547758070 :limits (:cpu 1.5 :memory #nil) ` +
+				`:name "svc" :ratio 0.5 :replicas 3 :tags ("a" 1 #nil (#t (:k "v")) ()) :weight 2.0)`},
+		{"vectors", Options{Objects: Plist, Vectors: true},
+			`(:big 9223372036854775807 :bigger 92233720368547758070 :limits (:cpu 1.5 :memory #nil) ` +
+				`:name "svc" :ratio 0.5 :replicas 3 :tags ["a" 1 #nil [#t (:k "v")] ()] :weight 2.0)`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := c.opts.FromJSONValue(decode(t, nested))
+			if err != nil {
+				t.Fatalf("FromJSONValue() err = %v; want nil", err)
+			} else if got := a.String(); got != c.want {
+				t.Fatalf("FromJSONValue() = %s; want %s", got, c.want)
+			}
+		})
+	}
+}
+
+// TestFromJSONValueKeys checks that objects whose keys are not bare symbols or keywords are written
+// as text that reads back as the same atom.
+func TestFromJSONValueKeys(t *testing.T) {
+	in := map[string]interface{}{"first name": "x", "": 1, "a)": true}
+	cases := []struct {
+		opts Options
+		want string
+	}{
+		{Options{}, `{|| 1 |a)| #t |first name| "x"}`},
+		{Options{Objects: Plist}, `(:|| 1 :|a)| #t :|first name| "x")`},
+	}
+	for _, c := range cases {
+		a, err := c.opts.FromJSONValue(in)
+		if err != nil {
+			t.Fatalf("%+v: FromJSONValue() err = %v; want nil", c.opts, err)
+		} else if got := a.String(); got != c.want {
+			t.Fatalf("%+v: FromJSONValue() = %s; want %s", c.opts, got, c.want)
+		}
+		if got, err := parser.ReadString(a.String()); err != nil || len(got) != 1 || !reflect.DeepEqual(got[0], a) {
+			t.Errorf("%+v: Read(%s) = %#v, %v; want [%#v], nil", c.opts, a, got, err, a)
+		}
+	}
+}
+
+func TestFromJSONValueNumbers(t *testing.T) {
+	cases := []struct {
+		in   interface{}
+		want skim.Atom
+	}{
+		{json.Number("9007199254740993"), skim.Int(9007199254740993)},
+		{json.Number("-1"), skim.Int(-1)},
+		{json.Number("1.0"), skim.Float(1)},
+		{json.Number("1e3"), skim.Float(1000)},
+		{float64(2), skim.Float(2)},
+		{int64(2), skim.Int(2)},
+		{2, skim.Int(2)},
+	}
+	for _, c := range cases {
+		got, err := FromJSONValue(c.in)
+		if err != nil {
+			t.Errorf("FromJSONValue(%#v) err = %v; want nil", c.in, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("FromJSONValue(%#v) = %#v; want %#v", c.in, got, c.want)
+		}
+	}
+
+	if _, err := FromJSONValue(struct{}{}); err == nil {
+		t.Errorf("FromJSONValue(struct{}{}) err = nil; want error")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, opts := range []Options{{}, {Objects: Plist}, {Vectors: true}} {
+		want := decode(t, nested)
+		a, err := opts.FromJSONValue(want)
+		if err != nil {
+			t.Fatalf("%+v: FromJSONValue() err = %v; want nil", opts, err)
+		}
+		v, err := opts.ToJSONValue(a)
+		if err != nil {
+			t.Fatalf("%+v: ToJSONValue(%v) err = %v; want nil", opts, a, err)
+		}
+		got, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("%+v: Marshal() err = %v; want nil", opts, err)
+		}
+		// Empty objects are converted to empty lists, which are converted back to arrays, and
+		// integral floats are marshaled by encoding/json without a fraction.
+		wantJSON, _ := json.Marshal(want)
+		wantJSON = []byte(strings.NewReplacer("{}", "[]", "2.0", "2").Replace(string(wantJSON)))
+		if string(got) != string(wantJSON) {
+			t.Fatalf("%+v: round trip = %s; want %s", opts, got, wantJSON)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// FromYAML converts the YAML documents in data to an atom using the zero Options.
+func FromYAML(data []byte) (skim.Atom, error) {
+	return Options{}.FromYAML(data)
+}
+
+// ToYAML converts a to a YAML document using the zero Options.
+func ToYAML(a skim.Atom) ([]byte, error) {
+	return Options{}.ToYAML(a)
+}
+
+// FromYAML converts the YAML documents in data to an atom. If data holds a single document, its
+// atom is returned; if it holds more than one, a Vector of their atoms is returned. If data holds
+// no documents, FromYAML returns nil.
+//
+// Mappings are converted as described by o.Objects, with their keys in the order they are
+// written, and sequences to lists, or vectors if o.Vectors is set. Aliases are expanded, and merge
+// keys, <<, add the members of the mappings they name that are not already in the mapping.
+// Scalars are converted by their tag: !!null to nil, !!bool to Bool, !!int to Int or BigInt,
+// !!float to Float, unless it is an untagged integer too large for an Int, !!binary to Bytes, and
+// any other scalar to String.
+func (o Options) FromYAML(data []byte) (skim.Atom, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var docs skim.Vector
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		a, err := o.fromYAML(&doc, nil)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, a)
+	}
+	switch len(docs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return docs[0], nil
+	}
+	return docs, nil
+}
+
+// fromYAML converts n to an atom. The aliases being expanded are held by expanding, so that an
+// alias to a node containing it is an error rather than expanded forever.
+func (o Options) fromYAML(n *yaml.Node, expanding []*yaml.Node) (skim.Atom, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return o.fromYAML(n.Content[0], expanding)
+	case yaml.AliasNode:
+		expanding, err := expand(n, expanding)
+		if err != nil {
+			return nil, err
+		}
+		return o.fromYAML(n.Alias, expanding)
+	case yaml.SequenceNode:
+		elems := make([]skim.Atom, len(n.Content))
+		for i, elem := range n.Content {
+			a, err := o.fromYAML(elem, expanding)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = a
+		}
+		if o.Vectors {
+			return skim.Vector(elems), nil
+		}
+		return skim.List(elems...), nil
+	case yaml.MappingNode:
+		var m yamlMapping
+		if err := o.yamlMapping(&m, n, expanding); err != nil {
+			return nil, err
+		}
+		return o.object(m.keys, m.values)
+	case yaml.ScalarNode:
+		return yamlScalar(n)
+	}
+	return nil, fmt.Errorf("skim: cannot convert YAML node of kind %d at line %d", n.Kind, n.Line)
+}
+
+// yamlMapping holds the members of a YAML mapping in the order they are written.
+type yamlMapping struct {
+	keys   []string
+	values []skim.Atom
+	seen   map[string]bool
+}
+
+func (m *yamlMapping) add(key string, value skim.Atom) {
+	if m.seen == nil {
+		m.seen = make(map[string]bool)
+	}
+	if !m.seen[key] {
+		m.seen[key] = true
+		m.keys, m.values = append(m.keys, key), append(m.values, value)
+	}
+}
+
+// yamlMapping adds the members of the mapping n to m. The members of mappings named by merge keys
+// are added after the members written in n.
+func (o Options) yamlMapping(m *yamlMapping, n *yaml.Node, expanding []*yaml.Node) error {
+	var merges []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			merges = append(merges, value)
+			continue
+		} else if key.Kind != yaml.ScalarNode {
+			return fmt.Errorf("skim: cannot convert YAML mapping key at line %d: key is not a scalar", key.Line)
+		}
+		a, err := o.fromYAML(value, expanding)
+		if err != nil {
+			return err
+		}
+		m.add(key.Value, a)
+	}
+
+	for _, merge := range merges {
+		targets := []*yaml.Node{merge}
+		if merge.Kind == yaml.SequenceNode {
+			targets = merge.Content
+		}
+		for _, target := range targets {
+			expanding, err := expand(target, expanding)
+			if err != nil {
+				return err
+			}
+			if target.Kind == yaml.AliasNode {
+				target = target.Alias
+			}
+			if target.Kind != yaml.MappingNode {
+				return fmt.Errorf("skim: YAML merge key at line %d must name a mapping", merge.Line)
+			}
+			if err := o.yamlMapping(m, target, expanding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expand returns expanding with n added if n is an alias. It returns an error if n is already
+// being expanded.
+func expand(n *yaml.Node, expanding []*yaml.Node) ([]*yaml.Node, error) {
+	if n.Kind != yaml.AliasNode {
+		return expanding, nil
+	}
+	for _, alias := range expanding {
+		if alias == n {
+			return nil, fmt.Errorf("skim: recursive YAML alias *%s at line %d", n.Value, n.Line)
+		}
+	}
+	return append(expanding[:len(expanding):len(expanding)], n), nil
+}
+
+func yamlScalar(n *yaml.Node) (skim.Atom, error) {
+	switch n.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		if err := n.Decode(&b); err != nil {
+			return nil, err
+		}
+		return skim.Bool(b), nil
+	case "!!int":
+		var i int64
+		if err := n.Decode(&i); err == nil {
+			return skim.Int(i), nil
+		}
+		if b, ok := new(big.Int).SetString(strings.ReplaceAll(n.Value, "_", ""), 0); ok {
+			return skim.NewBigInt(b), nil
+		}
+		return nil, fmt.Errorf("skim: invalid YAML integer %q at line %d", n.Value, n.Line)
+	case "!!float":
+		// Integers too large for an int64 are resolved as floats unless tagged as such.
+		if n.Style&yaml.TaggedStyle == 0 && !strings.ContainsAny(n.Value, ".eE") {
+			if b, ok := new(big.Int).SetString(strings.ReplaceAll(n.Value, "_", ""), 0); ok {
+				return skim.NewBigInt(b), nil
+			}
+		}
+		var f float64
+		if err := n.Decode(&f); err != nil {
+			return nil, err
+		}
+		return skim.Float(f), nil
+	case "!!binary":
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(n.Value), ""))
+		if err != nil {
+			return nil, fmt.Errorf("skim: invalid YAML binary at line %d: %v", n.Line, err)
+		}
+		return skim.Bytes(b), nil
+	}
+	return skim.String(n.Value), nil
+}
+
+// ToYAML converts a to a YAML document.
+//
+// Lists that form objects, as described by o.Objects, are converted to mappings, with their keys
+// in order and only the first value of each key kept. Other lists and vectors are converted to
+// sequences. Strings, symbols, and keywords are converted to strings, keywords without their
+// colon, and strings holding newlines are written as literal block scalars. Int, BigInt, Float,
+// Bool, Bytes, and nil are converted to the scalars that FromYAML converts back to them. Improper
+// lists, cyclic atoms, and any other atom are errors.
+func (o Options) ToYAML(a skim.Atom) ([]byte, error) {
+	if skim.Cyclic(a) {
+		return nil, fmt.Errorf("skim: cannot convert cyclic %T to YAML", a)
+	}
+	n, err := o.toYAML(a)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (o Options) toYAML(a skim.Atom) (*yaml.Node, error) {
+	scalar := func(tag, value string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+	}
+	switch a := a.(type) {
+	case nil:
+		return scalar("!!null", "null"), nil
+	case skim.Int:
+		return scalar("!!int", strconv.FormatInt(int64(a), 10)), nil
+	case skim.BigInt:
+		return scalar("!!int", a.String()), nil
+	case skim.Float:
+		return scalar("!!float", yamlFloat(float64(a))), nil
+	case skim.Bool:
+		return scalar("!!bool", strconv.FormatBool(bool(a))), nil
+	case skim.Bytes:
+		return scalar("!!binary", base64.StdEncoding.EncodeToString(a)), nil
+	case skim.String:
+		n := scalar("!!str", string(a))
+		if strings.Contains(string(a), "\n") {
+			n.Style = yaml.LiteralStyle
+		}
+		return n, nil
+	case skim.Symbol:
+		return scalar("!!str", string(a)), nil
+	case skim.Keyword:
+		return scalar("!!str", string(a)), nil
+	case skim.Vector:
+		return o.yamlSequence(a)
+	case *skim.Cons:
+		if skim.IsNil(a) {
+			return o.yamlSequence(nil)
+		}
+		elems, err := listElems(a)
+		if err != nil {
+			return nil, err
+		}
+		if keys, values, ok := o.members(elems); ok {
+			n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for i, key := range keys {
+				v, err := o.toYAML(values[i])
+				if err != nil {
+					return nil, err
+				}
+				n.Content = append(n.Content, scalar("!!str", key), v)
+			}
+			return n, nil
+		}
+		return o.yamlSequence(elems)
+	}
+	return nil, fmt.Errorf("skim: cannot convert %T to YAML", a)
+}
+
+func (o Options) yamlSequence(elems []skim.Atom) (*yaml.Node, error) {
+	n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	if len(elems) == 0 {
+		n.Style = yaml.FlowStyle
+	}
+	for _, elem := range elems {
+		v, err := o.toYAML(elem)
+		if err != nil {
+			return nil, err
+		}
+		n.Content = append(n.Content, v)
+	}
+	return n, nil
+}
+
+// yamlFloat formats f as a YAML float. Integral floats keep a decimal point so that they are not
+// read back as integers.
+func yamlFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	case math.IsNaN(f):
+		return ".nan"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
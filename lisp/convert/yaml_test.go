@@ -0,0 +1,162 @@
+package convert
+
+import (
+	"reflect"
+	"testing"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+const deployYAML = `# Deploy configuration for the api service.
+defaults: &defaults
+  replicas: 2
+  debug: false
+  timeout: 1.5
+
+service:
+  name: api
+  <<: *defaults
+  replicas: 3
+  ports:
+    - 80
+    - 443
+  env:
+    - {name: MODE, value: prod}
+    - {name: EMPTY, value: ~}
+  tls: yes
+  script: |
+    set -e
+    ./api --serve
+  summary: >
+    folded
+    text
+  big: 123456789012345678901234567890
+`
+
+func TestFromYAML(t *testing.T) {
+	cases := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"alist", Options{},
+			`((defaults (replicas . 2) (debug . #f) (timeout . 1.5)) ` +
+				`(service (name . "api") (replicas . 3) (ports 80 443) ` +
+				`(env {name "MODE" value "prod"} ((name . "EMPTY") (value))) (tls . "yes") ` +
+				`(script . "set -e\n./api --serve\n") (summary . "folded text\n") ` +
+				`(big . 123456789012345678901234567890) (debug . #f) (timeout . 1.5)))`},
+		{"plist vectors", Options{Objects: Plist, Vectors: true},
+			`(:defaults (:replicas 2 :debug #f :timeout 1.5) ` +
+				`:service (:name "api" :replicas 3 :ports [80 443] ` +
+				`:env [(:name "MODE" :value "prod") (:name "EMPTY" :value #nil)] :tls "yes" ` +
+				`:script "set -e\n./api --serve\n" :summary "folded text\n" ` +
+				`:big 123456789012345678901234567890 :debug #f :timeout 1.5))`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := c.opts.FromYAML([]byte(deployYAML))
+			if err != nil {
+				t.Fatalf("FromYAML() err = %v; want nil", err)
+			} else if got := a.String(); got != c.want {
+				t.Fatalf("FromYAML() =\n%s\nwant\n%s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFromYAMLDocuments(t *testing.T) {
+	cases := []struct {
+		in   string
+		want skim.Atom
+	}{
+		{"", nil},
+		{"1\n", skim.Int(1)},
+		{"a\n---\n2.0\n---\n[true, null]\n", skim.Vector{skim.String("a"), skim.Float(2), skim.List(skim.Bool(true), nil)}},
+		{"!!binary aGk=\n", skim.Bytes("hi")},
+	}
+	for _, c := range cases {
+		got, err := FromYAML([]byte(c.in))
+		if err != nil {
+			t.Errorf("FromYAML(%q) err = %v; want nil", c.in, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("FromYAML(%q) = %#v; want %#v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestFromYAMLError(t *testing.T) {
+	for _, in := range []string{
+		"a: [1\n",
+		"? [a]\n: 1\n",
+		"a: &x {b: 1}\nc:\n  <<: 1\n",
+		"a: &x [*x]\n",
+	} {
+		if got, err := FromYAML([]byte(in)); err == nil {
+			t.Errorf("FromYAML(%q) = %v; want error", in, got)
+		}
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	a := skim.List(
+		pair(skim.Symbol("name"), skim.String("api")),
+		pair(skim.Symbol("replicas"), skim.Int(3)),
+		pair(skim.Symbol("weight"), skim.Float(2)),
+		pair(skim.Symbol("debug"), skim.Bool(false)),
+		pair(skim.Symbol("tls"), skim.String("yes")),
+		pair(skim.Symbol("empty"), nil),
+		skim.List(skim.Symbol("ports"), skim.Int(80), skim.Int(443)),
+		pair(skim.Symbol("none"), skim.Nil),
+		pair(skim.Symbol("script"), skim.String("set -e\n./api --serve\n")),
+		pair(skim.Symbol("name"), skim.String("ignored")),
+	)
+	const want = `name: api
+replicas: 3
+weight: 2.0
+debug: false
+tls: yes
+empty: null
+ports:
+  - 80
+  - 443
+none: []
+script: |
+  set -e
+  ./api --serve
+`
+	got, err := ToYAML(a)
+	if err != nil {
+		t.Fatalf("ToYAML() err = %v; want nil", err)
+	} else if string(got) != want {
+		t.Fatalf("ToYAML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestToYAMLError(t *testing.T) {
+	cyclic := pair(skim.Int(1), nil)
+	cyclic.Cdr = cyclic
+	for _, a := range []skim.Atom{pair(skim.Int(1), skim.Int(2)), skim.Char('x'), cyclic} {
+		if got, err := ToYAML(a); err == nil {
+			t.Errorf("ToYAML(%v) = %s; want error", a, got)
+		}
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	for _, opts := range []Options{{}, {Objects: Plist}, {Vectors: true}} {
+		want, err := opts.FromYAML([]byte(deployYAML))
+		if err != nil {
+			t.Fatalf("%+v: FromYAML() err = %v; want nil", opts, err)
+		}
+		data, err := opts.ToYAML(want)
+		if err != nil {
+			t.Fatalf("%+v: ToYAML(%v) err = %v; want nil", opts, want, err)
+		}
+		got, err := opts.FromYAML(data)
+		if err != nil {
+			t.Fatalf("%+v: FromYAML(%s) err = %v; want nil", opts, data, err)
+		} else if got.String() != want.String() {
+			t.Fatalf("%+v: round trip = %v; want %v\nYAML:\n%s", opts, got, want, data)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+// Package encoding implements a compact binary serialization of atoms, so that parsed documents
+// can be cached and read back without parsing their source again.
+//
+// An encoding begins with a version byte, Version, followed by the encoded atom. Each atom is
+// written as a tag byte followed by its value: integers as zig-zag varints, floats as their 64 IEEE
+// 754 bits, big integers as a sign byte and length-prefixed magnitude, and strings, symbols,
+// keywords, and bytes as length-prefixed bytes. Vectors are written as their length followed by
+// their elements, and lists as the number of conses in the list, their cars, and the cdr of the
+// last cons, so that improper lists keep their tail. The empty list, (), is a list of no conses, as is
+// a nil *skim.Cons, which is decoded as skim.Nil.
+//
+// Encoding is deterministic: an atom always produces the same bytes, so encodings may be hashed to
+// form cache keys.
+package encoding
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// Version is the version of the encoding written by Encode. Decode returns an error for any other
+// version.
+const Version = 1
+
+// maxDepth is the deepest nesting of vectors and lists that Encode writes and Decode reads.
+const maxDepth = 10000
+
+// chunkSize is the most memory allocated for a length before the data it describes has been read.
+const chunkSize = 1 << 12
+
+// Tags of encoded atoms.
+const (
+	tagNil byte = iota
+	tagFalse
+	tagTrue
+	tagInt
+	tagBigInt
+	tagFloat
+	tagRational
+	tagSymbol
+	tagKeyword
+	tagString
+	tagBytes
+	tagChar
+	tagVector
+	tagList
+)
+
+// ErrVersion is returned by Decode if the data was not written by this version of Encode.
+var ErrVersion = errors.New("skim: unsupported encoding version")
+
+// ErrDepth is returned by Encode if an atom nests vectors and lists too deeply to be decoded, and by
+// Decode if the data nests them too deeply.
+var ErrDepth = errors.New("skim: encoding nested too deeply")
+
+// Encode writes the encoding of a to w. Every atom of the skim package can be encoded; other atoms,
+// such as procedures, cyclic atoms, and atoms nesting vectors and lists more deeply than Decode
+// reads are errors.
+func Encode(w io.Writer, a skim.Atom) error {
+	if skim.Cyclic(a) {
+		return fmt.Errorf("skim: cannot encode cyclic %T", a)
+	}
+	e := &encoder{buf: []byte{Version}}
+	if err := e.atom(a, 0); err != nil {
+		return err
+	}
+	_, err := w.Write(e.buf)
+	return err
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uvarint(u uint64) {
+	e.buf = binary.AppendUvarint(e.buf, u)
+}
+
+func (e *encoder) bytes(tag byte, b []byte) {
+	e.buf = append(e.buf, tag)
+	e.uvarint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) bigInt(b *big.Int) {
+	sign := byte(0)
+	if b.Sign() < 0 {
+		sign = 1
+	}
+	e.buf = append(e.buf, sign)
+	mag := b.Bytes()
+	e.uvarint(uint64(len(mag)))
+	e.buf = append(e.buf, mag...)
+}
+
+func (e *encoder) atom(a skim.Atom, depth int) error {
+	switch a := a.(type) {
+	case nil:
+		e.buf = append(e.buf, tagNil)
+	case skim.Bool:
+		if a {
+			e.buf = append(e.buf, tagTrue)
+		} else {
+			e.buf = append(e.buf, tagFalse)
+		}
+	case skim.Int:
+		e.buf = append(e.buf, tagInt)
+		e.buf = binary.AppendVarint(e.buf, int64(a))
+	case skim.BigInt:
+		e.buf = append(e.buf, tagBigInt)
+		e.bigInt(a.Big())
+	case skim.Float:
+		e.buf = append(e.buf, tagFloat)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(float64(a)))
+	case skim.Rational:
+		r := a.Big()
+		e.buf = append(e.buf, tagRational)
+		e.bigInt(r.Num())
+		e.bigInt(r.Denom())
+	case skim.Symbol:
+		e.bytes(tagSymbol, []byte(a))
+	case skim.Keyword:
+		e.bytes(tagKeyword, []byte(a))
+	case skim.String:
+		e.bytes(tagString, []byte(a))
+	case skim.Bytes:
+		e.bytes(tagBytes, a)
+	case skim.Char:
+		e.buf = append(e.buf, tagChar)
+		e.uvarint(uint64(uint32(a)))
+	case skim.Vector:
+		if depth++; depth > maxDepth {
+			return ErrDepth
+		}
+		e.buf = append(e.buf, tagVector)
+		e.uvarint(uint64(len(a)))
+		for _, elem := range a {
+			if err := e.atom(elem, depth); err != nil {
+				return err
+			}
+		}
+	case *skim.Cons:
+		if depth++; depth > maxDepth {
+			return ErrDepth
+		}
+		return e.list(a, depth)
+	default:
+		return fmt.Errorf("skim: cannot encode %T", a)
+	}
+	return nil
+}
+
+// list writes the list c as the number of its conses, their cars, and the cdr of its last cons.
+// The empty list, (), has no conses, nor does a nil *skim.Cons.
+func (e *encoder) list(c *skim.Cons, depth int) error {
+	var (
+		cars []skim.Atom
+		tail skim.Atom
+	)
+	if !skim.IsNil(c) {
+		for {
+			cars = append(cars, c.Car)
+			next, ok := c.Cdr.(*skim.Cons)
+			if !ok || skim.IsNil(next) {
+				if !skim.IsNil(c.Cdr) {
+					tail = c.Cdr
+				}
+				break
+			}
+			c = next
+		}
+	}
+	e.buf = append(e.buf, tagList)
+	e.uvarint(uint64(len(cars)))
+	for _, car := range cars {
+		if err := e.atom(car, depth); err != nil {
+			return err
+		}
+	}
+	return e.atom(tail, depth)
+}
+
+// Decode reads an encoding written by Encode from r and returns its atom. If r is not an
+// io.ByteReader, Decode may read past the end of the encoding.
+//
+// Decode does not trust the lengths in the data it reads: memory is allocated as the data a length
+// describes is read, rather than for the whole length at once. Truncated data is an
+// io.ErrUnexpectedEOF, and malformed data is an error.
+func Decode(r io.Reader) (skim.Atom, error) {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	d := &decoder{r: br}
+	version, err := d.byte()
+	if err != nil {
+		return nil, err
+	} else if version != Version {
+		return nil, fmt.Errorf("%w %d", ErrVersion, version)
+	}
+	return d.atom(0)
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+type decoder struct {
+	r byteReader
+}
+
+// unexpected returns io.ErrUnexpectedEOF in place of io.EOF.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (d *decoder) byte() (byte, error) {
+	b, err := d.r.ReadByte()
+	return b, unexpected(err)
+}
+
+func (d *decoder) uvarint() (uint64, error) {
+	u, err := binary.ReadUvarint(d.r)
+	return u, unexpected(err)
+}
+
+// length reads a length that may be no more than max.
+func (d *decoder) length(max uint64) (int, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return 0, err
+	} else if n > max {
+		return 0, fmt.Errorf("skim: encoded length %d out of range", n)
+	}
+	return int(n), nil
+}
+
+// bytes reads a length-prefixed byte string. Its memory is allocated in chunks as it is read.
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.length(math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 0, min(n, chunkSize))
+	for len(b) < n {
+		m := len(b)
+		b = append(b, make([]byte, min(n-m, chunkSize))...)
+		if _, err := io.ReadFull(d.r, b[m:]); err != nil {
+			return nil, unexpected(err)
+		}
+	}
+	return b, nil
+}
+
+func (d *decoder) bigInt() (*big.Int, error) {
+	sign, err := d.byte()
+	if err != nil {
+		return nil, err
+	} else if sign > 1 {
+		return nil, fmt.Errorf("skim: invalid encoded sign %d", sign)
+	}
+	mag, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+	b := new(big.Int).SetBytes(mag)
+	if sign == 1 {
+		b.Neg(b)
+	}
+	return b, nil
+}
+
+func (d *decoder) atom(depth int) (skim.Atom, error) {
+	tag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case tagNil:
+		return nil, nil
+	case tagFalse:
+		return skim.Bool(false), nil
+	case tagTrue:
+		return skim.Bool(true), nil
+	case tagInt:
+		i, err := binary.ReadVarint(d.r)
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		return skim.Int(i), nil
+	case tagBigInt:
+		b, err := d.bigInt()
+		if err != nil {
+			return nil, err
+		}
+		return skim.NewBigInt(b), nil
+	case tagFloat:
+		var bits uint64
+		for i := 0; i < 64; i += 8 {
+			c, err := d.byte()
+			if err != nil {
+				return nil, err
+			}
+			bits |= uint64(c) << i
+		}
+		return skim.Float(math.Float64frombits(bits)), nil
+	case tagRational:
+		num, err := d.bigInt()
+		if err != nil {
+			return nil, err
+		}
+		denom, err := d.bigInt()
+		if err != nil {
+			return nil, err
+		} else if denom.Sign() == 0 {
+			return nil, errors.New("skim: encoded rational has a zero denominator")
+		}
+		return skim.NewRational(new(big.Rat).SetFrac(num, denom)), nil
+	case tagSymbol, tagKeyword, tagString, tagBytes:
+		b, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		switch tag {
+		case tagSymbol:
+			return skim.Symbol(b), nil
+		case tagKeyword:
+			return skim.Keyword(b), nil
+		case tagString:
+			return skim.String(b), nil
+		}
+		return skim.Bytes(b), nil
+	case tagChar:
+		r, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		} else if r > math.MaxUint32 {
+			return nil, fmt.Errorf("skim: encoded char %d out of range", r)
+		}
+		return skim.Char(rune(uint32(r))), nil
+	case tagVector:
+		return d.vector(depth + 1)
+	case tagList:
+		return d.list(depth + 1)
+	}
+	return nil, fmt.Errorf("skim: invalid encoded tag %d", tag)
+}
+
+func (d *decoder) elems(depth int) ([]skim.Atom, error) {
+	if depth > maxDepth {
+		return nil, ErrDepth
+	}
+	n, err := d.length(math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	elems := make([]skim.Atom, 0, min(n, chunkSize))
+	for len(elems) < n {
+		a, err := d.atom(depth)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, a)
+	}
+	return elems, nil
+}
+
+func (d *decoder) vector(depth int) (skim.Atom, error) {
+	elems, err := d.elems(depth)
+	if err != nil {
+		return nil, err
+	}
+	if elems == nil {
+		// Keep empty vectors non-nil, as they are when read from source.
+		elems = []skim.Atom{}
+	}
+	return skim.Vector(elems), nil
+}
+
+func (d *decoder) list(depth int) (skim.Atom, error) {
+	cars, err := d.elems(depth)
+	if err != nil {
+		return nil, err
+	}
+	tail, err := d.atom(depth)
+	if err != nil {
+		return nil, err
+	}
+	if len(cars) == 0 {
+		if tail != nil {
+			return nil, errors.New("skim: encoded list has a tail but no elements")
+		}
+		return skim.Nil, nil
+	}
+	conses := make([]skim.Cons, len(cars))
+	for i, car := range cars {
+		conses[i].Car = car
+		if i+1 < len(conses) {
+			conses[i].Cdr = &conses[i+1]
+		}
+	}
+	conses[len(conses)-1].Cdr = tail
+	return &conses[0], nil
+}
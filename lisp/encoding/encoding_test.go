@@ -0,0 +1,210 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func readCorpus(tb testing.TB) skim.Vector {
+	tb.Helper()
+	data, err := parser.ReadFile(filepath.Join("..", "printer", "testdata", "corpus.skim"))
+	if err != nil {
+		tb.Fatalf("ReadFile(corpus.skim) err = %v; want nil", err)
+	}
+	return data
+}
+
+func encode(tb testing.TB, a skim.Atom) []byte {
+	tb.Helper()
+	var buf bytes.Buffer
+	if err := Encode(&buf, a); err != nil {
+		tb.Fatalf("Encode(%v) err = %v; want nil", a, err)
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	cases := []skim.Atom{
+		nil,
+		skim.Bool(true),
+		skim.Bool(false),
+		skim.Int(math.MinInt64),
+		skim.NewBigInt(new(big.Int).Lsh(big.NewInt(-1), 100)),
+		skim.NewRational(big.NewRat(-3, 4)),
+		skim.Float(math.Inf(-1)),
+		skim.Float(math.Copysign(0, -1)),
+		skim.Float(math.NaN()),
+		skim.Symbol("sym bol"),
+		skim.Keyword("key"),
+		skim.String("str\x00ing"),
+		skim.Bytes{0, 1, 255},
+		skim.Char('λ'),
+		skim.Vector{},
+		skim.Vector{nil, skim.Nil, skim.Vector{}},
+		skim.Nil,
+		(*skim.Cons)(nil),
+		skim.List(nil, nil, (*skim.Cons)(nil)),
+		&skim.Cons{Car: skim.Int(1), Cdr: skim.Int(2)},
+		&skim.Cons{Car: skim.Int(1), Cdr: skim.Nil},
+		skim.List(skim.Symbol("a"), &skim.Cons{Car: skim.Vector{skim.Int(1)}, Cdr: skim.Symbol("b")}),
+		readCorpus(t),
+	}
+	for _, want := range cases {
+		data := encode(t, want)
+		if data[0] != Version {
+			t.Errorf("Encode(%v) version = %d; want %d", want, data[0], Version)
+		}
+		got, err := Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("Decode(Encode(%v)) err = %v; want nil", want, err)
+		} else if reflect.TypeOf(got) != reflect.TypeOf(want) || fmt.Sprint(got) != fmt.Sprint(want) {
+			// Atoms are compared by their printed form, since NaN is not equal to itself. Their
+			// structure is compared by encoding them again.
+			t.Errorf("Decode(Encode(%v)) = %v; want %v", want, got, want)
+		} else if again := encode(t, got); !bytes.Equal(again, data) {
+			t.Errorf("Encode(%v) = %x, then %x; want equal", want, data, again)
+		}
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	cyclic := &skim.Cons{Car: skim.Int(1)}
+	cyclic.Cdr = cyclic
+	proc := struct{ skim.Atom }{skim.Int(1)}
+	for _, a := range []skim.Atom{cyclic, skim.Vector{proc}, skim.List(skim.Int(1), proc)} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, a); err == nil {
+			t.Errorf("Encode(%T) err = nil; want error", a)
+		} else if buf.Len() != 0 {
+			t.Errorf("Encode(%T) wrote %d bytes; want 0", a, buf.Len())
+		}
+	}
+}
+
+// nest returns n lists or vectors, nested in one another, the innermost being empty.
+func nest(n int, vector bool) skim.Atom {
+	var a skim.Atom = skim.Nil
+	if vector {
+		a = skim.Vector{}
+	}
+	for i := 1; i < n; i++ {
+		if vector {
+			a = skim.Vector{a}
+		} else {
+			a = &skim.Cons{Car: a, Cdr: skim.Int(i)}
+		}
+	}
+	return a
+}
+
+func TestEncodeDepth(t *testing.T) {
+	for _, vector := range []bool{false, true} {
+		deepest := nest(maxDepth, vector)
+		if a, err := Decode(bytes.NewReader(encode(t, deepest))); err != nil {
+			t.Errorf("Decode(Encode(%d nested, vector=%t)) = %v, %v; want nil error", maxDepth, vector, a, err)
+		}
+		var buf bytes.Buffer
+		if err := Encode(&buf, nest(maxDepth+1, vector)); err != ErrDepth {
+			t.Errorf("Encode(%d nested, vector=%t) err = %v; want %v", maxDepth+1, vector, err, ErrDepth)
+		} else if buf.Len() != 0 {
+			t.Errorf("Encode(%d nested, vector=%t) wrote %d bytes; want 0", maxDepth+1, vector, buf.Len())
+		}
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	data := encode(t, readCorpus(t))
+	for i := 0; i < len(data); i++ {
+		if a, err := Decode(bytes.NewReader(data[:i])); err != io.ErrUnexpectedEOF {
+			t.Fatalf("Decode(data[:%d]) = %v, %v; want %v", i, a, err, io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	nested := append([]byte{Version}, bytes.Repeat([]byte{tagList, 1}, maxDepth+1)...)
+	cases := []struct {
+		name string
+		in   []byte
+		want error
+	}{
+		{"version", []byte{Version + 1, tagNil}, ErrVersion},
+		{"depth", nested, ErrDepth},
+		{"long vector", []byte{Version, tagVector, 0xff, 0xff, 0xff, 0xff, 0x07, tagNil}, io.ErrUnexpectedEOF},
+		{"long string", []byte{Version, tagString, 0xff, 0xff, 0xff, 0xff, 0x07, 'a'}, io.ErrUnexpectedEOF},
+		{"length out of range", []byte{Version, tagBytes, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, nil},
+		{"tag", []byte{Version, 0xff}, nil},
+		{"sign", []byte{Version, tagBigInt, 2, 0}, nil},
+		{"zero denominator", []byte{Version, tagRational, 0, 1, 1, 0, 0}, nil},
+		{"char", []byte{Version, tagChar, 0xff, 0xff, 0xff, 0xff, 0x7f}, nil},
+		{"tail without elements", []byte{Version, tagList, 0, tagTrue}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := Decode(bytes.NewReader(c.in))
+			if err == nil {
+				t.Fatalf("Decode(%x) = %v; want error", c.in, a)
+			} else if c.want != nil && !errors.Is(err, c.want) {
+				t.Fatalf("Decode(%x) err = %v; want %v", c.in, err, c.want)
+			}
+		})
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add(encode(f, readCorpus(f)))
+	f.Add([]byte{Version, tagList, 2, tagInt, 2, tagNil, tagSymbol, 1, 'a'})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		a, err := Decode(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		// Anything decoded must encode and decode to the same atom.
+		again, err := Decode(bytes.NewReader(encode(t, a)))
+		if err != nil {
+			t.Fatalf("Decode(Encode(%v)) err = %v; want nil", a, err)
+		} else if fmt.Sprint(again) != fmt.Sprint(a) {
+			t.Fatalf("Decode(Encode(%v)) = %v", a, again)
+		}
+	})
+}
+
+func BenchmarkDecode(b *testing.B) {
+	unit := "(define (f x y) (list 'a x `(b ,y) [1 2 3] \"str\" :key 1.5 12345678901234567890))\n"
+	text := []byte(strings.Repeat(unit, (1<<20)/len(unit)))
+	doc, err := parser.ReadBytes(text)
+	if err != nil {
+		b.Fatal(err)
+	}
+	data := encode(b, doc)
+
+	b.Run("parse", func(b *testing.B) {
+		b.SetBytes(int64(len(text)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := parser.ReadBytes(text); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("decode", func(b *testing.B) {
+		b.SetBytes(int64(len(text)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Decode(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -21,6 +21,9 @@ func (unbound) String() string { return "#unbound" }
 
 var Unbound = unbound{}
 
+// Context is a scope in which atoms are evaluated: a table of symbols bound to values, upvalues
+// private to the scope, and a parent scope in which symbols that the table does not bind are
+// resolved. A Context is safe for concurrent use.
 type Context struct {
 	up *Context
 
@@ -35,6 +38,60 @@ type Context struct {
 	// such, contexts do not inherit each others' upvalues.
 	upval map[string]interface{}
 	um    sync.RWMutex
+
+	// src is the source map used to attach positions to errors. If nil, the parent's is used.
+	src *skim.SourceMap
+
+	// truth is the policy deciding which atoms are false. If unset, the parent's is used.
+	truth Truthiness
+
+	// listArgs is whether a list may be the argument list of a lambda: 1 if it may, -1 if not. If
+	// zero, the parent's is used.
+	listArgs int8
+}
+
+// Truthiness is a policy deciding which atoms are false when tested by forms such as cond, and,
+// and or.
+type Truthiness uint8
+
+const (
+	truthInherit Truthiness = iota
+
+	// Loose treats nil, #f, and the empty list as false, and every other atom as true. It is the
+	// default.
+	Loose
+	// SchemeStrict treats only #f as false, as Scheme does, so that the empty list is true.
+	SchemeStrict
+)
+
+// PosError is an error raised while evaluating an expression whose source position is known.
+type PosError struct {
+	Pos skim.Pos
+	Err error
+}
+
+func (e *PosError) Error() string {
+	return e.Pos.String() + ": " + e.Err.Error()
+}
+
+// GoString returns e as a Go expression holding its position and the message of its error, rather
+// than the address of its error, so that it is the same every time a program runs.
+func (e *PosError) GoString() string {
+	return fmt.Sprintf("&interp.PosError{Pos:%#v, Err:errors.New(%q)}", e.Pos, e.Err.Error())
+}
+
+func (e *PosError) Unwrap() error {
+	return e.Err
+}
+
+// withPos returns err as a *PosError at pos. If err already holds a PosError, the innermost
+// position is kept and err is returned as-is.
+func withPos(err error, pos skim.Pos, ok bool) error {
+	var pe *PosError
+	if !ok || errors.As(err, &pe) {
+		return err
+	}
+	return &PosError{Pos: pos, Err: err}
 }
 
 func NewContext() *Context {
@@ -42,8 +99,13 @@ func NewContext() *Context {
 }
 
 // Dup clones a context, flattening it into a single Context of known bindings and c's upvalues.
+// Symbols occluded by Unbind remain occluded in the flattened Context, so a duplicate resolves the
+// same symbols as c regardless of the parent it is later overlaid on.
 func (c *Context) Dup() *Context {
 	base := NewContext()
+	base.src = c.SourceMap()
+	base.truth = c.truthiness()
+	base.listArgs = c.listArguments()
 	{ // Copy upper-most upvalues
 		table := base.upval
 		for k, v := range c.upval {
@@ -52,9 +114,7 @@ func (c *Context) Dup() *Context {
 	}
 	for table := base.table; c != nil; c = c.up {
 		for k, v := range c.table {
-			if v == Unbound {
-				continue
-			} else if _, set := table[k]; !set {
+			if _, set := table[k]; !set {
 				table[k] = v
 			}
 		}
@@ -70,13 +130,95 @@ func (c *Context) Fork() *Context {
 	}
 }
 
+// Overlay returns a flattened duplicate of c (see Dup) whose parent is the given context. Bindings
+// and occlusions in c take precedence over those of parent.
 func (c *Context) Overlay(parent *Context) *Context {
 	c = c.Dup()
 	c.up = parent
 	return c
 }
 
+// SetSourceMap sets the SourceMap used to attach source positions to errors raised by Eval in c and
+// its descendants. The SourceMap is typically filled in by the parser.
+func (c *Context) SetSourceMap(src *skim.SourceMap) *Context {
+	c.src = src
+	return c
+}
+
+// SourceMap returns the SourceMap used by c, if any.
+func (c *Context) SourceMap() *skim.SourceMap {
+	for ; c != nil; c = c.up {
+		if c.src != nil {
+			return c.src
+		}
+	}
+	return nil
+}
+
+// SetTruthiness sets the policy deciding which atoms are false in c and its descendants.
+func (c *Context) SetTruthiness(t Truthiness) *Context {
+	c.truth = t
+	return c
+}
+
+// Truthiness returns the policy deciding which atoms are false in c. It is Loose unless c or one of
+// its parents sets another.
+func (c *Context) Truthiness() Truthiness {
+	if t := c.truthiness(); t != truthInherit {
+		return t
+	}
+	return Loose
+}
+
+// SetListArguments sets whether lambda in c and its descendants takes a list of symbols followed by
+// a body, as in (lambda (x) x), as its argument list rather than as the first form of its body.
+// Programs read with parser.Options.BracketsAsLists need it, since their argument lists, [x], are
+// read as lists.
+func (c *Context) SetListArguments(on bool) *Context {
+	c.listArgs = -1
+	if on {
+		c.listArgs = 1
+	}
+	return c
+}
+
+// ListArguments returns whether lambda in c takes a list of symbols as its argument list. It is
+// false unless c or one of its parents sets it.
+func (c *Context) ListArguments() bool {
+	return c.listArguments() > 0
+}
+
+func (c *Context) listArguments() int8 {
+	for ; c != nil; c = c.up {
+		if c.listArgs != 0 {
+			return c.listArgs
+		}
+	}
+	return 0
+}
+
+// truthiness returns the Truthiness set by c or its nearest parent setting one, if any.
+func (c *Context) truthiness() Truthiness {
+	for ; c != nil; c = c.up {
+		if c.truth != truthInherit {
+			return c.truth
+		}
+	}
+	return truthInherit
+}
+
+// IsTrue returns whether a is true under c's Truthiness: under Loose, as skim.IsTrue decides, and
+// under SchemeStrict, if a is anything other than #f.
+func (c *Context) IsTrue(a skim.Atom) bool {
+	if c.Truthiness() == SchemeStrict {
+		return a != skim.Bool(false)
+	}
+	return skim.IsTrue(a)
+}
+
 func (c *Context) SetUpvalue(name string, val interface{}) *Context {
+	c.um.Lock()
+	defer c.um.Unlock()
 	if val != nil {
 		c.upval[name] = val
 	} else {
@@ -104,20 +246,33 @@ func (c *Context) Bind(name skim.Symbol, value skim.Atom) *Context {
 	return c
 }
 
+// BindProc binds name to proc as a NamedProc carrying name, so that the procedure is written with
+// its name rather than its address. A nil proc is bound as it is.
 func (c *Context) BindProc(name skim.Symbol, proc Proc) *Context {
-	return c.Bind(name, proc)
+	if proc == nil {
+		return c.Bind(name, proc)
+	}
+	return c.Bind(name, NamedProc{Name: name, Proc: proc})
+}
+
+// BindFunc binds name to a procedure calling the Go function fn, as returned by FuncProc. It panics
+// if fn is not a non-variadic function.
+func (c *Context) BindFunc(name skim.Symbol, fn interface{}) *Context {
+	return c.BindProc(name, FuncProc(fn))
 }
 
+// Unbind occludes name in c. Once unbound, name cannot be resolved from c or any of its
+// descendants until it is bound again, even if a parent of c binds it. Parents of c are unaffected.
+// It returns true if name was resolvable from c prior to unbinding it.
 func (c *Context) Unbind(name skim.Symbol) (ok bool) {
 	if c == nil {
 		return false
 	}
 
+	_, ok = c.Resolve(name)
 	c.tm.Lock()
 	defer c.tm.Unlock()
-	if _, ok = c.table[name]; ok {
-		c.table[name] = Unbound
-	}
+	c.table[name] = Unbound
 	return ok
 }
 
@@ -132,6 +287,8 @@ func resolveInTable(name skim.Symbol, table map[skim.Symbol]skim.Atom) (value sk
 	return value, bound, ok
 }
 
+// Resolve looks up the value bound to name in c and, failing that, in each of c's parents. The
+// search ends at the first context that either binds or unbinds name.
 func (c *Context) Resolve(name skim.Symbol) (value skim.Atom, ok bool) {
 	var bound bool
 	for ; c != nil; c = c.up {
@@ -160,10 +317,21 @@ func (c *Context) Eval(a skim.Atom) (result skim.Atom, err error) {
 			return nil, nil
 		}
 
+		src := c.SourceMap()
+		if src != nil {
+			defer func() {
+				if err != nil {
+					pos, ok := src.List(a)
+					err = withPos(err, pos, ok)
+				}
+			}()
+		}
+
 		var proc skim.Atom
 		proc, err = c.Eval(a.Car)
 		if err != nil {
-			return nil, err
+			pos, ok := src.Car(a)
+			return nil, withPos(err, pos, ok)
 		}
 
 		evaler, ok := proc.(Evaler)
@@ -172,7 +340,7 @@ func (c *Context) Eval(a skim.Atom) (result skim.Atom, err error) {
 		}
 
 		var argv *skim.Cons
-		if a.Cdr == nil {
+		if skim.IsNil(a.Cdr) {
 			// niladic procedure call (proc has to determine if this is valid)
 		} else if argv, ok = a.Cdr.(*skim.Cons); !ok {
 			return nil, errors.New("skim: ill-formed procedure call")
@@ -0,0 +1,211 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestContextUnbindOcclusion(t *testing.T) {
+	const x = skim.Symbol("x")
+
+	root := NewContext().Bind(x, skim.Int(1))
+	child := root.Fork()
+	grandchild := child.Fork()
+
+	if ok := child.Unbind(x); !ok {
+		t.Fatalf("child.Unbind(%v) = false; want true", x)
+	}
+
+	type testcase struct {
+		ctx  *Context
+		want bool
+	}
+	cases := map[string]testcase{
+		"root":                   {ctx: root, want: true},
+		"child":                  {ctx: child, want: false},
+		"grandchild":             {ctx: grandchild, want: false},
+		"child/dup":              {ctx: child.Dup(), want: false},
+		"grandchild/dup":         {ctx: grandchild.Dup(), want: false},
+		"child/overlay-root":     {ctx: child.Overlay(root), want: false},
+		"grandchild/overlay-nil": {ctx: grandchild.Overlay(nil), want: false},
+		"root/overlay-child":     {ctx: root.Overlay(child), want: true},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			if _, ok := c.ctx.Resolve(x); ok != c.want {
+				t.Fatalf("Resolve(%v) ok = %t; want %t", x, ok, c.want)
+			}
+		})
+	}
+}
+
+func TestContextRebindAfterUnbind(t *testing.T) {
+	const x = skim.Symbol("x")
+
+	root := NewContext().Bind(x, skim.Int(1))
+	child := root.Fork()
+	child.Unbind(x)
+	child.Bind(x, skim.Int(2))
+
+	for name, ctx := range map[string]*Context{"child": child, "dup": child.Dup()} {
+		if v, ok := ctx.Resolve(x); !ok || v != skim.Int(2) {
+			t.Errorf("%s: Resolve(%v) = %v, %t; want 2, true", name, x, v, ok)
+		}
+	}
+
+	if ok := NewContext().Unbind(x); ok {
+		t.Errorf("Unbind(%v) of unbound symbol = true; want false", x)
+	}
+}
+
+func TestContextUpvalueIsolation(t *testing.T) {
+	root := NewContext().SetUpvalue("k", 1)
+	child := root.Fork()
+
+	if v := child.Upvalue("k"); v != nil {
+		t.Errorf("child.Upvalue(k) = %v; want nil, as upvalues are not inherited", v)
+	}
+	child.SetUpvalue("k", 2)
+	if v := root.Upvalue("k"); v != 1 {
+		t.Errorf("root.Upvalue(k) = %v after setting child's; want 1", v)
+	}
+	if v := child.Dup().Upvalue("k"); v != 2 {
+		t.Errorf("child.Dup().Upvalue(k) = %v; want 2", v)
+	}
+	if v := root.Fork().Dup().Upvalue("k"); v != nil {
+		t.Errorf("root.Fork().Dup().Upvalue(k) = %v; want nil, as only the context's own are copied", v)
+	}
+
+	dup := child.Dup()
+	child.SetUpvalue("k", nil)
+	if v := child.Upvalue("k"); v != nil {
+		t.Errorf("child.Upvalue(k) = %v after setting it to nil; want nil", v)
+	} else if v := dup.Upvalue("k"); v != 2 {
+		t.Errorf("dup.Upvalue(k) = %v after deleting child's; want 2", v)
+	}
+	if v := (*Context)(nil).Upvalue("k"); v != nil {
+		t.Errorf("nil.Upvalue(k) = %v; want nil", v)
+	}
+}
+
+func TestContextEvalAtoms(t *testing.T) {
+	ctx := NewContext().Bind("x", skim.Int(1)).Fork()
+
+	// Atoms other than symbols and non-empty lists evaluate to themselves.
+	self := []skim.Atom{
+		nil,
+		skim.Int(1),
+		skim.Float(1.5),
+		skim.NewBigInt(new(big.Int).Lsh(big.NewInt(1), 70)),
+		skim.NewRational(big.NewRat(1, 2)),
+		skim.String("x"),
+		skim.Keyword("x"),
+		skim.Bool(true),
+		skim.Char('x'),
+		skim.Bytes("x"),
+		skim.Vector{skim.Symbol("x")},
+		Unbound,
+	}
+	for _, a := range self {
+		if got, err := ctx.Eval(a); err != nil || !reflect.DeepEqual(got, a) {
+			t.Errorf("Eval(%v) = %v, %v; want %v, nil", a, got, err, a)
+		}
+	}
+	if got, err := ctx.Eval((*skim.Cons)(nil)); err != nil || got != nil {
+		t.Errorf("Eval(nil *Cons) = %v, %v; want nil, nil", got, err)
+	}
+
+	if got, err := ctx.Eval(skim.Symbol("x")); err != nil || got != skim.Int(1) {
+		t.Errorf("Eval(x) = %v, %v; want 1, nil", got, err)
+	}
+	for _, a := range []skim.Atom{skim.Symbol("y"), skim.List(), skim.List(skim.Int(1))} {
+		if got, err := ctx.Eval(a); err == nil {
+			t.Errorf("Eval(%v) = %v, nil; want error", a, got)
+		}
+	}
+}
+
+func TestContextEvalKeyword(t *testing.T) {
+	ctx := NewContext().Bind("port", skim.Int(1))
+	want := skim.Keyword("port")
+	if got, err := ctx.Eval(want); err != nil || got != want {
+		t.Fatalf("Eval(%v) = %v, %v; want %v, nil", want, got, err, want)
+	}
+}
+
+func TestContextEvalPosition(t *testing.T) {
+	src := skim.NewSourceMap()
+	data, err := parser.Options{SourceMap: src}.ReadNamed("in.skim", strings.NewReader(
+		"(list 1\n  (list 2 undefined))\n(undefined 3)\n(list [(list (undefined))])"))
+	if err != nil {
+		t.Fatalf("Read(..) err = %v; want nil", err)
+	}
+
+	list := Proc(func(ctx *Context, form *skim.Cons) (skim.Atom, error) {
+		var out []skim.Atom
+		err := skim.Walk(form, func(a skim.Atom) error {
+			if vec, ok := a.(skim.Vector); ok {
+				for _, a := range vec {
+					if _, err := ctx.Eval(a); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			v, err := ctx.Eval(a)
+			out = append(out, v)
+			return err
+		})
+		return skim.List(out...), err
+	})
+	ctx := NewContext().SetSourceMap(src).BindProc("list", list).Fork()
+
+	for i, want := range []string{"in.skim:2:3", "in.skim:3:2", "in.skim:4:15"} {
+		_, err := ctx.Eval(data[i])
+		var pe *PosError
+		if !errors.As(err, &pe) {
+			t.Errorf("Eval(%v) err = (%T) %v; want *PosError", data[i], err, err)
+		} else if got := pe.Pos.String(); got != want {
+			t.Errorf("Eval(%v) err at %s; want %s", data[i], got, want)
+		}
+	}
+
+	// Without a source map, errors are unchanged.
+	_, err = NewContext().BindProc("list", list).Eval(data[0])
+	if _, ok := err.(*PosError); err == nil || ok {
+		t.Errorf("Eval(%v) err = (%T) %v; want an error without a position", data[0], err, err)
+	}
+}
+
+func BenchmarkContextResolve(b *testing.B) {
+	// Long names sharing a prefix make comparing their text, rather than their pointers, costly.
+	name := strings.Repeat("resolve-", 8) + "symbol"
+	root := NewContext()
+	for i := 0; i < 64; i++ {
+		root.Bind(skim.Intern(fmt.Sprint(name, i)), skim.Int(i))
+	}
+	ctx := root.Fork().Fork().Fork()
+
+	lookups := map[string]skim.Symbol{
+		"interned": skim.Intern(name + "0"),
+		"copied":   skim.Symbol(strings.Clone(name + "0")),
+	}
+	for bench, sym := range lookups {
+		b.Run(bench, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, ok := ctx.Resolve(sym); !ok {
+					b.Fatalf("%v is not bound", sym)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package interp
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// FuncProc returns a Proc that calls the Go function fn. Its arguments are evaluated and decoded
+// into fn's parameters by skim.Unmarshal, and fn's results are converted to atoms with the
+// MarshalOptions Foreign option set, so that values such as pointers are passed through programs
+// as *skim.Foreign atoms. If fn's last result is an error, a non-nil error is returned from the
+// Proc and the result is not converted. A function returning no other results returns nil, one
+// returning one result returns its atom, and one returning several returns a list of them.
+//
+// FuncProc panics if fn is not a function or is variadic.
+func FuncProc(fn interface{}) Proc {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func || ft.IsVariadic() {
+		panic(fmt.Sprintf("skim: FuncProc: %T is not a non-variadic function", fn))
+	}
+	nout := ft.NumOut()
+	hasErr := nout > 0 && ft.Out(nout-1) == errorType
+	if hasErr {
+		nout--
+	}
+
+	return func(ctx *Context, form *skim.Cons) (skim.Atom, error) {
+		args := make([]reflect.Value, 0, ft.NumIn())
+		err := skim.Walk(form, func(a skim.Atom) error {
+			i := len(args)
+			if i >= ft.NumIn() {
+				return fmt.Errorf("expected %d arguments; got more", ft.NumIn())
+			}
+			a, err := ctx.Eval(a)
+			if err != nil {
+				return err
+			}
+			arg := reflect.New(ft.In(i))
+			if err := skim.Unmarshal(a, arg.Interface()); err != nil {
+				return fmt.Errorf("argument %d: %w", i+1, err)
+			}
+			args = append(args, arg.Elem())
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		} else if len(args) != ft.NumIn() {
+			return nil, fmt.Errorf("expected %d arguments; got %d", ft.NumIn(), len(args))
+		}
+
+		out := fv.Call(args)
+		if hasErr {
+			if err, _ := out[nout].Interface().(error); err != nil {
+				return nil, err
+			}
+			out = out[:nout]
+		}
+
+		results := make([]skim.Atom, len(out))
+		for i, r := range out {
+			if results[i], err = (skim.MarshalOptions{Foreign: true}).Marshal(r.Interface()); err != nil {
+				return nil, err
+			}
+		}
+		switch len(results) {
+		case 0:
+			return nil, nil
+		case 1:
+			return results[0], nil
+		}
+		return skim.List(results...), nil
+	}
+}
@@ -0,0 +1,84 @@
+package interp
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestBindFunc(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := NewContext().
+		Bind("out", skim.NewForeign("out", &buf)).
+		BindFunc("write", func(w *bytes.Buffer, s string, n int) int {
+			for i := 0; i < n; i++ {
+				w.WriteString(s)
+			}
+			return w.Len()
+		}).
+		BindFunc("buffer", func() *bytes.Buffer { return &buf }).
+		BindFunc("fail", func(msg string) (int, error) { return 0, errors.New(msg) }).
+		BindFunc("pair", func(a, b int) (int, int, error) { return b, a, nil })
+
+	eval := func(in string) (skim.Atom, error) {
+		t.Helper()
+		data, err := parser.ReadString(in)
+		if err != nil || len(data) != 1 {
+			t.Fatalf("Read(%q) = %v, %v; want one form", in, data, err)
+		}
+		return ctx.Eval(data[0])
+	}
+
+	if got, err := eval(`(write out "ab" 2)`); err != nil || got != skim.Int(4) {
+		t.Errorf(`(write out "ab" 2) = %v, %v; want 4`, got, err)
+	}
+	if buf.String() != "abab" {
+		t.Errorf("buffer = %q; want %q", buf.String(), "abab")
+	}
+
+	// Unknown Go types are returned as foreign atoms, and can be passed back.
+	got, err := eval("(buffer)")
+	if w, ok := skim.ForeignValue[*bytes.Buffer](got); err != nil || !ok || w != &buf {
+		t.Errorf("(buffer) = %v, %v; want a foreign *bytes.Buffer", got, err)
+	}
+	if _, err := eval(`(write (buffer) "c" 1)`); err != nil || buf.String() != "ababc" {
+		t.Errorf(`(write (buffer) "c" 1) err = %v, buffer = %q; want nil, "ababc"`, err, buf.String())
+	}
+
+	if got, err := eval("(pair 1 2)"); err != nil || got.String() != "(2 1)" {
+		t.Errorf("(pair 1 2) = %v, %v; want (2 1)", got, err)
+	}
+	if _, err := eval(`(fail "boom")`); err == nil || err.Error() != "boom" {
+		t.Errorf(`(fail "boom") err = %v; want boom`, err)
+	}
+
+	errs := []struct {
+		in, want string
+	}{
+		{`(write out "ab")`, "expected 3 arguments; got 2"},
+		{`(write out "ab" 1 2)`, "expected 3 arguments; got more"},
+		{`(write "out" "ab" 1)`, "argument 1"},
+	}
+	for _, c := range errs {
+		if _, err := eval(c.in); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("Eval(%s) err = %v; want %q", c.in, err, c.want)
+		}
+	}
+}
+
+func TestFuncProcPanics(t *testing.T) {
+	for _, fn := range []interface{}{1, func(...int) {}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("FuncProc(%T) did not panic", fn)
+				}
+			}()
+			FuncProc(fn)
+		}()
+	}
+}
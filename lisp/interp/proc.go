@@ -11,16 +11,51 @@ type Proc func(*Context, *skim.Cons) (skim.Atom, error)
 var _ Evaler = Proc(nil)
 
 func (Proc) SkimAtom() {}
+
+// String returns the procedure's address, as #<procedure 0x...>. Procedures bound by BindProc are
+// NamedProcs, which are written with their names instead.
 func (p Proc) String() string {
 	if p == nil {
-		return "proc#nil"
+		return "#<procedure nil>"
 	}
-	return fmt.Sprintf("proc#%p", p)
+	return fmt.Sprintf("#<procedure %p>", p)
 }
 
+func (p Proc) GoString() string { return p.String() }
+
+// ProcName returns the empty symbol: a Proc has no name.
+func (Proc) ProcName() skim.Symbol { return "" }
+
 func (p Proc) Eval(ctx *Context, form *skim.Cons) (skim.Atom, error) {
 	if p == nil {
 		return nil, fmt.Errorf("skim: proc is nil")
 	}
 	return p(ctx, form)
 }
+
+// NamedProc is a Proc with a name. It is written as #<procedure name>, rather than with its
+// address, so that it prints the same way every time a program runs.
+type NamedProc struct {
+	Name skim.Symbol
+	Proc Proc
+}
+
+var _ Evaler = NamedProc{}
+
+func (NamedProc) SkimAtom() {}
+
+func (p NamedProc) String() string {
+	if p.Name == "" {
+		return p.Proc.String()
+	}
+	return "#<procedure " + p.Name.String() + ">"
+}
+
+func (p NamedProc) GoString() string { return p.String() }
+
+// ProcName returns the procedure's name.
+func (p NamedProc) ProcName() skim.Symbol { return p.Name }
+
+func (p NamedProc) Eval(ctx *Context, form *skim.Cons) (skim.Atom, error) {
+	return p.Proc.Eval(ctx, form)
+}
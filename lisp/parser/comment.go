@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"io"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// CommentKind is the kind of a Comment.
+type CommentKind int
+
+const (
+	// LineComment is a comment from a ; to the end of its line.
+	LineComment CommentKind = iota
+	// BlockComment is a comment between #| and |#, including any block comments nested within it.
+	BlockComment
+	// DatumComment is a #; and the datum following it.
+	DatumComment
+)
+
+func (k CommentKind) String() string {
+	switch k {
+	case LineComment:
+		return "line comment"
+	case BlockComment:
+		return "block comment"
+	case DatumComment:
+		return "datum comment"
+	}
+	return "unknown comment"
+}
+
+// Comment is the position of a comment in the input, passed to Options.Comments as comments are
+// read. The text of a comment is the input from Pos up to End, not including the newline ending a
+// line comment.
+type Comment struct {
+	Kind     CommentKind
+	Pos, End skim.Pos
+}
+
+// endPos returns the position just past the last rune read: that of the current rune, unless the
+// input is exhausted.
+func (d *decoder) endPos() skim.Pos {
+	if d.err == io.EOF {
+		return d.pos(d.line, d.col+1, d.offset+d.size)
+	}
+	return d.pos(d.line, d.col, d.offset)
+}
+
+// comment passes the comment of the given kind beginning at line, col, and offset and ending at the
+// current position to Options.Comments, if set.
+func (d *decoder) comment(kind CommentKind, line, col, offset int) {
+	if d.opts.Comments != nil {
+		d.opts.Comments(Comment{Kind: kind, Pos: d.pos(line, col, offset), End: d.endPos()})
+	}
+}
@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// DispatchFunc reads the syntax following a registered dispatch prefix and returns the datum it
+// denotes. It is called with the Decoder's current rune being the one following the prefix, and
+// may use the Decoder's ReadDatum, ReadString, ReadRune, PeekRune, and SkipSpace methods to read
+// further input.
+type DispatchFunc func(dec *Decoder) (skim.Atom, error)
+
+// RegisterDispatch registers fn to read the datum introduced by prefix, a token beginning with '#'
+// such as "#date". When the Decoder reads a token equal to prefix, it calls fn and reads the datum
+// it returns in place of the token. If fn is nil, any function registered for prefix is removed.
+//
+// Prefixes used by built-in syntax, such as #t, #f, #nil, #u8, character literals, datum labels,
+// and directives, cannot be registered. RegisterDispatch panics if prefix is reserved or is not a
+// '#' followed by one or more symbolic runes.
+func (dec *Decoder) RegisterDispatch(prefix string, fn DispatchFunc) {
+	if err := checkDispatch(prefix); err != nil {
+		panic(err)
+	}
+	d := &dec.d
+	if fn == nil {
+		delete(d.dispatch, prefix)
+		return
+	}
+	if d.dispatch == nil {
+		d.dispatch = make(map[string]DispatchFunc)
+	}
+	d.dispatch[prefix] = fn
+}
+
+// checkDispatch returns an error if prefix cannot be registered as a dispatch prefix.
+func checkDispatch(prefix string) error {
+	if len(prefix) < 2 || prefix[0] != '#' {
+		return fmt.Errorf("skim: dispatch prefix %q must begin with # and name the prefix", prefix)
+	}
+	for _, r := range prefix {
+		if isSymbolic(r) {
+			return fmt.Errorf("skim: dispatch prefix %q contains %q", prefix, r)
+		}
+	}
+	switch second := prefix[1]; {
+	case prefix == "#t", prefix == "#f", prefix == "#nil", prefix == "#u8",
+		second == '\\', second == '!', second == '|', second == ';', isDigit(second, 10):
+		return fmt.Errorf("skim: dispatch prefix %q is reserved", prefix)
+	}
+	return nil
+}
+
+// lookupDispatch returns the function registered for the token txt, if any.
+func (d *decoder) lookupDispatch(txt []byte) DispatchFunc {
+	if len(d.dispatch) == 0 || len(txt) < 2 || txt[0] != '#' {
+		return nil
+	}
+	if d.fold {
+		txt = bytes.ToLower(txt)
+	}
+	return d.dispatch[string(txt)]
+}
+
+// readDispatch calls fn to read the datum introduced by prefix, which began at the given position,
+// and assigns it. Errors returned by fn that are not a SyntaxError are positioned at the prefix.
+func (d *decoder) readDispatch(fn DispatchFunc, prefix string, line, col, offset int) (next nextfunc, err error) {
+	outer := d.macro
+	d.macro.prefix, d.macro.line, d.macro.col, d.macro.offset = prefix, line, col, offset
+	a, err := fn(d.owner)
+	d.macro = outer
+	if se, ok := err.(*SyntaxError); ok {
+		return nil, se
+	} else if err != nil {
+		se = d.syntaxerr(err, "in ", prefix)
+		se.Line, se.Col, se.Offset = line, col, offset
+		return nil, se
+	}
+	// Reading further data moves the token position past the prefix.
+	d.tok.line, d.tok.col, d.tok.offset = line, col, offset
+	return d.assign(a)
+}
+
+// readDatum reads a single datum for the dispatch function being called and returns it and its
+// position. The datum is read into a scope of its own, positioned at the dispatch prefix, so it is
+// returned rather than appended to the current scope.
+func (d *decoder) readDatum() (datum skim.Atom, pos skim.Pos, err error) {
+	if d.macro.prefix == "" {
+		return nil, pos, errNoDispatch
+	}
+	if err = d.skipSpace(true); err != nil && err != io.EOF {
+		return nil, pos, err
+	}
+	pos = d.pos(d.line, d.col, d.offset)
+	s, err := d.push(scopeQuoted, d.macro.prefix)
+	if err != nil {
+		return nil, pos, err
+	}
+	s.line, s.col, s.offset = d.macro.line, d.macro.col, d.macro.offset
+	done := false
+	s.capture = func(a skim.Atom) { datum, done = a, true }
+	for next := nextfunc(d.readSyntax); !done; {
+		if next, err = next(); err == io.EOF || (err == nil && next == nil) {
+			return nil, pos, d.unclosed()
+		} else if err != nil {
+			return nil, pos, err
+		}
+	}
+	return datum, pos, nil
+}
+
+var errNoDispatch = errors.New("skim: Decoder may only be read from by a DispatchFunc")
+
+// ReadDatum reads the next datum from the input. It may only be called by a DispatchFunc, and
+// returns a SyntaxError if the input ends before a datum is read.
+func (dec *Decoder) ReadDatum() (skim.Atom, error) {
+	a, _, err := dec.d.readDatum()
+	return a, err
+}
+
+// ReadString reads the next datum from the input, which must be a string. It may only be called by
+// a DispatchFunc.
+func (dec *Decoder) ReadString() (skim.String, error) {
+	d := &dec.d
+	a, pos, err := d.readDatum()
+	if err != nil {
+		return "", err
+	}
+	s, ok := a.(skim.String)
+	if !ok {
+		se := d.syntaxerr(fmt.Errorf("skim: expected a string after %s, got %v", d.macro.prefix, a))
+		se.Line, se.Col, se.Offset = pos.Line, pos.Col, pos.Offset
+		return "", se
+	}
+	return s, nil
+}
+
+// ReadRune reads and returns the current rune and its size in bytes, advancing past it. Runes read
+// this way are not parsed, so they may be used to implement syntax of a DispatchFunc's own. It
+// returns io.EOF once the input is exhausted.
+func (dec *Decoder) ReadRune() (r rune, size int, err error) {
+	d := &dec.d
+	if d.err != nil {
+		return 0, 0, d.err
+	}
+	r, size = d.current, d.size
+	d.nextRune() // Any error is returned by the next call.
+	return r, size, nil
+}
+
+// PeekRune returns the current rune without advancing past it. It returns io.EOF once the input
+// is exhausted.
+func (dec *Decoder) PeekRune() (rune, error) {
+	d := &dec.d
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.current, nil
+}
+
+// SkipSpace advances past any whitespace at the current rune. It may only be called by a
+// DispatchFunc.
+func (dec *Decoder) SkipSpace() error {
+	if err := dec.d.skipSpace(true); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
@@ -7,22 +7,93 @@ import (
 
 var ErrUnquoteContext = errors.New("use of unquote outside of quasiquote context")
 
+// ErrStop may be returned by the function passed to ReadFunc to stop reading without an error.
+var ErrStop = errors.New("skim: stop reading")
+
+// ErrUnclosedComment is set as the Err field of a SyntaxError when a block comment, #| ... |#, is
+// not closed before EOF. The SyntaxError's position is that of the comment's opening #|.
+var ErrUnclosedComment = errors.New("skim: unclosed block comment, expecting |#")
+
+// ErrInvalidUTF8 is set as the Err field of a SyntaxError when the input contains invalid UTF-8.
+// The SyntaxError's position is that of the first invalid byte.
+var ErrInvalidUTF8 = errors.New("skim: invalid UTF-8")
+
+// ErrOctalEscape is set as the Err field of a SyntaxError when an octal escape in a string or
+// pipe-quoted symbol, such as \400, is greater than \377.
+var ErrOctalEscape = errors.New("skim: octal escape out of range")
+
+// ErrDuplicateLabel is set as the Err field of a SyntaxError when a datum label, #N=, is defined
+// more than once in the same top-level datum.
+var ErrDuplicateLabel = errors.New("skim: duplicate datum label")
+
+// ErrDottedPair is set as the Err field of a SyntaxError when a dotted pair is malformed, such as
+// a dot with no preceding datum, no tail, or more than one datum following it.
+var ErrDottedPair = errors.New("skim: malformed dotted pair")
+
+// ErrTooLong is set as the Err field of a SyntaxError when a token or string exceeds the
+// MaxTokenBytes or MaxStringBytes options. The SyntaxError's position is that of the token's start.
+var ErrTooLong = errors.New("skim: token too long")
+
+// ErrMapKey is set as the Err field of a SyntaxError when a map, { ... }, has a key with no value.
+// The SyntaxError's position is that of the dangling key.
+var ErrMapKey = errors.New("skim: map key has no value")
+
+// ErrByte is set as the Err field of a SyntaxError when an element of a bytevector, #u8( ... ), is
+// not an integer from 0 to 255. The SyntaxError's position is that of the element.
+var ErrByte = errors.New("skim: invalid bytevector element")
+
 // SyntaxError is an error returned when the INI parser encounters any syntax it does not
-// understand. It contains the line, column, any other error encountered, and a description of the
-// syntax error.
+// understand. It contains the name of the input (if any), line, column, byte offset, any other
+// error encountered, and a description of the syntax error.
 type SyntaxError struct {
+	Name      string // Name of the input; empty if unnamed
 	Line, Col int
+	Offset    int // Byte offset of the rune where the error was found
 	Err       error
 	Desc      string
 }
 
 func (s *SyntaxError) Error() string {
+	if s.Name != "" {
+		if s.Desc == "" {
+			return fmt.Sprintf("%s:%d:%d: skim: syntax error: %v", s.Name, s.Line, s.Col, s.Err)
+		}
+		return fmt.Sprintf("%s:%d:%d: skim: syntax error: %v -- %s", s.Name, s.Line, s.Col, s.Err, s.Desc)
+	}
 	if s.Desc == "" {
 		return fmt.Sprintf("skim: syntax error at %d:%d: %v", s.Line, s.Col, s.Err)
 	}
 	return fmt.Sprintf("skim: syntax error at %d:%d: %v -- %s", s.Line, s.Col, s.Err, s.Desc)
 }
 
+// Unwrap returns the error wrapped by the SyntaxError, allowing use of errors.Is and errors.As.
+func (s *SyntaxError) Unwrap() error {
+	return s.Err
+}
+
+// MultiError is returned when reading with Options.Recover set and one or more syntax errors were
+// encountered. It holds each SyntaxError in the order it was encountered.
+type MultiError []*SyntaxError
+
+func (m MultiError) Error() string {
+	switch len(m) {
+	case 0:
+		return "skim: no errors"
+	case 1:
+		return m[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more errors)", m[0], len(m)-1)
+}
+
+// Unwrap returns the errors held by the MultiError, allowing use of errors.Is and errors.As.
+func (m MultiError) Unwrap() []error {
+	errs := make([]error, len(m))
+	for i, se := range m {
+		errs[i] = se
+	}
+	return errs
+}
+
 // UnclosedError is an error describing an unclosed bracket from {, (, [, and <. It is typically set
 // as the Err field of a SyntaxError.
 //
@@ -49,6 +120,64 @@ func (u UnclosedError) Error() string {
 	return fmt.Sprintf("skim: unclosed %c, expecting %c", rune(u), u.Expecting())
 }
 
+// UnbalancedError is an error describing a closing bracket, ), ], or }, that does not close the
+// current scope. It is typically set as the Err field of a SyntaxError.
+type UnbalancedError rune
+
+func (u UnbalancedError) Error() string {
+	return fmt.Sprintf("skim: unbalanced %c", rune(u))
+}
+
+// DepthError is an error describing input whose nesting exceeds the maximum depth, given by its
+// value. It is typically set as the Err field of a SyntaxError.
+type DepthError int
+
+func (e DepthError) Error() string {
+	return fmt.Sprintf("skim: maximum nesting depth %d exceeded", int(e))
+}
+
+// EscapeError is an error describing an unknown escape sequence in a string or pipe-quoted
+// symbol, such as \q. Its value is the rune following the backslash. It is typically set as the
+// Err field of a SyntaxError.
+type EscapeError rune
+
+func (e EscapeError) Error() string {
+	return fmt.Sprintf("skim: unknown escape sequence \\%c", rune(e))
+}
+
+// LabelError is an error describing a reference, #N#, to an undefined datum label. Its value is
+// the label. It is typically set as the Err field of a SyntaxError.
+type LabelError int
+
+func (e LabelError) Error() string {
+	return fmt.Sprintf("skim: undefined datum label #%d#", int(e))
+}
+
+// CharNameError is an error describing an unrecognized character name or code in a character
+// literal, such as #\bogus. It is typically set as the Err field of a SyntaxError.
+type CharNameError string
+
+func (e CharNameError) Error() string {
+	return fmt.Sprintf("skim: unknown character name %q", string(e))
+}
+
+// NumberError is an error describing a malformed number with radix or exactness prefixes, such as
+// #e#i1 or #xfg. It is typically set as the Err field of a SyntaxError.
+type NumberError string
+
+func (e NumberError) Error() string {
+	return fmt.Sprintf("skim: invalid number %q", string(e))
+}
+
+// ExtensionError is an error describing syntax that skim adds to Scheme, such as a heredoc, read
+// with Options.Strict set. Its value names the extension. It is typically set as the Err field of a
+// SyntaxError.
+type ExtensionError string
+
+func (e ExtensionError) Error() string {
+	return fmt.Sprintf("skim: %s is not allowed in strict mode", string(e))
+}
+
 // BadCharError is an error describing an invalid character encountered during parsing. It is
 // typically set as the Err field of a SyntaxError.
 type BadCharError rune
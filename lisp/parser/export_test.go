@@ -0,0 +1,13 @@
+package parser
+
+// ValidParseInputs returns the inputs in TestParse's table that are read without error, keyed by
+// name, for tests in package parser_test.
+func ValidParseInputs() map[string]string {
+	inputs := make(map[string]string)
+	for name, c := range parseCases() {
+		if !c.fail {
+			inputs[name] = c.in
+		}
+	}
+	return inputs
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// readHeredoc reads the body of a heredoc terminated by end. The heredoc's opening token, <<<END or
+// <<<~END, must already have been read and the current rune must be the newline following it.
+//
+// The heredoc ends at the first line consisting only of end, optionally followed by spaces and
+// tabs. The terminating line may also be followed by a closing ) or ], which is left to be read
+// as syntax. If squiggly is true, the terminator may be indented and the longest common leading
+// whitespace of the body's lines is removed from each line.
+func (d *decoder) readHeredoc(end []byte, squiggly bool) (skim.Atom, error) {
+	d.buffer.Reset()
+	max := d.opts.MaxStringBytes
+	for line := 0; ; {
+		r, _, err := d.nextRune()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if err == io.EOF || r == '\n' || r == rCloseParen || r == rCloseBracket {
+			if buf := d.buffer.Bytes(); isHeredocEnd(buf[line:], end, squiggly) {
+				buf = buf[:line]
+				if squiggly {
+					buf = dedent(buf)
+				}
+				return skim.String(buf), nil
+			} else if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+		}
+
+		d.buffer.WriteRune(r)
+		if r == '\n' {
+			line = d.buffer.Len()
+		}
+		// The line being read is not part of the string if it turns out to be the terminator, so
+		// the buffer may hold the terminator beyond the limit.
+		if max > 0 && d.buffer.Len() > max+len(end) {
+			return nil, errTooLong
+		}
+	}
+}
+
+// heredocNewline skips spaces and tabs following the heredoc opener, such as <<<END, and returns a
+// SyntaxError if they are not followed by a newline. The opener may be followed by any rune that
+// ends a token, such as a closing parenthesis, so this is only checked once the opener is read.
+func (d *decoder) heredocNewline(opener []byte) error {
+	if err := d.skipSpace(false); err != nil && err != io.EOF {
+		return err
+	} else if d.err == io.EOF {
+		return d.syntaxerr(io.ErrUnexpectedEOF, "expected a newline after ", string(opener))
+	} else if d.current != '\n' {
+		return d.syntaxerr(BadCharError(d.current), "expected a newline after ", string(opener))
+	}
+	return nil
+}
+
+// isHeredocEnd returns whether line is the terminator of a heredoc ending with end.
+func isHeredocEnd(line, end []byte, squiggly bool) bool {
+	line = bytes.TrimRight(line, " \t")
+	if squiggly {
+		line = bytes.TrimLeft(line, " \t")
+	}
+	return bytes.Equal(line, end)
+}
+
+// isIndent returns whether b consists only of spaces and tabs.
+func isIndent(b []byte) bool {
+	for _, c := range b {
+		if c != ' ' && c != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// dedent removes the longest common prefix of spaces and tabs from each line in body. Lines
+// consisting only of spaces and tabs do not affect the prefix, and are emptied if they are no
+// longer than it or if every line consists only of spaces and tabs. Spaces and tabs are distinct,
+// so a line indented by a tab and a line indented by spaces have no common prefix.
+func dedent(body []byte) []byte {
+	lines := bytes.SplitAfter(body, []byte{'\n'})
+
+	var prefix []byte
+	found := false
+	for _, line := range lines {
+		text := bytes.TrimSuffix(line, []byte{'\n'})
+		if isIndent(text) {
+			continue
+		}
+		indent := text[:len(text)-len(bytes.TrimLeft(text, " \t"))]
+		if !found {
+			prefix, found = indent, true
+			continue
+		}
+		n := 0
+		for n < len(prefix) && n < len(indent) && prefix[n] == indent[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+
+	out := make([]byte, 0, len(body))
+	for _, line := range lines {
+		text := bytes.TrimSuffix(line, []byte{'\n'})
+		switch {
+		case isIndent(text) && len(text) <= len(prefix), !found:
+			line = line[len(text):]
+		case bytes.HasPrefix(line, prefix):
+			line = line[len(prefix):]
+		}
+		out = append(out, line...)
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// labelRef is a placeholder for a reference to a datum label, #N#, whose datum has not been fully
+// read. Placeholders are replaced once the labeled datum is sealed.
+type labelRef int
+
+func (labelRef) SkimAtom()        {}
+func (l labelRef) String() string { return "#" + strconv.Itoa(int(l)) + "#" }
+
+// parseLabel parses a datum label definition, #N=, or reference, #N#, at the start of txt. It
+// returns the label, the length of the definition or reference, and whether it is a definition. If
+// txt does not begin with either, n is 0.
+func parseLabel(txt []byte) (label, n int, def bool) {
+	if len(txt) < 3 || txt[0] != '#' {
+		return 0, 0, false
+	}
+	end := 1
+	for end < len(txt) && isDigit(txt[end], 10) {
+		end++
+	}
+	if end == 1 || end == len(txt) || (txt[end] != '=' && txt[end] != '#') {
+		return 0, 0, false
+	}
+	label, err := strconv.Atoi(string(txt[1:end]))
+	if err != nil {
+		return 0, 0, false
+	}
+	return label, end + 1, txt[end] == '='
+}
+
+// readLabel begins a datum labeled by #N=. The datum is recorded under its label once sealed.
+func (d *decoder) readLabel(label int) (next nextfunc, err error) {
+	if _, ok := d.labels[label]; ok {
+		return nil, d.syntaxerr(ErrDuplicateLabel, fmt.Sprintf("#%d=", label))
+	}
+	s, err := d.push(scopeQuoted, fmt.Sprintf("#%d=", label))
+	if err != nil {
+		return nil, err
+	}
+	s.labeled, s.label = true, label
+	s.line, s.col, s.offset = d.tok.line, d.tok.col, d.tok.offset
+	if d.labels == nil {
+		d.labels = make(map[int]skim.Atom)
+	}
+	d.labels[label] = labelRef(label)
+	return d.readSyntax, nil
+}
+
+// readLabelRef assigns the datum referenced by #N#. If the datum is still being read, a
+// placeholder is assigned and replaced once the datum is sealed.
+func (d *decoder) readLabelRef(label int) (next nextfunc, err error) {
+	a, ok := d.labels[label]
+	if !ok {
+		return nil, d.syntaxerr(LabelError(label))
+	}
+	return d.assign(a)
+}
+
+// sealLabel records the datum of the labeled scope s and replaces any placeholders for it.
+func (d *decoder) sealLabel(s *scope) (skim.Atom, error) {
+	a := s.head.(*skim.Cons).Car
+	if a == labelRef(s.label) {
+		return nil, d.syntaxerr(LabelError(s.label), "datum label refers only to itself")
+	}
+	d.labels[s.label] = a
+	resolveLabel(a, labelRef(s.label), a, make(map[interface{}]bool))
+	return a, nil
+}
+
+// resolveLabel replaces each occurrence of ref in a with v. Cons pairs and vectors already in seen
+// are skipped, allowing a to contain cycles.
+func resolveLabel(a skim.Atom, ref labelRef, v skim.Atom, seen map[interface{}]bool) {
+	switch a := a.(type) {
+	case *skim.Cons:
+		if a == nil || seen[a] {
+			return
+		}
+		seen[a] = true
+		for _, p := range [...]*skim.Atom{&a.Car, &a.Cdr} {
+			if *p == ref {
+				*p = v
+			} else {
+				resolveLabel(*p, ref, v, seen)
+			}
+		}
+	case skim.Vector:
+		if len(a) == 0 || seen[&a[0]] {
+			return
+		}
+		seen[&a[0]] = true
+		for i := range a {
+			if a[i] == ref {
+				a[i] = v
+			} else {
+				resolveLabel(a[i], ref, v, seen)
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// parseInteger parses an unsigned integer in the given base, negating it if neg is true. Integers
+// that do not fit in an Int are returned as a BigInt.
+func parseInteger(txt string, base int, neg bool) (skim.Atom, bool) {
+	if integer, err := strconv.ParseUint(txt, base, 63); err == nil {
+		if neg {
+			return skim.Int(-int64(integer)), true
+		}
+		return skim.Int(integer), true
+	} else if ne, ok := err.(*strconv.NumError); !ok || ne.Err != strconv.ErrRange {
+		return nil, false
+	}
+
+	integer, ok := new(big.Int).SetString(txt, base)
+	if !ok {
+		return nil, false
+	} else if neg {
+		integer.Neg(integer)
+	}
+	return skim.NewInteger(integer), true
+}
+
+// digits returns the text of a numeric literal with any '_' digit separators removed. As in Go, a
+// separator must appear between two digits of the given base or, if prefixed is true, between the
+// literal's base prefix and its first digit. If a separator is misplaced, digits returns an empty
+// string.
+func digits(txt []byte, base int, prefixed bool) string {
+	if len(txt) > 0 && (txt[0] == '-' || txt[0] == '+') {
+		return "" // signs are handled by the caller
+	} else if bytes.IndexByte(txt, '_') == -1 {
+		return string(txt)
+	}
+
+	buf := make([]byte, 0, len(txt))
+	for i, c := range txt {
+		if c != '_' {
+			buf = append(buf, c)
+			continue
+		}
+
+		after := i == 0 && prefixed || i > 0 && isDigit(txt[i-1], base)
+		if !after || i+1 == len(txt) || !isDigit(txt[i+1], base) {
+			return ""
+		}
+	}
+	return string(buf)
+}
+
+func isDigit(c byte, base int) bool {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c-'0') < base
+	case c >= 'a' && c <= 'z':
+		return int(c-'a')+10 < base
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A')+10 < base
+	}
+	return false
+}
+
+// parseRational parses an unsigned ratio of two integers, num/den, in the given base, negating it
+// if neg is true. Ratios with a denominator of one are returned as integers.
+func parseRational(txt []byte, base int, neg bool) (skim.Atom, bool) {
+	i := bytes.IndexByte(txt, '/')
+	if i == -1 {
+		return nil, false
+	}
+
+	num, ok := new(big.Int).SetString(digits(txt[:i], base, false), base)
+	if !ok {
+		return nil, false
+	}
+	den, ok := new(big.Int).SetString(digits(txt[i+1:], base, false), base)
+	if !ok || den.Sign() == 0 {
+		return nil, false
+	}
+
+	ratio := new(big.Rat).SetFrac(num, den)
+	if neg {
+		ratio.Neg(ratio)
+	}
+	return skim.NewRational(ratio), true
+}
+
+// parseSpecialFloat parses the infinite and not-a-number floats, +inf.0, -inf.0, +nan.0, and
+// -nan.0.
+func parseSpecialFloat(txt []byte) (skim.Float, bool) {
+	switch string(txt) {
+	case "+inf.0":
+		return skim.Float(math.Inf(1)), true
+	case "-inf.0":
+		return skim.Float(math.Inf(-1)), true
+	case "+nan.0", "-nan.0":
+		return skim.Float(math.NaN()), true
+	}
+	return 0, false
+}
+
+// isDecimal returns true if txt consists only of runes permitted in a base 10 floating point
+// number, excluding its sign.
+func isDecimal(txt []byte) bool {
+	for _, c := range txt {
+		if !isDigit(c, 10) && !strings.ContainsRune("._eE+-", rune(c)) {
+			return false
+		}
+	}
+	return len(txt) > 0
+}
+
+// numberPrefix describes the Scheme-style radix and exactness prefixes of a number, such as the
+// #x and #e in #x#e10.
+type numberPrefix struct {
+	exactness byte // 'e', 'i', or 0 if unspecified
+	base      int
+	digits    []byte // the number following its prefixes
+	conflict  string // describes a duplicate or conflicting prefix, if any
+}
+
+func isNumberPrefix(c byte) bool {
+	return strings.IndexByte("eibodxEIBODX", c) != -1
+}
+
+// parseNumberPrefix parses the radix and exactness prefixes of txt. It returns false if txt does
+// not begin with prefixes followed by something resembling a number in the prefixed base.
+func parseNumberPrefix(txt []byte) (prefix numberPrefix, ok bool) {
+	var radix byte
+	prefix.base = 10
+	for len(txt) >= 2 && txt[0] == '#' && isNumberPrefix(txt[1]) {
+		c := txt[1] | 0x20 // lowercase
+		txt = txt[2:]
+
+		last := &radix
+		if c == 'e' || c == 'i' {
+			last = &prefix.exactness
+		}
+		if *last == c && prefix.conflict == "" {
+			prefix.conflict = fmt.Sprintf("duplicate prefix #%c", c)
+		} else if *last != 0 && prefix.conflict == "" {
+			prefix.conflict = fmt.Sprintf("prefix #%c conflicts with #%c", c, *last)
+		}
+		*last = c
+
+		switch c {
+		case 'b':
+			prefix.base = 2
+		case 'o':
+			prefix.base = 8
+		case 'd':
+			prefix.base = 10
+		case 'x':
+			prefix.base = 16
+		}
+	}
+
+	prefix.digits = txt
+	if len(txt) > 1 && (txt[0] == '-' || txt[0] == '+') {
+		txt = txt[1:]
+	}
+	return prefix, len(txt) > 0 && (txt[0] == '.' || isDigit(txt[0], prefix.base))
+}
+
+// readPrefixedNumber assigns the number in txt, given its parsed prefixes.
+func (d *decoder) readPrefixedNumber(txt []byte, prefix numberPrefix) (next nextfunc, err error) {
+	if prefix.conflict != "" {
+		return nil, d.syntaxerr(NumberError(txt), prefix.conflict)
+	}
+
+	num, neg := prefix.digits, false
+	if first := num[0]; first == '-' || first == '+' {
+		num, neg = num[1:], first == '-'
+	}
+
+	a, ok := parseInteger(digits(num, prefix.base, false), prefix.base, neg)
+	if !ok {
+		a, ok = parseRational(num, prefix.base, neg)
+	}
+	if !ok && prefix.base == 10 && isDecimal(num) {
+		if prefix.exactness == 'e' {
+			var ratio *big.Rat
+			if ratio, ok = new(big.Rat).SetString(digits(num, 10, false)); ok {
+				if neg {
+					ratio.Neg(ratio)
+				}
+				a = skim.NewRational(ratio)
+			}
+		} else if fp, err := strconv.ParseFloat(digits(num, 10, false), 64); err == nil {
+			if neg {
+				fp = -fp
+			}
+			a, ok = skim.Float(fp), true
+		}
+	}
+	if !ok {
+		return nil, d.syntaxerr(NumberError(txt), fmt.Sprintf("invalid number in base %d", prefix.base))
+	}
+
+	if n := a.(skim.Numeric); prefix.exactness == 'i' && n.Exact() {
+		fp, _ := n.Float64()
+		a = skim.Float(fp)
+	}
+	return d.assignNumber(num, a, "")
+}
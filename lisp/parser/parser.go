@@ -1,10 +1,12 @@
 package parser
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strconv"
 	"strings"
 	"unicode"
@@ -21,10 +23,40 @@ type scope struct {
 	newPair func() *skim.Cons
 	up      *scope
 	open    bool // if true, requires a closing parenthesis
+	discard bool // if true, the scope's datum is dropped when sealed
+	labeled bool // if true, the scope's datum is recorded under label when sealed
+	label   int
+	braced  bool // if true, the scope is a map whose keys and values are held in head as a Vector
+	bytes   bool // if true, the scope is a bytevector whose elements must be integers from 0 to 255
+	dot     dotState
 	head    skim.Atom
 	cdr     *skim.Atom
+	tail    *skim.Cons // the last pair of head, if it is a list
+
+	// Position of the rune(s) that opened the scope, used to report unclosed and mismatched
+	// scopes.
+	opener            string
+	line, col, offset int
+
+	// Positions of the elements of a map or bytevector scope, or of a vector scope if the decoder
+	// records them in a SourceMap.
+	elems []skim.Pos
+
+	// If set, the scope's datum is passed to capture when sealed instead of being appended to
+	// the parent scope. Used to read data for a DispatchFunc.
+	capture func(skim.Atom)
 }
 
+// dotState describes whether a list scope has encountered the dot of a dotted pair and, if so,
+// whether its tail has been read yet.
+type dotState int
+
+const (
+	dotNone    dotState = iota
+	dotPending          // read a '.', expecting the tail datum
+	dotDone             // read the tail datum, expecting a closing parenthesis
+)
+
 func newScope(up *scope, open bool, newPair func() *skim.Cons) *scope {
 	s := new(scope)
 	s.reset(up, open, newPair)
@@ -36,36 +68,52 @@ func (s *scope) reset(up *scope, open bool, newPair func() *skim.Cons) {
 		newPair: newPair,
 		up:      up,
 		open:    open,
+		discard: false,
+		dot:     dotNone,
 		head:    nil,
 		cdr:     &s.head,
+		elems:   s.elems[:0],
 	}
 }
 
 func (s *scope) cons() skim.Atom {
 	if s.head == nil {
-		return s.newPair()
+		return skim.Nil
 	}
 	return s.head
 }
 
-func (s *scope) append(tip skim.Atom) {
+// append appends tip to the scope's datum. If tip is held in a new cons pair, it returns the pair.
+func (s *scope) append(tip skim.Atom) *skim.Cons {
 	if v, ok := s.head.(skim.Vector); ok {
 		s.head = append(v, tip)
-		return
+		return nil
+	}
+	if s.dot == dotPending {
+		*s.cdr, s.cdr = tip, nil
+		s.dot = dotDone
+		return nil
 	}
 	next := s.newPair()
-	next.Car, *s.cdr, s.cdr = tip, next, &next.Cdr
+	next.Car, *s.cdr, s.cdr, s.tail = tip, next, &next.Cdr, next
+	return next
 }
 
 // decoder is a wrapper around an io.Reader for the purpose of doing by-rune parsing of input. It
 // also holds enough state to track line, column, key prefixes (from sections), and errors.
 type decoder struct {
+	opts     Options
+	name     string // name of the input, used in errors
 	rd       io.Reader
+	br       *bufio.Reader // buffer for readers that do not implement io.RuneReader
 	readrune func() (rune, int, error)
 
 	err       error
 	current   rune
 	line, col int
+	offset    int  // byte offset of the current rune
+	size      int  // size of the current rune in bytes
+	bom       bool // if true, the next rune may be a byte order mark
 
 	// Storage
 	buffer bytes.Buffer
@@ -74,14 +122,38 @@ type decoder struct {
 	// peek / next state
 	havenext bool
 	next     rune
+	nextsize int
 	nexterr  error
 
-	root scope
-	last *scope
+	root  scope
+	last  *scope
+	free  *scope // sealed scopes available for reuse, linked by up
+	depth int    // number of scopes above root
+
+	// Position of the current top-level datum
+	datum struct{ line, col, offset int }
+	// Position of the datum being read
+	tok struct{ line, col, offset int }
+
+	src *skim.SourceMap
+
+	labels map[int]skim.Atom // datum labels of the current top-level datum
+	fold   bool              // if true, symbols and character names are lowercased
+
+	errs []*SyntaxError // errors recovered from, if Options.Recover is set
+
+	owner    *Decoder
+	dispatch map[string]DispatchFunc // reader macros registered with RegisterDispatch
+	// Prefix and position of the reader macro being read, if any
+	macro struct {
+		prefix            string
+		line, col, offset int
+	}
 
 	pairbufSize int
 	pairbufHead int
 	pairbuf     []skim.Cons
+	isolate     bool // if true, top-level data never share a pair buffer
 }
 
 const (
@@ -91,10 +163,17 @@ const (
 	rCloseParen   = ')'
 	rOpenBracket  = '['
 	rCloseBracket = ']'
+	rOpenBrace    = '{'
+	rCloseBrace   = '}'
 	rString       = '"'
 	rQuote        = '\''
 	rBacktick     = '`'
 	rComma        = ','
+	rAt           = '@'
+	rHash         = '#'
+	rPipe         = '|'
+
+	byteOrderMark = '\uFEFF'
 )
 
 func (d *decoder) allocPair() *skim.Cons {
@@ -119,29 +198,80 @@ func (d *decoder) readSyntax() (next nextfunc, err error) {
 		return nil, d.err
 	}
 
+	if d.last == &d.root {
+		d.datum.line, d.datum.col, d.datum.offset = d.line, d.col, d.offset
+		d.labels = nil // Datum labels are local to a top-level datum
+		if d.isolate {
+			// Start a new pair buffer so that this datum does not keep earlier ones alive.
+			d.pairbufHead = len(d.pairbuf)
+		}
+	}
+
+	switch d.current {
+	case rComment:
+		return d.readComment()
+	case rHash:
+		switch r, _ := d.peekRune(); r {
+		case rPipe:
+			return d.readBlockComment()
+		case rComment:
+			return d.readDatumComment()
+		}
+	}
+
+	if d.last.dot == dotDone && d.current != rCloseParen && d.current != rCloseBracket {
+		return nil, d.syntaxerr(ErrDottedPair, "expected ) after the tail of a dotted pair")
+	}
+
 	d.buffer.Reset()
+	d.tok.line, d.tok.col, d.tok.offset = d.line, d.col, d.offset
 	switch d.current {
 	case rOpenParen:
 		return d.readList()
 	case rCloseParen:
 		return d.closeList()
-	case rComment:
-		return d.readComment()
 	case rQuote, rBacktick, rComma:
 		return d.readLiteral()
 	case rString:
 		return d.readString()
+	case rPipe:
+		return d.readPipeSymbol(false)
 	case rOpenBracket:
 		return d.readVector()
 	case rCloseBracket:
 		return d.closeVector()
+	case rOpenBrace:
+		return d.readMap()
+	case rCloseBrace:
+		return d.closeMap()
 	default:
+		if r, _ := d.peekRune(); d.current == ':' && r == rPipe {
+			return d.readPipeSymbol(true)
+		}
 		return d.readSymbol()
 	}
 
 	return nil, d.syntaxerr(BadCharError(d.current))
 }
 
+// readOctalCode reads an octal escape of up to three digits, the first of which is first. The
+// value of the escape must fit in a byte.
+func (d *decoder) readOctalCode(first rune) (result rune, err error) {
+	result = first - '0'
+	for i := 1; i < 3; i++ {
+		r, err := d.peekRune()
+		if err != nil || r < '0' || r > '7' {
+			break // Any error is returned by the next read
+		}
+		d.skip()
+		result = result<<3 | (r - '0')
+	}
+	if result > 0377 {
+		return -1, d.syntaxerr(ErrOctalEscape, fmt.Sprintf("\\%o", result))
+	}
+	return result, nil
+}
+
 func (d *decoder) readHexCode(size int) (result rune, err error) {
 	for i := 0; i < size; i++ {
 		r, sz, err := d.nextRune()
@@ -170,47 +300,121 @@ func (d *decoder) readHexCode(size int) (result rune, err error) {
 }
 
 func (d *decoder) readString() (next nextfunc, err error) {
-	err = d.readUntilBuffer(runestr(`"\`))
-	if err == io.EOF {
-		return nil, d.syntaxerr(UnclosedError('"'), "encountered EOF inside string")
-	} else if err != nil {
+	line, col, offset := d.line, d.col, d.offset
+	for {
+		if err = d.readUntilBuffer(runestr(`"\`), d.opts.MaxStringBytes); err == nil {
+			if d.current == rString {
+				break
+			}
+			err = d.readEscape(rString)
+		}
+
+		if err == io.EOF {
+			se := d.syntaxerr(UnclosedError('"'), "encountered EOF inside string")
+			se.Line, se.Col, se.Offset = line, col, offset
+			return nil, se
+		} else if err == errTooLong {
+			return nil, d.tooLong("string literal", d.opts.MaxStringBytes, line, col, offset)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	str := skim.String(d.buffer.String())
+	if err = d.skip(); err != nil && err != io.EOF {
 		return nil, err
 	}
+	return d.assign(str)
+}
 
-	switch d.current {
-	case '"':
-		// done
+// readEscape reads the escape sequence following a backslash in a string or pipe-quoted symbol and
+// writes the rune or byte it represents to the buffer. delim is the rune closing the string or
+// symbol, which may always be escaped.
+func (d *decoder) readEscape(delim rune) error {
+	line, col, offset := d.line, d.col, d.offset
+	r, _, err := d.nextRune()
+	if err != nil {
+		return err
+	}
+	switch r {
+	case 'x': // 1 octet
+		r, err = d.readHexCode(2)
+		d.buffer.WriteByte(byte(r & 0xFF))
+	case 'u': // 2 octets
+		r, err = d.readHexCode(4)
+		d.buffer.WriteRune(r)
+	case 'U': // 4 octets
+		r, err = d.readHexCode(8)
+		d.buffer.WriteRune(r)
+	case '0', '1', '2', '3', '4', '5', '6', '7': // 1 octet
+		if r, err = d.readOctalCode(r); err != nil {
+			se, ok := err.(*SyntaxError)
+			if !ok {
+				return err
+			}
+			se.Line, se.Col, se.Offset = line, col, offset
+			if !d.tolerate(se) {
+				return err
+			}
+			return nil
+		}
+		d.buffer.WriteByte(byte(r))
+	default:
+		er, ok := escaped(r)
+		if !ok && r != delim && d.opts.StrictEscapes {
+			se := d.syntaxerr(EscapeError(r))
+			se.Line, se.Col, se.Offset = line, col, offset
+			if !d.tolerate(se) {
+				return se
+			}
+		}
+		d.buffer.WriteRune(er)
+	}
+	return err
+}
 
-	case '\\':
-		r, _, err := d.nextRune()
-		if err != nil {
+// readPipeSymbol reads a symbol quoted by pipes, such as |foo bar|. Pipe-quoted symbols may contain
+// any rune and the same escape sequences as strings. If keyword is true, the current rune is the
+// colon of a keyword quoted the same way, such as :|foo bar|.
+func (d *decoder) readPipeSymbol(keyword bool) (next nextfunc, err error) {
+	line, col, offset := d.line, d.col, d.offset
+	if keyword {
+		if err = d.skip(); err != nil {
 			return nil, err
 		}
-		switch r {
-		case 'x': // 1 octet
-			r, err = d.readHexCode(2)
-			d.buffer.WriteByte(byte(r & 0xFF))
-		case 'u': // 2 octets
-			r, err = d.readHexCode(4)
-			d.buffer.WriteRune(r)
-		case 'U': // 4 octets
-			r, err = d.readHexCode(8)
-			d.buffer.WriteRune(r)
-		default:
-			d.buffer.WriteRune(escaped(r))
-		}
-		return d.readString, err
 	}
+	for {
+		if err = d.readUntilBuffer(runestr(`|\`), d.opts.MaxTokenBytes); err == nil {
+			if d.current == rPipe {
+				break
+			}
+			err = d.readEscape(rPipe)
+		}
 
-	d.last.append(skim.String(d.buffer.String()))
+		if err == io.EOF {
+			se := d.syntaxerr(UnclosedError(rPipe), "encountered EOF inside symbol")
+			se.Line, se.Col, se.Offset = line, col, offset
+			return nil, se
+		} else if err == errTooLong {
+			return nil, d.tooLong("symbol", d.opts.MaxTokenBytes, line, col, offset)
+		} else if err != nil {
+			return nil, err
+		}
+	}
 
-	if err = d.skip(); err == io.EOF {
-		return nil, nil
+	var a skim.Atom
+	if keyword {
+		a = skim.Keyword(d.buffer.Bytes())
+	} else {
+		a = d.symbol(d.buffer.Bytes())
 	}
-	return d.readSyntax, err
+	if err = d.skip(); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return d.assign(a)
 }
 
-var sentinelRunes = runestr("()[]'\",`;")
+var sentinelRunes = runestr("()[]{}'\",`;")
 
 func isSymbolic(r rune) bool {
 	return unicode.IsSpace(r) || sentinelRunes.Contains(r)
@@ -218,40 +422,168 @@ func isSymbolic(r rune) bool {
 
 func (d *decoder) seal(force bool) (nextfunc, error) {
 	for ; force || (d.last.up != nil && !d.last.open); force = false {
-		a := d.last.cons()
-		if a != nil {
-			d.last.up.append(a)
+		s := d.last
+		d.last = s.up
+		d.depth--
+		if s.discard {
+			d.comment(DatumComment, s.line, s.col, s.offset)
+			// The parent scope received no datum, so it cannot be sealed yet either.
+			d.release(s)
+			break
+		} else if s.capture != nil {
+			// The datum belongs to a dispatch function, which assigns its own result to the
+			// parent scope.
+			s.capture(s.head.(*skim.Cons).Car)
+			d.release(s)
+			break
+		} else if s.labeled {
+			a, err := d.sealLabel(s)
+			if err != nil {
+				return nil, err
+			}
+			pos := d.pos(s.line, s.col, s.offset)
+			d.span(a, pos)
+			d.append(d.last, a, pos)
+		} else if s.bytes {
+			a, err := d.bytevector(s)
+			if err != nil {
+				return nil, err
+			}
+			pos := d.pos(s.line, s.col, s.offset)
+			d.span(a, pos)
+			d.append(d.last, a, pos)
+		} else if s.braced {
+			a := d.alist(s)
+			if d.src != nil {
+				d.src.SetList(a, d.pos(s.line, s.col, s.offset))
+				d.src.SetEnd(a, d.endPos())
+			}
+			d.append(d.last, a, d.pos(s.line, s.col, s.offset))
+		} else if a := s.cons(); a != nil {
+			pos := d.pos(s.line, s.col, s.offset)
+			if cons, ok := a.(*skim.Cons); ok && d.src != nil {
+				d.src.SetList(cons, pos)
+				d.src.SetEnd(cons, d.endPos())
+			} else if v, ok := a.(skim.Vector); ok && d.src != nil {
+				for i := range v {
+					d.src.SetElem(v, i, s.elems[i])
+				}
+			}
+			d.append(d.last, a, pos)
 		}
-		d.last = d.last.up
+		d.release(s)
 	}
 
 	return d.readSyntax, nil
 }
 
-func (d *decoder) close() (nextfunc, error) {
+// release returns the sealed scope s to the decoder's free list. The scope's datum has already
+// been appended to its parent, so only the scope itself is reused.
+func (d *decoder) release(s *scope) {
+	s.head, s.cdr, s.up, d.free = nil, nil, d.free, s
+}
+
+func (d *decoder) close(closer rune) (nextfunc, error) {
 	if d.last.up == nil {
-		return nil, d.syntaxerr(errors.New("cannot close current scope"))
+		return d.stray(closer)
 	}
 	return d.seal(true)
 }
 
+// symbol returns the symbol whose text is b, interned if Options.InternSymbols is set.
+func (d *decoder) symbol(b []byte) skim.Symbol {
+	if d.opts.InternSymbols {
+		return skim.InternBytes(b)
+	}
+	return skim.Symbol(b)
+}
+
 func (d *decoder) assign(a skim.Atom) (nextfunc, error) {
-	d.last.append(a)
+	pos := d.pos(d.tok.line, d.tok.col, d.tok.offset)
+	d.span(a, pos)
+	d.append(d.last, a, pos)
 	return d.seal(false)
 }
 
+// append appends a, read at pos, to the scope s and records its position in the decoder's
+// SourceMap, if any.
+func (d *decoder) append(s *scope, a skim.Atom, pos skim.Pos) {
+	if _, vec := s.head.(skim.Vector); s.braced || s.bytes || vec && d.src != nil {
+		s.elems = append(s.elems, pos)
+	}
+	dotted := s.dot == dotPending
+	cons := s.append(a)
+	switch {
+	case d.src == nil:
+	case cons != nil:
+		d.src.SetCar(cons, pos)
+	case dotted:
+		d.src.SetCdr(s.tail, pos)
+	}
+}
+
+// span records in the decoder's SourceMap, if any, that the text of a, which began at pos, ends at
+// the current position. Nothing is recorded for lists and vectors.
+func (d *decoder) span(a skim.Atom, pos skim.Pos) {
+	switch a.(type) {
+	case *skim.Cons, skim.Vector:
+		return
+	}
+	if d.src != nil {
+		d.src.SetSpan(pos, d.endPos())
+	}
+}
+
+func (d *decoder) pos(line, col, offset int) skim.Pos {
+	return skim.Pos{Name: d.name, Line: line, Col: col, Offset: offset}
+}
+
 func (d *decoder) readSymbol() (next nextfunc, err error) {
+	line, col, offset := d.line, d.col, d.offset
 	d.buffer.WriteRune(d.current)
-	err = d.readUntilBuffer(runeFunc(isSymbolic))
+	err = d.readUntilBuffer(runeFunc(isSymbolic), d.opts.MaxTokenBytes)
 	if err == io.EOF {
 		err = nil // handle it next time around
+	} else if err == errTooLong {
+		return nil, d.tooLong("token", d.opts.MaxTokenBytes, line, col, offset)
 	} else if err != nil {
 		return nil, err
 	}
 
+	if fn := d.lookupDispatch(d.buffer.Bytes()); fn != nil {
+		return d.readDispatch(fn, d.buffer.String(), line, col, offset)
+	}
+
+	// Report malformed tokens at their first rune rather than the rune following them.
+	defer func() {
+		if se, ok := err.(*SyntaxError); ok {
+			se.Line, se.Col, se.Offset = line, col, offset
+			if malformed(se.Err) && d.tolerate(se) {
+				// Drop the token and continue with the next.
+				next, err = d.readSyntax, nil
+			}
+		}
+	}()
+
 	txt := d.buffer.Bytes()
 
+	// Datum labels, #N=, may directly precede the datum they label.
+	for {
+		label, n, def := parseLabel(txt)
+		if !def {
+			break
+		} else if _, err = d.readLabel(label); err != nil {
+			return nil, err
+		}
+		if txt = txt[n:]; len(txt) == 0 {
+			return d.readSyntax, nil
+		}
+	}
+
 	// Try numbers
+	if f, ok := parseSpecialFloat(txt); ok {
+		return d.assign(f)
+	}
 	{
 		var (
 			n     = len(txt)
@@ -272,16 +604,27 @@ func (d *decoder) readSymbol() (next nextfunc, err error) {
 		if first == '.' {
 			goto float
 		} else if zero && n > 1 {
-			var integer int64
 			switch second := txt[1]; second {
 			case 'x': // hex (16)
-				if integer, err = strconv.ParseInt(string(txt[2:]), 16, 64); err == nil {
-					break
+				if integer, ok := parseInteger(digits(txt[2:], 16, true), 16, neg); ok {
+					return d.assignNumber(txt, integer, "0x literal")
+				}
+				// Hex floats require a binary exponent, as in 0x1.8p1.
+				if fp, err := strconv.ParseFloat(string(txt), 64); err == nil {
+					if neg {
+						fp = -fp
+					}
+					return d.assignNumber(txt, skim.Float(fp), "0x literal")
+				}
+				goto symbol
+			case 'b': // binary (2)
+				if integer, ok := parseInteger(digits(txt[2:], 2, true), 2, neg); ok {
+					return d.assignNumber(txt, integer, "0b literal")
 				}
 				goto symbol
-			case '0', '1', '2', '3', '4', '5', '6', '7': // octal (8)
-				if integer, err = strconv.ParseInt(string(txt[1:]), 8, 64); err == nil {
-					break
+			case '0', '1', '2', '3', '4', '5', '6', '7', '_': // octal (8)
+				if integer, ok := parseInteger(digits(txt[1:], 8, true), 8, neg); ok {
+					return d.assignNumber(txt, integer, "leading-zero octal literal")
 				}
 				goto integer
 			case '8', '9':
@@ -291,11 +634,6 @@ func (d *decoder) readSymbol() (next nextfunc, err error) {
 			default:
 				goto symbol
 			}
-
-			if neg {
-				integer = -integer
-			}
-			return d.assign(skim.Int(integer))
 		} else if zero {
 			return d.assign(skim.Int(0))
 		}
@@ -305,26 +643,55 @@ func (d *decoder) readSymbol() (next nextfunc, err error) {
 			goto symbol
 		}
 
-		if integer, err := strconv.ParseInt(string(txt), 10, 64); err == nil {
-			if neg {
-				integer = -integer
-			}
-			return d.assign(skim.Int(integer))
+		if integer, ok := parseInteger(digits(txt, 10, false), 10, neg); ok {
+			return d.assignNumber(txt, integer, "")
+		}
+		if ratio, ok := parseRational(txt, 10, neg); ok {
+			return d.assignNumber(txt, ratio, "")
 		}
 
 	float:
-		if fp, err := strconv.ParseFloat(string(txt), 64); err == nil {
+		if fp, err := strconv.ParseFloat(digits(txt, 10, false), 64); err == nil {
 			if neg {
 				fp = -fp
 			}
-			return d.assign(skim.Float(fp))
+			return d.assignNumber(txt, skim.Float(fp), "")
 		}
 	}
 
 symbol:
+	if len(txt) == 1 && txt[0] == '.' {
+		return d.readDot()
+	}
+
+	// Character literals fold only their names, and heredoc terminators are never folded.
+	if d.fold && !bytes.HasPrefix(txt, []byte(`#\`)) && !bytes.HasPrefix(txt, []byte("<<<")) {
+		txt = bytes.ToLower(txt)
+	}
+
 	var a skim.Atom
 	if n := len(txt); txt[0] == '#' && n > 1 {
 		switch second := txt[1]; {
+		case second == '\\':
+			return d.readChar(txt[2:])
+		case second == 'u' && string(txt) == "#u8" && d.current == rOpenParen:
+			return d.readBytes()
+		case second == '!' && string(txt) == "#!fold-case":
+			d.fold = true
+			return d.readSyntax, nil
+		case second == '!' && string(txt) == "#!no-fold-case":
+			d.fold = false
+			return d.readSyntax, nil
+		case isDigit(second, 10):
+			if label, n, def := parseLabel(txt); n == len(txt) && !def {
+				return d.readLabelRef(label)
+			}
+			a = d.symbol(txt)
+		case isNumberPrefix(second):
+			if prefix, ok := parseNumberPrefix(txt); ok {
+				return d.readPrefixedNumber(txt, prefix)
+			}
+			a = d.symbol(txt)
 		case n == 2 && (second == 't' || second == 'f'):
 			a = skim.Bool(second == 't')
 		case n == 4 && second == 'n':
@@ -334,41 +701,83 @@ symbol:
 			}
 			fallthrough
 		default:
-			a = skim.Symbol(txt)
+			a = d.symbol(txt)
 		}
-	} else if n > 3 && d.current == '\n' && txt[2] == '<' && txt[1] == '<' && txt[0] == '<' {
+	} else if n > 1 && txt[0] == ':' {
+		a = skim.Keyword(txt[1:])
+	} else if n > 3 && txt[2] == '<' && txt[1] == '<' && txt[0] == '<' {
 		// HEREDOC
-		end := make([]byte, n-3)
-		copy(end, txt[3:])
-		d.buffer.Reset()
-
-		for {
-			err = d.readUntilBuffer(runeFunc(isSymbolic))
-			buf := d.buffer.Bytes()
-			if (err == io.EOF || err == nil) && bytes.HasSuffix(buf, end) {
-				buf = buf[:len(buf)-len(end)]
-				if len(buf) == 0 || buf[len(buf)-1] == '\n' {
-					a = skim.String(buf)
-					break
-				}
-			} else if err != nil {
-				if err == io.EOF {
-					err = io.ErrUnexpectedEOF
-				}
-				return nil, err
-			}
-			d.buffer.WriteRune(d.current)
+		if err = d.extension("heredoc"); err != nil {
+			return nil, err
+		}
+		end, squiggly := txt[3:], false
+		if n > 4 && end[0] == '~' {
+			end, squiggly = end[1:], true
+		}
+		end = append([]byte(nil), end...)
+		if err = d.heredocNewline(txt); err != nil {
+			return nil, err
+		}
+		if a, err = d.readHeredoc(end, squiggly); err == errTooLong {
+			return nil, d.tooLong("heredoc", d.opts.MaxStringBytes, line, col, offset)
+		} else if err != nil {
+			return nil, err
 		}
 	} else {
-		a = skim.Symbol(txt)
+		a = d.symbol(txt)
 	}
 
 	return d.assign(a)
 }
 
+// readChar assigns a character literal, given the text of the literal following its #\ prefix. If
+// name is empty, the character is the current rune, which may be a space or sentinel rune.
+func (d *decoder) readChar(name []byte) (next nextfunc, err error) {
+	if len(name) == 0 {
+		if d.err != nil {
+			return nil, d.syntaxerr(io.ErrUnexpectedEOF, "expected character after #\\")
+		}
+		c := skim.Char(d.current)
+		if err = d.skip(); err != nil && err != io.EOF {
+			return nil, err
+		}
+		return d.assign(c)
+	}
+
+	if r, size := utf8.DecodeRune(name); size == len(name) {
+		return d.assign(skim.Char(r))
+	} else if d.fold {
+		name = bytes.ToLower(name)
+	}
+
+	if c, ok := skim.LookupCharName(string(name)); ok {
+		return d.assign(c)
+	} else if name[0] == 'x' {
+		if code, err := strconv.ParseUint(string(name[1:]), 16, 32); err == nil && utf8.ValidRune(rune(code)) {
+			return d.assign(skim.Char(code))
+		}
+	}
+	return nil, d.syntaxerr(CharNameError(name))
+}
+
+// readDot marks the current list scope as a dotted pair, such that the next datum read becomes the
+// Cdr of the list's last pair.
+func (d *decoder) readDot() (next nextfunc, err error) {
+	s := d.last
+	if _, ok := s.head.(*skim.Cons); !ok || !s.open || s.bytes {
+		return nil, d.syntaxerr(ErrDottedPair, "dot must follow at least one datum in a list")
+	} else if s.dot != dotNone {
+		return nil, d.syntaxerr(ErrDottedPair, "dotted pair has more than one dot")
+	}
+	s.dot = dotPending
+	return d.readSyntax, nil
+}
+
 func (d *decoder) closeVector() (next nextfunc, err error) {
-	if _, ok := d.last.head.(skim.Vector); !ok || !d.last.open {
-		return nil, d.syntaxerr(BadCharError(']'))
+	if !d.last.open || d.last.opener != "[" {
+		return d.stray(rCloseBracket)
+	} else if d.last.dot == dotPending {
+		return nil, d.syntaxerr(ErrDottedPair, "expected a datum after dot")
 	}
 
 	err = d.skip()
@@ -378,12 +787,39 @@ func (d *decoder) closeVector() (next nextfunc, err error) {
 		return nil, err
 	}
 
-	return d.close()
+	return d.close(rCloseBracket)
+}
+
+func (d *decoder) closeMap() (next nextfunc, err error) {
+	s := d.last
+	if !s.braced || !s.open {
+		return d.stray(rCloseBrace)
+	} else if n := len(s.elems); n%2 == 1 {
+		kv, key := s.head.(skim.Vector), s.elems[n-1]
+		se := d.syntaxerr(ErrMapKey, "map key ", kv[n-1], " has no value")
+		se.Line, se.Col, se.Offset = key.Line, key.Col, key.Offset
+		if !d.tolerate(se) {
+			return nil, se
+		}
+		// Drop the dangling key.
+		s.head, s.elems = kv[:n-1], s.elems[:n-1]
+	}
+
+	err = d.skip()
+	if err == io.EOF {
+		err = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return d.close(rCloseBrace)
 }
 
 func (d *decoder) closeList() (next nextfunc, err error) {
-	if _, ok := d.last.head.(*skim.Cons); (!ok && d.last.head != nil) || !d.last.open {
-		return nil, d.syntaxerr(BadCharError(')'))
+	if _, ok := d.last.head.(*skim.Cons); (!ok && d.last.head != nil) || !d.last.open || d.last.opener == "[" {
+		return d.stray(rCloseParen)
+	} else if d.last.dot == dotPending {
+		return nil, d.syntaxerr(ErrDottedPair, "expected a datum after dot")
 	}
 
 	err = d.skip()
@@ -393,7 +829,7 @@ func (d *decoder) closeList() (next nextfunc, err error) {
 		return nil, err
 	}
 
-	return d.close()
+	return d.close(rCloseParen)
 }
 
 func (d *decoder) unimplemented() (nextfunc, error) {
@@ -401,20 +837,121 @@ func (d *decoder) unimplemented() (nextfunc, error) {
 }
 
 func (d *decoder) readList() (next nextfunc, err error) {
-	d.push(scopeBraced)
+	if _, err = d.push(scopeBraced, "("); err != nil {
+		return nil, err
+	}
 	return d.readSyntax, d.skip()
 }
 
 func (d *decoder) readVector() (next nextfunc, err error) {
-	d.push(scopeBraced)
+	if d.opts.BracketsAsLists {
+		// Read as a list, but keep "[" as the opener so that only ] closes it.
+		if _, err = d.push(scopeBraced, "["); err != nil {
+			return nil, err
+		}
+		return d.readSyntax, d.skip()
+	}
+	if err = d.extension("bracket vector"); err != nil {
+		return nil, err
+	}
+	if _, err = d.push(scopeBraced, "["); err != nil {
+		return nil, err
+	}
 	d.last.head = skim.Vector{}
 	return d.readSyntax, d.skip()
 }
 
-func (d *decoder) push(open bool) *scope {
-	s := newScope(d.last, open, d.allocPair)
+func (d *decoder) readMap() (next nextfunc, err error) {
+	if err = d.extension("brace map"); err != nil {
+		return nil, err
+	}
+	s, err := d.push(scopeBraced, "{")
+	if err != nil {
+		return nil, err
+	}
+	s.head, s.braced = skim.Vector{}, true
+	return d.readSyntax, d.skip()
+}
+
+// readBytes begins a bytevector, #u8( ... ), at the current opening parenthesis.
+func (d *decoder) readBytes() (next nextfunc, err error) {
+	s, err := d.push(scopeBraced, "#u8(")
+	if err != nil {
+		return nil, err
+	}
+	s.bytes = true
+	s.line, s.col, s.offset = d.tok.line, d.tok.col, d.tok.offset
+	return d.readSyntax, d.skip()
+}
+
+// bytevector returns the elements of the bytevector scope s as skim.Bytes. Each element must be an
+// integer from 0 to 255.
+func (d *decoder) bytevector(s *scope) (skim.Bytes, error) {
+	b := skim.Bytes{}
+	i := 0
+	err := skim.Walk(s.cons(), func(a skim.Atom) error {
+		n, ok := a.(skim.Int)
+		if !ok || n < 0 || n > 255 {
+			pos := s.elems[i]
+			se := d.syntaxerr(ErrByte, "bytevector element ", a, " is not an integer from 0 to 255")
+			se.Line, se.Col, se.Offset = pos.Line, pos.Col, pos.Offset
+			i++
+			if d.tolerate(se) {
+				return nil
+			}
+			return se
+		}
+		b = append(b, byte(n))
+		i++
+		return nil
+	})
+	return b, err
+}
+
+// alist returns the keys and values of the map scope s as an association list of (key . value)
+// pairs. An empty map is an empty list.
+func (d *decoder) alist(s *scope) *skim.Cons {
+	kv := s.head.(skim.Vector)
+	if len(kv) == 0 {
+		return skim.Nil
+	}
+	head := d.allocPair()
+	for i, tail := 0, head; i < len(kv); i += 2 {
+		pair := d.allocPair()
+		pair.Car, pair.Cdr = kv[i], kv[i+1]
+		if i > 0 {
+			next := d.allocPair()
+			tail.Cdr, tail = next, next
+		}
+		tail.Car = pair
+		if d.src != nil {
+			key := s.elems[i]
+			d.src.SetList(pair, key)
+			d.src.SetCar(pair, key)
+			d.src.SetCdr(pair, s.elems[i+1])
+			d.src.SetCar(tail, key)
+		}
+	}
+	return head
+}
+
+// push opens a new scope at the current rune. opener is the syntax that opened the scope. If the
+// new scope would exceed the decoder's MaxDepth, push returns a SyntaxError.
+func (d *decoder) push(open bool, opener string) (*scope, error) {
+	if max := d.opts.MaxDepth; max > 0 && d.depth >= max {
+		return nil, d.syntaxerr(DepthError(max))
+	}
+	s := d.free
+	if s == nil {
+		s = newScope(d.last, open, d.allocPair)
+	} else {
+		d.free = s.up
+		s.reset(d.last, open, d.allocPair)
+	}
+	s.opener, s.line, s.col, s.offset = opener, d.line, d.col, d.offset
 	d.last = s
-	return d.last
+	d.depth++
+	return d.last, nil
 }
 
 const scopeBraced = true
@@ -429,14 +966,25 @@ func (d *decoder) readLiteral() (next nextfunc, err error) {
 		sym = skim.Unquote
 	}
 
-	// ok:
-	d.push(scopeQuoted)
-	d.last.append(sym)
-	return d.readSyntax, d.skip()
+	// Push the scope before skipping the quote so that an EOF following it leaves the scope
+	// unclosed.
+	s, err := d.push(scopeQuoted, string(d.current))
+	if err != nil {
+		return nil, err
+	}
+	if err = d.skip(); err == nil && sym == skim.Unquote && d.current == rAt {
+		sym, s.opener = skim.UnquoteSplicing, ",@"
+		err = d.skip()
+	}
+	d.append(s, sym, d.pos(s.line, s.col, s.offset))
+	return d.readSyntax, err
 }
 
 func (d *decoder) start() (next nextfunc, err error) {
 	_, _, err = d.nextRune()
+	if err == nil && d.current == byteOrderMark {
+		_, _, err = d.nextRune()
+	}
 	if err == io.EOF {
 		return nil, nil
 	}
@@ -444,66 +992,391 @@ func (d *decoder) start() (next nextfunc, err error) {
 }
 
 func (d *decoder) readComment() (next nextfunc, err error) {
-	if err = d.readUntilBuffer(oneRune(rNewline)); err == io.EOF {
+	line, col, offset := d.line, d.col, d.offset
+	// Comments are skipped rather than buffered, so their length is not limited.
+	for r := d.current; r != rNewline && err == nil; {
+		r, _, err = d.nextRune()
+	}
+	if err == nil || err == io.EOF {
+		d.comment(LineComment, line, col, offset)
+	}
+	if err == io.EOF {
 		return nil, nil
 	}
 	return d.readSyntax, err
 }
 
-func (d *decoder) reset(r io.Reader) {
-	const (
-		defaultPairbufSize = 16
-		defaultBufferCap   = 64
-	)
+// readBlockComment skips a block comment, #| ... |#, including any block comments nested within it.
+// The current rune must be the opening '#'.
+func (d *decoder) readBlockComment() (next nextfunc, err error) {
+	line, col, offset := d.line, d.col, d.offset
+	d.skip() // '|'
+	for depth := 1; depth > 0; {
+		r, _, err := d.nextRune()
+		if err == io.EOF {
+			se := d.syntaxerr(ErrUnclosedComment, "encountered EOF inside block comment")
+			se.Line, se.Col, se.Offset = line, col, offset
+			return nil, se
+		} else if err != nil {
+			return nil, err
+		}
 
+		var pair rune
+		switch r {
+		case rPipe:
+			pair = rHash
+		case rHash:
+			pair = rPipe
+		default:
+			continue
+		}
+
+		if p, _ := d.peekRune(); p != pair {
+			continue
+		} else if d.skip(); r == rHash {
+			depth++
+		} else {
+			depth--
+		}
+	}
+
+	if err = d.skip(); err == io.EOF {
+		err = nil // handle it next time around
+	}
+	if err == nil {
+		d.comment(BlockComment, line, col, offset)
+	}
+	return d.readSyntax, err
+}
+
+// readDatumComment reads the datum following a #; and discards it once it's sealed. The current
+// rune must be the opening '#'.
+func (d *decoder) readDatumComment() (next nextfunc, err error) {
+	s, err := d.push(scopeQuoted, "#;")
+	if err != nil {
+		return nil, err
+	}
+	s.discard = true
+	d.skip() // ';'
+	return d.readSyntax, d.skip()
+}
+
+const (
+	defaultPairbufSize = 16
+	defaultBufferCap   = 64
+
+	defaultReadBufferSize = 4096
+)
+
+func (d *decoder) reset(r io.Reader) {
+	for d.last != nil && d.last != &d.root {
+		s := d.last
+		d.last = s.up
+		d.release(s)
+	}
 	d.root.reset(nil, false, d.allocPair)
 	d.root.head = skim.Vector(nil)
 	d.last = &d.root
+	d.depth = 0
+	d.errs = nil
+	d.macro.prefix = ""
+	d.src = d.opts.SourceMap
+	d.fold = d.opts.FoldCase
 
-	if rx, ok := r.(runeReader); ok {
+	if r == nil {
+		d.readrune = nil
+	} else if rx, ok := r.(runeReader); ok {
 		d.readrune = rx.ReadRune
 	} else {
-		d.readrune = nil
+		// Reading a rune at a time from an io.Reader issues a Read per byte, so buffer it.
+		size := d.opts.ReadBufferSize
+		if size <= 0 {
+			size = defaultReadBufferSize
+		}
+		if d.br == nil || d.br.Size() != size {
+			d.br = bufio.NewReaderSize(r, size)
+		} else {
+			d.br.Reset(r)
+		}
+		r, d.readrune = d.br, d.br.ReadRune
 	}
 
 	d.rd = r
 	d.err = nil
 
 	d.current = 0
+	d.bom = true
 	d.line = 1
 	d.col = 0
+	d.offset = 0
+	d.size = 0
 
 	d.buffer.Reset()
-	d.buffer.Grow(defaultBufferCap)
+	if d.opts.BufferSize > 0 {
+		d.buffer.Grow(d.opts.BufferSize)
+	} else {
+		d.buffer.Grow(defaultBufferCap)
+	}
 
 	d.havenext = false
+	d.nextsize = 0
 	d.nexterr = nil
 
-	if d.pairbufSize <= 0 {
-		d.pairbufSize = defaultPairbufSize
+	// Pairs in the pair buffer are never handed out twice, so any not yet used by previously
+	// read data can be kept.
+	size := d.opts.PairBufferSize
+	if size <= 0 {
+		size = defaultPairbufSize
+	}
+	if size != d.pairbufSize {
+		d.pairbufSize, d.pairbufHead, d.pairbuf = size, 0, nil
 	}
-	d.pairbufHead, d.pairbuf = 0, nil
+}
+
+// Options configures how input is read. The zero value is the configuration used by Read,
+// ReadNamed, and ReadFile.
+type Options struct {
+	// MaxDepth is the maximum depth of nested lists, vectors, quotes, and datum comments. If
+	// MaxDepth is zero or less, nesting depth is unlimited.
+	MaxDepth int
+
+	// PairBufferSize is the number of cons cells allocated at a time when reading lists. If
+	// PairBufferSize is 1, each cell is allocated individually. If zero or less, a default of 16
+	// is used.
+	PairBufferSize int
+
+	// BufferSize is the initial capacity, in bytes, of the buffer used to read symbols, strings,
+	// and other tokens. If zero or less, a default of 64 is used.
+	BufferSize int
+
+	// ReadBufferSize is the size of the bufio.Reader used to wrap input readers that do not
+	// implement io.RuneReader. If zero or less, a default of 4096 is used.
+	ReadBufferSize int
+
+	// SourceMap, if not nil, records the positions of lists and of the elements of lists and vectors
+	// read, and where the text of each other atom read ends.
+	SourceMap *skim.SourceMap
+
+	// Comments, if not nil, is called with the position of each comment read, in the order they
+	// are read. A datum comment is passed once the datum following its #; has been read.
+	Comments func(Comment)
+
+	// FoldCase, if true, lowercases symbols, keywords, and character names as they are read.
+	// Strings and pipe-quoted symbols are never folded. The #!fold-case and #!no-fold-case
+	// directives change this from the point they occur in the input.
+	FoldCase bool
+
+	// StrictEscapes, if true, makes unknown escape sequences in strings and pipe-quoted symbols,
+	// such as "\q", a SyntaxError. Otherwise, an unknown escape is read as the escaped rune.
+	StrictEscapes bool
+
+	// BracketsAsLists, if true, reads [ ... ] as a list rather than a vector, as in most Schemes.
+	// A list opened by [ must still be closed by ]. Lambda argument lists, such as [x], are then
+	// read as lists, which the interpreter only accepts if interp.Context.SetListArguments is set.
+	BracketsAsLists bool
+
+	// MaxTokenBytes is the maximum length, in bytes, of a symbol, number, or other token. If zero
+	// or less, tokens are unlimited in length.
+	MaxTokenBytes int
+
+	// MaxStringBytes is the maximum length, in bytes, of a string or heredoc after escapes are
+	// processed. If zero or less, strings are unlimited in length.
+	MaxStringBytes int
+
+	// Strict, if true, makes syntax that skim adds to Scheme a SyntaxError with an ExtensionError:
+	// heredocs, bracket vectors (unless BracketsAsLists is set), brace maps, 0x and 0b integers,
+	// octal integers with a leading zero, and digit separators.
+	Strict bool
+
+	// Recover, if true, continues reading past syntax errors that do not prevent reading the rest
+	// of the input: unknown or out-of-range escapes, stray and mismatched closing brackets,
+	// malformed characters, numbers, and datum labels, misplaced dots, map keys without values,
+	// and invalid bytevector elements. Malformed tokens and stray brackets are skipped. Once the
+	// input is exhausted, or an error that cannot be recovered from occurs, all errors are
+	// returned together as a MultiError.
+	Recover bool
+
+	// AllowInvalidUTF8, if true, reads each byte of invalid UTF-8 as U+FFFD instead of
+	// returning a SyntaxError.
+	AllowInvalidUTF8 bool
+
+	// InternSymbols, if true, interns each symbol read with skim.InternBytes, so that repeated
+	// symbols share their text. This saves memory when reading programs that repeat a small set of
+	// symbols many times.
+	InternSymbols bool
 }
 
 func Read(r io.Reader) (skim.Vector, error) {
-	var dec decoder
-	return dec.Read(r)
+	return Options{}.Read(r)
+}
+
+// ReadNamed is the same as Read, except that any SyntaxError returned carries the given name.
+func ReadNamed(name string, r io.Reader) (skim.Vector, error) {
+	return Options{}.ReadNamed(name, r)
+}
+
+// ReadFile opens and reads the file at path. Syntax errors are named by the path.
+func ReadFile(path string) (skim.Vector, error) {
+	return Options{}.ReadFile(path)
+}
+
+// ReadString reads all data from s. It is the same as passing a strings.Reader to Read, but avoids
+// reading through an io.Reader.
+func ReadString(s string) (skim.Vector, error) {
+	return Options{}.ReadString(s)
+}
+
+// ReadBytes reads all data from b. It is the same as passing a bytes.Reader to Read, but avoids
+// reading through an io.Reader.
+func ReadBytes(b []byte) (skim.Vector, error) {
+	return Options{}.ReadBytes(b)
+}
+
+// Read is the same as the Read function, but uses the configuration in o.
+func (o Options) Read(r io.Reader) (skim.Vector, error) {
+	return o.ReadNamed("", r)
+}
+
+// ReadNamed is the same as the ReadNamed function, but uses the configuration in o.
+func (o Options) ReadNamed(name string, r io.Reader) (skim.Vector, error) {
+	return readAll(o.NewNamedDecoder(name, r))
+}
+
+// ReadString is the same as the ReadString function, but uses the configuration in o.
+func (o Options) ReadString(s string) (skim.Vector, error) {
+	dec := New(o)
+	dec.d.resetString(s)
+	dec.next = dec.d.start
+	return readAll(dec)
+}
+
+// ReadBytes is the same as the ReadBytes function, but uses the configuration in o.
+func (o Options) ReadBytes(b []byte) (skim.Vector, error) {
+	dec := New(o)
+	dec.d.resetBytes(b)
+	dec.next = dec.d.start
+	return readAll(dec)
+}
+
+// ReadFunc reads each top-level datum from r and passes it to fn as soon as it is read, without
+// holding on to data already passed to fn. If fn returns ErrStop, ReadFunc stops reading and
+// returns nil. Any other error returned by fn is returned by ReadFunc.
+func ReadFunc(r io.Reader, fn func(skim.Atom) error) error {
+	return Options{}.ReadFunc(r, fn)
+}
+
+// ReadFunc is the same as the ReadFunc function, but uses the configuration in o.
+func (o Options) ReadFunc(r io.Reader, fn func(skim.Atom) error) error {
+	dec := o.NewDecoder(r)
+	dec.d.isolate = true
+	for {
+		datum, err := dec.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err = fn(datum); err == ErrStop {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func readAll(dec *Decoder) (skim.Vector, error) {
+	var data skim.Vector
+	for {
+		datum, err := dec.Next()
+		if err == io.EOF {
+			return data, nil
+		} else if err != nil {
+			return nil, err
+		}
+		data = append(data, datum)
+	}
 }
 
-func (d *decoder) Read(r io.Reader) (skim.Vector, error) {
-	d.reset(r)
-	if err := d.read(); err != nil {
+// ReadFile is the same as the ReadFile function, but uses the configuration in o.
+func (o Options) ReadFile(path string) (skim.Vector, error) {
+	f, err := os.Open(path)
+	if err != nil {
 		return nil, err
 	}
-	root := d.root.cons()
-	d.root, d.last = scope{head: skim.Vector(nil)}, &d.root
-	d.buffer.Reset()
-	d.pairbufHead, d.pairbuf = 0, nil
+	defer f.Close()
+	return o.ReadNamed(path, bufio.NewReader(f))
+}
+
+// Decoder reads top-level data from an input stream one at a time.
+//
+// A Decoder owns its input reader. If the reader does not implement io.RuneReader, the Decoder
+// buffers it and may read past the end of the last datum returned by Next, so the reader should
+// not be read from elsewhere while the Decoder is in use.
+type Decoder struct {
+	d    decoder
+	next nextfunc
+	err  error
+}
 
-	return root.(skim.Vector), nil
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return Options{}.NewDecoder(r)
 }
 
-func (d *decoder) read() (err error) {
+// NewNamedDecoder is the same as NewDecoder, except that any SyntaxError returned by the Decoder
+// carries the given name.
+func NewNamedDecoder(name string, r io.Reader) *Decoder {
+	return Options{}.NewNamedDecoder(name, r)
+}
+
+// NewDecoder is the same as the NewDecoder function, but uses the configuration in o.
+func (o Options) NewDecoder(r io.Reader) *Decoder {
+	return o.NewNamedDecoder("", r)
+}
+
+// NewNamedDecoder is the same as the NewNamedDecoder function, but uses the configuration in o.
+func (o Options) NewNamedDecoder(name string, r io.Reader) *Decoder {
+	dec := New(o)
+	dec.d.name = name
+	dec.Reset(r)
+	return dec
+}
+
+// New returns a Decoder using the configuration in opts. The Decoder has no input until Reset is
+// called; until then, Next returns io.EOF.
+func New(opts Options) *Decoder {
+	dec := &Decoder{d: decoder{opts: opts}}
+	dec.d.owner = dec
+	dec.d.reset(nil)
+	return dec
+}
+
+// Reset discards the Decoder's state and any buffered input and begins reading from r. The
+// Decoder's configuration and name are kept.
+func (dec *Decoder) Reset(r io.Reader) {
+	dec.d.reset(r)
+	dec.next, dec.err = dec.d.start, nil
+}
+
+// ReadAll reads all data from r, as with Reset followed by calls to Next until io.EOF. ReadAll
+// reuses the Decoder's internal buffers and scopes, so reading many inputs with one Decoder
+// allocates less than calling Read for each. Data returned by earlier calls are never modified by
+// later ones.
+func (dec *Decoder) ReadAll(r io.Reader) (skim.Vector, error) {
+	dec.Reset(r)
+	return readAll(dec)
+}
+
+// Next reads and returns the next top-level datum from the input. It returns io.EOF once the input
+// is exhausted. Once Next returns an error, it returns the same error on all subsequent calls.
+//
+// Next may read one rune past the end of the datum it returns.
+func (dec *Decoder) Next() (datum skim.Atom, err error) {
+	if dec.err != nil {
+		return nil, dec.err
+	}
+
+	d := &dec.d
 	defer func() {
 		rc := recover()
 		if perr, ok := rc.(error); ok {
@@ -511,26 +1384,146 @@ func (d *decoder) read() (err error) {
 		} else if rc != nil {
 			err = fmt.Errorf("skim: panic: %v", rc)
 		}
-		if err == io.EOF {
-			err = io.ErrUnexpectedEOF
+		if err != nil {
+			datum, dec.err, dec.next = nil, err, nil
 		}
 	}()
 
-	var next nextfunc = d.start
+	next := dec.next
 	for next != nil && err == nil {
 		next, err = next()
+		if v := d.root.head.(skim.Vector); len(v) > 0 {
+			datum, v[0], d.root.head = v[0], nil, v[:0]
+			dec.next = next
+			if err == io.EOF {
+				// Report EOF on the following call.
+				dec.next, err = nil, nil
+			}
+			return datum, err
+		}
 	}
-	if err == io.EOF && d.last == &d.root {
-		err = nil
+
+	if err != nil && err != io.EOF {
+		return nil, d.collect(err)
+	} else if d.last != &d.root {
+		return nil, d.collect(d.unclosed())
+	} else if len(d.errs) > 0 {
+		return nil, MultiError(d.errs)
 	}
-	return err
+	return nil, io.EOF
+}
+
+// collect returns err, or, if the decoder has recovered from earlier errors, a MultiError ending
+// with err.
+func (d *decoder) collect(err error) error {
+	if len(d.errs) == 0 {
+		return err
+	}
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		se = d.syntaxerr(err)
+	}
+	return MultiError(append(d.errs, se))
+}
+
+// Pos returns the line, column, and byte offset at which the datum last returned by Next began.
+func (dec *Decoder) Pos() (line, col, offset int) {
+	return dec.d.datum.line, dec.d.datum.col, dec.d.datum.offset
+}
+
+// unclosed returns a SyntaxError for the innermost scope left open at EOF, positioned at the
+// syntax that opened it.
+func (d *decoder) unclosed() *SyntaxError {
+	s := d.last
+	var se *SyntaxError
+	if s.open {
+		se = d.syntaxerr(UnclosedError(s.opener[len(s.opener)-1]), "encountered EOF inside ", s.opener)
+	} else {
+		se = d.syntaxerr(io.ErrUnexpectedEOF, "expected a datum after ", s.opener)
+	}
+	se.Line, se.Col, se.Offset = s.line, s.col, s.offset
+	return se
+}
+
+// unbalanced returns a SyntaxError for a closing bracket that does not close the current scope.
+// If there is an open scope, its opener, position, and expected closer are included in the error's
+// description.
+func (d *decoder) unbalanced(closer rune) *SyntaxError {
+	s := d.last
+	switch {
+	case s.up == nil:
+		return d.syntaxerr(UnbalancedError(closer), "no open scope")
+	case s.open:
+		expecting := UnclosedError(s.opener[len(s.opener)-1]).Expecting()
+		return d.syntaxerr(UnbalancedError(closer), fmt.Sprintf("%c does not close %s opened at %d:%d, expected %c",
+			closer, s.opener, s.line, s.col, expecting))
+	default:
+		return d.syntaxerr(UnbalancedError(closer),
+			fmt.Sprintf("expected a datum after %s at %d:%d", s.opener, s.line, s.col))
+	}
+}
+
+// extension returns a SyntaxError naming a skim extension to Scheme syntax if the decoder is
+// strict. Otherwise, it returns nil.
+func (d *decoder) extension(name string) error {
+	if !d.opts.Strict {
+		return nil
+	}
+	return d.syntaxerr(ExtensionError(name))
+}
+
+// assignNumber assigns the number a, read from txt. If the decoder is strict, numbers written using
+// the extension ext, if any, or containing digit separators are a SyntaxError.
+func (d *decoder) assignNumber(txt []byte, a skim.Atom, ext string) (nextfunc, error) {
+	if ext == "" && bytes.IndexByte(txt, '_') != -1 {
+		ext = "digit separator"
+	}
+	if ext != "" {
+		if err := d.extension(ext); err != nil {
+			return nil, err
+		}
+	}
+	return d.assign(a)
+}
+
+// stray returns a SyntaxError for a closing bracket that does not close the current scope. If the
+// decoder is recovering from errors, the error is recorded and the bracket is skipped instead.
+func (d *decoder) stray(closer rune) (nextfunc, error) {
+	se := d.unbalanced(closer)
+	if !d.tolerate(se) {
+		return nil, se
+	}
+	if err := d.skip(); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return d.readSyntax, nil
+}
+
+// tolerate records se and returns true if the decoder is recovering from errors. Otherwise, it
+// returns false and se should be returned as-is.
+func (d *decoder) tolerate(se *SyntaxError) bool {
+	if !d.opts.Recover {
+		return false
+	}
+	d.errs = append(d.errs, se)
+	return true
+}
+
+// malformed returns true if err describes a malformed token that can be dropped when recovering
+// from errors.
+func malformed(err error) bool {
+	switch err.(type) {
+	case CharNameError, NumberError, LabelError:
+		return true
+	}
+	return err == ErrDuplicateLabel || err == ErrDottedPair
 }
 
 func (d *decoder) syntaxerr(err error, msg ...interface{}) *SyntaxError {
 	if se, ok := err.(*SyntaxError); ok {
 		return se
 	}
-	se := &SyntaxError{Line: d.line, Col: d.col, Err: err, Desc: fmt.Sprint(msg...)}
+	se := &SyntaxError{Name: d.name, Line: d.line, Col: d.col, Offset: d.offset, Err: err, Desc: fmt.Sprint(msg...)}
 	return se
 }
 
@@ -563,25 +1556,92 @@ func (d *decoder) nextRune() (r rune, size int, err error) {
 		return 0, 1, d.err
 	}
 
-	if d.readrune != nil {
-		r, size, err = d.readrune()
-	} else { // slow fallback
-		r, size, err = readrune(d.rd)
+	if d.havenext {
+		r, size, err = d.next, d.nextsize, d.nexterr
+		d.havenext = false
+	} else {
+		r, size, err = d.readRune()
 	}
 
+	if err == nil {
+		// Line and column refer to the current rune, so a newline is counted once the rune
+		// following it is read.
+		if d.current == rNewline {
+			d.line++
+			d.col = 0
+		}
+		d.col++
+		d.offset += d.size
+		d.size = size
+	}
 	d.current = r
 
+	if err == nil && r == byteOrderMark && !d.bom {
+		err = d.syntaxerr(BadCharError(r), "byte order mark is only permitted at the start of input")
+	} else if err == nil && r == utf8.RuneError && size == 1 && !d.opts.AllowInvalidUTF8 {
+		err = d.syntaxerr(ErrInvalidUTF8)
+	}
+	d.bom = false
+
 	if err != nil {
 		d.err = err
 		d.rd = nil
 	}
 
-	if d.current == '\n' {
-		d.line++
-		d.col = 1
+	return r, size, err
+}
+
+// peekRune returns the rune following the current rune without consuming it. Any error returned
+// by peekRune is returned again by the following call to nextRune.
+func (d *decoder) peekRune() (r rune, err error) {
+	if d.err != nil {
+		return 0, d.err
+	} else if !d.havenext {
+		d.next, d.nextsize, d.nexterr = d.readRune()
+		d.havenext = true
+	}
+	return d.next, d.nexterr
+}
+
+// resetString resets the decoder to read from s without going through an io.Reader.
+func (d *decoder) resetString(s string) {
+	d.reset(nil)
+	i := 0
+	d.readrune = func() (rune, int, error) {
+		if i >= len(s) {
+			return 0, 0, io.EOF
+		} else if c := s[i]; c < utf8.RuneSelf {
+			i++
+			return rune(c), 1, nil
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		return r, size, nil
 	}
+}
 
-	return r, size, err
+// resetBytes resets the decoder to read from b without going through an io.Reader.
+func (d *decoder) resetBytes(b []byte) {
+	d.reset(nil)
+	i := 0
+	d.readrune = func() (rune, int, error) {
+		if i >= len(b) {
+			return 0, 0, io.EOF
+		} else if c := b[i]; c < utf8.RuneSelf {
+			i++
+			return rune(c), 1, nil
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		i += size
+		return r, size, nil
+	}
+}
+
+func (d *decoder) readRune() (r rune, size int, err error) {
+	if d.readrune != nil {
+		return d.readrune()
+	}
+	return readrune(d.rd) // slow fallback
 }
 
 func (d *decoder) skip() error {
@@ -589,9 +1649,18 @@ func (d *decoder) skip() error {
 	return err
 }
 
-func (d *decoder) readUntilBuffer(oneof runeset) (err error) {
+// errTooLong is returned by readUntilBuffer when the buffer exceeds its maximum length. Callers
+// replace it with a SyntaxError describing what was being read.
+var errTooLong = errors.New("buffer exceeds maximum length")
+
+// readUntilBuffer writes runes to the buffer until it reads one of oneof, which is not written. If
+// max is greater than zero and the buffer grows longer than max bytes, it returns errTooLong.
+func (d *decoder) readUntilBuffer(oneof runeset, max int) (err error) {
 	var r rune
 	for out := &d.buffer; ; {
+		if max > 0 && out.Len() > max {
+			return errTooLong
+		}
 		r, _, err = d.nextRune()
 		if err != nil {
 			return err
@@ -602,6 +1671,14 @@ func (d *decoder) readUntilBuffer(oneof runeset) (err error) {
 	}
 }
 
+// tooLong returns a SyntaxError for a token, described by what, that exceeds max bytes. The error
+// is positioned at the start of the token.
+func (d *decoder) tooLong(what string, max, line, col, offset int) *SyntaxError {
+	se := d.syntaxerr(ErrTooLong, fmt.Sprintf("%s exceeds %d bytes", what, max))
+	se.Line, se.Col, se.Offset = line, col, offset
+	return se
+}
+
 // Rune handling
 
 type runeReader interface {
@@ -642,25 +1719,29 @@ func (fn runeFunc) Contains(r rune) bool { return fn(r) }
 
 func (lhs oneRune) Contains(rhs rune) bool { return rune(lhs) == rhs }
 
-func escaped(r rune) rune {
+// escaped returns the rune represented by the single-rune escape sequence \r. If r is not a known
+// escape, escaped returns r and false.
+func escaped(r rune) (rune, bool) {
 	switch r {
-	case '0':
-		return 0
 	case 'a':
-		return '\a'
+		return '\a', true
 	case 'b':
-		return '\b'
+		return '\b', true
 	case 'f':
-		return '\f'
+		return '\f', true
 	case 'n':
-		return '\n'
+		return '\n', true
 	case 'r':
-		return '\r'
+		return '\r', true
 	case 't':
-		return '\t'
+		return '\t', true
 	case 'v':
-		return '\v'
+		return '\v', true
+	case '"':
+		return '"', true
+	case '\\':
+		return '\\', true
 	default:
-		return r
+		return r, false
 	}
 }
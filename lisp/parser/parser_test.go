@@ -1,11 +1,24 @@
 package parser
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"testing/iotest"
+	"unicode"
+	"unique"
+	"unsafe"
 
 	"go.spiff.io/skim/internal/debug"
 	"go.spiff.io/skim/lisp/skim"
@@ -19,14 +32,29 @@ func quote(a skim.Atom) skim.Atom {
 	return cons(skim.Quote, cons(a, nil))
 }
 
-func TestParse(t *testing.T) {
-	type testcase struct {
-		in   string
-		out  skim.Atom
-		fail bool
+func bigint(s string) skim.Atom {
+	i, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		panic("invalid big.Int: " + s)
 	}
+	return skim.NewInteger(i)
+}
+
+func ratio(a, b int64) skim.Atom {
+	return skim.NewRational(big.NewRat(a, b))
+}
+
+type parseCase struct {
+	in   string
+	out  skim.Atom
+	fail bool
+	err  string // substring of the error message, if fail is true
+	ext  bool   // if true, the input uses a syntax extension and fails to read with Strict
+}
 
-	cases := map[string]testcase{
+// parseCases returns the inputs read by TestParse, keyed by name.
+func parseCases() map[string]parseCase {
+	return map[string]parseCase{
 		"empty": {
 			in:  "",
 			out: skim.Vector(nil),
@@ -59,6 +87,23 @@ func TestParse(t *testing.T) {
 			in:  `"\0\x0a\x0A\a\b\f\n\r\t\v\u0000\U00000000"`,
 			out: skim.Vector{skim.String("\x00\n\n\a\b\f\n\r\t\v\u0000\U00000000")},
 		},
+		"string/quoted": {
+			in:  `('"a" b) '"c"`,
+			out: skim.Vector{skim.List(quote(skim.String("a")), skim.Symbol("b")), quote(skim.String("c"))},
+		},
+		"string/octal": {
+			in:  `"\101\x42\103 \1\7\77x \0123 \377\x7f"`,
+			out: skim.Vector{skim.String("ABC \x01\x07?x \n3 \xff\x7f")},
+		},
+		"string/octal-nul-digit": {
+			in:  `"\01 \08 \00"`,
+			out: skim.Vector{skim.String("\x01 \x008 \x00")},
+		},
+		"error/string/octal-range": {
+			in:   `"\400"`,
+			fail: true,
+			err:  `1:2: skim: octal escape out of range -- \400`,
+		},
 		"negative/symbol": {
 			in:  "-",
 			out: skim.Vector{skim.Symbol("-")},
@@ -70,10 +115,12 @@ func TestParse(t *testing.T) {
 		"negative/integer-0xff": {
 			in:  "-0xff",
 			out: skim.Vector{skim.Int(-255)},
+			ext: true,
 		},
 		"negative/integer-0654": {
 			in:  "-0654",
 			out: skim.Vector{skim.Int(-428)},
+			ext: true,
 		},
 		"integer-0": {
 			in:  "((0))",
@@ -82,18 +129,118 @@ func TestParse(t *testing.T) {
 		"integer-0xff": {
 			in:  "0xff",
 			out: skim.Vector{skim.Int(255)},
+			ext: true,
 		},
 		"integer-0654": {
 			in:  "0654",
 			out: skim.Vector{skim.Int(428)},
+			ext: true,
 		},
 		"integer-+0xff": {
 			in:  "+0xff",
 			out: skim.Vector{skim.Int(255)},
+			ext: true,
 		},
 		"integer-+0654": {
 			in:  "+0654",
 			out: skim.Vector{skim.Int(428)},
+			ext: true,
+		},
+		"negative/integer-0b1011": {
+			in:  "-0b1011",
+			out: skim.Vector{skim.Int(-11)},
+			ext: true,
+		},
+		"integer-0b1011": {
+			in:  "0b1011",
+			out: skim.Vector{skim.Int(11)},
+			ext: true,
+		},
+		"integer-+0b1011": {
+			in:  "+0b1011",
+			out: skim.Vector{skim.Int(11)},
+			ext: true,
+		},
+		"separators/integer": {
+			in:  "1_000 -10_000_000 +1_2_3",
+			out: skim.Vector{skim.Int(1000), skim.Int(-10000000), skim.Int(123)},
+			ext: true,
+		},
+		"separators/hex": {
+			in:  "0xDE_AD 0x_ff",
+			out: skim.Vector{skim.Int(0xdead), skim.Int(0xff)},
+			ext: true,
+		},
+		"separators/octal": {
+			in:  "07_55 0_7",
+			out: skim.Vector{skim.Int(0755), skim.Int(7)},
+			ext: true,
+		},
+		"separators/binary": {
+			in:  "0b1010_1010",
+			out: skim.Vector{skim.Int(0xaa)},
+			ext: true,
+		},
+		"separators/float": {
+			in:  "3.141_592 1_000.5 -1_0.0_1",
+			out: skim.Vector{skim.Float(3.141592), skim.Float(1000.5), skim.Float(-10.01)},
+			ext: true,
+		},
+		"separators/misplaced": {
+			in: "_1 1_ 1__0 0x__f 0xf_ 3._1 3_.1 1.0_ 1_e5",
+			out: skim.Vector{
+				skim.Symbol("_1"), skim.Symbol("1_"), skim.Symbol("1__0"),
+				skim.Symbol("0x__f"), skim.Symbol("0xf_"), skim.Symbol("3._1"),
+				skim.Symbol("3_.1"), skim.Symbol("1.0_"), skim.Symbol("1_e5"),
+			},
+		},
+		"integer/big": {
+			in: "9223372036854775808 -9223372036854775809 0x1_0000_0000_0000_0000 -0b1000000000000000000000000000000000000000000000000000000000000000 01000000000000000000000",
+			out: skim.Vector{
+				bigint("9223372036854775808"),
+				bigint("-9223372036854775809"),
+				bigint("0x10000000000000000"),
+				skim.Int(math.MinInt64),
+				bigint("0o1000000000000000000000"),
+			},
+			ext: true,
+		},
+		"integer/min-int64": {
+			in:  "-9223372036854775808",
+			out: skim.Vector{skim.Int(math.MinInt64)},
+		},
+		"symbol/signed-digits": {
+			in:  "--1 +-1 0x-1",
+			out: skim.Vector{skim.Symbol("--1"), skim.Symbol("+-1"), skim.Symbol("0x-1")},
+		},
+		"rational": {
+			in:  "1/2 -3/6 4/2 1_0/3",
+			out: skim.Vector{ratio(1, 2), ratio(-1, 2), skim.Int(2), ratio(10, 3)},
+			ext: true,
+		},
+		"symbol/rational-like": {
+			in:  "1/0 1/ /2 1/2/3 1/x",
+			out: skim.Vector{skim.Symbol("1/0"), skim.Symbol("1/"), skim.Symbol("/2"), skim.Symbol("1/2/3"), skim.Symbol("1/x")},
+		},
+		"prefix/radix": {
+			in:  "#b101 #o17 #d10 #xFF #X-ff #x1/a",
+			out: skim.Vector{skim.Int(5), skim.Int(15), skim.Int(10), skim.Int(255), skim.Int(-255), ratio(1, 10)},
+		},
+		"prefix/exact": {
+			in:  "#e1.5 #e0.1 #e-2.50 #e1e3 #e3 #e1/3",
+			out: skim.Vector{ratio(3, 2), ratio(1, 10), ratio(-5, 2), skim.Int(1000), skim.Int(3), ratio(1, 3)},
+		},
+		"prefix/inexact": {
+			in:  "#i3 #i1/4 #i-1.5 #i#x10",
+			out: skim.Vector{skim.Float(3), skim.Float(0.25), skim.Float(-1.5), skim.Float(16)},
+		},
+		"prefix/radix-and-exactness": {
+			in:  "#x#e10 #e#x10 #b#i11",
+			out: skim.Vector{skim.Int(16), skim.Int(16), skim.Float(3)},
+		},
+		"prefix/symbols": {
+			in:  "#define #else #include #x #e- #bad",
+			out: skim.Vector{skim.Symbol("#define"), skim.Symbol("#else"), skim.Symbol("#include"), skim.Symbol("#x"), skim.Symbol("#e-"), skim.Symbol("#bad")},
 		},
 		"negative/float-0.0": {
 			in:  "-0.0",
@@ -111,32 +258,234 @@ func TestParse(t *testing.T) {
 			in:  "0xfoobar",
 			out: skim.Vector{skim.Symbol("0xfoobar")},
 		},
+		"float/hex": {
+			in:  "0x1.fp3 -0x1.8p1 +0x1p0 0x1p-2 0x_1_0p0",
+			out: skim.Vector{skim.Float(15.5), skim.Float(-3.0), skim.Float(1.0), skim.Float(0.25), skim.Float(16.0)},
+			ext: true,
+		},
+		"symbol/hex-float-like": {
+			in:  "0x1.f 0x1.fp 0xp3",
+			out: skim.Vector{skim.Symbol("0x1.f"), skim.Symbol("0x1.fp"), skim.Symbol("0xp3")},
+		},
+		"symbol/binary-like": {
+			in:  "0b102 0b",
+			out: skim.Vector{skim.Symbol("0b102"), skim.Symbol("0b")},
+		},
+		"symbol/pipe": {
+			in:  `|foo bar| (|(x)| |1|) ||`,
+			out: skim.Vector{skim.Symbol("foo bar"), skim.List(skim.Symbol("(x)"), skim.Symbol("1")), skim.Symbol("")},
+		},
+		"symbol/pipe-escapes": {
+			in:  `|a\|b| |\x41\n\\|`,
+			out: skim.Vector{skim.Symbol("a|b"), skim.Symbol("A\n\\")},
+		},
+		"keyword": {
+			in:  `(server :port 8080 :host "x")`,
+			out: skim.Vector{skim.List(skim.Symbol("server"), skim.Keyword("port"), skim.Int(8080), skim.Keyword("host"), skim.String("x"))},
+		},
+		"keyword/colon": {
+			in:  `: :: a:b`,
+			out: skim.Vector{skim.Symbol(":"), skim.Keyword(":"), skim.Symbol("a:b")},
+		},
+		"keyword/pipe": {
+			in:  `:|a b| :|| :|a)| :|x\|y\n| :|1|`,
+			out: skim.Vector{skim.Keyword("a b"), skim.Keyword(""), skim.Keyword("a)"), skim.Keyword("x|y\n"), skim.Keyword("1")},
+		},
 		"symbol/#foobar": {
 			in:  "#foobar",
 			out: skim.Vector{skim.Symbol("#foobar")},
 		},
+		"char/simple": {
+			in:  `#\a #\λ #\x #\0`,
+			out: skim.Vector{skim.Char('a'), skim.Char('λ'), skim.Char('x'), skim.Char('0')},
+		},
+		"char/named": {
+			in:  `#\space #\newline #\tab #\nul`,
+			out: skim.Vector{skim.Char(' '), skim.Char('\n'), skim.Char('\t'), skim.Char(0)},
+		},
+		"char/hex": {
+			in:  `#\x41 #\x3bb`,
+			out: skim.Vector{skim.Char('A'), skim.Char('λ')},
+		},
+		"char/sentinels": {
+			in:  `(#\( #\) #\; #\" #\  #\[)`,
+			out: skim.Vector{skim.List(skim.Char('('), skim.Char(')'), skim.Char(';'), skim.Char('"'), skim.Char(' '), skim.Char('['))},
+		},
+		"char/in-list": {
+			in:  `(#\a #\b)`,
+			out: skim.Vector{skim.List(skim.Char('a'), skim.Char('b'))},
+		},
 		"heredoc/lines": {
 			in: `(<<<---EOF
 		Foobar
 		Baz
 ---EOF)`,
 			out: skim.Vector{cons(skim.String("\t\tFoobar\n\t\tBaz\n"), nil)},
+			ext: true,
 		},
 		"heredoc/empty": {
 			in: `(<<<---EOF
 ---EOF)`,
 			out: skim.Vector{cons(skim.String(""), nil)},
+			ext: true,
 		},
 		"heredoc/empty-line": {
 			in: `(<<<---EOF
 
 ---EOF)`,
 			out: skim.Vector{cons(skim.String("\n"), nil)},
+			ext: true,
+		},
+		"heredoc/indented-terminator": {
+			in:  "(<<<EOF\n  EOF\nEOF)",
+			out: skim.Vector{cons(skim.String("  EOF\n"), nil)},
+			ext: true,
+		},
+		"heredoc/terminator-mid-line": {
+			in:  "(<<<---EOF\nfoo ---EOF bar\n---EOF)",
+			out: skim.Vector{cons(skim.String("foo ---EOF bar\n"), nil)},
+			ext: true,
+		},
+		"heredoc/terminator-prefix": {
+			in:  "(<<<---EOF\n---EOF bar\n---EOFX\n---EOF)",
+			out: skim.Vector{cons(skim.String("---EOF bar\n---EOFX\n"), nil)},
+			ext: true,
+		},
+		"heredoc/terminator-trailing-space": {
+			in:  "(<<<EOF\nbody\nEOF \t\n a)",
+			out: skim.Vector{skim.List(skim.String("body\n"), skim.Symbol("a"))},
+			ext: true,
+		},
+		"heredoc/terminator-eof": {
+			in:  "<<<EOF\nbody\nEOF",
+			out: skim.Vector{skim.String("body\n")},
+			ext: true,
+		},
+		"heredoc/terminator-vector": {
+			in:  "[<<<EOF\nbody]\nEOF]",
+			out: skim.Vector{skim.Vector{skim.String("body]\n")}},
+			ext: true,
+		},
+		"heredoc/top-level": {
+			in:  "a\n<<<EOF\nbody\nEOF\n<<<EOF\nlast\nEOF\n",
+			out: skim.Vector{skim.Symbol("a"), skim.String("body\n"), skim.String("last\n")},
+			ext: true,
+		},
+		"heredoc/last-element": {
+			in:  "(a <<<EOF\nbody\nEOF\n) (b <<<EOF\nbody\nEOF)",
+			out: skim.Vector{skim.List(skim.Symbol("a"), skim.String("body\n")), skim.List(skim.Symbol("b"), skim.String("body\n"))},
+			ext: true,
+		},
+		"heredoc/two-in-list": {
+			in:  "(<<<A\na\nA\n<<<B\nb\nB\n c)",
+			out: skim.Vector{skim.List(skim.String("a\n"), skim.String("b\n"), skim.Symbol("c"))},
+			ext: true,
+		},
+		"heredoc/opener-trailing-space": {
+			in:  "(<<<EOF \t\nbody\nEOF)",
+			out: skim.Vector{cons(skim.String("body\n"), nil)},
+			ext: true,
+		},
+		"error/heredoc/opener-close-paren": {
+			in:   "(a\n  <<<EOF)\nbody\nEOF",
+			fail: true,
+			err:  "2:3: skim: encountered invalid character ')' -- expected a newline after <<<EOF",
+			ext:  true,
+		},
+		"error/heredoc/opener-text": {
+			in:   "<<<EOF body\nEOF",
+			fail: true,
+			err:  "1:1: skim: encountered invalid character 'b' -- expected a newline after <<<EOF",
+			ext:  true,
+		},
+		"error/heredoc/opener-eof": {
+			in:   "<<<EOF",
+			fail: true,
+			err:  "unexpected EOF -- expected a newline after <<<EOF",
+			ext:  true,
+		},
+		"error/heredoc/unterminated": {
+			in:   "(<<<EOF\nbody EOF)",
+			fail: true,
+			err:  "unexpected EOF",
+			ext:  true,
+		},
+		"heredoc/squiggly": {
+			in: `(<<<~---EOF
+		Foobar
+		  Baz
+		---EOF)`,
+			out: skim.Vector{cons(skim.String("Foobar\n  Baz\n"), nil)},
+			ext: true,
+		},
+		"heredoc/squiggly-terminator-indent": {
+			in:  "(<<<~EOF\n    a\n      b\n        EOF)",
+			out: skim.Vector{cons(skim.String("a\n  b\n"), nil)},
+			ext: true,
+		},
+		"heredoc/squiggly-blank-lines": {
+			in:  "(<<<~EOF\n    a\n\n  \n      \n    b\n  EOF)",
+			out: skim.Vector{cons(skim.String("a\n\n\n  \nb\n"), nil)},
+			ext: true,
+		},
+		"heredoc/squiggly-only-blank": {
+			in:  "(<<<~EOF\n\n   \n\tEOF)",
+			out: skim.Vector{cons(skim.String("\n\n"), nil)},
+			ext: true,
+		},
+		"heredoc/squiggly-mixed-indent": {
+			in:  "(<<<~EOF\n\t  a\n\t b\nEOF\n<<<~EOF\n\ta\n  b\nEOF)",
+			out: skim.Vector{skim.List(skim.String(" a\nb\n"), skim.String("\ta\n  b\n"))},
+			ext: true,
+		},
+		"heredoc/squiggly-empty": {
+			in:  "(<<<~EOF\nEOF)",
+			out: skim.Vector{cons(skim.String(""), nil)},
+			ext: true,
 		},
 		"quasiquote-to-unquote": {
 			in:  "`(,())",
 			out: skim.Vector{cons(skim.Quasiquote, cons(cons(cons(skim.Unquote, cons(cons(nil, nil), nil)), nil), nil))},
 		},
+		"quasiquote-to-unquote-splicing": {
+			in: "`(1 ,@(list 2 3))",
+			out: skim.Vector{cons(skim.Quasiquote, cons(skim.List(
+				skim.Int(1),
+				skim.List(skim.UnquoteSplicing, skim.List(skim.Symbol("list"), skim.Int(2), skim.Int(3))),
+			), nil))},
+		},
+		"unquote-splicing/symbol": {
+			in:  ",@xs",
+			out: skim.Vector{skim.List(skim.UnquoteSplicing, skim.Symbol("xs"))},
+		},
+		"unquote/at-symbol": {
+			in:  ", @xs",
+			out: skim.Vector{skim.List(skim.Unquote, skim.Symbol("@xs"))},
+		},
+		"quote/vector": {
+			in:  `'[1 2 3] '[]`,
+			out: skim.Vector{quote(skim.Vector{skim.Int(1), skim.Int(2), skim.Int(3)}), quote(skim.Vector{})},
+			ext: true,
+		},
+		"quote/vector-elements": {
+			in:  `['a '[b]]`,
+			out: skim.Vector{skim.Vector{quote(skim.Symbol("a")), quote(skim.Vector{skim.Symbol("b")})}},
+			ext: true,
+		},
+		"quasiquote/vector": {
+			in: "`[a ,b ,@c]",
+			out: skim.Vector{skim.List(skim.Quasiquote, skim.Vector{
+				skim.Symbol("a"),
+				skim.List(skim.Unquote, skim.Symbol("b")),
+				skim.List(skim.UnquoteSplicing, skim.Symbol("c")),
+			})},
+			ext: true,
+		},
+		"unquote/vector": {
+			in:  ",[a]",
+			out: skim.Vector{skim.List(skim.Unquote, skim.Vector{skim.Symbol("a")})},
+			ext: true,
+		},
 		"quote/empty-list": {
 			in:  `'()`,
 			out: skim.Vector{quote(cons(nil, nil))},
@@ -165,6 +514,14 @@ func TestParse(t *testing.T) {
 			in:  `(quote (()))`,
 			out: skim.Vector{quote(cons(cons(nil, nil), nil))},
 		},
+		"byte-order-mark": {
+			in:  "\uFEFF(+ 1 2)",
+			out: skim.Vector{skim.List(skim.Symbol("+"), skim.Int(1), skim.Int(2))},
+		},
+		"byte-order-mark/only": {
+			in:  "\uFEFF",
+			out: skim.Vector(nil),
+		},
 		"comment": {
 			in:  "\n\n; a comment\n\n",
 			out: skim.Vector(nil),
@@ -173,21 +530,74 @@ func TestParse(t *testing.T) {
 			in:  "\n\n; a comment",
 			out: skim.Vector(nil),
 		},
+		"comment/block": {
+			in:  `(1 #| two |# 3)`,
+			out: skim.Vector{skim.List(skim.Int(1), skim.Int(3))},
+		},
+		"comment/block-nested": {
+			in:  "#| outer #| inner |# still comment |# 1",
+			out: skim.Vector{skim.Int(1)},
+		},
+		"comment/block-multiline": {
+			in:  "#|\n  (a b c) \"\n|#",
+			out: skim.Vector(nil),
+		},
+		"comment/block-adjacent": {
+			in:  "(a #|b|#c)",
+			out: skim.Vector{skim.List(skim.Symbol("a"), skim.Symbol("c"))},
+		},
+		"comment/block-dotted-tail": {
+			in:  "(a . b #| c |#)",
+			out: skim.Vector{cons(skim.Symbol("a"), skim.Symbol("b"))},
+		},
+		"comment/datum": {
+			in:  `(a #;(b c) d)`,
+			out: skim.Vector{skim.List(skim.Symbol("a"), skim.Symbol("d"))},
+		},
+		"comment/datum-top-level": {
+			in:  `#;1 2`,
+			out: skim.Vector{skim.Int(2)},
+		},
+		"comment/datum-only-form": {
+			in:  `#; (a "b" [c] 'd)`,
+			out: skim.Vector(nil),
+			ext: true,
+		},
+		"comment/datum-last-in-list": {
+			in:  `(a #;b)`,
+			out: skim.Vector{skim.List(skim.Symbol("a"))},
+		},
+		"comment/datum-nested": {
+			in:  `(a #;#;b c d)`,
+			out: skim.Vector{skim.List(skim.Symbol("a"), skim.Symbol("d"))},
+		},
+		"comment/datum-quoted": {
+			in:  `('#;a b #;'c)`,
+			out: skim.Vector{skim.List(quote(skim.Symbol("b")))},
+		},
+		"comment/datum-dotted": {
+			in:  `(a . #;b c #;d)`,
+			out: skim.Vector{cons(skim.Symbol("a"), skim.Symbol("c"))},
+		},
 		"vector/empty": {
 			in:  "[]",
 			out: skim.Vector{skim.Vector{}},
+			ext: true,
 		},
 		"vector/nonempty": {
 			in:  `[1 -2 "three"]`,
 			out: skim.Vector{skim.Vector{skim.Int(1), skim.Int(-2), skim.String("three")}},
+			ext: true,
 		},
 		"vector/nested-in-vector": {
 			in:  `[[1 -2 "three"]]`,
 			out: skim.Vector{skim.Vector{skim.Vector{skim.Int(1), skim.Int(-2), skim.String("three")}}},
+			ext: true,
 		},
 		"vector/nested-in-list": {
 			in:  `([1 -2 "three"])`,
 			out: skim.Vector{skim.List(skim.Vector{skim.Int(1), skim.Int(-2), skim.String("three")})},
+			ext: true,
 		},
 		"let": {
 			in: `(let ((name "Foo Bar")                                              ; Comment on first line
@@ -209,40 +619,241 @@ func TestParse(t *testing.T) {
 				),
 			},
 		},
+		"dotted/pair": {
+			in:  `(a . b)`,
+			out: skim.Vector{cons(skim.Symbol("a"), skim.Symbol("b"))},
+		},
+		"dotted/list": {
+			in:  `(1 2 . 3)`,
+			out: skim.Vector{cons(skim.Int(1), cons(skim.Int(2), skim.Int(3)))},
+		},
+		"dotted/list-tail": {
+			in:  `(1 . (2 3))`,
+			out: skim.Vector{skim.List(skim.Int(1), skim.Int(2), skim.Int(3))},
+		},
+		"dotted/quoted-tail": {
+			in:  `(1 . 'b) ; comment`,
+			out: skim.Vector{cons(skim.Int(1), quote(skim.Symbol("b")))},
+		},
+		"dotted/float": {
+			in:  `(.5 . -.5)`,
+			out: skim.Vector{cons(skim.Float(.5), skim.Float(-.5))},
+		},
+		"map": {
+			in: `{"a" 1 "b" [2 3]}`,
+			out: skim.Vector{skim.List(
+				cons(skim.String("a"), skim.Int(1)),
+				cons(skim.String("b"), skim.Vector{skim.Int(2), skim.Int(3)}),
+			)},
+			ext: true,
+		},
+		"map/empty": {
+			in:  `{}`,
+			out: skim.Vector{skim.List()},
+			ext: true,
+		},
+		"map/nested": {
+			in: `{a {b 'c} d (1 2)}`,
+			out: skim.Vector{skim.List(
+				cons(skim.Symbol("a"), skim.List(cons(skim.Symbol("b"), quote(skim.Symbol("c"))))),
+				cons(skim.Symbol("d"), skim.List(skim.Int(1), skim.Int(2))),
+			)},
+			ext: true,
+		},
+		"map/comments": {
+			in:  "{a #;b 1 ; comment\n c #| d |# 2}",
+			out: skim.Vector{skim.List(cons(skim.Symbol("a"), skim.Int(1)), cons(skim.Symbol("c"), skim.Int(2)))},
+			ext: true,
+		},
+		"map/symbol-sentinels": {
+			in:  `{a 1}b{}`,
+			out: skim.Vector{skim.List(cons(skim.Symbol("a"), skim.Int(1))), skim.Symbol("b"), skim.List()},
+			ext: true,
+		},
+		"bytes": {
+			in:  `#u8(0 255 0x1f #;256 #b11) #u8()`,
+			out: skim.Vector{skim.Bytes{0, 255, 0x1f, 3}, skim.Bytes{}},
+			ext: true,
+		},
+		"bytes/quoted": {
+			in:  `'#u8(1)`,
+			out: skim.Vector{quote(skim.Bytes{1})},
+		},
+		"bytes/symbol": {
+			in:  `(#u8 (1))`,
+			out: skim.Vector{skim.List(skim.Symbol("#u8"), skim.List(skim.Int(1)))},
+		},
+		"error/bytes/dotted": {
+			in:   `#u8(1 . 2)`,
+			fail: true,
+			err:  "1:7: skim: malformed dotted pair -- dot must follow at least one datum in a list",
+		},
+
+		"error/dotted/no-car": {
+			in:   `(. x)`,
+			fail: true,
+			err:  "1:2: skim: malformed dotted pair -- dot must follow at least one datum in a list",
+		},
+		"error/dotted/two-tails": {
+			in:   `(a . b c)`,
+			fail: true,
+			err:  "1:8: skim: malformed dotted pair -- expected ) after the tail of a dotted pair",
+		},
+		"error/dotted/no-tail": {
+			in:   `(a .)`,
+			fail: true,
+			err:  "1:5: skim: malformed dotted pair -- expected a datum after dot",
+		},
+		"error/dotted/two-dots": {
+			in:   `(a . . b)`,
+			fail: true,
+			err:  "1:6: skim: malformed dotted pair -- dotted pair has more than one dot",
+		},
+		"error/dotted/vector": {
+			in:   `[a . b]`,
+			fail: true,
+			err:  "1:4: skim: malformed dotted pair -- dot must follow at least one datum in a list",
+			ext:  true,
+		},
 
+		"error/unquote-splicing/eof": {
+			in:   ",@",
+			fail: true,
+			err:  "1:1: unexpected EOF -- expected a datum after ,@",
+		},
+		"error/char/unknown-name": {
+			in:   `#\bogus`,
+			fail: true,
+			err:  "1:1: skim: unknown character name \"bogus\"",
+		},
+		"error/char/bad-hex": {
+			in:   `#\x110000`,
+			fail: true,
+			err:  "1:1: skim: unknown character name \"x110000\"",
+		},
+		"error/char/eof": {
+			in:   `#\`,
+			fail: true,
+			err:  `1:1: unexpected EOF -- expected character after #\`,
+		},
+		"error/comment/block-unclosed": {
+			in:   "#| #| |#",
+			fail: true,
+			err:  "1:1: skim: unclosed block comment, expecting |# -- encountered EOF inside block comment",
+		},
+		"error/comment/datum-unclosed-string": {
+			in:   `#;(a "b) c`,
+			fail: true,
+			err:  "1:6: skim: unclosed \", expecting \" -- encountered EOF inside string",
+		},
+		"error/comment/datum-bad-char": {
+			in:   `(#;#\bogus a)`,
+			fail: true,
+			err:  "1:4: skim: unknown character name \"bogus\"",
+		},
+		"error/comment/datum-missing": {
+			in:   `(a #;)`,
+			fail: true,
+			err:  "1:6: skim: unbalanced ) -- expected a datum after #; at 1:4",
+		},
+		"error/comment/datum-eof": {
+			in:   `#;`,
+			fail: true,
+			err:  "1:1: unexpected EOF -- expected a datum after #;",
+		},
+		"error/prefix/conflicting-exactness": {
+			in:   "#e#i1",
+			fail: true,
+			err:  "1:1: skim: invalid number \"#e#i1\" -- prefix #i conflicts with #e",
+		},
+		"error/prefix/duplicate-exactness": {
+			in:   "#e#e1",
+			fail: true,
+			err:  "1:1: skim: invalid number \"#e#e1\" -- duplicate prefix #e",
+		},
+		"error/prefix/conflicting-radix": {
+			in:   "#x#b1",
+			fail: true,
+			err:  "1:1: skim: invalid number \"#x#b1\" -- prefix #b conflicts with #x",
+		},
+		"error/prefix/bad-digits": {
+			in:   "#xfg",
+			fail: true,
+			err:  "1:1: skim: invalid number \"#xfg\" -- invalid number in base 16",
+		},
+		"error/prefix/hex-float": {
+			in:   "#x1.5",
+			fail: true,
+			err:  "1:1: skim: invalid number \"#x1.5\" -- invalid number in base 16",
+		},
+		"error/symbol/pipe-unclosed": {
+			in:   `(|foo bar)`,
+			fail: true,
+			err:  "1:2: skim: unclosed |, expecting | -- encountered EOF inside symbol",
+		},
+		"error/byte-order-mark/twice": {
+			in:   "\uFEFF\uFEFF(+ 1 2)",
+			fail: true,
+			err:  `1:2: skim: encountered invalid character '\ufeff'`,
+		},
+		"error/byte-order-mark/in-symbol": {
+			in:   "(+\uFEFF 1 2)",
+			fail: true,
+			err:  `1:3: skim: encountered invalid character '\ufeff'`,
+		},
+		"error/byte-order-mark/in-string": {
+			in:   "\"\uFEFF\"",
+			fail: true,
+			err:  `1:2: skim: encountered invalid character '\ufeff'`,
+		},
 		"error/cons/closed-by-vector": {
 			in:   `(]`,
 			fail: true,
+			err:  "1:2: skim: unbalanced ] -- ] does not close ( opened at 1:1, expected )",
 		},
 		"error/cons/unclosed": {
 			in:   `(`,
 			fail: true,
+			err:  "1:1: skim: unclosed (, expecting ) -- encountered EOF inside (",
 		},
 		"error/cons/double-close": {
 			in:   `())`,
 			fail: true,
+			err:  "1:3: skim: unbalanced ) -- no open scope",
 		},
 		"error/vector/closed-by-list": {
 			in:   `[)`,
 			fail: true,
+			err:  "1:2: skim: unbalanced ) -- ) does not close [ opened at 1:1, expected ]",
+			ext:  true,
 		},
 		"error/vector/unclosed": {
 			in:   `[`,
 			fail: true,
+			err:  "1:1: skim: unclosed [, expecting ] -- encountered EOF inside [",
+			ext:  true,
 		},
 		"error/vector/double-close": {
 			in:   `[]]`,
 			fail: true,
+			err:  "1:3: skim: unbalanced ] -- no open scope",
+			ext:  true,
 		},
 		"error/vector/close-root": {
 			in:   `]`,
 			fail: true,
+			err:  "1:1: skim: unbalanced ] -- no open scope",
 		},
 		"error/cons/close-root": {
 			in:   `)`,
 			fail: true,
+			err:  "1:1: skim: unbalanced ) -- no open scope",
 		},
 	}
+}
+
+func TestParse(t *testing.T) {
+	cases := parseCases()
 
 	keys := make([]string, 0, len(cases))
 	for name := range cases {
@@ -263,6 +874,9 @@ func TestParse(t *testing.T) {
 				}
 				t.Fatalf("Read(%q) err = (%T) %v; want %s", c.in, err, err, want)
 			}
+			if c.fail && !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("Read(%q) err = %v; want an error containing %q", c.in, err, c.err)
+			}
 			if !c.fail && !reflect.DeepEqual(got, want) {
 				t.Fatalf("Read(%q) failed;\ngot  %v\nwant %v", c.in, got, want)
 			}
@@ -282,5 +896,1320 @@ func TestParse(t *testing.T) {
 				t.Fatalf("Read(%q) failed;\ngot  %v\nwant %v", c.in, got, want)
 			}
 		})
+		t.Run(name+"/in-memory", func(t *testing.T) {
+			debug.SetLoggerf(t.Logf)
+			want, wanterr := Read(strings.NewReader(c.in))
+			if got, err := ReadString(c.in); !reflect.DeepEqual(got, want) || !reflect.DeepEqual(err, wanterr) {
+				t.Fatalf("ReadString(%q) = %v, %v; want %v, %v", c.in, got, err, want, wanterr)
+			}
+			if got, err := ReadBytes([]byte(c.in)); !reflect.DeepEqual(got, want) || !reflect.DeepEqual(err, wanterr) {
+				t.Fatalf("ReadBytes(%q) = %v, %v; want %v, %v", c.in, got, err, want, wanterr)
+			}
+		})
+		t.Run(name+"/strict", func(t *testing.T) {
+			debug.SetLoggerf(t.Logf)
+			got, err := Options{Strict: true}.Read(strings.NewReader(c.in))
+			var ext ExtensionError
+			if errors.As(err, &ext) != c.ext {
+				t.Fatalf("Read(%q) with Strict err = (%T) %v; want ExtensionError = %t", c.in, err, err, c.ext)
+			} else if c.ext {
+				return
+			}
+			want, wanterr := Read(strings.NewReader(c.in))
+			if !reflect.DeepEqual(got, want) || !reflect.DeepEqual(err, wanterr) {
+				t.Fatalf("Read(%q) with Strict = %v, %v; want %v, %v", c.in, got, err, want, wanterr)
+			}
+		})
+	}
+}
+
+func TestParseEmptyList(t *testing.T) {
+	data, err := ReadString("() '() {} (#nil) (a . ())")
+	if err != nil {
+		t.Fatal(err)
+	}
+	quoted, _ := skim.Cadr(data[1])
+	tail, _ := skim.Cdr(data[4])
+	for i, a := range []skim.Atom{data[0], quoted, data[2], tail} {
+		if a != skim.Atom(skim.Nil) {
+			t.Errorf("empty list %d = %#v; want skim.Nil", i, a)
+		}
+	}
+	if pair := data[3].(*skim.Cons); pair == skim.Nil || skim.IsNil(pair) {
+		t.Errorf("(#nil) = %#v; want a pair holding #nil", pair)
+	}
+}
+
+func TestParseDottedPairErrors(t *testing.T) {
+	for _, in := range []string{`(. x)`, `(a . b c)`, `(a .)`, `(a . (b) "c")`} {
+		_, err := Read(strings.NewReader(in))
+		se, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Read(%q) err = (%T) %v; want *SyntaxError", in, err, err)
+		} else if se.Err != ErrDottedPair {
+			t.Errorf("Read(%q) err = %v; want %v", in, se.Err, ErrDottedPair)
+		} else if se.Line != 1 {
+			t.Errorf("Read(%q) line = %d; want 1", in, se.Line)
+		}
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	cases := map[string]struct {
+		in                string
+		line, col, offset int
+	}{
+		"string":        {"(a\n b\n  (c \"unterminated)", 3, 6, 11},
+		"string/escape": {"\"\\x4g\"", 1, 5, 4},
+		"string/octal":  {"(a \"b\\777\")", 1, 6, 5},
+		"close":         {"\n\n  )", 3, 3, 4},
+		"close/vector":  {"[a\n  (b c]", 2, 7, 9},
+		"char":          {"(a\n  #\\bogus)", 2, 3, 5},
+		"pipe":          {"(a\n\t|b\n", 2, 2, 4},
+		"block-comment": {"(a\n  #| x\n", 2, 3, 5},
+		"dotted-pair":   {"(a . b c)\n", 1, 8, 7},
+		"bom":           {"(a \uFEFF)", 1, 4, 3},
+		"multibyte":     {"(λ \"x", 1, 4, 4},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(c.in))
+			se, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("Read(%q) err = (%T) %v; want *SyntaxError", c.in, err, err)
+			} else if se.Line != c.line || se.Col != c.col {
+				t.Fatalf("Read(%q) position = %d:%d; want %d:%d", c.in, se.Line, se.Col, c.line, c.col)
+			} else if se.Offset != c.offset {
+				t.Fatalf("Read(%q) offset = %d; want %d", c.in, se.Offset, c.offset)
+			}
+		})
+	}
+}
+
+func TestParseUnclosedScope(t *testing.T) {
+	cases := map[string]struct {
+		in        string
+		err       error
+		line, col int
+		desc      string
+	}{
+		"list":            {"(a\n  (b\n   [c d]\n   (e f)", UnclosedError('('), 2, 3, "encountered EOF inside ("},
+		"vector":          {"(a [b\n  c", UnclosedError('['), 1, 4, "encountered EOF inside ["},
+		"string":          {"(a \"b)", UnclosedError('"'), 1, 4, "encountered EOF inside string"},
+		"comment":         {"(a ; b)", UnclosedError('('), 1, 1, "encountered EOF inside ("},
+		"quote":           {"(a\n 'b\n  '", io.ErrUnexpectedEOF, 3, 3, "expected a datum after '"},
+		"quote/root":      {"a '", io.ErrUnexpectedEOF, 1, 3, "expected a datum after '"},
+		"splice":          {"`(a ,@", io.ErrUnexpectedEOF, 1, 5, "expected a datum after ,@"},
+		"datum-comment":   {"(a #;", io.ErrUnexpectedEOF, 1, 4, "expected a datum after #;"},
+		"mismatch/vector": {"(a\n  [b c)", UnbalancedError(')'), 2, 7, ") does not close [ opened at 2:3, expected ]"},
+		"mismatch/list":   {"[a (b c]", UnbalancedError(']'), 1, 8, "] does not close ( opened at 1:4, expected )"},
+		"mismatch/quote":  {"(a ')", UnbalancedError(')'), 1, 5, "expected a datum after ' at 1:4"},
+		"mismatch/root":   {"a]", UnbalancedError(']'), 1, 2, "no open scope"},
+		"map":             {"(a {b 1\n  c", UnclosedError('{'), 1, 4, "encountered EOF inside {"},
+		"mismatch/map":    {"{a (b c}", UnbalancedError('}'), 1, 8, "} does not close ( opened at 1:4, expected )"},
+		"mismatch/brace":  {"[a {b c]", UnbalancedError(']'), 1, 8, "] does not close { opened at 1:4, expected }"},
+		"bytes":           {"(#u8(1 2", UnclosedError('('), 1, 2, "encountered EOF inside #u8("},
+		"bytes/range":     {"#u8(1\n 256)", ErrByte, 2, 2, "bytevector element 256 is not an integer from 0 to 255"},
+		"bytes/type":      {"#u8(1 2 a)", ErrByte, 1, 9, "bytevector element a is not an integer from 0 to 255"},
+		"bytes/negative":  {"#u8(-1)", ErrByte, 1, 5, "bytevector element -1 is not an integer from 0 to 255"},
+		"mismatch/bytes":  {"#u8(1]", UnbalancedError(']'), 1, 6, "] does not close #u8( opened at 1:1, expected )"},
+		"map/dangling":    {"{a 1\n b}", ErrMapKey, 2, 2, "map key b has no value"},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(c.in))
+			se, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("Read(%q) err = (%T) %v; want *SyntaxError", c.in, err, err)
+			} else if se.Err != c.err {
+				t.Fatalf("Read(%q) err = %v; want %v", c.in, se.Err, c.err)
+			} else if se.Line != c.line || se.Col != c.col {
+				t.Fatalf("Read(%q) position = %d:%d; want %d:%d", c.in, se.Line, se.Col, c.line, c.col)
+			} else if se.Desc != c.desc {
+				t.Fatalf("Read(%q) desc = %q; want %q", c.in, se.Desc, c.desc)
+			}
+		})
+	}
+}
+
+func TestParseErrorsAs(t *testing.T) {
+	cases := map[string]struct {
+		in    string
+		check func(error) bool
+	}{
+		"string": {`(a "b`, func(err error) bool {
+			var want UnclosedError
+			return errors.As(err, &want) && want == '"'
+		}},
+		"unbalanced/list": {`(a]`, func(err error) bool {
+			var want UnbalancedError
+			return errors.As(err, &want) && want == ']'
+		}},
+		"unbalanced/root": {`a)`, func(err error) bool {
+			var want UnbalancedError
+			return errors.As(err, &want) && want == ')'
+		}},
+		"hex": {`"\x4g"`, func(err error) bool {
+			var want BadCharError
+			return errors.As(err, &want) && want == 'g'
+		}},
+		"hex/eof": {`"\x4`, func(err error) bool {
+			return errors.Is(err, io.ErrUnexpectedEOF)
+		}},
+		"block-comment": {`#| a`, func(err error) bool {
+			return errors.Is(err, ErrUnclosedComment)
+		}},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(c.in))
+			if _, ok := err.(*SyntaxError); !ok {
+				t.Fatalf("Read(%q) err = (%T) %v; want *SyntaxError", c.in, err, err)
+			} else if !c.check(err) {
+				t.Fatalf("Read(%q) err = (%T) %v; wrapped error does not match", c.in, err.(*SyntaxError).Err, err)
+			}
+		})
+	}
+}
+
+func TestParseCharNameError(t *testing.T) {
+	_, err := Read(strings.NewReader("(a\n  #\\bogus)"))
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("Read(..) err = (%T) %v; want *SyntaxError", err, err)
+	} else if want := CharNameError("bogus"); se.Err != want {
+		t.Fatalf("Read(..) err = %v; want %v", se.Err, want)
+	} else if se.Line != 2 {
+		t.Fatalf("Read(..) line = %d; want 2", se.Line)
+	}
+}
+
+func TestParseUnclosedBlockComment(t *testing.T) {
+	_, err := Read(strings.NewReader("(a\n#| b\n\n c)"))
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("Read(..) err = (%T) %v; want *SyntaxError", err, err)
+	} else if se.Err != ErrUnclosedComment {
+		t.Fatalf("Read(..) err = %v; want %v", se.Err, ErrUnclosedComment)
+	} else if se.Line != 2 {
+		t.Fatalf("Read(..) line = %d; want 2", se.Line)
+	}
+}
+
+func TestParseBigIntRoundTrip(t *testing.T) {
+	for _, want := range []skim.Atom{
+		bigint("9223372036854775808"),
+		bigint("-170141183460469231731687303715884105728"),
+	} {
+		got, err := Read(strings.NewReader(want.String()))
+		if err != nil {
+			t.Errorf("Read(%q) err = %v; want nil", want, err)
+		} else if !reflect.DeepEqual(got, skim.Vector{want}) {
+			t.Errorf("Read(%q) = %v; want %v", want, got, skim.Vector{want})
+		}
+	}
+}
+
+func TestParseNumberPrefixError(t *testing.T) {
+	_, err := Read(strings.NewReader("\n\n(#x#e#x10)"))
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("Read(..) err = (%T) %v; want *SyntaxError", err, err)
+	} else if want := NumberError("#x#e#x10"); se.Err != want {
+		t.Fatalf("Read(..) err = %v; want %v", se.Err, want)
+	} else if se.Line != 3 {
+		t.Fatalf("Read(..) line = %d; want 3", se.Line)
+	} else if want := "duplicate prefix #x"; se.Desc != want {
+		t.Fatalf("Read(..) desc = %q; want %q", se.Desc, want)
+	}
+}
+
+func TestParseUnclosedPipeSymbol(t *testing.T) {
+	_, err := Read(strings.NewReader("(a\n  |b c\n\n d)"))
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("Read(..) err = (%T) %v; want *SyntaxError", err, err)
+	} else if want := UnclosedError('|'); se.Err != want {
+		t.Fatalf("Read(..) err = %v; want %v", se.Err, want)
+	} else if se.Line != 2 {
+		t.Fatalf("Read(..) line = %d; want 2", se.Line)
+	}
+}
+
+func TestParseSymbolRoundTrip(t *testing.T) {
+	for _, want := range []skim.Symbol{"foo", "foo bar", "(x)", "a|b", `a\b`, "", ".", "x;y", "tab\there"} {
+		got, err := Read(strings.NewReader(want.String()))
+		if err != nil {
+			t.Errorf("Read(%q) err = %v; want nil", want.String(), err)
+		} else if !reflect.DeepEqual(got, skim.Vector{want}) {
+			t.Errorf("Read(%q) = %#v; want %#v", want.String(), got, skim.Vector{want})
+		}
+	}
+}
+
+func TestParseBytesRoundTrip(t *testing.T) {
+	want := skim.Bytes{0, 1, 0x7f, 0x80, 255}
+	for _, in := range []string{want.String(), want.GoString()} {
+		got, err := Read(strings.NewReader(in))
+		if err != nil {
+			t.Errorf("Read(%q) err = %v; want nil", in, err)
+		} else if !reflect.DeepEqual(got, skim.Vector{want}) {
+			t.Errorf("Read(%q) = %#v; want %#v", in, got, skim.Vector{want})
+		}
+	}
+}
+
+func TestParseSpecialFloats(t *testing.T) {
+	cases := map[string]float64{
+		"+inf.0": math.Inf(1),
+		"-inf.0": math.Inf(-1),
+		"+nan.0": math.NaN(),
+		"-nan.0": math.NaN(),
+	}
+
+	for in, want := range cases {
+		got, err := ReadString(in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", in, err)
+		}
+		f, ok := got[0].(skim.Float)
+		if !ok || !(float64(f) == want || math.IsNaN(float64(f)) && math.IsNaN(want)) {
+			t.Fatalf("Read(%q) = %#v; want %v", in, got, want)
+		}
+
+		// Printed floats read back as the same value.
+		if got, err = ReadString(f.String()); err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", f.String(), err)
+		} else if g, ok := got[0].(skim.Float); !ok || g.String() != f.String() {
+			t.Fatalf("Read(%q) = %#v; want %v", f.String(), got, f)
+		}
+	}
+
+	// Other spellings remain symbols.
+	for _, in := range []string{"inf.0", "nan.0", "+inf", "+Inf", "NaN"} {
+		if got, err := ReadString(in); err != nil || got[0] != skim.Symbol(in) {
+			t.Errorf("Read(%q) = %#v, %v; want symbol, nil", in, got, err)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	const max = 16
+	opts := Options{MaxTokenBytes: max, MaxStringBytes: max}
+	long := strings.Repeat("x", max+1)
+	exact := strings.Repeat("x", max)
+
+	ok := []string{
+		exact,
+		"-" + strings.Repeat("1", max-1),
+		`"` + exact + `"`,
+		`"` + strings.Repeat(`\n`, max) + `"`,
+		"|" + exact + "|",
+		"(<<<END\n" + strings.Repeat("x", max-1) + "\nEND\n)",
+		"; " + long + long + "\na",
+	}
+	for _, in := range ok {
+		if _, err := opts.ReadString(in); err != nil {
+			t.Errorf("Read(%q) err = %v; want nil", in, err)
+		}
+	}
+
+	fail := []struct {
+		in, desc  string
+		line, col int
+	}{
+		{"(a\n  " + long + ")", "token exceeds 16 bytes", 2, 3},
+		{"(a " + strings.Repeat("1", max+1) + ")", "token exceeds 16 bytes", 1, 4},
+		{`(a "` + long + `")`, "string literal exceeds 16 bytes", 1, 4},
+		{`"` + strings.Repeat(`\n`, max+1) + `"`, "string literal exceeds 16 bytes", 1, 1},
+		{"|" + long + "|", "symbol exceeds 16 bytes", 1, 1},
+		{"(<<<END\n" + exact + "\nEND\n)", "heredoc exceeds 16 bytes", 1, 2},
+		{"(<<<END\n" + long + long, "heredoc exceeds 16 bytes", 1, 2},
+		{"(<<<END\n" + strings.Repeat(" ", 5000), "heredoc exceeds 16 bytes", 1, 2},
+		{"(<<<~END\n" + strings.Repeat(" \n", 5000), "heredoc exceeds 16 bytes", 1, 2},
+	}
+	for _, c := range fail {
+		_, err := opts.ReadString(c.in)
+		se, ok := err.(*SyntaxError)
+		if !ok || se.Err != ErrTooLong || se.Desc != c.desc {
+			t.Errorf("Read(%q) err = %v; want %v -- %s", c.in, err, ErrTooLong, c.desc)
+		} else if se.Line != c.line || se.Col != c.col {
+			t.Errorf("Read(%q) position = %d:%d; want %d:%d", c.in, se.Line, se.Col, c.line, c.col)
+		}
+
+		// Lengths are unlimited by default.
+		if _, err := ReadString(c.in); errors.Is(err, ErrTooLong) {
+			t.Errorf("Read(%q) without limits err = %v", c.in, err)
+		}
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	cases := []struct {
+		in        string
+		ext       ExtensionError
+		line, col int
+	}{
+		{"(a\n  <<<EOF\nb\nEOF\n)", "heredoc", 2, 3},
+		{"(a [b])", "bracket vector", 1, 4},
+		{"{a 1}", "brace map", 1, 1},
+		{"(+ 1 -0x1f)", "0x literal", 1, 6},
+		{"0b11", "0b literal", 1, 1},
+		{"(017)", "leading-zero octal literal", 1, 2},
+		{"1_000", "digit separator", 1, 1},
+		{"#x1_0", "digit separator", 1, 1},
+	}
+
+	for _, c := range cases {
+		_, err := Options{Strict: true}.ReadString(c.in)
+		se, ok := err.(*SyntaxError)
+		if !ok || se.Err != c.ext {
+			t.Errorf("Read(%q) err = %v; want %v", c.in, err, c.ext)
+		} else if se.Line != c.line || se.Col != c.col {
+			t.Errorf("Read(%q) position = %d:%d; want %d:%d", c.in, se.Line, se.Col, c.line, c.col)
+		}
+	}
+}
+
+func TestParseBracketsAsLists(t *testing.T) {
+	sym := func(s string) skim.Atom { return skim.Symbol(s) }
+	cases := map[string]skim.Atom{
+		"[a b]":     skim.List(sym("a"), sym("b")),
+		"[]":        skim.List(),
+		"[a . b]":   cons(sym("a"), sym("b")),
+		"([a] (b))": skim.List(skim.List(sym("a")), skim.List(sym("b"))),
+		"'[a [b]]":  quote(skim.List(sym("a"), skim.List(sym("b")))),
+	}
+	for _, opts := range []Options{{BracketsAsLists: true}, {BracketsAsLists: true, Strict: true}} {
+		for in, want := range cases {
+			if got, err := opts.ReadString(in); err != nil || !reflect.DeepEqual(got, skim.Vector{want}) {
+				t.Errorf("Read(%q) = %v, %v; want %v, nil", in, got, err, skim.Vector{want})
+			}
+		}
+
+		// Lists must be closed by the bracket that opened them.
+		for _, in := range []string{"(]", "[)", "[a (b])", "([a)]", "[a . ]"} {
+			if _, err := opts.ReadString(in); err == nil {
+				t.Errorf("Read(%q) err = nil; want an error", in)
+			}
+		}
+	}
+}
+
+func TestParseRecover(t *testing.T) {
+	type pos struct{ line, col int }
+	opts := Options{Recover: true, StrictEscapes: true}
+	cases := map[string]struct {
+		in   string
+		want []pos
+		out  string // data read, if the input can be read to the end
+	}{
+		"three": {
+			in:   "(a \"b\\q\")\n)\n[c #\\bogus d]",
+			want: []pos{{1, 6}, {2, 1}, {3, 4}},
+			out:  `[(a "bq") [c d]]`,
+		},
+		"mismatched": {
+			in:   "(a [b c) d])",
+			want: []pos{{1, 8}},
+			out:  "[(a [b c d])]",
+		},
+		"map-and-bytes": {
+			in:   "{a 1 b} #u8(1 256 2) (. x) #1#",
+			want: []pos{{1, 6}, {1, 15}, {1, 23}, {1, 28}},
+			out:  "[{a 1} #u8(1 2) (x)]",
+		},
+		"unrecoverable": {
+			in:   "a ) (b \"c",
+			want: []pos{{1, 3}, {1, 8}},
+		},
+		"none": {
+			in:  "(a b)",
+			out: "[(a b)]",
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			var got skim.Vector
+			dec := opts.NewDecoder(strings.NewReader(c.in))
+			var err error
+			for err == nil {
+				var datum skim.Atom
+				if datum, err = dec.Next(); err == nil {
+					got = append(got, datum)
+				}
+			}
+
+			var merr MultiError
+			if len(c.want) == 0 {
+				if err != io.EOF {
+					t.Fatalf("Next() err = %v; want EOF", err)
+				}
+			} else if !errors.As(err, &merr) {
+				t.Fatalf("Next() err = (%T) %v; want MultiError", err, err)
+			} else if len(merr) != len(c.want) {
+				t.Fatalf("Next() err = %v; want %d errors", err, len(c.want))
+			} else {
+				for i, se := range merr {
+					if (pos{se.Line, se.Col}) != c.want[i] {
+						t.Errorf("error %d at %d:%d; want %d:%d (%v)", i, se.Line, se.Col, c.want[i].line, c.want[i].col, se)
+					}
+				}
+			}
+
+			if c.out != "" && fmt.Sprint(got) != c.out {
+				t.Errorf("Next() read %v; want %s", got, c.out)
+			}
+		})
+	}
+
+	// Without Recover, reading stops at the first error.
+	_, err := Options{StrictEscapes: true}.ReadString(`"\q" )`)
+	if se, ok := err.(*SyntaxError); !ok || se.Err != EscapeError('q') {
+		t.Fatalf("Read(..) err = (%T) %v; want *SyntaxError for \\q", err, err)
+	}
+}
+
+func TestParseNamedError(t *testing.T) {
+	const in = "(a\n  ]"
+
+	_, err := Read(strings.NewReader(in))
+	if want := "skim: syntax error at 2:3: skim: unbalanced ] -- ] does not close ( opened at 1:1, expected )"; err == nil || err.Error() != want {
+		t.Errorf("Read(%q) err = %v; want %s", in, err, want)
+	}
+
+	_, err = ReadNamed("input.skim", strings.NewReader(in))
+	if se, ok := err.(*SyntaxError); !ok {
+		t.Fatalf("ReadNamed(%q) err = (%T) %v; want *SyntaxError", in, err, err)
+	} else if se.Name != "input.skim" {
+		t.Errorf("ReadNamed(%q) name = %q; want %q", in, se.Name, "input.skim")
+	}
+	if want := "input.skim:2:3: skim: syntax error: skim: unbalanced ] -- ] does not close ( opened at 1:1, expected )"; err.Error() != want {
+		t.Errorf("ReadNamed(%q) err = %v; want %s", in, err, want)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.skim")
+	if err := os.WriteFile(path, []byte("(a b)\n(c\n\"d"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ReadFile(path)
+	if se, ok := err.(*SyntaxError); !ok {
+		t.Fatalf("ReadFile(%q) err = (%T) %v; want *SyntaxError", path, err, err)
+	} else if se.Name != path || se.Line != 3 || se.Col != 1 {
+		t.Fatalf("ReadFile(%q) err at %s:%d:%d; want %s:3:1", path, se.Name, se.Line, se.Col, path)
+	}
+
+	if _, err = ReadFile(path + ".missing"); !os.IsNotExist(err) {
+		t.Fatalf("ReadFile(%q) err = %v; want not-exist error", path+".missing", err)
+	}
+}
+
+func TestParseMaxDepth(t *testing.T) {
+	const max = 64
+	opts := Options{MaxDepth: max}
+
+	in := strings.Repeat("(", max) + strings.Repeat(")", max)
+	if _, err := opts.Read(strings.NewReader(in)); err != nil {
+		t.Fatalf("Read(depth %d) err = %v; want nil", max, err)
+	}
+
+	for open, close := range map[string]string{"(": ")", "[": "]", "{": " 1}", "'": "", "#;": ""} {
+		in := strings.Repeat("(", max-1) + open + "a" + close + strings.Repeat(")", max-1)
+		if _, err := opts.Read(strings.NewReader(in)); err != nil {
+			t.Fatalf("Read(depth %d with %s) err = %v; want nil", max, open, err)
+		}
+
+		in = strings.Repeat("(", max) + open + "a" + close + strings.Repeat(")", max)
+		_, err := opts.Read(strings.NewReader(in))
+		se, ok := err.(*SyntaxError)
+		if !ok {
+			t.Fatalf("Read(depth %d with %s) err = (%T) %v; want *SyntaxError", max+1, open, err, err)
+		} else if se.Err != DepthError(max) {
+			t.Fatalf("Read(depth %d with %s) err = %v; want %v", max+1, open, se.Err, DepthError(max))
+		} else if se.Line != 1 || se.Col != max+1 {
+			t.Fatalf("Read(depth %d with %s) position = %d:%d; want 1:%d", max+1, open, se.Line, se.Col, max+1)
+		}
+	}
+
+	// Depth is unlimited by default.
+	in = strings.Repeat("(", 4*max) + strings.Repeat(")", 4*max)
+	if _, err := Read(strings.NewReader(in)); err != nil {
+		t.Fatalf("Read(depth %d) err = %v; want nil", 4*max, err)
+	}
+}
+
+func TestDecoderNext(t *testing.T) {
+	const in = "(a b)\n; comment\n  [c]\n'd #;e\n\"f\" g"
+	type pos struct{ line, col, offset int }
+	want := []struct {
+		datum skim.Atom
+		pos   pos
+	}{
+		{skim.List(skim.Symbol("a"), skim.Symbol("b")), pos{1, 1, 0}},
+		{skim.Vector{skim.Symbol("c")}, pos{3, 3, 18}},
+		{quote(skim.Symbol("d")), pos{4, 1, 22}},
+		{skim.String("f"), pos{5, 1, 29}},
+		{skim.Symbol("g"), pos{5, 5, 33}},
+	}
+
+	dec := NewDecoder(strings.NewReader(in))
+	for i, w := range want {
+		got, err := dec.Next()
+		if err != nil {
+			t.Fatalf("Next() #%d err = %v; want nil", i, err)
+		} else if !reflect.DeepEqual(got, w.datum) {
+			t.Fatalf("Next() #%d = %v; want %v", i, got, w.datum)
+		}
+		if line, col, offset := dec.Pos(); (pos{line, col, offset}) != w.pos {
+			t.Fatalf("Pos() #%d = %d:%d+%d; want %d:%d+%d", i, line, col, offset, w.pos.line, w.pos.col, w.pos.offset)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if got, err := dec.Next(); err != io.EOF {
+			t.Fatalf("Next() = %v, %v; want io.EOF", got, err)
+		}
+	}
+}
+
+func TestDecoderNextError(t *testing.T) {
+	dec := NewNamedDecoder("input.skim", strings.NewReader("a\n(b)\nc\n[d]\n  (e]\nf"))
+	for i := 0; i < 4; i++ {
+		if _, err := dec.Next(); err != nil {
+			t.Fatalf("Next() #%d err = %v; want nil", i, err)
+		}
+	}
+
+	_, err := dec.Next()
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("Next() err = (%T) %v; want *SyntaxError", err, err)
+	} else if se.Name != "input.skim" || se.Line != 5 || se.Col != 5 {
+		t.Fatalf("Next() err at %s:%d:%d; want input.skim:5:5", se.Name, se.Line, se.Col)
+	}
+
+	if _, again := dec.Next(); again != err {
+		t.Fatalf("Next() err = %v; want %v", again, err)
+	}
+}
+
+// blockingReader returns errBlocked once its underlying reader is exhausted, simulating an
+// interactive input with no further input available.
+type blockingReader struct{ *strings.Reader }
+
+var errBlocked = errors.New("read blocked")
+
+func (b blockingReader) ReadRune() (rune, int, error) {
+	r, sz, err := b.Reader.ReadRune()
+	if err == io.EOF {
+		err = errBlocked
+	}
+	return r, sz, err
+}
+
+func TestDecoderNextInteractive(t *testing.T) {
+	dec := NewDecoder(blockingReader{strings.NewReader("(+ 1 2)\n")})
+	got, err := dec.Next()
+	if want := skim.List(skim.Symbol("+"), skim.Int(1), skim.Int(2)); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("Next() = %v, %v; want %v, nil", got, err, want)
+	}
+	if _, err = dec.Next(); err != errBlocked {
+		t.Fatalf("Next() err = %v; want %v", err, errBlocked)
+	}
+}
+
+func TestDecoderReadAll(t *testing.T) {
+	dec := New(Options{PairBufferSize: 64})
+	first, err := dec.ReadAll(strings.NewReader("(a (b [c]) {d 1})"))
+	if err != nil {
+		t.Fatalf("ReadAll(..) err = %v; want nil", err)
+	}
+	want := fmt.Sprint(first)
+
+	// Leave scopes open on error, then read again with the same decoder.
+	if _, err = dec.ReadAll(strings.NewReader("(x (y [z")); err == nil {
+		t.Fatalf("ReadAll(unclosed) err = nil; want an error")
+	}
+	for i := 0; i < 8; i++ {
+		got, err := dec.ReadAll(plainReader{strings.NewReader("(e (f [g]) {h 2})")})
+		if err != nil {
+			t.Fatalf("ReadAll(%d) err = %v; want nil", i, err)
+		} else if s := fmt.Sprint(got); s != "[(e (f [g]) {h 2})]" {
+			t.Fatalf("ReadAll(%d) = %s; want [(e (f [g]) {h 2})]", i, s)
+		}
+	}
+
+	// Data from earlier reads are not modified by later ones.
+	if got := fmt.Sprint(first); got != want {
+		t.Fatalf("first ReadAll result = %s after reuse; want %s", got, want)
+	}
+}
+
+func TestReadFunc(t *testing.T) {
+	var got skim.Vector
+	collect := func(a skim.Atom) error {
+		got = append(got, a)
+		return nil
+	}
+
+	in := "(a b) 1 #;(skipped) [c] 'd"
+	want, err := ReadString(in)
+	if err != nil {
+		t.Fatalf("Read(%q) err = %v; want nil", in, err)
+	}
+	if err = ReadFunc(strings.NewReader(in), collect); err != nil {
+		t.Fatalf("ReadFunc(%q) err = %v; want nil", in, err)
+	} else if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReadFunc(%q) read %v; want %v", in, got, want)
+	}
+
+	// ErrStop ends reading without an error, before any later syntax error.
+	n := 0
+	err = ReadFunc(strings.NewReader("a b c )"), func(skim.Atom) error {
+		if n++; n == 2 {
+			return ErrStop
+		}
+		return nil
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("ReadFunc(..) stopped after %d data, err = %v; want 2, nil", n, err)
+	}
+
+	// Other errors from fn are returned as-is.
+	errFn := errors.New("fn error")
+	if err = ReadFunc(strings.NewReader("a b"), func(skim.Atom) error { return errFn }); err != errFn {
+		t.Fatalf("ReadFunc(..) err = %v; want %v", err, errFn)
+	}
+
+	// Syntax errors are returned after the data preceding them.
+	got = nil
+	err = ReadFunc(strings.NewReader("a (b"), collect)
+	if _, ok := err.(*SyntaxError); !ok || len(got) != 1 {
+		t.Fatalf("ReadFunc(..) read %v, err = %v; want [a], *SyntaxError", got, err)
+	}
+}
+
+func TestReadFuncAllocs(t *testing.T) {
+	const form = "(record (id 1) (tags [a b c]) \"name\")\n"
+	allocsPerForm := func(n int) float64 {
+		in := strings.Repeat(form, n)
+		allocs := testing.AllocsPerRun(5, func() {
+			if err := ReadFunc(strings.NewReader(in), func(skim.Atom) error { return nil }); err != nil {
+				t.Fatalf("ReadFunc(..) err = %v; want nil", err)
+			}
+		})
+		return allocs / float64(n)
+	}
+
+	// Allocations grow only with the number of forms read, not with the data already read.
+	small, large := allocsPerForm(1000), allocsPerForm(10000)
+	if large > small*1.1 {
+		t.Fatalf("ReadFunc allocations per form = %.2f for 10000 forms; want about %.2f, as for 1000", large, small)
+	}
+}
+
+func TestDecoderReset(t *testing.T) {
+	want := skim.List(skim.Symbol("a"), skim.List(skim.Symbol("b"), skim.Int(1)), skim.String("c"))
+	for _, size := range []int{1, 2, 16, 256} {
+		dec := New(Options{PairBufferSize: size})
+		if got, err := dec.Next(); err != io.EOF {
+			t.Fatalf("Next() with no input = %v, %v; want io.EOF", got, err)
+		}
+
+		dec.Reset(strings.NewReader("(a"))
+		if _, err := dec.Next(); err == nil {
+			t.Fatalf("Next() err = nil; want error")
+		}
+
+		dec.Reset(strings.NewReader(`(a (b 1) "c")`))
+		if got, err := dec.Next(); err != nil || !reflect.DeepEqual(got, want) {
+			t.Fatalf("Next() with pair buffer size %d = %v, %v; want %v, nil", size, got, err, want)
+		}
+	}
+}
+
+func BenchmarkReadPairBufferSize(b *testing.B) {
+	in := strings.Repeat("(define (f x y) (list 'a x `(b ,y) [1 2 3] \"str\"))\n", 256)
+	for _, size := range []int{1, 16, 256} {
+		opts := Options{PairBufferSize: size}
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			b.SetBytes(int64(len(in)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := opts.Read(strings.NewReader(in)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecoderReadAll(b *testing.B) {
+	in := "(record (id 1) (name \"a\") (tags [x y z]))"
+	b.Run("read", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Read(plainReader{strings.NewReader(in)}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("reuse", func(b *testing.B) {
+		b.ReportAllocs()
+		dec := New(Options{})
+		for i := 0; i < b.N; i++ {
+			if _, err := dec.ReadAll(plainReader{strings.NewReader(in)}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkReadString(b *testing.B) {
+	in := strings.Repeat("(define (f x y) (list 'a x `(b ,y) [1 2 3] \"str\" |sym bol| #\\λ))\n", 256)
+	b.Run("strings-reader", func(b *testing.B) {
+		b.SetBytes(int64(len(in)))
+		for i := 0; i < b.N; i++ {
+			if _, err := Read(strings.NewReader(in)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("string", func(b *testing.B) {
+		b.SetBytes(int64(len(in)))
+		for i := 0; i < b.N; i++ {
+			if _, err := ReadString(in); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("bytes", func(b *testing.B) {
+		buf := []byte(in)
+		b.SetBytes(int64(len(buf)))
+		for i := 0; i < b.N; i++ {
+			if _, err := ReadBytes(buf); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// plainReader hides any methods of its Reader other than Read, such as those of an *os.File.
+type plainReader struct{ io.Reader }
+
+func BenchmarkReadPlainReader(b *testing.B) {
+	unit := "(define (f x y) (list 'a x `(b ,y) [1 2 3] \"str\"))\n"
+	in := []byte(strings.Repeat(unit, (1<<20)/len(unit)))
+
+	b.Run("buffered", func(b *testing.B) {
+		b.SetBytes(int64(len(in)))
+		for i := 0; i < b.N; i++ {
+			if _, err := Read(plainReader{bytes.NewReader(in)}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("unbuffered", func(b *testing.B) {
+		b.SetBytes(int64(len(in)))
+		for i := 0; i < b.N; i++ {
+			r := plainReader{bytes.NewReader(in)}
+			dec := NewDecoder(r)
+			dec.d.rd, dec.d.readrune = r, nil // Force one Read call per byte
+			if _, err := readAll(dec); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestParseInvalidUTF8(t *testing.T) {
+	cases := map[string]struct {
+		in          string
+		col, offset int
+		lenient     skim.Atom
+	}{
+		"symbol":     {"(ab\xe2\x82 c)", 4, 3, skim.List(skim.Symbol("ab��"), skim.Symbol("c"))},
+		"symbol/end": {"(ab \xf0\x9f\x98", 5, 4, nil},
+		"string":     {"(\"a\xf0\x9f\" b)", 4, 3, skim.List(skim.String("a��"), skim.Symbol("b"))},
+		"byte":       {"(a \xff)", 4, 3, skim.List(skim.Symbol("a"), skim.Symbol("�"))},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			readers := map[string]func() (skim.Vector, error){
+				"string-reader":   func() (skim.Vector, error) { return Read(strings.NewReader(c.in)) },
+				"one-byte-reader": func() (skim.Vector, error) { return Read(iotest.OneByteReader(strings.NewReader(c.in))) },
+				"in-memory":       func() (skim.Vector, error) { return ReadString(c.in) },
+			}
+			for rname, read := range readers {
+				_, err := read()
+				se, ok := err.(*SyntaxError)
+				if !ok {
+					t.Fatalf("%s: Read(%q) err = (%T) %v; want *SyntaxError", rname, c.in, err, err)
+				} else if se.Err != ErrInvalidUTF8 {
+					t.Fatalf("%s: Read(%q) err = %v; want %v", rname, c.in, se.Err, ErrInvalidUTF8)
+				} else if se.Line != 1 || se.Col != c.col || se.Offset != c.offset {
+					t.Fatalf("%s: Read(%q) position = %d:%d+%d; want 1:%d+%d", rname, c.in, se.Line, se.Col, se.Offset, c.col, c.offset)
+				}
+			}
+
+			if c.lenient == nil {
+				return
+			}
+			got, err := Options{AllowInvalidUTF8: true}.Read(strings.NewReader(c.in))
+			if want := (skim.Vector{c.lenient}); err != nil || !reflect.DeepEqual(got, want) {
+				t.Fatalf("Read(%q) lenient = %v, %v; want %v, nil", c.in, got, err, want)
+			}
+		})
+	}
+}
+
+func TestParseStrictEscapes(t *testing.T) {
+	strict := Options{StrictEscapes: true}
+
+	const in = `"\0\a\b\f\n\r\t\v\x41é\U0001F600\"\\" |\|\\\n|`
+	want := skim.Vector{
+		skim.String("\x00\a\b\f\n\r\t\vAé😀\"\\"),
+		skim.Symbol("|\\\n"),
+	}
+	if got, err := strict.Read(strings.NewReader(in)); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("Read(%q) = %#v, %v; want %#v, nil", in, got, err, want)
+	}
+
+	for _, c := range []struct {
+		in          string
+		esc         rune
+		col, offset int
+	}{
+		{"(a\n  \"b\\q\")", 'q', 5, 7},
+		{"\"line\\N\"", 'N', 6, 5},
+		{"|a\\q|", 'q', 3, 2},
+	} {
+		_, err := strict.Read(strings.NewReader(c.in))
+		se, ok := err.(*SyntaxError)
+		if !ok {
+			t.Fatalf("Read(%q) err = (%T) %v; want *SyntaxError", c.in, err, err)
+		} else if se.Err != EscapeError(c.esc) {
+			t.Fatalf("Read(%q) err = %v; want %v", c.in, se.Err, EscapeError(c.esc))
+		} else if se.Col != c.col || se.Offset != c.offset {
+			t.Fatalf("Read(%q) position = %d+%d; want %d+%d", c.in, se.Col, se.Offset, c.col, c.offset)
+		}
+
+		// Unknown escapes are read as the escaped rune by default.
+		if _, err = Read(strings.NewReader(c.in)); err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", c.in, err)
+		}
+	}
+}
+
+func TestParseSourceMap(t *testing.T) {
+	src := skim.NewSourceMap()
+	data, err := Options{SourceMap: src}.ReadNamed("in.skim", strings.NewReader("(a\n  '(b c)\n  [(d) e])"))
+	if err != nil {
+		t.Fatalf("Read(..) err = %v; want nil", err)
+	}
+
+	at := func(line, col, offset int) skim.Pos {
+		return skim.Pos{Name: "in.skim", Line: line, Col: col, Offset: offset}
+	}
+	cell := func(a skim.Atom, path string) *skim.Cons {
+		for _, c := range path {
+			var err error
+			if c == 'a' {
+				a, err = skim.Car(a)
+			} else {
+				a, err = skim.Cdr(a)
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+		}
+		return a.(*skim.Cons)
+	}
+
+	root := data[0]
+	quoted := cell(root, "da")
+	vec, _ := skim.Car(cell(root, "dd"))
+
+	lists := map[string]struct {
+		cons      *skim.Cons
+		want, end skim.Pos
+	}{
+		"root":        {cell(root, ""), at(1, 1, 0), at(3, 11, 22)},
+		"quote":       {quoted, at(2, 3, 5), at(2, 9, 11)},
+		"quoted-list": {cell(quoted, "da"), at(2, 4, 6), at(2, 9, 11)},
+		"vector-elem": {vec.(skim.Vector)[0].(*skim.Cons), at(3, 4, 15), at(3, 7, 18)},
+	}
+	for name, c := range lists {
+		if got, ok := src.List(c.cons); !ok || got != c.want {
+			t.Errorf("List(%s) = %v, %t; want %v, true", name, got, ok, c.want)
+		}
+		if got, ok := src.End(c.cons); !ok || got != c.end {
+			t.Errorf("End(%s) = %v, %t; want %v, true", name, got, ok, c.end)
+		}
+	}
+
+	cars := map[string]struct {
+		cons *skim.Cons
+		want skim.Pos
+	}{
+		"a":            {cell(root, ""), at(1, 2, 1)},
+		"quote":        {cell(root, "d"), at(2, 3, 5)},
+		"vector":       {cell(root, "dd"), at(3, 3, 14)},
+		"quote-symbol": {quoted, at(2, 3, 5)},
+		"b":            {cell(quoted, "da"), at(2, 5, 7)},
+		"c":            {cell(quoted, "dad"), at(2, 7, 9)},
+		"d":            {vec.(skim.Vector)[0].(*skim.Cons), at(3, 5, 16)},
+	}
+	for name, c := range cars {
+		if got, ok := src.Car(c.cons); !ok || got != c.want {
+			t.Errorf("Car(%s) = %v, %t; want %v, true", name, got, ok, c.want)
+		}
+	}
+}
+
+func TestParseMapSourceMap(t *testing.T) {
+	src := skim.NewSourceMap()
+	data, err := Options{SourceMap: src}.ReadString("{a 1\n b 2}")
+	if err != nil {
+		t.Fatalf("Read(..) err = %v; want nil", err)
+	}
+
+	alist := data[0].(*skim.Cons)
+	if got, ok := src.List(alist); !ok || got != (skim.Pos{Line: 1, Col: 1}) {
+		t.Errorf("List(map) = %v, %t; want 1:1, true", got, ok)
+	}
+	for i, want := range []skim.Pos{{Line: 1, Col: 2, Offset: 1}, {Line: 2, Col: 2, Offset: 6}} {
+		pair := alist.Car.(*skim.Cons)
+		if got, ok := src.List(pair); !ok || got != want {
+			t.Errorf("List(pair %d) = %v, %t; want %v, true", i, got, ok, want)
+		}
+		if got, ok := src.Car(alist); !ok || got != want {
+			t.Errorf("Car(map %d) = %v, %t; want %v, true", i, got, ok, want)
+		}
+		alist, _ = alist.Cdr.(*skim.Cons)
+	}
+}
+
+func TestParseSourceMapSpans(t *testing.T) {
+	const in = `(a . 0x1) [b "c\x41"] #0= 12 {k 1_0}`
+	src := skim.NewSourceMap()
+	data, err := Options{SourceMap: src}.ReadString(in)
+	if err != nil {
+		t.Fatalf("ReadString(%q) err = %v; want nil", in, err)
+	}
+
+	// known returns pos, which must have been recorded.
+	known := func(pos skim.Pos, ok bool) skim.Pos {
+		t.Helper()
+		if !ok {
+			t.Fatal("position not recorded")
+		}
+		return pos
+	}
+
+	dotted := data[0].(*skim.Cons)
+	vec := data[1].(skim.Vector)
+	alist := data[3].(*skim.Cons).Car.(*skim.Cons)
+	cases := []struct {
+		name string
+		pos  skim.Pos
+		want string
+	}{
+		{"a", known(src.Car(dotted)), "a"},
+		{"tail", known(src.Cdr(dotted)), "0x1"},
+		{"b", known(src.Elem(vec, 0)), "b"},
+		{"c", known(src.Elem(vec, 1)), `"c\x41"`},
+		{"labeled", skim.Pos{Line: 1, Col: 23, Offset: 22}, "#0= 12"},
+		{"key", known(src.Car(alist)), "k"},
+		{"value", known(src.Cdr(alist)), "1_0"},
+	}
+	for _, c := range cases {
+		if end, ok := src.Span(c.pos.Offset); !ok {
+			t.Errorf("Span(%d) of %s not recorded", c.pos.Offset, c.name)
+		} else if got := in[c.pos.Offset:end.Offset]; got != c.want {
+			t.Errorf("text of %s = %q; want %q", c.name, got, c.want)
+		}
+	}
+	if _, ok := src.Elem(vec, 2); ok {
+		t.Errorf("Elem(vec, 2) ok = true; want false")
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	const in = "; first\n(a #| b #| c |# |# d) #;(e\n f) g ;; last"
+	var got []Comment
+	data, err := Options{Comments: func(c Comment) { got = append(got, c) }}.ReadString(in)
+	if err != nil {
+		t.Fatalf("ReadString(%q) err = %v; want nil", in, err)
+	} else if want := "[(a d) g]"; data.String() != want {
+		t.Fatalf("ReadString(%q) = %v; want %s", in, data, want)
+	}
+
+	want := []struct {
+		kind CommentKind
+		text string
+	}{
+		{LineComment, "; first"},
+		{BlockComment, "#| b #| c |# |#"},
+		{DatumComment, "#;(e\n f)"},
+		{LineComment, ";; last"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Comments = %v; want %d comments", got, len(want))
+	}
+	for i, c := range got {
+		if text := in[c.Pos.Offset:c.End.Offset]; c.Kind != want[i].kind || text != want[i].text {
+			t.Errorf("Comments[%d] = %v %q; want %v %q", i, c.Kind, text, want[i].kind, want[i].text)
+		}
+	}
+	if got[2].Pos.Line != 2 || got[2].End.Line != 3 {
+		t.Errorf("Comments[2] lines = %d-%d; want 2-3", got[2].Pos.Line, got[2].End.Line)
+	}
+}
+
+func TestParseDatumLabels(t *testing.T) {
+	read := func(in string) skim.Atom {
+		t.Helper()
+		data, err := ReadString(in)
+		if err != nil {
+			t.Fatalf("Read(%q) err = %v; want nil", in, err)
+		} else if len(data) != 1 {
+			t.Fatalf("Read(%q) read %d data; want 1", in, len(data))
+		}
+		return data[0]
+	}
+
+	// Shared structure
+	shared := read("(#0=(a b) #0#)").(*skim.Cons)
+	if first, second := shared.Car, shared.Cdr.(*skim.Cons).Car; first != second {
+		t.Errorf("(#0=(a b) #0#): elements are not the same pair")
+	} else if want := skim.List(skim.Symbol("a"), skim.Symbol("b")); !reflect.DeepEqual(first, want) {
+		t.Errorf("(#0=(a b) #0#): car = %v; want %v", first, want)
+	}
+
+	// Circular cdr
+	circ := read("#0=(1 . #0#)").(*skim.Cons)
+	if circ.Car != skim.Int(1) || circ.Cdr != circ {
+		t.Errorf("#0=(1 . #0#): car = %#v, cdr aliases pair = %t; want 1, true", circ.Car, circ.Cdr == circ)
+	}
+
+	// Nested labels referring to an enclosing label
+	outer := read("#0=(a #1=(b #0#) #1#)").(*skim.Cons)
+	inner := outer.Cdr.(*skim.Cons).Car.(*skim.Cons)
+	if inner.Car != skim.Symbol("b") || inner.Cdr.(*skim.Cons).Car != outer {
+		t.Errorf("#0=(a #1=(b #0#) #1#): #1# does not refer back to #0#")
+	}
+	if third := outer.Cdr.(*skim.Cons).Cdr.(*skim.Cons).Car; third != inner {
+		t.Errorf("#0=(a #1=(b #0#) #1#): third element is not #1#")
+	}
+
+	// Vectors
+	vec := read("#0=[1 #0#]").(skim.Vector)
+	if self, ok := vec[1].(skim.Vector); !ok || len(self) != 2 || &self[0] != &vec[0] {
+		t.Errorf("#0=[1 #0#]: second element does not alias the vector")
+	}
+
+	// Not a label
+	if got := read("#1abc"); got != skim.Symbol("#1abc") {
+		t.Errorf("Read(#1abc) = %v; want symbol #1abc", got)
+	}
+
+	for in, want := range map[string]error{
+		"(#0#)":           LabelError(0),
+		"(#0=a #1#)":      LabelError(1),
+		"#0=#0#":          LabelError(0),
+		"#0=a #0#":        LabelError(0), // labels are local to a top-level datum
+		"(#0=a #0=b)":     ErrDuplicateLabel,
+		"(#0=a\n #0=(b))": ErrDuplicateLabel,
+	} {
+		_, err := ReadString(in)
+		if se, ok := err.(*SyntaxError); !ok || se.Err != want {
+			t.Errorf("Read(%q) err = %v; want %v", in, err, want)
+		}
+	}
+}
+
+func TestParseFoldCase(t *testing.T) {
+	const in = `(DEFINE Foo "Bar" |Baz| #\A #\NEWLINE :Key #T)
+#!fold-case
+(DEFINE Foo "Bar" |Baz| #\A #\NEWLINE :Key #T #X1F)
+#!no-fold-case
+(DEFINE Foo)`
+
+	sym := func(s string) skim.Atom { return skim.Symbol(s) }
+	want := skim.Vector{
+		skim.List(sym("DEFINE"), sym("Foo"), skim.String("Bar"), sym("Baz"), skim.Char('A'), sym(`#\NEWLINE`), skim.Keyword("Key"), sym("#T")),
+		skim.List(sym("define"), sym("foo"), skim.String("Bar"), sym("Baz"), skim.Char('A'), skim.Char('\n'), skim.Keyword("key"), skim.Bool(true), skim.Int(31)),
+		skim.List(sym("DEFINE"), sym("Foo")),
+	}
+
+	_, err := ReadString(in)
+	if se, ok := err.(*SyntaxError); !ok || se.Err != CharNameError("NEWLINE") {
+		t.Fatalf("Read(..) err = %v; want %v", err, CharNameError("NEWLINE"))
+	}
+
+	// Replace the unfolded character name, which is an error, to check the remaining data.
+	got, err := ReadString(strings.Replace(in, `#\NEWLINE`, `|#\\NEWLINE|`, 1))
+	if err != nil {
+		t.Fatalf("Read(..) err = %v; want nil", err)
+	} else if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Read(..) = %v; want %v", got, want)
+	}
+
+	// FoldCase folds from the start of input.
+	got, err = Options{FoldCase: true}.ReadString("(Foo |Bar|) #!no-fold-case Baz")
+	if want := (skim.Vector{skim.List(sym("foo"), sym("Bar")), sym("Baz")}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("Read(..) with FoldCase = %v, %v; want %v, nil", got, err, want)
+	}
+}
+
+func TestRegisterDispatch(t *testing.T) {
+	t.Setenv("SKIM_TEST_ENV", "value")
+	env := func(dec *Decoder) (skim.Atom, error) {
+		a, err := dec.ReadDatum()
+		if err != nil {
+			return nil, err
+		}
+		switch name := a.(type) {
+		case skim.Symbol:
+			return skim.String(os.Getenv(string(name))), nil
+		case skim.String:
+			return skim.String(os.Getenv(string(name))), nil
+		}
+		return nil, fmt.Errorf("expected a symbol, got %v", a)
+	}
+	date := func(dec *Decoder) (skim.Atom, error) {
+		s, err := dec.ReadString()
+		return skim.List(skim.Symbol("date"), s), err
+	}
+	// #rev reads runes up to the next space and reverses them.
+	rev := func(dec *Decoder) (skim.Atom, error) {
+		if err := dec.SkipSpace(); err != nil {
+			return nil, err
+		}
+		var rs []rune
+		for r, err := dec.PeekRune(); !unicode.IsSpace(r); r, err = dec.PeekRune() {
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			dec.ReadRune()
+			rs = append([]rune{r}, rs...)
+		}
+		return skim.Symbol(rs), nil
+	}
+	read := func(in string) (skim.Vector, error) {
+		dec := NewDecoder(strings.NewReader(in))
+		dec.RegisterDispatch("#env", env)
+		dec.RegisterDispatch("#date", date)
+		dec.RegisterDispatch("#rev", rev)
+		return readAll(dec)
+	}
+
+	got, err := read(`#env SKIM_TEST_ENV (a #env ; comment
+  SKIM_TEST_ENV b) #date"2024-01-01" [#date "x" #rev olleh) #t #f #nil #u8(1)] #env #env SKIM_TEST_ENV`)
+	want := skim.Vector{
+		skim.String("value"),
+		skim.List(skim.Symbol("a"), skim.String("value"), skim.Symbol("b")),
+		skim.List(skim.Symbol("date"), skim.String("2024-01-01")),
+		skim.Vector{
+			skim.List(skim.Symbol("date"), skim.String("x")),
+			skim.Symbol(")hello"),
+			skim.Bool(true), skim.Bool(false), nil, skim.Bytes{1},
+		},
+		skim.String(""),
+	}
+	if err != nil {
+		t.Fatalf("Read(..) err = %v; want nil", err)
+	} else if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Read(..) = %v; want %v", got, want)
+	}
+
+	fail := []struct {
+		in, err   string
+		line, col int
+	}{
+		{"(a\n  #env 1)", "expected a symbol, got 1", 2, 3},
+		{"(a #env", "expected a datum after #env", 1, 4},
+		{"(a #env)", "expected a datum after #env at 1:4", 1, 8},
+		{"#date 1", "expected a string after #date, got 1", 1, 7},
+		{"#date (x", "encountered EOF inside (", 1, 7},
+	}
+	for _, c := range fail {
+		_, err := read(c.in)
+		se, ok := err.(*SyntaxError)
+		if !ok || !strings.Contains(se.Error(), c.err) {
+			t.Errorf("Read(%q) err = %v; want an error containing %q", c.in, err, c.err)
+		} else if se.Line != c.line || se.Col != c.col {
+			t.Errorf("Read(%q) position = %d:%d; want %d:%d", c.in, se.Line, se.Col, c.line, c.col)
+		}
+	}
+
+	// Without a registered function, a prefix is read as a symbol.
+	if got, err := ReadString("#env X"); err != nil || !reflect.DeepEqual(got, skim.Vector{skim.Symbol("#env"), skim.Symbol("X")}) {
+		t.Errorf("Read(..) without dispatch = %v, %v; want [#env X], nil", got, err)
+	}
+
+	// The Decoder's reading methods are only usable by a DispatchFunc.
+	if _, err := NewDecoder(strings.NewReader("x")).ReadDatum(); err != errNoDispatch {
+		t.Errorf("ReadDatum() outside of dispatch err = %v; want %v", err, errNoDispatch)
+	}
+
+	for _, prefix := range []string{"", "#", "env", "#t", "#f", "#nil", "#u8", `#\x`, "#!x", "#1x", "#a b"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterDispatch(%q, ..) did not panic", prefix)
+				}
+			}()
+			NewDecoder(strings.NewReader("")).RegisterDispatch(prefix, env)
+		}()
+	}
+}
+
+func TestInternSymbols(t *testing.T) {
+	// Hold the interned text so that it is not dropped by a collection during the test.
+	defer runtime.KeepAlive(unique.Make("abc"))
+	for _, intern := range []bool{false, true} {
+		data, err := Options{InternSymbols: intern}.ReadString("(abc |abc|)")
+		if err != nil {
+			t.Fatalf("Read err = %v; want nil", err)
+		}
+		a, b := data[0].(*skim.Cons).Car.(skim.Symbol), data[0].(*skim.Cons).Cdr.(*skim.Cons).Car.(skim.Symbol)
+		if shared := unsafe.StringData(string(a)) == unsafe.StringData(string(b)); a != b || shared != intern {
+			t.Errorf("InternSymbols = %t: read %v and %v sharing text = %t; want equal, %t", intern, a, b, shared, intern)
+		}
+	}
+}
+
+func BenchmarkReadSymbols(b *testing.B) {
+	// Programs repeat a small set of symbols many times; interned, each occurrence shares its text.
+	unit := "(define (handle-request request response) (if (request-valid? request) (respond response request) (reject response)))\n"
+	in := strings.Repeat(unit, 512)
+	for name, opts := range map[string]Options{"copied": {}, "interned": {InternSymbols: true}} {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(in)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := opts.ReadString(in); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
 	}
 }
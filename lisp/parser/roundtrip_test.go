@@ -0,0 +1,43 @@
+package parser_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/printer"
+)
+
+// TestParseRoundTrip checks that every atom read from a valid input in TestParse's table reads back
+// as an equal atom once written by printer.Write. It is in package parser_test because the printer
+// imports the parser.
+func TestParseRoundTrip(t *testing.T) {
+	inputs := parser.ValidParseInputs()
+	names := make([]string, 0, len(inputs))
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		in := inputs[name]
+		t.Run(name, func(t *testing.T) {
+			data, err := parser.ReadString(in)
+			if err != nil {
+				t.Fatalf("Read(%q) err = %v; want nil", in, err)
+			}
+			for _, want := range data {
+				var out strings.Builder
+				if err := printer.Write(&out, want); err != nil {
+					t.Fatalf("Write(%v) err = %v; want nil", want, err)
+				}
+				got, err := parser.ReadString(out.String())
+				if err != nil || len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+					t.Fatalf("Read(Write(%#v)) = Read(%q) = %#v, %v; want [%#v], nil", want, out.String(), got, err, want)
+				}
+			}
+		})
+	}
+}
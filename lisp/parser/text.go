@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// ReadAtom reads text holding exactly one atom, written as it would be in source, and returns it.
+// It is the reverse of the MarshalText methods of atoms. ReadAtom returns an error if text holds
+// no atoms or more than one.
+func ReadAtom(text []byte) (skim.Atom, error) {
+	atoms, err := ReadBytes(text)
+	if err != nil {
+		return nil, err
+	}
+	switch len(atoms) {
+	case 0:
+		return nil, errors.New("skim: text holds no atom")
+	case 1:
+		return atoms[0], nil
+	}
+	return nil, fmt.Errorf("skim: text holds %d atoms; want 1", len(atoms))
+}
+
+// Value holds an atom read from text, so that a whole datum can be read from a command-line flag
+// or any other text. A *Value implements flag.Value, encoding.TextMarshaler, and
+// encoding.TextUnmarshaler.
+type Value struct {
+	Atom skim.Atom
+}
+
+// String returns the text of the Value's atom, or the empty string if it has none.
+func (v *Value) String() string {
+	if v == nil || v.Atom == nil {
+		return ""
+	}
+	return v.Atom.String()
+}
+
+// Set reads s with ReadAtom and sets the Value's atom to the result.
+func (v *Value) Set(s string) error {
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v *Value) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The Value is left unchanged if text cannot be
+// read.
+func (v *Value) UnmarshalText(text []byte) error {
+	a, err := ReadAtom(text)
+	if err != nil {
+		return err
+	}
+	v.Atom = a
+	return nil
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"encoding"
+	"flag"
+	"io"
+	"math"
+	"testing"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestValueText(t *testing.T) {
+	cases := []struct {
+		in   encoding.TextMarshaler
+		want string
+	}{
+		{&Value{Atom: skim.List(skim.Symbol("a"))}, "(a)"},
+		{&Value{}, ""},
+	}
+	for _, c := range cases {
+		text, err := c.in.MarshalText()
+		if err != nil || string(text) != c.want {
+			t.Errorf("%#v.MarshalText() = %q, %v; want %q, nil", c.in, text, err, c.want)
+		}
+	}
+
+	var v Value
+	if err := v.UnmarshalText([]byte("(a)")); err != nil || v.String() != "(a)" {
+		t.Errorf("UnmarshalText(%q) = %v, %v; want (a), nil", "(a)", v.Atom, err)
+	}
+}
+
+func TestFloatRoundTrip(t *testing.T) {
+	for _, f := range []float64{
+		1e21, 1e-10, 0.1, math.Copysign(0, -1), math.MaxFloat64, math.SmallestNonzeroFloat64,
+		-123456789.125, 1 << 53, 2.5e-5, 100,
+	} {
+		text := skim.Float(f).String()
+		a, err := ReadAtom([]byte(text))
+		if err != nil {
+			t.Errorf("ReadAtom(%q) err = %v; want nil", text, err)
+			continue
+		}
+		got, ok := a.(skim.Float)
+		if !ok || float64(got) != f || math.Signbit(float64(got)) != math.Signbit(f) {
+			t.Errorf("ReadAtom(%q) = %#v; want Float %v", text, a, f)
+		}
+	}
+}
+
+func TestReadAtomError(t *testing.T) {
+	for _, in := range []string{"", "; comment", "1 2", "(a"} {
+		if a, err := ReadAtom([]byte(in)); err == nil {
+			t.Errorf("ReadAtom(%q) = %v; want error", in, a)
+		}
+	}
+}
+
+func TestValueFlag(t *testing.T) {
+	var init Value
+	fs := flag.NewFlagSet("skim", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(&init, "init", "form to evaluate")
+
+	if err := fs.Parse([]string{"-init", "(let ((x 1)) x)", "rest"}); err != nil {
+		t.Fatalf("Parse() err = %v; want nil", err)
+	}
+	const want = "(let ((x 1)) x)"
+	if init.Atom == nil || init.Atom.String() != want {
+		t.Fatalf("-init = %v; want %s", init.Atom, want)
+	} else if got := fs.Lookup("init").Value.String(); got != want {
+		t.Fatalf("-init String() = %q; want %q", got, want)
+	} else if args := fs.Args(); len(args) != 1 || args[0] != "rest" {
+		t.Fatalf("Args() = %q; want [rest]", args)
+	}
+
+	if err := fs.Parse([]string{"-init", "(a b"}); err == nil {
+		t.Fatal("Parse(-init \"(a b\") err = nil; want error")
+	} else if init.Atom.String() != want {
+		t.Fatalf("-init = %v after error; want %s", init.Atom, want)
+	}
+}
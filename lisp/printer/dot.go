@@ -0,0 +1,128 @@
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// Dot writes the structure of a to w as a Graphviz DOT digraph, for debugging sharing and mutation.
+// Each *skim.Cons is one node, numbered in the order it is first reached, with edges labeled car and
+// cdr to the atoms it holds. Each vector is one record-shaped node with a port per index. Other
+// atoms are leaves labeled with their String methods, and are written once per reference. Conses
+// and vectors held in more than one place are written once, with an edge from each place, so cyclic
+// atoms can be written.
+func Dot(w io.Writer, a skim.Atom) error {
+	g := &dot{
+		conses:  make(map[*skim.Cons]string),
+		vectors: make(map[vectorKey]string),
+	}
+	g.buf.WriteString("digraph skim {\n")
+	g.node(a)
+	g.buf.WriteString("}\n")
+	_, err := w.Write(g.buf.Bytes())
+	return err
+}
+
+// vectorKey identifies a vector by its first element and length, since vectors sharing both share
+// all their elements.
+type vectorKey struct {
+	first *skim.Atom
+	len   int
+}
+
+type dot struct {
+	buf     bytes.Buffer
+	n       int
+	conses  map[*skim.Cons]string
+	vectors map[vectorKey]string
+}
+
+// id returns the next node ID.
+func (g *dot) id() string {
+	id := "n" + strconv.Itoa(g.n)
+	g.n++
+	return id
+}
+
+// node writes the node for a, if it has not already been written, and returns its ID.
+func (g *dot) node(a skim.Atom) string {
+	switch a := a.(type) {
+	case *skim.Cons:
+		if a == nil {
+			break
+		} else if id, ok := g.conses[a]; ok {
+			return id
+		}
+		id := g.id()
+		g.conses[a] = id
+		fmt.Fprintf(&g.buf, "\t%s [label=%q shape=circle];\n", id, "#"+id[1:])
+		g.edge(id, "car", g.node(a.Car))
+		g.edge(id, "cdr", g.node(a.Cdr))
+		return id
+	case skim.Vector:
+		if len(a) == 0 {
+			break
+		}
+		key := vectorKey{&a[0], len(a)}
+		if id, ok := g.vectors[key]; ok {
+			return id
+		}
+		id := g.id()
+		g.vectors[key] = id
+		label := "{"
+		for i := range a {
+			if i > 0 {
+				label += "|"
+			}
+			label += "<" + strconv.Itoa(i) + "> " + strconv.Itoa(i)
+		}
+		fmt.Fprintf(&g.buf, "\t%s [label=%q shape=record];\n", id, label+"}")
+		for i, elem := range a {
+			g.edge(id+":"+strconv.Itoa(i), "", g.node(elem))
+		}
+		return id
+	}
+
+	id := g.id()
+	fmt.Fprintf(&g.buf, "\t%s [label=%s shape=box];\n", id, quoteDot(leafLabel(a)))
+	return id
+}
+
+func (g *dot) edge(from, label, to string) {
+	if label == "" {
+		fmt.Fprintf(&g.buf, "\t%s -> %s;\n", from, to)
+		return
+	}
+	fmt.Fprintf(&g.buf, "\t%s -> %s [label=%s];\n", from, to, label)
+}
+
+// leafLabel returns the label of the leaf node for a: its String method, or #nil if a is nil.
+func leafLabel(a skim.Atom) string {
+	if a == nil {
+		return "#nil"
+	}
+	return a.String()
+}
+
+// quoteDot quotes s as a DOT string, in which only double quotes and backslashes are escaped.
+func quoteDot(s string) string {
+	var b bytes.Buffer
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
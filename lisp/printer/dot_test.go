@@ -0,0 +1,58 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestDotShared(t *testing.T) {
+	x := skim.List(skim.Symbol("a"), skim.String("b\"c")).(*skim.Cons)
+	a := skim.List(x, x)
+
+	var b strings.Builder
+	if err := Dot(&b, a); err != nil {
+		t.Fatalf("Dot(%v) err = %v; want nil", a, err)
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, "digraph skim {\n") || !strings.HasSuffix(out, "}\n") {
+		t.Errorf("Dot(%v) =\n%s\nwant a digraph", a, out)
+	}
+	// The two pairs of a and the two pairs of x.
+	if got, want := strings.Count(out, "shape=circle"), 4; got != want {
+		t.Errorf("Dot(%v) has %d cons nodes; want %d:\n%s", a, got, want, out)
+	}
+	// x is reached from the car of both pairs of a, but is written once.
+	if got := strings.Count(out, "-> n1 [label=car]"); got != 2 {
+		t.Errorf("Dot(%v) has %d edges to x; want 2:\n%s", a, got, out)
+	}
+	if !strings.Contains(out, `[label="\"b\\\"c\"" shape=box]`) {
+		t.Errorf("Dot(%v) does not hold the escaped string leaf:\n%s", a, out)
+	}
+}
+
+func TestDotCyclic(t *testing.T) {
+	c := &skim.Cons{Car: skim.Int(1)}
+	v := skim.Vector{c, nil}
+	c.Cdr = &skim.Cons{Car: v}
+
+	var b strings.Builder
+	if err := Dot(&b, v); err != nil {
+		t.Fatalf("Dot(cyclic) err = %v; want nil", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`n0 [label="{<0> 0|<1> 1}" shape=record];`,
+		"n0:0 -> n1;",
+		"n3 -> n0 [label=car];",
+		`[label="#nil" shape=box]`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Dot(cyclic) =\n%s\nwant it to contain %s", out, want)
+		}
+	}
+	if got := strings.Count(out, "shape=record"); got != 1 {
+		t.Errorf("Dot(cyclic) has %d vector nodes; want 1:\n%s", got, out)
+	}
+}
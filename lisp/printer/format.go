@@ -0,0 +1,216 @@
+package printer
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"unicode"
+
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// FormatSource parses src and returns it formatted: each top-level datum is written by the pretty
+// printer configured by opts, separated from the next by a single blank line. Atoms other than lists
+// and vectors, such as numbers, strings, and heredocs, are written as their text in src. Comments
+// are kept. A comment following other text on its line is written after the element of the
+// innermost enclosing list that begins before it, and any other comment is written on its own line
+// before the following element, or before the end of the list or source if there is none. Comments
+// within vectors and cyclic data are moved out of them.
+//
+// Formatting is idempotent: formatting the result of FormatSource again returns it unchanged. If
+// src cannot be parsed, FormatSource returns the parser's error.
+func FormatSource(src []byte, opts PrettyOptions) ([]byte, error) {
+	var comments []parser.Comment
+	f := &formatter{
+		src: src,
+		sm:  skim.NewSourceMap(),
+		p: &pretty{
+			opts:  opts,
+			notes: make(map[*skim.Cons]*notes),
+			ends:  make(map[*skim.Cons][]string),
+		},
+	}
+	f.p.src, f.p.sm = src, f.sm
+	popts := parser.Options{
+		SourceMap: f.sm,
+		Comments:  func(c parser.Comment) { comments = append(comments, c) },
+	}
+	dec := popts.NewDecoder(bytes.NewReader(src))
+	for {
+		a, err := dec.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		_, _, offset := dec.Pos()
+		f.forms = append(f.forms, a)
+		f.starts = append(f.starts, offset)
+	}
+	f.top = make([]notes, len(f.forms))
+
+	// Comments within a datum comment, #;, are part of its text.
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].Pos.Offset < comments[j].Pos.Offset })
+	end := 0
+	for _, c := range comments {
+		if c.Pos.Offset < end {
+			continue
+		}
+		end = c.End.Offset
+		f.attach(c)
+	}
+	return f.format(), nil
+}
+
+type formatter struct {
+	src    []byte
+	sm     *skim.SourceMap
+	p      *pretty
+	forms  []skim.Atom
+	starts []int    // offsets of forms
+	top    []notes  // comments attached to forms
+	tail   []string // comments following the last of forms
+}
+
+// attach attaches the comment c to the element of the innermost list enclosing it, or to a
+// top-level form.
+func (f *formatter) attach(c parser.Comment) {
+	off := c.Pos.Offset
+	text := string(bytes.TrimRightFunc(f.src[off:c.End.Offset], unicode.IsSpace))
+	trailing := len(bytes.TrimSpace(f.src[bytes.LastIndexByte(f.src[:off], '\n')+1:off])) > 0
+
+	i := sort.SearchInts(f.starts, off+1) - 1 // the last form beginning before c
+	var list *skim.Cons
+	if i >= 0 && !skim.Cyclic(f.forms[i]) {
+		list = f.innermost(f.forms[i], off)
+	}
+	if list == nil {
+		switch {
+		case trailing && i >= 0:
+			f.top[i].trailing = append(f.top[i].trailing, text)
+		case i+1 < len(f.forms):
+			f.top[i+1].leading = append(f.top[i+1].leading, text)
+		default:
+			f.tail = append(f.tail, text)
+		}
+		return
+	}
+
+	var last, next *skim.Cons
+	for e := list; e != nil && next == nil; e, _ = e.Cdr.(*skim.Cons) {
+		if pos, ok := f.sm.Car(e); !ok {
+			continue
+		} else if pos.Offset < off {
+			last = e
+		} else {
+			next = e
+		}
+	}
+	switch {
+	case trailing && last != nil:
+		f.note(last).trailing = append(f.note(last).trailing, text)
+	case next != nil:
+		f.note(next).leading = append(f.note(next).leading, text)
+	default:
+		f.p.ends[list] = append(f.p.ends[list], text)
+	}
+}
+
+func (f *formatter) note(pair *skim.Cons) *notes {
+	n := f.p.notes[pair]
+	if n == nil {
+		n = new(notes)
+		f.p.notes[pair] = n
+	}
+	return n
+}
+
+// innermost returns the innermost list within a whose source encloses off, or nil if there is
+// none. Pairs whose source is not known, such as those of a map's keys and values, are searched
+// but are not returned.
+func (f *formatter) innermost(a skim.Atom, off int) *skim.Cons {
+	switch a := a.(type) {
+	case skim.Vector:
+		for _, elem := range a {
+			if c := f.innermost(elem, off); c != nil {
+				return c
+			}
+		}
+	case *skim.Cons:
+		if a == nil {
+			return nil
+		}
+		start, ok := f.sm.List(a)
+		end, hasEnd := f.sm.End(a)
+		ok = ok && hasEnd
+		if ok && (off < start.Offset || off >= end.Offset) {
+			return nil
+		}
+		for e := a; e != nil; {
+			if c := f.innermost(e.Car, off); c != nil {
+				return c
+			}
+			next, isCons := e.Cdr.(*skim.Cons)
+			if !isCons {
+				if c := f.innermost(e.Cdr, off); c != nil {
+					return c
+				}
+			}
+			e = next
+		}
+		if ok {
+			return a
+		}
+	}
+	return nil
+}
+
+func (f *formatter) format() []byte {
+	p := f.p
+	for i, a := range f.forms {
+		if i > 0 {
+			p.writeString("\n\n")
+		}
+		for _, text := range f.top[i].leading {
+			p.comment(text)
+			p.newline(0)
+		}
+		p.top(a, skim.Pos{Offset: f.starts[i]}, true)
+		for _, text := range f.top[i].trailing {
+			p.writeString(" ")
+			p.comment(text)
+		}
+		p.brk = false
+	}
+	for i, text := range f.tail {
+		switch {
+		case i > 0:
+			p.writeString("\n")
+		case len(f.forms) > 0:
+			p.writeString("\n\n")
+		}
+		p.comment(text)
+	}
+	if len(p.buf) > 0 {
+		p.writeString("\n")
+	}
+	return p.buf
+}
+
+// sourceText returns the text in src of a, read at pos if ok, if a is neither a list nor a vector
+// and sm records where its text ends.
+func sourceText(src []byte, sm *skim.SourceMap, a skim.Atom, pos skim.Pos, ok bool) (string, bool) {
+	switch a.(type) {
+	case *skim.Cons, skim.Vector:
+		return "", false
+	}
+	if !ok {
+		return "", false
+	}
+	end, ok := sm.Span(pos.Offset)
+	if !ok || end.Offset > len(src) {
+		return "", false
+	}
+	return string(src[pos.Offset:end.Offset]), true
+}
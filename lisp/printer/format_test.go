@@ -0,0 +1,226 @@
+package printer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestPretty(t *testing.T) {
+	cases := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"(a b c)", 0, "(a b c)"},
+		{"(f a b c)", 6, "(f a\n   b\n   c)"},
+		{"(define (f x) (g x) (h x))", 20, "(define (f x)\n  (g x)\n  (h x))"},
+		{"(let ((a 1) (b 2)) (+ a b))", 20, "(let ((a 1) (b 2))\n  (+ a b))"},
+		{"(cond (a b) (c d))", 10, "(cond\n  (a b)\n  (c d))"},
+		{"((f x) y z)", 8, "((f x)\n y\n z)"},
+		{"[1 2 3]", 4, "[1\n 2\n 3]"},
+		{"{a 1 b 2}", 8, "{a 1\n b 2}"},
+		{"'(a b c)", 6, "'(a b\n    c)"},
+		{"(a b . c)", 6, "(a b\n   . c)"},
+		{"(a-very-long-name x y)", 20, "(a-very-long-name\n  x\n  y)"},
+		{`"a long string"`, 4, `"a long string"`},
+	}
+	for _, c := range cases {
+		a := readOne(t, c.in)
+		var b strings.Builder
+		if err := (PrettyOptions{Width: c.width}).Pretty(&b, a); err != nil {
+			t.Errorf("Pretty(%s) err = %v; want nil", c.in, err)
+		} else if got := b.String(); got != c.want {
+			t.Errorf("Pretty(%s) with width %d =\n%s\nwant\n%s", c.in, c.width, got, c.want)
+		} else if again := readOne(t, got); fmtstring(again) != fmtstring(a) {
+			t.Errorf("Pretty(%s) reads back as %v", c.in, again)
+		}
+	}
+}
+
+func TestPrettyIndent(t *testing.T) {
+	var b strings.Builder
+	a := readOne(t, "(when a (b) (c))")
+	if err := (PrettyOptions{Width: 10, Indent: 4}).Pretty(&b, a); err != nil {
+		t.Fatalf("Pretty(%v) err = %v; want nil", a, err)
+	} else if got, want := b.String(), "(when a\n    (b)\n    (c))"; got != want {
+		t.Errorf("Pretty(%v) =\n%s\nwant\n%s", a, got, want)
+	}
+}
+
+func TestPrettyRules(t *testing.T) {
+	rules := DefaultIndentRules()
+	rules["with-db"] = 1
+	opts := PrettyOptions{Width: 20, Rules: rules}
+	cases := []struct {
+		opts     PrettyOptions
+		in, want string
+	}{
+		{opts, "(with-db conn (query a) (query b))", "(with-db conn\n  (query a)\n  (query b))"},
+		{opts, "(match conn (query a) (query b))", "(match conn\n       (query a)\n       (query b))"},
+		{opts, "(let ((a 1)) (query a) (query b))", "(let ((a 1))\n  (query a)\n  (query b))"},
+		{PrettyOptions{Width: 20}, "(with-db conn (query a) (query b))", "(with-db conn\n         (query a)\n         (query b))"},
+		{PrettyOptions{Width: 20, Rules: IndentRules{}}, "(let ((a 1)) (query a) (query b))", "(let ((a 1))\n     (query a)\n     (query b))"},
+	}
+	for _, c := range cases {
+		var b strings.Builder
+		if err := c.opts.Pretty(&b, readOne(t, c.in)); err != nil {
+			t.Errorf("Pretty(%s) err = %v; want nil", c.in, err)
+		} else if got := b.String(); got != c.want {
+			t.Errorf("Pretty(%s) =\n%s\nwant\n%s", c.in, got, c.want)
+		}
+	}
+	if _, ok := DefaultIndentRules()["with-db"]; ok {
+		t.Errorf("DefaultIndentRules() holds a rule added to an earlier result")
+	}
+}
+
+func TestFormatSourceGolden(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "format.skim"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := format(t, string(src))
+
+	golden := filepath.Join("testdata", "format.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	} else if got != string(want) {
+		t.Errorf("FormatSource(format.skim) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatSource(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"  \n\n", ""},
+		{"; only\n", "; only\n"},
+		{"a b\n\n\n c", "a\n\nb\n\nc\n"},
+		{"(a   b)   ; trailing\n", "(a b) ; trailing\n"},
+		{"; leading\n\n\n(a b)", "; leading\n(a b)\n"},
+		{"(a ; one\n b)", "(a ; one\n   b)\n"},
+		{"(a\n ; before b\n b)", "(a\n   ; before b\n   b)\n"},
+		{"(a b\n ; dangling\n )", "(a b\n   ; dangling\n   )\n"},
+		{"(a #| block |# b)", "(a #| block |#\n   b)\n"},
+		{"(a #;(b\n c) d)", "(a #;(b\n c)\n   d)\n"},
+		{"(a #;(b #| c |# ; d\n) e)", "(a #;(b #| c |# ; d\n)\n   e)\n"},
+		{"(; first\n a)", "(; first\n a)\n"},
+		{"( ; empty\n)", "() ; empty\n"}, // the empty list has no position
+		{"(a [1 ; in vector\n 2])", "(a [1 2] ; in vector\n   )\n"},
+		{"{a 1 ; pair\n b 2}", "{a 1 ; pair\n b 2}\n"},
+		{"'(a ; quoted\n b)", "'(a ; quoted\n    b)\n"},
+		{"' ; quote\n a", "(quote ; quote\n       a)\n"},
+		{"#0=(a . #0#) ; cyclic", "#0=(a . #0#) ; cyclic\n"},
+		{"#0=(a ; inside\n . #0#)", "#0=(a . #0#) ; inside\n"},
+		{"a ; x\n; y", "a ; x\n\n; y\n"},
+	}
+	for _, c := range cases {
+		if got := format(t, c.in); got != c.want {
+			t.Errorf("FormatSource(%q) =\n%s\nwant\n%s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestFormatSourceText(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"0xff", "0xff\n"},
+		{"(a  1_000   1e21)", "(a 1_000 1e21)\n"},
+		{`(#\x41 "\x41" "héllo")`, `(#\x41 "\x41" "héllo")` + "\n"},
+		{"[0b101  |sym bol|]", "[0b101 |sym bol|]\n"},
+		{"(a . 0o17)", "(a . 0o17)\n"},
+		{"{k  0x10}", "{k 0x10}\n"},
+		{"'0x1", "'0x1\n"},
+		{"#u8(#xff  1)", "#u8(#xff  1)\n"},
+		{"(#0=0x1 #0#)", "(#0=0x1 #0#)\n"},
+		{"(f <<<END\n  a\nEND)", "(f <<<END\n  a\nEND)\n"},
+		{"(f <<<~END\n  a\n  END\n x)", "(f <<<~END\n  a\n  END\n   x)\n"},
+		{"[<<<END\na\nEND\n 1]", "[<<<END\na\nEND\n 1]\n"},
+	}
+	for _, c := range cases {
+		if got := format(t, c.in); got != c.want {
+			t.Errorf("FormatSource(%q) =\n%s\nwant\n%s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestFormatSourceCorpus(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "corpus.skim"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, width := range []int{0, 20, 1} {
+		out, err := FormatSource(src, PrettyOptions{Width: width})
+		if err != nil {
+			t.Fatalf("FormatSource(corpus.skim) err = %v; want nil", err)
+		} else if again, err := FormatSource(out, PrettyOptions{Width: width}); err != nil || string(again) != string(out) {
+			t.Errorf("FormatSource(corpus.skim) with width %d is not idempotent: %v", width, err)
+		} else if got, want := readAll(t, string(out)), readAll(t, string(src)); got.String() != want.String() {
+			t.Errorf("FormatSource(corpus.skim) with width %d reads as %v; want %v", width, got, want)
+		}
+	}
+}
+
+func TestFormatSourceError(t *testing.T) {
+	for _, in := range []string{"(a", "a)", "#| open"} {
+		if out, err := FormatSource([]byte(in), PrettyOptions{}); err == nil {
+			t.Errorf("FormatSource(%q) = %q; want error", in, out)
+		}
+	}
+}
+
+// format formats src, checking that the result is unchanged by formatting it again, that it reads
+// as the same data as src, and that it holds every comment in src.
+func format(t *testing.T, src string) string {
+	t.Helper()
+	out, err := FormatSource([]byte(src), PrettyOptions{})
+	if err != nil {
+		t.Fatalf("FormatSource(%q) err = %v; want nil", src, err)
+	}
+	again, err := FormatSource(out, PrettyOptions{})
+	if err != nil {
+		t.Fatalf("FormatSource(FormatSource(%q)) err = %v; want nil", src, err)
+	} else if string(again) != string(out) {
+		t.Errorf("FormatSource(%q) is not idempotent:\n%s\nthen\n%s", src, out, again)
+	}
+
+	want, got := readAll(t, src), readAll(t, string(out))
+	if got.String() != want.String() {
+		t.Errorf("FormatSource(%q) = %q reads as %v; want %v", src, out, got, want)
+	}
+	if n, m := strings.Count(src, ";"), strings.Count(string(out), ";"); n != m {
+		t.Errorf("FormatSource(%q) = %q has %d semicolons; want %d", src, out, m, n)
+	}
+	return string(out)
+}
+
+func readAll(t *testing.T, src string) skim.Vector {
+	t.Helper()
+	data, err := parser.ReadString(src)
+	if err != nil {
+		t.Fatalf("ReadString(%q) err = %v; want nil", src, err)
+	}
+	return data
+}
+
+func readOne(t *testing.T, src string) skim.Atom {
+	t.Helper()
+	data := readAll(t, src)
+	if len(data) != 1 {
+		t.Fatalf("ReadString(%q) read %d data; want 1", src, len(data))
+	}
+	return data[0]
+}
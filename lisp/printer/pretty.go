@@ -0,0 +1,405 @@
+package printer
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// PrettyOptions configures Pretty and FormatSource.
+type PrettyOptions struct {
+	PrintOptions
+
+	// Width is the column at which lines are broken. If zero, lines are broken at 80 columns. Atoms
+	// that cannot be broken, such as long strings, may extend past it.
+	Width int
+
+	// Indent is the number of columns by which the bodies of special forms, such as define and let,
+	// are indented. If zero, they are indented by 2 columns.
+	Indent int
+
+	// Rules are the special forms whose bodies are indented by Indent. If nil, the rules returned by
+	// DefaultIndentRules are used.
+	Rules IndentRules
+}
+
+func (o PrettyOptions) width() int {
+	if o.Width <= 0 {
+		return 80
+	}
+	return o.Width
+}
+
+func (o PrettyOptions) rules() IndentRules {
+	if o.Rules == nil {
+		return defaultIndentRules
+	}
+	return o.Rules
+}
+
+func (o PrettyOptions) indent() int {
+	if o.Indent <= 0 {
+		return 2
+	}
+	return o.Indent
+}
+
+// IndentRules maps the symbols beginning special forms, such as let, to the number of their
+// distinguished arguments, which are written on the same line as the symbol if a form must be
+// broken across lines. The arguments after them, the body of the form, are written one per line and
+// indented by PrettyOptions.Indent. Lists beginning with any other symbol are written as calls,
+// with their arguments aligned under the first.
+type IndentRules map[skim.Symbol]int
+
+// DefaultIndentRules returns a new IndentRules holding the rules for the special forms of Scheme,
+// such as define, lambda, let, and cond. Rules for other forms, such as macros, may be added to it.
+func DefaultIndentRules() IndentRules {
+	return IndentRules{
+		"and":    0,
+		"begin":  0,
+		"case":   1,
+		"cond":   0,
+		"define": 1,
+		"do":     2,
+		"lambda": 1,
+		"let":    1,
+		"let*":   1,
+		"letrec": 1,
+		"or":     0,
+		"unless": 1,
+		"when":   1,
+	}
+}
+
+// defaultIndentRules are the rules used when PrettyOptions.Rules is nil.
+var defaultIndentRules = DefaultIndentRules()
+
+// Pretty writes a to w as by Write, breaking lists and vectors that do not fit in 80 columns across
+// lines and indenting them. It uses the zero PrettyOptions.
+func Pretty(w io.Writer, a skim.Atom) error {
+	return PrettyOptions{}.Pretty(w, a)
+}
+
+// Pretty writes a to w as by Write, except that lists and vectors that do not fit within o.Width
+// are broken across lines. A list is written on one line if it fits. Otherwise, the arguments of a
+// special form, as given by o.Rules, are written as its body, indented by o.Indent, and the
+// arguments of any other list beginning with a symbol are aligned under its first argument. Other
+// lists and vectors are written with one element per line. Cyclic atoms are written on one line
+// with datum labels, as by Write. No newline is written after a.
+func (o PrettyOptions) Pretty(w io.Writer, a skim.Atom) error {
+	p := &pretty{opts: o}
+	p.top(a, skim.Pos{}, false)
+	_, err := w.Write(p.buf)
+	return err
+}
+
+// notes are the comments attached to an element of a list: those on the lines before it and those
+// following it on its line.
+type notes struct {
+	leading, trailing []string
+}
+
+// sep is how an element of a list is separated from the text before it.
+type sep int
+
+const (
+	sepNone  sep = iota // directly after the list's opening bracket
+	sepSpace            // on the same line, after a space
+	sepLine             // on a new line
+)
+
+type pretty struct {
+	opts PrettyOptions
+	buf  []byte
+	line int  // offset in buf of the beginning of the current line
+	brk  bool // if true, the current line ends with a comment, so nothing else may be written on it
+	eol  bool // if true, the current line ends a heredoc, so only a closing bracket may follow it
+
+	// Comments attached to the atoms being written, if any. notes is keyed by the pair holding an
+	// element of a list, and ends by the first pair of a list whose last element the comments follow.
+	notes map[*skim.Cons]*notes
+	ends  map[*skim.Cons][]string
+
+	// The text the atoms being written were read from and where, if atoms other than lists and
+	// vectors are to be written as it.
+	src []byte
+	sm  *skim.SourceMap
+}
+
+// col returns the column, from zero, at which the next rune is written.
+func (p *pretty) col() int {
+	return utf8.RuneCount(p.buf[p.line:])
+}
+
+func (p *pretty) writeString(s string) {
+	p.buf = append(p.buf, s...)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		p.line = len(p.buf) - len(s) + i + 1
+	}
+}
+
+// comment writes the text of a comment, after which the line must be broken.
+func (p *pretty) comment(text string) {
+	p.writeString(text)
+	p.brk = true
+}
+
+// newline ends the current line, without trailing spaces, and indents the next by ind columns.
+func (p *pretty) newline(ind int) {
+	p.buf = bytes.TrimRight(p.buf, " ")
+	p.buf = append(p.buf, '\n')
+	p.line = len(p.buf)
+	for i := 0; i < ind; i++ {
+		p.buf = append(p.buf, ' ')
+	}
+	p.brk, p.eol = false, false
+}
+
+// place separates the next element from the text before it.
+func (p *pretty) place(s sep, ind int) {
+	switch {
+	case s == sepLine || p.brk || p.eol:
+		p.newline(ind)
+	case s == sepSpace:
+		p.buf = append(p.buf, ' ')
+	}
+}
+
+func (p *pretty) flat(a skim.Atom) string {
+	var buf bytes.Buffer
+	_ = p.opts.PrintOptions.write(&buf, a, false, p.src, p.sm)
+	return buf.String()
+}
+
+func (p *pretty) fits(s string) bool {
+	return p.col()+utf8.RuneCountInString(s) <= p.opts.width()
+}
+
+// top writes a top-level atom, read at pos if ok. Cyclic atoms are only written on one line, by
+// Write.
+func (p *pretty) top(a skim.Atom, pos skim.Pos, ok bool) {
+	if skim.Cyclic(a) {
+		p.writeString(p.flat(a))
+		return
+	}
+	p.elem(a, pos, ok)
+}
+
+// elem writes a, read at pos if ok, as its source text if that is known. Text spanning lines, such
+// as that of a heredoc, may only be followed on its last line by a closing bracket.
+func (p *pretty) elem(a skim.Atom, pos skim.Pos, ok bool) {
+	if text, ok := sourceText(p.src, p.sm, a, pos, ok); ok {
+		p.writeString(text)
+		p.eol = strings.Contains(text, "\n")
+		return
+	}
+	p.atom(a)
+}
+
+func (p *pretty) car(c *skim.Cons) {
+	pos, ok := p.sm.Car(c)
+	p.elem(c.Car, pos, ok)
+}
+
+func (p *pretty) cdr(c *skim.Cons) {
+	pos, ok := p.sm.Cdr(c)
+	p.elem(c.Cdr, pos, ok)
+}
+
+func (p *pretty) atom(a skim.Atom) {
+	// Text spanning lines is written by elem, after which the line must be broken.
+	if s := p.flat(a); !p.commented(a) && p.fits(s) && !strings.Contains(s, "\n") {
+		p.writeString(s)
+		return
+	}
+	switch a := a.(type) {
+	case skim.Vector:
+		p.vector(a)
+	case *skim.Cons:
+		if a == nil {
+			p.writeString(p.flat(a))
+			return
+		}
+		p.list(a)
+	default:
+		p.writeString(p.flat(a))
+	}
+}
+
+// commented returns whether any comments are attached within a.
+func (p *pretty) commented(a skim.Atom) bool {
+	if len(p.notes) == 0 && len(p.ends) == 0 {
+		return false
+	}
+	has := func(c *skim.Cons) bool {
+		_, ok := p.notes[c]
+		if !ok {
+			_, ok = p.ends[c]
+		}
+		return ok
+	}
+	// Empty lists are not visited by Traverse, so they are checked by the pairs holding them.
+	if c, ok := a.(*skim.Cons); ok && skim.IsNil(c) {
+		return has(c)
+	}
+	found := false
+	var visit skim.Visitor
+	visit = func(a skim.Atom) (skim.Visitor, error) {
+		c, ok := a.(*skim.Cons)
+		if !ok {
+			return visit, nil
+		}
+		if car, ok := c.Car.(*skim.Cons); has(c) || ok && skim.IsNil(car) && has(car) {
+			found = true
+			return nil, skim.StopTraversal
+		}
+		return visit, nil
+	}
+	_ = skim.Traverse(a, visit)
+	return found
+}
+
+func (p *pretty) vector(v skim.Vector) {
+	p.writeString("[")
+	ind := p.col()
+	for i, elem := range v {
+		if i > 0 {
+			p.newline(ind)
+		}
+		pos, ok := p.sm.Elem(v, i)
+		p.elem(elem, pos, ok)
+	}
+	p.writeString("]")
+}
+
+// noted writes the element held in pair, preceded by the comments on the lines before it and
+// followed by those on its line.
+func (p *pretty) noted(pair *skim.Cons, s sep, ind int, write func()) {
+	n := p.notes[pair]
+	if n != nil {
+		for _, text := range n.leading {
+			if s == sepSpace {
+				// A comment following text on its line would be read back as trailing that text.
+				s = sepLine
+			}
+			p.place(s, ind)
+			p.comment(text)
+			s = sepLine
+		}
+	}
+	p.place(s, ind)
+	write()
+	if n != nil {
+		for _, text := range n.trailing {
+			p.writeString(" ")
+			p.comment(text)
+		}
+	}
+}
+
+// close writes the comments following the last element of the list c, if any, and its closing
+// bracket. The comments are written on their own lines, indented by ind columns.
+func (p *pretty) close(c *skim.Cons, closer string, empty bool, ind int) {
+	for _, text := range p.ends[c] {
+		if empty {
+			empty = false
+		} else {
+			p.newline(ind)
+		}
+		p.comment(text)
+	}
+	if p.brk {
+		p.newline(ind)
+	}
+	p.writeString(closer)
+	p.eol = false
+}
+
+func (p *pretty) list(c *skim.Cons) {
+	start := p.col()
+	if skim.IsNil(c) {
+		p.writeString("(")
+		p.close(c, ")", true, start+1)
+		return
+	}
+
+	if quo, rest := skim.QuoteAbbrev(c); rest != nil && p.notes[c] == nil && p.ends[c] == nil && p.notes[rest] == nil {
+		p.writeString(quo)
+		p.car(rest)
+		return
+	}
+
+	if skim.IsAlist(c, nil) {
+		p.alist(c)
+		return
+	}
+
+	// Choose where the arguments following the head of the list are written: distinguished
+	// arguments of special forms on the head's line, other arguments of lists beginning with a
+	// symbol aligned under the first, and all elements of other lists aligned under the head.
+	var (
+		width, indent = p.opts.width(), p.opts.indent()
+		special       = -1 // the number of distinguished arguments, if the list is a special form
+		argSep        = sepLine
+		argInd        = start + 1
+	)
+	if sym, ok := c.Car.(skim.Symbol); ok {
+		if n, ok := p.opts.rules()[sym]; ok {
+			special = max(n, 0)
+		} else if ind := start + 2 + utf8.RuneCountInString(p.flat(sym)); ind-start <= width/2 {
+			argSep, argInd = sepSpace, ind
+		} else {
+			argInd = start + indent
+		}
+	}
+
+	p.writeString("(")
+	i, s, ind := c, sepNone, start+1
+	for n := 0; ; n++ {
+		pair := i
+		p.noted(pair, s, ind, func() { p.car(pair) })
+
+		switch {
+		case special >= 0 && n < special:
+			s, ind = sepSpace, start+2*indent
+		case special >= 0:
+			s, ind = sepLine, start+indent
+		case n == 0:
+			s, ind = argSep, argInd
+		default:
+			s, ind = sepLine, argInd
+		}
+
+		next, ok := i.Cdr.(*skim.Cons)
+		if !ok && i.Cdr != nil {
+			p.place(s, ind)
+			p.writeString(". ")
+			p.cdr(i)
+			break
+		} else if skim.IsNil(next) {
+			break
+		}
+		i = next
+	}
+	p.close(c, ")", false, ind)
+}
+
+// alist writes the association list c as a map, with one key and value per line.
+func (p *pretty) alist(c *skim.Cons) {
+	p.writeString("{")
+	ind := p.col()
+	s := sepNone
+	for i := c; !skim.IsNil(i); i, _ = i.Cdr.(*skim.Cons) {
+		pair := i.Car.(*skim.Cons)
+		p.noted(i, s, ind, func() {
+			p.car(pair)
+			p.place(sepSpace, ind)
+			p.cdr(pair)
+		})
+		s = sepLine
+	}
+	p.close(c, "}", false, ind)
+}
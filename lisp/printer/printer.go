@@ -0,0 +1,315 @@
+// Package printer writes skim atoms to an io.Writer.
+//
+// Atoms have two written representations. The external representation, written by Write, is the
+// same as an atom's String method and can be read back by the parser, so strings are quoted and
+// characters are written as #\x. The display representation, written by Display, is meant for
+// people to read: strings and characters, including those held in lists and vectors, are written as
+// their raw text.
+package printer
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"go.spiff.io/skim/lisp/skim"
+)
+
+// flushSize is the size at which the printer's buffer is written out.
+const flushSize = 4096
+
+// NilForm is how the nil atom is written by PrintOptions.
+type NilForm int
+
+const (
+	// NilHash writes the nil atom as #nil, as its String method does.
+	NilHash NilForm = iota
+	// NilEmptyList writes the nil atom as the empty list, (). The parser reads () back as an empty
+	// *skim.Cons, which skim.IsNil reports as nil.
+	NilEmptyList
+)
+
+// PrintOptions configures Write and Display. The zero PrintOptions writes atoms as their String
+// methods do.
+type PrintOptions struct {
+	// Nil is how the nil atom is written, both on its own and when held in a list or vector.
+	Nil NilForm
+
+	// UTF8, if true, writes strings with their printable non-ASCII runes as they are, rather than
+	// as \u escapes. Quotes, backslashes, and control characters are still escaped, so the string
+	// can be read back. Display always writes the raw text of strings.
+	UTF8 bool
+
+	// Deterministic, if true, writes procedures without names, such as an interp.Proc, as
+	// #<procedure> rather than with their addresses, so that output is the same every time a program
+	// runs. Named procedures are always written with their names.
+	Deterministic bool
+}
+
+// procedure is implemented by procedure atoms, such as interp.Proc and interp.NamedProc, whose
+// String methods write their addresses unless they have names.
+type procedure interface {
+	ProcName() skim.Symbol
+}
+
+// Write writes a to w as it is formatted by its String method, using the zero PrintOptions.
+func Write(w io.Writer, a skim.Atom) error {
+	return PrintOptions{}.Write(w, a)
+}
+
+// Display writes the display representation of a to w, using the zero PrintOptions.
+func Display(w io.Writer, a skim.Atom) error {
+	return PrintOptions{}.Display(w, a)
+}
+
+// Write writes a to w as it is formatted by its String method, except as configured by o. Lists,
+// vectors, and the atoms they hold are written into a single buffer rather than formatted as a
+// string per atom. Cyclic atoms are written with datum labels, as in #0=(a . #0#). Write returns
+// the first error returned by w, after which nothing more is written.
+func (o PrintOptions) Write(w io.Writer, a skim.Atom) error {
+	return o.write(w, a, false, nil, nil)
+}
+
+// Display writes the display representation of a to w. It is the same as Write, except that
+// strings and characters are written without quotes or escapes.
+func (o PrintOptions) Display(w io.Writer, a skim.Atom) error {
+	return o.write(w, a, true, nil, nil)
+}
+
+// write writes a to w. If sm is not nil, atoms whose text in src it records, other than lists and
+// vectors, are written as that text.
+func (o PrintOptions) write(w io.Writer, a skim.Atom, display bool, src []byte, sm *skim.SourceMap) error {
+	p := printers.Get().(*printer)
+	p.w, p.err, p.display, p.opts = w, nil, display, o
+	p.src, p.sm = src, sm
+	p.labels = skim.NewLabels(a)
+	p.atom(a)
+	p.flush()
+	err := p.err
+	p.w, p.err, p.src, p.sm, p.labels = nil, nil, nil, nil, nil
+	printers.Put(p)
+	return err
+}
+
+// printers holds printers whose buffers can be reused by calls to Write.
+var printers = sync.Pool{
+	New: func() interface{} { return &printer{buf: make([]byte, 0, 256)} },
+}
+
+type printer struct {
+	w       io.Writer
+	buf     []byte
+	err     error
+	display bool // if true, strings and characters are written as raw text
+	opts    PrintOptions
+
+	// The text the atoms being written were read from and where, if they are to be written as it.
+	src []byte
+	sm  *skim.SourceMap
+
+	labels *skim.Labels // datum labels of the atom being written, if it is cyclic
+}
+
+func (p *printer) flush() {
+	if p.err == nil && len(p.buf) > 0 {
+		_, p.err = p.w.Write(p.buf)
+	}
+	p.buf = p.buf[:0]
+}
+
+func (p *printer) writeString(s string) {
+	p.buf = append(p.buf, s...)
+}
+
+func (p *printer) writeByte(c byte) {
+	p.buf = append(p.buf, c)
+}
+
+// label writes the datum label of a, if it has one, and returns whether a has already been
+// written, in which case only its reference, #N#, is written.
+func (p *printer) label(a skim.Atom) (written bool) {
+	label, written := p.labels.Label(a)
+	p.writeString(label)
+	return written
+}
+
+// elem writes a, read at pos if ok, as its text in the printer's source if that is known.
+func (p *printer) elem(a skim.Atom, pos skim.Pos, ok bool) {
+	if text, ok := sourceText(p.src, p.sm, a, pos, ok); ok {
+		p.writeString(text)
+		return
+	}
+	p.atom(a)
+}
+
+func (p *printer) car(c *skim.Cons) {
+	pos, ok := p.sm.Car(c)
+	p.elem(c.Car, pos, ok)
+}
+
+func (p *printer) cdr(c *skim.Cons) {
+	pos, ok := p.sm.Cdr(c)
+	p.elem(c.Cdr, pos, ok)
+}
+
+func (p *printer) atom(a skim.Atom) {
+	if p.err != nil {
+		return
+	} else if len(p.buf) >= flushSize {
+		p.flush()
+	}
+
+	switch a := a.(type) {
+	case nil:
+		if p.opts.Nil == NilEmptyList {
+			p.writeString("()")
+		} else {
+			p.writeString("#nil")
+		}
+	case *skim.Cons:
+		p.cons(a)
+	case skim.Vector:
+		if p.label(a) {
+			break
+		}
+		p.writeByte('[')
+		for i, elem := range a {
+			if i > 0 {
+				p.writeByte(' ')
+			}
+			pos, ok := p.sm.Elem(a, i)
+			p.elem(elem, pos, ok)
+		}
+		p.writeByte(']')
+	case *skim.HashMap:
+		if p.label(a) {
+			break
+		}
+		p.writeByte('{')
+		for i, key := range a.Keys() {
+			if i > 0 {
+				p.writeByte(' ')
+			}
+			value, _ := a.Get(key)
+			p.atom(key)
+			p.writeByte(' ')
+			p.atom(value)
+		}
+		p.writeByte('}')
+	case *skim.Set:
+		if p.label(a) {
+			break
+		}
+		p.writeString("#{")
+		for i, elem := range a.Elems() {
+			if i > 0 {
+				p.writeByte(' ')
+			}
+			p.atom(elem)
+		}
+		p.writeByte('}')
+	case skim.Int:
+		p.buf = strconv.AppendInt(p.buf, int64(a), 10)
+	case skim.Float:
+		p.float(float64(a))
+	case skim.String:
+		if p.display {
+			p.writeString(string(a))
+		} else if p.opts.UTF8 {
+			p.buf = strconv.AppendQuote(p.buf, string(a))
+		} else {
+			p.buf = strconv.AppendQuoteToASCII(p.buf, string(a))
+		}
+	case skim.Char:
+		if p.display {
+			p.buf = utf8.AppendRune(p.buf, rune(a))
+		} else {
+			p.writeString(a.String())
+		}
+	case skim.Bool:
+		if a {
+			p.writeString("#t")
+		} else {
+			p.writeString("#f")
+		}
+	default:
+		if proc, ok := a.(procedure); ok && p.opts.Deterministic && proc.ProcName() == "" {
+			p.writeString("#<procedure>")
+			break
+		}
+		p.writeString(a.String())
+	}
+}
+
+func (p *printer) float(f float64) {
+	switch {
+	case math.IsInf(f, 1):
+		p.writeString("+inf.0")
+	case math.IsInf(f, -1):
+		p.writeString("-inf.0")
+	case math.IsNaN(f):
+		p.writeString("+nan.0")
+	default:
+		n := len(p.buf)
+		p.buf = strconv.AppendFloat(p.buf, f, 'g', -1, 64)
+		if bytes.IndexAny(p.buf[n:], ".e") == -1 {
+			p.writeString(".0")
+		}
+	}
+}
+
+func (p *printer) cons(c *skim.Cons) {
+	if skim.IsNil(c) {
+		p.writeString("()")
+		return
+	}
+
+	if p.label(c) {
+		return
+	}
+
+	if quo, rest := skim.QuoteAbbrev(c); rest != nil && !p.labels.Labeled(rest) {
+		p.writeString(quo)
+		p.car(rest)
+		return
+	}
+
+	if skim.IsAlist(c, p.labels.LabeledPair) {
+		p.writeByte('{')
+		for i := c; !skim.IsNil(i); i, _ = i.Cdr.(*skim.Cons) {
+			if i != c {
+				p.writeByte(' ')
+			}
+			pair := i.Car.(*skim.Cons)
+			p.car(pair)
+			p.writeByte(' ')
+			p.cdr(pair)
+		}
+		p.writeByte('}')
+		return
+	}
+
+	ch := byte('(')
+	for last, a := c, skim.Atom(c); a != nil; {
+		cons, ok := a.(*skim.Cons)
+		if ok && skim.IsNil(cons) {
+			break
+		}
+		first := ch == '('
+		p.writeByte(ch)
+		ch = ' '
+
+		if !ok || (!first && p.labels.Labeled(cons)) {
+			p.writeString(". ")
+			p.cdr(last)
+			break
+		}
+
+		p.car(cons)
+		last, a = cons, cons.Cdr
+	}
+	p.writeByte(')')
+}
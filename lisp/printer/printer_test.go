@@ -0,0 +1,349 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func readCorpus(tb testing.TB) skim.Vector {
+	data, err := parser.ReadFile(filepath.Join("testdata", "corpus.skim"))
+	if err != nil {
+		tb.Fatalf("ReadFile(corpus.skim) err = %v; want nil", err)
+	}
+	return data
+}
+
+func TestWriteGolden(t *testing.T) {
+	var out bytes.Buffer
+	for _, a := range readCorpus(t) {
+		var b strings.Builder
+		if err := Write(&b, a); err != nil {
+			t.Fatalf("Write(%v) err = %v; want nil", a, err)
+		} else if got, want := b.String(), fmtstring(a); got != want {
+			t.Errorf("Write(%v) = %q; want %q", a, got, want)
+		}
+		out.WriteString(b.String())
+		out.WriteByte('\n')
+	}
+
+	golden := filepath.Join("testdata", "corpus.golden")
+	if *update {
+		if err := os.WriteFile(golden, out.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	} else if got := out.String(); got != string(want) {
+		t.Errorf("Write(corpus) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteAtoms(t *testing.T) {
+	cases := []skim.Atom{
+		nil,
+		(*skim.Cons)(nil),
+		skim.Nil,
+		skim.List(nil, (*skim.Cons)(nil), skim.Nil),
+		&skim.Cons{Car: skim.Symbol("a"), Cdr: skim.Nil},
+		skim.List(skim.Quote, skim.List(skim.Unquote, skim.Symbol("x"))),
+		skim.List(skim.Quote, skim.Nil),
+		skim.List(skim.List(skim.Quote, skim.Symbol("a"))),
+		skim.Vector{nil, skim.Vector(nil), skim.Bytes{0xff}},
+		skim.Symbol(""),
+		skim.Symbol("."),
+		skim.Symbol("a\x00b"),
+		skim.String("\x00\xff"),
+		skim.Float(-0.0),
+		skim.Float(2),
+		skim.Symbol("1"),
+		skim.Symbol("+inf.0"),
+		&skim.Cons{Car: skim.Symbol("a"), Cdr: (*skim.Cons)(nil)},
+		skim.List(skim.Unquote, skim.Symbol("@x")),
+		skim.List(skim.Quote, nil),
+		skim.List(skim.Quote, skim.Symbol("a"), skim.Symbol("b")),
+		skim.List(skim.UnquoteSplicing, skim.Symbol("x"), skim.Symbol("y")),
+		&skim.Cons{Car: skim.Quote, Cdr: &skim.Cons{Car: skim.Symbol("a"), Cdr: (*skim.Cons)(nil)}},
+	}
+	cyclic := &skim.Cons{Car: skim.Int(1)}
+	cyclic.Cdr = cyclic
+	quoted := &skim.Cons{Car: skim.Quote, Cdr: &skim.Cons{Cdr: skim.Nil}}
+	quoted.Cdr.(*skim.Cons).Car = quoted
+	shared := &skim.Cons{Car: skim.Symbol("b")}
+	shared.Cdr = shared
+	vec := skim.Vector{nil, skim.Int(1)}
+	vec[0] = vec
+	cases = append(cases, cyclic, skim.Vector{cyclic, cyclic}, quoted, &skim.Cons{Car: skim.Symbol("a"), Cdr: shared}, vec)
+	for _, a := range cases {
+		var b strings.Builder
+		if err := Write(&b, a); err != nil {
+			t.Errorf("Write(%#v) err = %v; want nil", a, err)
+		} else if got, want := b.String(), fmtstring(a); got != want {
+			t.Errorf("Write(%#v) = %q; want %q", a, got, want)
+		}
+	}
+}
+
+func TestWriteQuoteRoundTrip(t *testing.T) {
+	for _, in := range []string{
+		"'a",
+		"`(a ,b ,@c)",
+		"`(a `(b ,(c ,@d)) ,@[e ,f])",
+		",@(a b)",
+		"'(quote a b)",
+		"(quasiquote a b)",
+		"(unquote-splicing)",
+	} {
+		want, err := parser.ReadString(in)
+		if err != nil {
+			t.Fatalf("ReadString(%q) err = %v; want nil", in, err)
+		}
+		var b strings.Builder
+		if err := Write(&b, want[0]); err != nil {
+			t.Fatalf("Write(%v) err = %v; want nil", want[0], err)
+		} else if b.String() != in {
+			t.Errorf("Write(%#v) = %q; want %q", want[0], b.String(), in)
+		}
+		got, err := parser.ReadString(b.String())
+		if err != nil {
+			t.Errorf("ReadString(%q) err = %v; want nil", b.String(), err)
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadString(%q) = %#v; want %#v", b.String(), got, want)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	cases := []struct {
+		in             skim.Atom
+		display, write string
+	}{
+		{skim.String("hi"), `hi`, `"hi"`},
+		{skim.String("a \"λ\"\n"), "a \"λ\"\n", `"a \"\u03bb\"\n"`},
+		{skim.Char('a'), `a`, `#\a`},
+		{skim.Char(' '), ` `, `#\space`},
+		{skim.Symbol("a b"), `|a b|`, `|a b|`},
+		{
+			skim.List(skim.String("hi"), skim.Char('λ'), skim.Vector{skim.String("x y"), skim.List(skim.Quote, skim.String("z"))}),
+			`(hi λ [x y 'z])`,
+			`("hi" #\λ ["x y" '"z"])`,
+		},
+		{skim.List(&skim.Cons{Car: skim.String("k"), Cdr: skim.String("v")}), `{k v}`, `{"k" "v"}`},
+	}
+	for _, c := range cases {
+		var display, write strings.Builder
+		if err := Display(&display, c.in); err != nil || display.String() != c.display {
+			t.Errorf("Display(%v) = %q, %v; want %q, nil", c.in, display.String(), err, c.display)
+		}
+		if err := Write(&write, c.in); err != nil || write.String() != c.write {
+			t.Errorf("Write(%v) = %q, %v; want %q, nil", c.in, write.String(), err, c.write)
+		}
+	}
+}
+
+func TestPrintOptionsNil(t *testing.T) {
+	cases := []struct {
+		in          skim.Atom
+		hash, empty string
+	}{
+		{nil, "#nil", "()"},
+		{(*skim.Cons)(nil), "()", "()"},
+		{skim.Nil, "()", "()"},
+		{skim.List(nil, skim.Int(1), skim.Nil), "(#nil 1 ())", "(() 1 ())"},
+		{skim.Vector{nil}, "[#nil]", "[()]"},
+		{&skim.Cons{Car: skim.Symbol("a"), Cdr: nil}, "(a)", "(a)"},
+		{skim.List(skim.Quote, nil), "'#nil", "'()"},
+	}
+	for _, c := range cases {
+		var hash, empty strings.Builder
+		if err := (PrintOptions{Nil: NilHash}).Write(&hash, c.in); err != nil || hash.String() != c.hash {
+			t.Errorf("Write(%#v) with NilHash = %q, %v; want %q, nil", c.in, hash.String(), err, c.hash)
+		} else if got := fmtstring(c.in); got != c.hash {
+			t.Errorf("String(%#v) = %q; want %q", c.in, got, c.hash)
+		}
+		if err := (PrintOptions{Nil: NilEmptyList}).Write(&empty, c.in); err != nil || empty.String() != c.empty {
+			t.Errorf("Write(%#v) with NilEmptyList = %q, %v; want %q, nil", c.in, empty.String(), err, c.empty)
+		}
+		for _, text := range []string{hash.String(), empty.String()} {
+			if _, err := parser.ReadString(text); err != nil {
+				t.Errorf("ReadString(%q) err = %v; want nil", text, err)
+			}
+		}
+	}
+}
+
+func TestPrintOptionsUTF8(t *testing.T) {
+	cases := []struct {
+		in          skim.String
+		ascii, utf8 string
+	}{
+		{"plain", `"plain"`, `"plain"`},
+		{"設定値", `"\u8a2d\u5b9a\u5024"`, `"設定値"`},
+		{"😀 \"ok\"\\", `"\U0001f600 \"ok\"\\"`, `"😀 \"ok\"\\"`},
+		{"tab\tnul\x00\u200b", `"tab\tnul\x00\u200b"`, `"tab\tnul\x00\u200b"`},
+	}
+	for _, c := range cases {
+		var ascii, utf8 strings.Builder
+		if err := Write(&ascii, c.in); err != nil || ascii.String() != c.ascii {
+			t.Errorf("Write(%q) = %s, %v; want %s, nil", string(c.in), ascii.String(), err, c.ascii)
+		}
+		if err := (PrintOptions{UTF8: true}).Write(&utf8, skim.Vector{c.in}); err != nil || utf8.String() != "["+c.utf8+"]" {
+			t.Errorf("Write([%q]) with UTF8 = %s, %v; want [%s], nil", string(c.in), utf8.String(), err, c.utf8)
+		}
+		if got := c.in.GoString(); got != c.ascii {
+			t.Errorf("GoString(%q) = %s; want %s", string(c.in), got, c.ascii)
+		}
+		for _, text := range []string{ascii.String(), c.utf8} {
+			if got, err := parser.ReadString(text); err != nil || len(got) != 1 || got[0] != c.in {
+				t.Errorf("ReadString(%s) = %v, %v; want [%s]", text, got, err, c.ascii)
+			}
+		}
+	}
+}
+
+// testProc is a procedure atom whose String method writes its address unless it has a name.
+type testProc struct{ name skim.Symbol }
+
+func (testProc) SkimAtom() {}
+
+func (p *testProc) String() string {
+	if p.name != "" {
+		return "#<procedure " + string(p.name) + ">"
+	}
+	return fmt.Sprintf("#<procedure %p>", p)
+}
+
+func (p *testProc) ProcName() skim.Symbol { return p.name }
+
+func TestPrintOptionsDeterministic(t *testing.T) {
+	anon, named := &testProc{}, &testProc{name: "f"}
+	cases := []struct {
+		in                  skim.Atom
+		deterministic, want string
+	}{
+		{anon, "#<procedure>", anon.String()},
+		{named, "#<procedure f>", "#<procedure f>"},
+		{skim.List(named, skim.Vector{anon}), "(#<procedure f> [#<procedure>])", "(#<procedure f> [" + anon.String() + "])"},
+	}
+	for _, c := range cases {
+		var det, def strings.Builder
+		if err := (PrintOptions{Deterministic: true}).Write(&det, c.in); err != nil || det.String() != c.deterministic {
+			t.Errorf("Write(%v) with Deterministic = %q, %v; want %q, nil", c.in, det.String(), err, c.deterministic)
+		}
+		if err := Write(&def, c.in); err != nil || def.String() != c.want {
+			t.Errorf("Write(%v) = %q, %v; want %q, nil", c.in, def.String(), err, c.want)
+		}
+	}
+}
+
+func TestPrintOptionsCyclic(t *testing.T) {
+	named, anon := &testProc{name: "f"}, &testProc{}
+	last := &skim.Cons{Car: anon}
+	c := &skim.Cons{Car: skim.String("λ"), Cdr: &skim.Cons{Car: nil, Cdr: last}}
+	last.Cdr = c
+	cases := []struct {
+		opts    PrintOptions
+		display bool
+		want    string
+	}{
+		{PrintOptions{}, true, `#0=(λ #nil ` + anon.String() + ` . #0#)`},
+		{PrintOptions{Nil: NilEmptyList, UTF8: true, Deterministic: true}, false, `#0=("λ" () #<procedure> . #0#)`},
+		{PrintOptions{Deterministic: true}, false, `#0=("\u03bb" #nil #<procedure> . #0#)`},
+	}
+	for _, tc := range cases {
+		var b strings.Builder
+		if err := tc.opts.write(&b, c, tc.display, nil, nil); err != nil || b.String() != tc.want {
+			t.Errorf("write(%+v, display=%t) = %q, %v; want %q, nil", tc.opts, tc.display, b.String(), err, tc.want)
+		}
+	}
+
+	// Labels are numbered in the order they are written, and atoms that are not part of a cycle are
+	// written as by any other Write.
+	v := skim.Vector{nil, skim.List(skim.Quote, named)}
+	v[0] = v
+	var b strings.Builder
+	if err := (PrintOptions{Nil: NilEmptyList}).Write(&b, skim.List(v, c, nil)); err != nil {
+		t.Fatalf("Write(cyclic list) err = %v; want nil", err)
+	} else if want := `(#0=[#0# '#<procedure f>] #1=("\u03bb" () ` + anon.String() + ` . #1#) ())`; b.String() != want {
+		t.Errorf("Write(cyclic list) = %q; want %q", b.String(), want)
+	}
+}
+
+// errWriter accepts n bytes and then fails with err.
+type errWriter struct {
+	n   int
+	err error
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, w.err
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteError(t *testing.T) {
+	errWrite := errors.New("write error")
+	long := make(skim.Vector, flushSize)
+	for i := range long {
+		long[i] = skim.Int(i)
+	}
+
+	for _, a := range []skim.Atom{skim.Int(1), long} {
+		if err := Write(&errWriter{err: errWrite}, a); err != errWrite {
+			t.Errorf("Write(..) err = %v; want %v", err, errWrite)
+		}
+	}
+
+	// Long data is written out as it is formatted, so errors are returned from the middle of it.
+	if err := Write(&errWriter{n: flushSize, err: errWrite}, long); err != errWrite {
+		t.Errorf("Write(..) err = %v; want %v", err, errWrite)
+	}
+}
+
+// fmtstring returns a as formatted by its String method, or #nil if a is nil.
+func fmtstring(a skim.Atom) string {
+	if a == nil {
+		return "#nil"
+	}
+	return a.String()
+}
+
+func BenchmarkWrite(b *testing.B) {
+	data := readCorpus(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, a := range data {
+			if err := Write(io.Discard, a); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	data := readCorpus(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, a := range data {
+			io.WriteString(io.Discard, fmtstring(a))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package skim
+
+import "fmt"
+
+// Assoc returns the first pair of the association list, list, whose car is Equal to key, such as
+// (b . 2) in ((a . 1) (b . 2)), and whether there is one. The whole list is checked, so Assoc
+// returns an error if list is not a proper list or any of its elements is not a pair, even if key
+// is found before it.
+func Assoc(list, key Atom) (*Cons, bool, error) {
+	return assoc(list, key, Equal)
+}
+
+// Assq returns the first pair of the association list, list, whose car is Eqv to key, and whether
+// there is one. It returns the same errors as Assoc.
+func Assq(list, key Atom) (*Cons, bool, error) {
+	return assoc(list, key, Eqv)
+}
+
+// AlistGet returns the value of the first pair of the association list, list, whose car is Equal to
+// key, and whether there is one. It returns the same errors as Assoc.
+func AlistGet(list, key Atom) (value Atom, ok bool, err error) {
+	pair, ok, err := Assoc(list, key)
+	if !ok || err != nil {
+		return nil, false, err
+	}
+	return pair.Cdr, true, nil
+}
+
+// AlistToMap converts an association list whose keys are Symbols or Strings, such as
+// ((host . "x") ("port" . 8080)), to a map of their names to their values. If a key occurs more than
+// once, its first value is kept; a Symbol and a String with the same name are the same key.
+// AlistToMap returns an error if list is not a proper list, has an element that is not a pair, or
+// has a key that is neither a Symbol nor a String.
+func AlistToMap(list Atom) (map[string]Atom, error) {
+	m := make(map[string]Atom)
+	err := walkAlist(list, func(i int, pair *Cons) error {
+		var key string
+		switch k := pair.Car.(type) {
+		case Symbol:
+			key = string(k)
+		case String:
+			key = string(k)
+		default:
+			return fmt.Errorf("skim: alist: key of element %d is a %T, not a Symbol or String", i, pair.Car)
+		}
+		if _, dup := m[key]; !dup {
+			m[key] = pair.Cdr
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// assoc returns the first pair of list whose car is the same as key, as decided by same.
+func assoc(list, key Atom, same func(a, b Atom) bool) (found *Cons, ok bool, err error) {
+	err = walkAlist(list, func(_ int, pair *Cons) error {
+		if !ok && same(pair.Car, key) {
+			found, ok = pair, true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return found, ok, nil
+}
+
+// walkAlist calls fn for each pair of the association list, list, and its index, in order.
+func walkAlist(list Atom, fn func(int, *Cons) error) error {
+	i := 0
+	return Walk(list, func(a Atom) error {
+		defer func() { i++ }()
+		pair, ok := a.(*Cons)
+		if !ok || IsNil(pair) {
+			return fmt.Errorf("skim: alist: element %d is %s, not a pair", i, fmtstring(a))
+		}
+		return fn(i, pair)
+	})
+}
@@ -0,0 +1,124 @@
+package skim
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestAssoc(t *testing.T) {
+	key := List(Symbol("k"))
+	b := &Cons{Car: Symbol("b"), Cdr: Int(2)}
+	lk := &Cons{Car: key, Cdr: String("list")}
+	one := &Cons{Car: Int(1), Cdr: nil}
+	alist := List(
+		&Cons{Car: Symbol("a"), Cdr: Int(1)},
+		b,
+		&Cons{Car: Symbol("b"), Cdr: Int(3)}, // duplicate: the first is found
+		lk,
+		one,
+	)
+
+	cases := []struct {
+		key           Atom
+		assoc, assq   *Cons
+		value         Atom
+		found, foundq bool
+	}{
+		{key: Symbol("b"), assoc: b, assq: b, value: Int(2), found: true, foundq: true},
+		{key: Symbol("z")},
+		{key: List(Symbol("k")), assoc: lk, value: String("list"), found: true}, // equal, but not eqv
+		{key: key, assoc: lk, assq: lk, value: String("list"), found: true, foundq: true},
+		{key: Int(1), assoc: one, assq: one, found: true, foundq: true},
+	}
+	for _, c := range cases {
+		if got, ok, err := Assoc(alist, c.key); err != nil || got != c.assoc || ok != c.found {
+			t.Errorf("Assoc(%v) = %v, %t, %v; want %v, %t, nil", c.key, got, ok, err, c.assoc, c.found)
+		}
+		if got, ok, err := Assq(alist, c.key); err != nil || got != c.assq || ok != c.foundq {
+			t.Errorf("Assq(%v) = %v, %t, %v; want %v, %t, nil", c.key, got, ok, err, c.assq, c.foundq)
+		}
+		if got, ok, err := AlistGet(alist, c.key); err != nil || got != c.value || ok != c.found {
+			t.Errorf("AlistGet(%v) = %v, %t, %v; want %v, %t, nil", c.key, got, ok, err, c.value, c.found)
+		}
+	}
+
+	for _, list := range []Atom{nil, Nil, Vector{}} {
+		if got, ok, err := Assoc(list, Symbol("a")); got != nil || ok || err != nil {
+			t.Errorf("Assoc(%v, a) = %v, %t, %v; want nil, false, nil", list, got, ok, err)
+		}
+	}
+
+	malformed := []struct {
+		in   Atom
+		want string
+	}{
+		{List(&Cons{Car: Symbol("a"), Cdr: Int(1)}, Int(3)), "skim: alist: element 1 is 3, not a pair"},
+		{List(&Cons{Car: Symbol("a"), Cdr: Int(1)}, Nil), "skim: alist: element 1 is (), not a pair"},
+		{&Cons{Car: &Cons{Car: Symbol("a"), Cdr: Int(1)}, Cdr: Int(2)}, "skim: cannot walk skim.Int"},
+	}
+	for _, c := range malformed {
+		if _, _, err := Assoc(c.in, Symbol("a")); err == nil || err.Error() != c.want {
+			t.Errorf("Assoc(%v, a) err = %v; want %s", c.in, err, c.want)
+		}
+		if _, _, err := AlistGet(c.in, Symbol("a")); err == nil || err.Error() != c.want {
+			t.Errorf("AlistGet(%v, a) err = %v; want %s", c.in, err, c.want)
+		}
+	}
+}
+
+func TestAlistToMap(t *testing.T) {
+	got, err := AlistToMap(List(
+		&Cons{Car: Symbol("host"), Cdr: String("x")},
+		&Cons{Car: String("port"), Cdr: Int(8080)},
+		&Cons{Car: String("host"), Cdr: String("y")}, // duplicate: the first is kept
+		List(Symbol("tags"), Symbol("a")),
+	))
+	want := map[string]Atom{"host": String("x"), "port": Int(8080), "tags": List(Symbol("a"))}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("AlistToMap() = %v, %v; want %v", got, err, want)
+	}
+
+	if got, err := AlistToMap(nil); err != nil || len(got) != 0 {
+		t.Errorf("AlistToMap(nil) = %v, %v; want an empty map", got, err)
+	}
+	errs := []struct {
+		in   Atom
+		want string
+	}{
+		{List(&Cons{Car: Symbol("a"), Cdr: Int(1)}, &Cons{Car: Int(2), Cdr: Int(3)}), "skim: alist: key of element 1 is a skim.Int, not a Symbol or String"},
+		{List(Symbol("a")), "skim: alist: element 0 is a, not a pair"},
+	}
+	for _, c := range errs {
+		if _, err := AlistToMap(c.in); err == nil || err.Error() != c.want {
+			t.Errorf("AlistToMap(%v) err = %v; want %s", c.in, err, c.want)
+		}
+	}
+}
+
+func TestEqv(t *testing.T) {
+	l, v, b := List(Int(1)), Vector{Int(1)}, Bytes{1}
+	cases := []struct {
+		a, b Atom
+		want bool
+	}{
+		{nil, Nil, true},
+		{Int(1), Int(1), true},
+		{Int(1), Float(1), false},
+		{String("a"), String("a"), true},
+		{NewBigInt(big.NewInt(1)), NewBigInt(big.NewInt(1)), true},
+		{l, l, true},
+		{l, List(Int(1)), false},
+		{v, v, true},
+		{v, Vector{Int(1)}, false},
+		{Vector{}, Vector{}, true},
+		{b, b, true},
+		{b, Bytes{1}, false},
+		{funcAtom(func() {}), funcAtom(func() {}), false},
+	}
+	for _, c := range cases {
+		if got := Eqv(c.a, c.b); got != c.want {
+			t.Errorf("Eqv(%v, %v) = %t; want %t", c.a, c.b, got, c.want)
+		}
+	}
+}
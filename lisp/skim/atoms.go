@@ -1,16 +1,21 @@
 package skim
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
 // Atom defines any value understood to be a member of a skim list, including lists themselves.
 type Atom interface {
 	// SkimAtom is an empty method -- it exists only to mark a type as an Atom at compile time.
 	SkimAtom()
+	// String returns the atom's external representation, as written by the write builtin. Where
+	// possible, it can be read back as an equal atom, so strings are quoted and escaped. The
+	// printer package's Display function writes the display representation instead.
 	String() string
 }
 
@@ -18,6 +23,8 @@ type Numeric interface {
 	Atom
 
 	IsFloat() bool
+	// Exact returns whether the number is exact: an integer or ratio, rather than a float.
+	Exact() bool
 	Int64() (int64, bool)
 	Float64() (float64, bool)
 }
@@ -31,6 +38,9 @@ type Dupper interface {
 	Dup() Atom
 }
 
+// Dup returns a deep copy of a. Atoms implementing Dupper, such as lists, vectors, and Bytes, are
+// copied by their Dup methods. Any other atom, such as a String, Int, or Symbol, is a value and is
+// returned as is.
 func Dup(a Atom) Atom {
 	if a, ok := a.(Dupper); ok {
 		return a.Dup()
@@ -38,6 +48,73 @@ func Dup(a Atom) Atom {
 	return a
 }
 
+// DupShallow returns a copy of the list or Vector a that shares its elements with a. Only the cons
+// pairs following the cdrs of a list are copied, and the copy ends in the same tail as a, so
+// changing the cdrs of the copy does not change a. If the cdrs of a form a cycle, so do those of the
+// copy. Any other atom is returned as is.
+func DupShallow(a Atom) Atom {
+	switch v := a.(type) {
+	case Vector:
+		if v == nil {
+			return v
+		}
+		d := make(Vector, len(v))
+		copy(d, v)
+		return d
+	case *Cons:
+		if v == nil {
+			return nil
+		} else if v == Nil {
+			return v
+		}
+		return v.dupShallow()
+	}
+	return a
+}
+
+// dupShallow copies the cons pairs following the cdrs of c, allocating them together unless they
+// form a cycle.
+func (c *Cons) dupShallow() *Cons {
+	n, slow := 0, c
+	for fast := c; !IsNil(fast); {
+		n++
+		next, _ := fast.Cdr.(*Cons)
+		if n%2 == 0 {
+			slow, _ = slow.Cdr.(*Cons)
+		}
+		if next != nil && next == slow {
+			return c.dupCircular()
+		}
+		fast = next
+	}
+
+	pairs := make([]Cons, n)
+	for i := range pairs {
+		pairs[i] = *c
+		if i < n-1 {
+			pairs[i].Cdr = &pairs[i+1]
+			c = c.Cdr.(*Cons)
+		}
+	}
+	return &pairs[0]
+}
+
+// dupCircular copies the cons pairs following the cdrs of c, which form a cycle.
+func (c *Cons) dupCircular() *Cons {
+	result := &Cons{Car: c.Car}
+	dups := map[*Cons]*Cons{c: result}
+	for d := result; ; {
+		next := c.Cdr.(*Cons)
+		if dn, ok := dups[next]; ok {
+			d.Cdr = dn
+			return result
+		}
+		dn := &Cons{Car: next.Car}
+		dups[next] = dn
+		d.Cdr, d, c = dn, dn, next
+	}
+}
+
 type goStringer interface {
 	GoString() string
 }
@@ -73,33 +150,141 @@ type Int int64
 func (Int) SkimAtom()                  {}
 func (i Int) String() string           { return strconv.FormatInt(int64(i), 10) }
 func (Int) IsFloat() bool              { return false }
+func (Int) Exact() bool                { return true }
 func (i Int) Float64() (float64, bool) { return float64(i), true }
 func (i Int) Int64() (int64, bool)     { return int64(i), true }
 
 type Float float64
 
 func (Float) SkimAtom()                  {}
-func (f Float) String() string           { return strconv.FormatFloat(float64(f), 'f', -1, 64) }
+func (f Float) String() string           { return f.string() }
 func (Float) IsFloat() bool              { return true }
+func (Float) Exact() bool                { return false }
 func (f Float) Float64() (float64, bool) { return float64(f), true }
-func (f Float) Int64() (int64, bool)     { return int64(f), true }
+
+// Int64 returns f truncated toward zero. It returns false if f is NaN, infinite, or outside the range
+// of an int64.
+func (f Float) Int64() (int64, bool) {
+	// -2^63 is exactly representable, but MaxInt64 rounds up to 2^63, which is out of range.
+	if !(f >= math.MinInt64 && f < -math.MinInt64) {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// string returns the float as it would be written in source. Infinities and NaN are written as
+// +inf.0, -inf.0, and +nan.0.
+func (f Float) string() string {
+	switch v := float64(f); {
+	case math.IsInf(v, 1):
+		return "+inf.0"
+	case math.IsInf(v, -1):
+		return "-inf.0"
+	case math.IsNaN(v):
+		return "+nan.0"
+	}
+	// Floats are written in their shortest form, using an exponent for large and small magnitudes,
+	// such as 1e+21 and 1e-10.
+	s := strconv.FormatFloat(float64(f), 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		// Integral floats keep a decimal point so that they are not read back as integers.
+		s += ".0"
+	}
+	return s
+}
 
 type Symbol string
 
 const (
-	noQuote    = Symbol("")
-	Quote      = Symbol("quote")
-	Quasiquote = Symbol("quasiquote")
-	Unquote    = Symbol("unquote")
+	noQuote         = Symbol("")
+	Quote           = Symbol("quote")
+	Quasiquote      = Symbol("quasiquote")
+	Unquote         = Symbol("unquote")
+	UnquoteSplicing = Symbol("unquote-splicing")
 )
 
 func (Symbol) SkimAtom() {}
 
-func (s Symbol) String() string   { return string(s) }
+// String returns the symbol as it would be written in source. Symbols that cannot be read back
+// as-is, such as those containing whitespace or parentheses or that would be read as a number or
+// other atom, are quoted by pipes: |foo bar|, |1|.
+func (s Symbol) String() string {
+	if s.isBare() {
+		return string(s)
+	}
+	return s.quote()
+}
+
 func (s Symbol) GoString() string { return string(s) }
 
+// symbolSentinels is the set of runes, in addition to whitespace, that terminate a bare symbol.
+const symbolSentinels = "()[]{}'\",`;|"
+
+func (s Symbol) isBare() bool {
+	if s == "" || s == "." || s.isSyntax() {
+		return false
+	}
+	for _, r := range s {
+		if unicode.IsSpace(r) || strings.ContainsRune(symbolSentinels, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSyntax returns whether s, written bare, may be read as something other than a symbol, such
+// as a number, keyword, character, or heredoc.
+func (s Symbol) isSyntax() bool {
+	switch first := s[0]; {
+	case first == '#', isDigit(first), strings.HasPrefix(string(s), "<<<") && len(s) > 3:
+		return true
+	case first == ':':
+		return len(s) > 1
+	case first == '.':
+		return len(s) > 1 && isDigit(s[1])
+	case first == '+' || first == '-':
+		rest := string(s[1:])
+		if strings.EqualFold(rest, "inf.0") || strings.EqualFold(rest, "nan.0") {
+			return true
+		}
+		rest = strings.TrimPrefix(rest, ".")
+		return rest != "" && isDigit(rest[0])
+	}
+	return false
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func (s Symbol) quote() string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('|')
+	for _, r := range string(s) {
+		switch {
+		case r == '|' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('|')
+	return b.String()
+}
+
 type Cons struct{ Car, Cdr Atom }
 
+// Nil is the empty list, (). It is the only empty cons pair: a pair holding two nils, such as that
+// made by (cons #nil #nil), is the list (#nil) of one element. The nil atom and a nil *Cons also
+// end lists and are reported empty by IsNil. Nil is shared, so it must never be modified.
+var Nil = &Cons{}
+
 func IsTrue(a Atom) bool {
 	switch a := a.(type) {
 	case Bool:
@@ -107,144 +292,73 @@ func IsTrue(a Atom) bool {
 	case nil:
 		return false
 	case *Cons:
-		return a != nil && (a.Car != nil || a.Cdr != nil)
+		return a != nil && a != Nil
 	}
 	return true
 }
 
+// IsNil returns whether a is an empty list: nil, a nil *Cons, or Nil.
 func IsNil(a Atom) bool {
 	if a == nil {
 		return true
 	}
 	switch a := a.(type) {
 	case *Cons:
-		return a == nil || (a.Cdr == nil && a.Car == nil)
+		return a == nil || a == Nil
 	default:
 		return false
 	}
 }
 
+// Dup returns a deep copy of c. Every cons pair and vector reachable from c is copied, and the
+// other atoms they hold are copied by Dup. If c is cyclic, each cons pair and vector that c holds
+// more than once is copied once, so the copy has the same cycles and sharing as c; otherwise, each
+// is copied every time it is held. The cons pairs of each list are allocated together, and c may be
+// nested to any depth.
 func (c *Cons) Dup() Atom {
 	if c == nil {
 		return nil
+	} else if Cyclic(c) {
+		return dupAtom(c, make(map[interface{}]Atom))
 	}
-
-	n := 1
-	for counter := c; counter.Cdr != nil; {
-		n++
-		if counter.Cdr == nil {
-			break
-		} else if next, ok := counter.Cdr.(*Cons); ok {
-			counter = next
-		} else {
-			break
-		}
-	}
-
-	var (
-		result Atom
-		dupd   = make([]Cons, n)
-		pred   = &result
-	)
-	for i := range dupd {
-		dpair := &dupd[i]
-		dpair.Car = Dup(c.Car)
-		*pred, pred = dpair, &dpair.Cdr
-		if next, ok := c.Cdr.(*Cons); ok {
-			c = next
-		} else {
-			dpair.Cdr = Dup(c.Cdr)
-			break
-		}
-	}
-
-	return result
+	return dupAtom(c, nil)
 }
 
 func (*Cons) SkimAtom() {}
-func (c *Cons) string(gostring bool) string {
-	if c == nil {
-		return "#null"
-	}
-
-	if IsNil(c) {
-		return "()"
-	}
-
-	fmtfn := fmtstring
-	if gostring {
-		fmtfn = fmtgostring
-	} else if !gostring {
-		quo := "'"
-		switch c.Car {
-		case Quote:
-		case Unquote:
-			quo = ","
-		case Quasiquote:
-			quo = "`"
-		default:
-			goto list
-		}
-
-		if c, ok := c.Cdr.(*Cons); ok {
-			if IsNil(c) {
-				return quo + "()"
-			}
-
-			switch c.Cdr.(type) {
-			case *Cons:
-				return quo + fmtstring(c)
-			case nil:
-				return quo + fmtstring(c.Car)
-			}
-		}
-	}
-
-list:
-	var b bytes.Buffer
-	ch := byte('(')
-	for c := Atom(c); c != nil; {
-		b.WriteByte(ch)
-		ch = ' '
-
-		cons, ok := c.(*Cons)
-		if !ok {
-			b.WriteString(". ")
-			b.WriteString(fmtfn(c))
-			break
-		}
-
-		b.WriteString(fmtfn(cons.Car))
-		c = cons.Cdr
-	}
-	b.WriteByte(')')
-	return b.String()
+func (c *Cons) String() string {
+	w := newWriter(c, false)
+	w.cons(c)
+	return w.String()
 }
 
-func (c *Cons) String() string { return c.string(false) }
-
+// GoString returns the cons pair written as a dotted pair, (car . cdr), with its car and cdr
+// written by their GoString methods.
 func (c *Cons) GoString() string {
-	if c == nil {
-		return "#null"
-	}
-	return "(" + fmtgostring(c.Car) + " . " + fmtgostring(c.Cdr) + ")"
+	w := newWriter(c, true)
+	w.cons(c)
+	return w.String()
 }
 
+// Map returns a new list holding the result of fn for the car of each cons pair in c, allocating
+// all of its pairs at once. If c is an improper list, such as (1 2 . 3), the new list ends in the
+// same tail, which is not passed to fn. If fn returns an error, Map returns it and a nil list.
 func (c *Cons) Map(fn MapFunc) (result Atom, err error) {
 	if c == nil { // typed nil - distinct from Atom(nil)
 		return nil, nil
+	} else if c == Nil {
+		return Nil, nil
 	}
 
-	n := 1
-	for counter := c; counter.Cdr != nil; {
-		n++
-		if counter.Cdr == nil {
+	n, tail := 1, c.Cdr
+	for {
+		next, ok := tail.(*Cons)
+		if !ok {
+			break
+		} else if IsNil(next) {
+			tail = nil
 			break
-		} else if next, ok := counter.Cdr.(*Cons); ok {
-			counter = next
-		} else {
-			return nil, fmt.Errorf("skim: map: cannot map a list with a Cdr of %T", counter.Cdr)
 		}
+		n, tail = n+1, next.Cdr
 	}
 
 	var (
@@ -259,34 +373,34 @@ func (c *Cons) Map(fn MapFunc) (result Atom, err error) {
 		*pred, pred = mpair, &mpair.Cdr
 		c, _ = c.Cdr.(*Cons)
 	}
+	*pred = tail
 
 	return result, nil
 }
 
 type Vector []Atom
 
-func (Vector) SkimAtom()          {}
-func (v Vector) String() string   { return v.format(fmtstring) }
-func (v Vector) GoString() string { return v.format(fmtgostring) }
+func (Vector) SkimAtom() {}
 
-func (v Vector) format(format func(interface{}) string) string {
-	vs := "["
-	for i, a := range v {
-		if i > 0 {
-			vs += " "
-		}
-		vs += format(a)
-	}
-	vs += "]"
-	return vs
+func (v Vector) String() string {
+	w := newWriter(v, false)
+	w.vector(v)
+	return w.String()
+}
+
+func (v Vector) GoString() string {
+	w := newWriter(v, true)
+	w.vector(v)
+	return w.String()
 }
 
+// Dup returns a deep copy of v, as (*Cons).Dup does for lists. Copying an empty vector returns a
+// new empty vector.
 func (v Vector) Dup() Atom {
-	d := make(Vector, len(v))
-	for i, a := range v {
-		d[i] = Dup(a)
+	if Cyclic(v) {
+		return dupAtom(v, make(map[interface{}]Atom))
 	}
-	return d
+	return dupAtom(v, nil)
 }
 
 func (v Vector) Map(fn MapFunc) (result Atom, err error) {
@@ -303,6 +417,9 @@ func (v Vector) Map(fn MapFunc) (result Atom, err error) {
 	return mapped, nil
 }
 
+// String is a string. Its String method returns the string double-quoted and with any non-ASCII
+// or non-printable runes escaped, so that it can be read back; convert it to a Go string for its
+// raw text.
 type String string
 
 func (String) SkimAtom()          {}
@@ -319,104 +436,298 @@ func (b Bool) String() string {
 	return "#f"
 }
 
+// Pair returns the elements of a, which must be a list of two elements, (lhs rhs). It returns an
+// error naming a and the position at which it differs from such a list if it does not have exactly
+// two elements or is improper.
 func Pair(a Atom) (lhs, rhs Atom, err error) {
 	la, ok := a.(*Cons)
-	if !ok || la == nil {
-		return nil, nil, errors.New("skim: (car atom) is not a *Cons")
+	if !ok || IsNil(la) {
+		return nil, nil, fmt.Errorf("skim: pair: %s has no first element", fmtstring(a))
 	}
 	ra, ok := la.Cdr.(*Cons)
-	if !ok || ra == nil {
-		return nil, nil, errors.New("skim: (cdr atom) is not a *Cons")
-	} else if ra.Cdr != nil {
-		return nil, nil, errors.New("skim: (cdr atom) is not a *Cons of the form (a . (b . #nil))")
+	if !ok || IsNil(ra) {
+		return nil, nil, fmt.Errorf("skim: pair: %s has no second element", fmtstring(a))
+	} else if !IsNil(ra.Cdr) {
+		if _, ok := ra.Cdr.(*Cons); ok {
+			return nil, nil, fmt.Errorf("skim: pair: %s has more than two elements", fmtstring(a))
+		}
+		return nil, nil, fmt.Errorf("skim: pair: %s has a dotted tail after its second element", fmtstring(a))
 	}
 	return la.Car, ra.Car, nil
 }
 
+// DottedPair returns the car and cdr of a if it is a dotted pair, (lhs . rhs), whose cdr is not a
+// list, and otherwise the elements of a as Pair does, so that both (lhs . rhs) and (lhs rhs) are
+// accepted. It returns the errors of Pair if a is neither.
+func DottedPair(a Atom) (lhs, rhs Atom, err error) {
+	if c, ok := a.(*Cons); ok && !IsNil(c) {
+		if _, ok := c.Cdr.(*Cons); !ok && c.Cdr != nil {
+			return c.Car, c.Cdr, nil
+		}
+	}
+	return Pair(a)
+}
+
 type Visitor func(Atom) (Visitor, error)
 
+// SkipSubtree and StopTraversal may be returned by a Visitor or CtxVisitor to control traversal
+// without failing it. SkipSubtree skips the atoms within the atom just visited, as a nil visitor
+// does; for a cons pair, this is its car and the rest of its list. StopTraversal ends traversal, after
+// which Traverse and TraverseCtx return nil. Neither is ever returned by Traverse or TraverseCtx.
+var (
+	SkipSubtree   = errors.New("skim: skip subtree")
+	StopTraversal = errors.New("skim: stop traversal")
+)
+
+// VisitContext describes where an atom visited by TraverseCtx is.
+type VisitContext struct {
+	// Depth is the number of lists, vectors, maps, and sets enclosing the atom, from zero. The atom
+	// passed to TraverseCtx has a depth of zero, as do the cdrs of cons pairs, which are the rest
+	// of the same list.
+	Depth int
+
+	// Pos is the position of the atom in source, if HasPos is true. It is only known for atoms
+	// recorded in the SourceMap passed to TraverseCtx: lists, and the elements of lists. The
+	// position of the rest of a list is that of its first element.
+	Pos    Pos
+	HasPos bool
+}
+
+// CtxVisitor is a Visitor that is also passed the context of the atom it visits.
+type CtxVisitor func(VisitContext, Atom) (CtxVisitor, error)
+
 // Traverse will recursively visit all cons pairs and left and right elements, in order. Traversal
 // ends when a visitor returns a nil visitor for nested elements and all adjacent and upper elements
 // are traversed. If a Vector is encountered, the vector itself is passed to the visitor function
-// followed by its elements (passed to the visitor returned for the Vector).
-func Traverse(a Atom, visitor Visitor) (err error) {
-traverseCdr:
-	if IsNil(a) {
-		return nil
+// followed by its elements (passed to the visitor returned for the Vector). A HashMap is traversed
+// in the same way, with each of its keys followed by its value, sorted by its keys, as is a Set, with
+// its elements sorted.
+//
+// Each cons pair is passed to the visitor, after which its car is traversed using the visitor
+// returned for it, followed by its cdr. Traverse keeps its own stack rather than recursing, so the
+// depth of a is limited only by memory. If a visitor returns SkipSubtree or StopTraversal, traversal
+// continues or ends as described by them. If a visitor returns any other error, traversal ends and
+// Traverse returns it, however deeply nested the atom it was returned for, including errors
+// returned while traversing the car of a pair.
+func Traverse(a Atom, visitor Visitor) error {
+	return traverse(a, nil, visitors{plain: visitor})
+}
+
+// TraverseCtx visits a as Traverse does, but also passes each visitor the context of the atom it
+// visits: its depth and, if it is recorded in sm, its source position. sm may be nil.
+func TraverseCtx(a Atom, sm *SourceMap, visitor CtxVisitor) error {
+	return traverse(a, sm, visitors{ctx: visitor})
+}
+
+// visitors holds the visitor of Traverse or TraverseCtx, whichever is in use.
+type visitors struct {
+	plain Visitor
+	ctx   CtxVisitor
+}
+
+// visit calls the visitor for a and returns the visitor it returns, and whether it is not nil.
+func (v visitors) visit(ctx VisitContext, a Atom) (visitors, bool, error) {
+	if v.ctx != nil {
+		next, err := v.ctx(ctx, a)
+		return visitors{ctx: next}, next != nil, err
 	}
+	next, err := v.plain(a)
+	return visitors{plain: next}, next != nil, err
+}
 
-	if vec, ok := a.(Vector); ok {
-		visitor, err = visitor(a)
-		if visitor == nil || err != nil {
+func traverse(a Atom, sm *SourceMap, visitor visitors) error {
+	type frame struct {
+		a       Atom
+		visitor visitors
+		ctx     VisitContext
+	}
+	stack := []frame{{a, visitor, VisitContext{}}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		a, visitor, ctx := top.a, top.visitor, top.ctx
+
+	traverseCdr:
+		if IsNil(a) {
+			continue
+		}
+		if cons, ok := a.(*Cons); ok && !ctx.HasPos {
+			if ctx.Pos, ctx.HasPos = sm.List(cons); !ctx.HasPos {
+				ctx.Pos, ctx.HasPos = sm.Car(cons)
+			}
+		}
+
+		visitor, ok, err := visitor.visit(ctx, a)
+		switch {
+		case err == SkipSubtree:
+			continue
+		case err == StopTraversal:
+			return nil
+		case err != nil:
 			return err
+		case !ok:
+			continue
 		}
 
-		for _, a := range vec {
-			if err = Traverse(a, visitor); err != nil {
-				return err
+		switch v := a.(type) {
+		case *HashMap:
+			a = mapVector(v)
+		case *Set:
+			a = Vector(v.Elems())
+		}
+		if vec, ok := a.(Vector); ok {
+			for i := len(vec) - 1; i >= 0; i-- {
+				stack = append(stack, frame{vec[i], visitor, VisitContext{Depth: ctx.Depth + 1}})
 			}
+			continue
 		}
-		return nil
-	}
-
-	visitor, err = visitor(a)
-	if visitor == nil || err != nil {
-		return err
-	}
 
-	cons, _ := a.(*Cons)
-	if cons == nil {
-		return nil
-	}
+		cons, _ := a.(*Cons)
+		if cons == nil {
+			continue
+		}
 
-	if !IsNil(cons.Car) {
-		err = Traverse(cons.Car, visitor)
-		if err != nil {
-			return nil
+		rest := VisitContext{Depth: ctx.Depth}
+		if !IsNil(cons.Car) {
+			// Traverse the car before the cdr, which is resumed once the car is done.
+			car := VisitContext{Depth: ctx.Depth + 1}
+			car.Pos, car.HasPos = sm.Car(cons)
+			stack = append(stack, frame{cons.Cdr, visitor, rest}, frame{cons.Car, visitor, car})
+			continue
 		}
-	}
 
-	a = cons.Cdr
-	goto traverseCdr
+		a, ctx = cons.Cdr, rest
+		goto traverseCdr
+	}
+	return nil
 }
 
 // Walk recursively visits all cons pairs in a singly-linked list, calling fn for the car of each
 // cons pair and walking through each cdr it encounters a nil cdr. If a cdr is encountered that is
 // neither a cons pair nor nil, Walk returns an error. If the atom, a, is a Vector, it will call fn
-// for each element of the vector.
+// for each element of the vector. If it is a HashMap, it calls fn for each key and then its value,
+// and if it is a Set, for each element. Walk is WalkTail, for which an improper list is an error.
 func Walk(a Atom, fn func(Atom) error) error {
+	tail, err := WalkTail(a, fn)
+	if err == nil && tail != nil {
+		err = fmt.Errorf("skim: cannot walk %T", tail)
+	}
+	return err
+}
+
+// WalkIndexed calls fn for each element of the list or Vector a and its index, from zero, as Walk
+// does, so that errors may say which element they concern. It returns the same errors as Walk.
+func WalkIndexed(a Atom, fn func(i int, a Atom) error) error {
+	i := 0
+	return Walk(a, func(a Atom) error {
+		err := fn(i, a)
+		i++
+		return err
+	})
+}
+
+// WalkTail calls fn for each element of the list or Vector a, as Walk does, and returns the tail of
+// the list: the last cdr that is neither a cons pair nor nil, which ends an improper list. The tail
+// of a proper list or Vector is nil. An atom that is not a list is its own tail, as in a list with
+// no elements. A HashMap is walked as a Vector of its keys and values, [key value ...], sorted by
+// its keys, and a Set as a Vector of its sorted elements. If fn returns an error, WalkTail returns
+// it and a nil tail.
+func WalkTail(a Atom, fn func(Atom) error) (tail Atom, err error) {
+	switch v := a.(type) {
+	case *HashMap:
+		a = mapVector(v)
+	case *Set:
+		a = Vector(v.Elems())
+	}
 	if vec, ok := a.(Vector); ok {
 		for _, elem := range vec {
 			if err := fn(elem); err != nil {
-				return err
+				return nil, err
 			}
 		}
-		return nil
+		return nil, nil
 	}
 
 	for {
 		switch cons := a.(type) {
 		case nil:
-			return nil
+			return nil, nil
 		case *Cons:
-			if cons.Car == nil && cons.Cdr == nil {
-				// nil / sentinel cons
-				return nil
+			if cons == nil || cons == Nil {
+				return nil, nil
 			}
 
 			if err := fn(cons.Car); err != nil {
-				return err
+				return nil, err
 			}
 			a = cons.Cdr
 		default:
-			return fmt.Errorf("skim: cannot walk %T", a)
+			return a, nil
+		}
+	}
+}
+
+// Length returns the number of elements in a proper list or Vector. As with Walk, the list ends at
+// a nil cdr or at Nil, so nil and the empty list have a length of 0. Length returns
+// an error if a is an improper list, describing its tail, if a is a cyclic list, or if a is neither
+// a list nor a Vector.
+func Length(a Atom) (int, error) {
+	n, tail, err := length(a)
+	if err != nil {
+		return 0, err
+	} else if tail != nil {
+		return 0, fmt.Errorf("skim: length: improper list of %d elements ends in %T", n, tail)
+	}
+	return n, nil
+}
+
+// LengthOK returns the number of elements in a, which may be a proper or improper list or a Vector,
+// and whether a is a proper list or Vector. The tail of an improper list is not counted, so the
+// length of (a b . c) is 2. If a is a cyclic list or neither a list nor a Vector, LengthOK returns
+// -1 and false.
+func LengthOK(a Atom) (n int, proper bool) {
+	n, tail, err := length(a)
+	if err != nil {
+		return -1, false
+	}
+	return n, tail == nil
+}
+
+// length returns the number of elements of the list or Vector a and the tail of a, if it is an
+// improper list. It detects cycles by advancing a second cursor through the list at half speed:
+// if the list is cyclic, the first cursor meets the second.
+func length(a Atom) (n int, tail Atom, err error) {
+	switch v := a.(type) {
+	case Vector:
+		return len(v), nil, nil
+	case nil, *Cons:
+	default:
+		return 0, nil, fmt.Errorf("skim: length: %T is not a list", a)
+	}
+
+	slow := a
+	for ; ; n++ {
+		c, ok := a.(*Cons)
+		if !ok {
+			return n, a, nil
+		} else if IsNil(c) {
+			return n, nil, nil
+		}
+		a = c.Cdr
+		if n%2 == 1 {
+			slow = slow.(*Cons).Cdr
+		}
+		if next, ok := a.(*Cons); ok && next != nil && a == slow {
+			return 0, nil, errors.New("skim: length: list is cyclic")
 		}
 	}
 }
 
+// List returns a proper list of args, whose pairs are allocated together. If args is empty, it
+// returns Nil.
 func List(args ...Atom) Atom {
 	if len(args) == 0 {
-		return &Cons{}
+		return Nil
 	}
 	cons := make([]Cons, len(args)+1)
 	for i, q := range args {
@@ -429,20 +740,241 @@ func List(args ...Atom) Atom {
 	return &cons[0]
 }
 
-func cadr(a Atom, seq string) (Atom, error) {
-	var c *Cons
-	var op byte
-	for i := len(seq) - 1; i >= 0; i-- {
-		op = seq[i]
-		c, _ = a.(*Cons)
+// ToSlice returns the elements of a proper list or Vector as a new slice. As with Walk, the list
+// ends at a nil cdr or Nil. ToSlice returns an error if a is an improper or cyclic
+// list, or neither a list nor a Vector.
+func ToSlice(a Atom) ([]Atom, error) {
+	n, err := Length(a)
+	if err != nil {
+		return nil, err
+	}
+	return appendElems(make([]Atom, 0, n), a), nil
+}
+
+// AppendToSlice appends the elements of a proper list or Vector to dst and returns the extended
+// slice, so that a caller's buffer can be reused. It returns dst and an error if a is not a proper
+// list or Vector, as ToSlice does.
+func AppendToSlice(dst []Atom, a Atom) ([]Atom, error) {
+	if _, err := Length(a); err != nil {
+		return dst, err
+	}
+	return appendElems(dst, a), nil
+}
+
+// appendElems appends the elements of a, which must be a proper list or Vector, to dst.
+func appendElems(dst []Atom, a Atom) []Atom {
+	if v, ok := a.(Vector); ok {
+		return append(dst, v...)
+	}
+	for c, _ := a.(*Cons); !IsNil(c); c, _ = c.Cdr.(*Cons) {
+		dst = append(dst, c.Car)
+	}
+	return dst
+}
+
+// FromSlice returns a proper list of the elements of s, whose pairs are allocated together. If s is
+// empty, it returns Nil, as List does.
+func FromSlice(s []Atom) Atom {
+	if len(s) == 0 {
+		return Nil
+	}
+	cons := make([]Cons, len(s))
+	for i, elem := range s {
+		cons[i].Car = elem
+		if i > 0 {
+			cons[i-1].Cdr = &cons[i]
+		}
+	}
+	return &cons[0]
+}
+
+// VectorOf returns a new Vector holding the elements of s. The Vector does not share s's memory, and
+// is empty rather than nil if s is empty.
+func VectorOf(s []Atom) Vector {
+	return append(make(Vector, 0, len(s)), s...)
+}
+
+// Append returns the concatenation of lists, as Scheme's append does. Every list but the last is
+// copied, and the last is shared: it becomes the tail of the result, which is improper if the last
+// list is. The copied pairs are allocated together, as by List. If only one list is given, it is
+// returned as it is, and if none are given, Append returns nil. Append returns an error if any list
+// but the last is not a proper list.
+func Append(lists ...Atom) (Atom, error) {
+	if len(lists) == 0 {
+		return nil, nil
+	}
+	init, last := lists[:len(lists)-1], lists[len(lists)-1]
+
+	total := 0
+	for i, list := range init {
+		if _, ok := list.(Vector); ok {
+			return nil, fmt.Errorf("skim: append: argument %d is a Vector, not a list", i+1)
+		}
+		n, err := Length(list)
+		if err != nil {
+			return nil, fmt.Errorf("skim: append: argument %d: %w", i+1, err)
+		}
+		total += n
+	}
+	if total == 0 {
+		return last, nil
+	}
+
+	cons := make([]Cons, total)
+	i := 0
+	for _, list := range init {
+		for c, _ := list.(*Cons); !IsNil(c); c, _ = c.Cdr.(*Cons) {
+			cons[i].Car = c.Car
+			if i > 0 {
+				cons[i-1].Cdr = &cons[i]
+			}
+			i++
+		}
+	}
+	if !IsNil(last) {
+		cons[total-1].Cdr = last
+	}
+	return &cons[0], nil
+}
+
+// Reverse returns a new list or Vector holding the elements of a in reverse order. a is not
+// modified. The reverse of nil is nil, and that of the empty list is Nil. Reverse
+// returns an error if a is not a proper list or a Vector.
+func Reverse(a Atom) (Atom, error) {
+	if v, ok := a.(Vector); ok {
+		r := make(Vector, len(v))
+		for i, elem := range v {
+			r[len(v)-1-i] = elem
+		}
+		return r, nil
+	}
+
+	n, err := Length(a)
+	if err != nil {
+		return nil, fmt.Errorf("skim: reverse: %w", err)
+	} else if a == nil {
+		return nil, nil
+	} else if n == 0 {
+		return List(), nil
+	}
+	cons := make([]Cons, n)
+	i := n - 1
+	for c := a.(*Cons); !IsNil(c); c, _ = c.Cdr.(*Cons) {
+		cons[i].Car = c.Car
+		if i < n-1 {
+			cons[i].Cdr = &cons[i+1]
+		}
+		i--
+	}
+	return &cons[0], nil
+}
+
+// ReverseInPlace reverses the proper list c by relinking the cdrs of its pairs, and returns the
+// pair that now begins it, which was its last. It allocates nothing. An empty list is returned as it
+// is. If c is not a proper list, ReverseInPlace returns an error and c is not modified.
+func ReverseInPlace(c *Cons) (*Cons, error) {
+	if _, err := Length(c); err != nil {
+		return nil, fmt.Errorf("skim: reverse: %w", err)
+	} else if IsNil(c) {
+		return c, nil
+	}
+	var prev *Cons
+	for c != nil {
+		next, _ := c.Cdr.(*Cons)
+		if IsNil(next) {
+			next = nil
+		}
+		if prev == nil {
+			c.Cdr = nil
+		} else {
+			c.Cdr = prev
+		}
+		prev, c = c, next
+	}
+	return prev, nil
+}
+
+// Cxr applies the car and cdr operations named by path to a, as the Cadr family of functions do
+// for paths of up to four steps: each 'a' in path takes the car and each 'd' the cdr, applied from
+// the last to the first, so Cxr("ad", a) is the car of the cdr of a. Cxr returns an error if path
+// is empty or holds any other characters, or if a step is applied to an atom that is not a non-nil
+// *Cons. The error names the path, the step, counted from 1 in the order steps are applied, and the
+// atom's type.
+func Cxr(path string, a Atom) (Atom, error) {
+	if path == "" {
+		return nil, errors.New("skim: cxr: empty path")
+	}
+	for i := 0; i < len(path); i++ {
+		if c := path[i]; c != 'a' && c != 'd' {
+			return nil, fmt.Errorf("skim: cxr %q: invalid step %q at offset %d; want 'a' or 'd'", path, c, i)
+		}
+	}
+	r, i := cxr(path, a)
+	if i >= 0 {
+		return nil, fmt.Errorf("skim: cxr %q: step %d ('%c'): %T is not a *Cons", path, len(path)-i, path[i], r)
+	}
+	return r, nil
+}
+
+// cxr applies the steps of path to a, from last to first. If a step is applied to an atom that is
+// not a non-nil *Cons, cxr returns that atom and the index of the step in path. Otherwise, it
+// returns the result and -1.
+func cxr(path string, a Atom) (Atom, int) {
+	for i := len(path) - 1; i >= 0; i-- {
+		c, _ := a.(*Cons)
 		if c == nil {
-			return nil, fmt.Errorf("skim: c%cr: %T is not a *Cons", op, a)
-		} else if op == 'a' {
+			return a, i
+		} else if path[i] == 'a' {
 			a = c.Car
 		} else {
 			a = c.Cdr
 		}
 	}
+	return a, -1
+}
+
+func cadr(a Atom, seq string) (Atom, error) {
+	r, i := cxr(seq, a)
+	if i >= 0 {
+		return nil, fmt.Errorf("skim: c%cr: %T is not a *Cons", seq[i], r)
+	}
+	return r, nil
+}
+
+// Nth returns the element at index n, from 0, of the list or Vector a. It returns an error if n is
+// out of range or a is not a list or Vector.
+func Nth(a Atom, n int) (Atom, error) {
+	if v, ok := a.(Vector); ok {
+		if n < 0 || n >= len(v) {
+			return nil, fmt.Errorf("skim: nth: index %d out of range for vector of length %d", n, len(v))
+		}
+		return v[n], nil
+	}
+	tail, err := NthCdr(a, n)
+	if err != nil {
+		return nil, err
+	}
+	c, _ := tail.(*Cons)
+	if IsNil(c) {
+		return nil, fmt.Errorf("skim: nth: index %d out of range for list of length %d", n, n)
+	}
+	return c.Car, nil
+}
+
+// NthCdr returns the list following the first n elements of a, by taking its cdr n times. The
+// NthCdr of a list with n elements is its final cdr, which is nil for a proper list. NthCdr returns
+// an error if n is negative or a has fewer than n elements.
+func NthCdr(a Atom, n int) (Atom, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("skim: nthcdr: negative index %d", n)
+	}
+	for i := 0; i < n; i++ {
+		c, _ := a.(*Cons)
+		if IsNil(c) {
+			return nil, fmt.Errorf("skim: nthcdr: index %d out of range for list of length %d", n, i)
+		}
+		a = c.Cdr
+	}
 	return a, nil
 }
 
@@ -517,7 +1049,89 @@ func Map(list Atom, mapfn MapFunc) (result Atom, err error) {
 
 	m, ok := list.(Mapper)
 	if !ok {
-		return nil, fmt.Errorf("skim: cannot map %T; does not implement Mapper")
+		return nil, fmt.Errorf("skim: cannot map %T; does not implement Mapper", list)
 	}
 	return m.Map(mapfn)
 }
+
+// Filter returns a new sequence of the elements of a for which keep returns true, in order: a
+// Vector if a is a Vector, and otherwise a proper list, whose pairs are allocated together. If no
+// elements are kept from a list, Filter returns Nil. A nil a is returned as nil, as with Map. Filter
+// returns an error if a is an improper or cyclic list or neither a list nor a Vector, and returns
+// the first error returned by keep.
+func Filter(a Atom, keep func(Atom) (bool, error)) (Atom, error) {
+	if a == nil {
+		return nil, nil
+	} else if keep == nil {
+		return nil, errors.New("skim: filter: keep function is nil")
+	}
+
+	n, err := Length(a)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := a.(Vector); ok {
+		if v == nil {
+			return Vector(nil), nil
+		}
+		kept := make(Vector, 0, n)
+		for _, elem := range v {
+			ok, err := keep(elem)
+			if err != nil {
+				return nil, err
+			} else if ok {
+				kept = append(kept, elem)
+			}
+		}
+		return kept, nil
+	}
+
+	var (
+		kept        = make([]Cons, n)
+		result Atom = Nil
+		pred        = &result
+		i      int
+	)
+	for c, _ := a.(*Cons); !IsNil(c); c, _ = c.Cdr.(*Cons) {
+		ok, err := keep(c.Car)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		pair := &kept[i]
+		pair.Car = c.Car
+		*pred, pred = pair, &pair.Cdr
+		i++
+	}
+	return result, nil
+}
+
+// Fold calls fn for each element of the list or Vector a, from first to last, passing it the
+// result of the previous call, starting with acc, and returns the result of the last call. If a has
+// no elements, Fold returns acc. Fold returns an error if a is an improper or cyclic list or neither
+// a list nor a Vector, in which case fn is not called, and returns the first error returned by fn.
+func Fold(a Atom, acc Atom, fn func(acc, elem Atom) (Atom, error)) (Atom, error) {
+	if _, err := Length(a); err != nil {
+		return nil, err
+	} else if fn == nil {
+		return nil, errors.New("skim: fold: fold function is nil")
+	}
+
+	var err error
+	if v, ok := a.(Vector); ok {
+		for _, elem := range v {
+			if acc, err = fn(acc, elem); err != nil {
+				return nil, err
+			}
+		}
+		return acc, nil
+	}
+	for c, _ := a.(*Cons); !IsNil(c); c, _ = c.Cdr.(*Cons) {
+		if acc, err = fn(acc, c.Car); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
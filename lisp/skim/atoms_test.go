@@ -2,8 +2,11 @@ package skim
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"reflect"
 	"testing"
+	"unicode/utf8"
 
 	"go.spiff.io/skim/internal/debug"
 )
@@ -16,7 +19,7 @@ func TestList(t *testing.T) {
 	cases := []testcase{
 		{
 			Args: nil,
-			Want: &Cons{},
+			Want: Nil,
 		},
 		{
 			Args: []Atom{Int(1)},
@@ -144,3 +147,1080 @@ func BenchmarkCadrs(b *testing.B) {
 		})
 	}
 }
+
+func TestCxr(t *testing.T) {
+	// ((1 (2 3)) 4 5)
+	deep := List(List(Int(1), List(Int(2), Int(3))), Int(4), Int(5))
+	cases := []struct {
+		path string
+		want Atom
+		err  string
+	}{
+		{"a", List(Int(1), List(Int(2), Int(3))), ""},
+		{"add", Int(5), ""},
+		{"dd", List(Int(5)), ""},
+		{"adada", Int(3), ""},
+		{"aadada", nil, `skim: cxr "aadada": step 6 ('a'): skim.Int is not a *Cons`},
+		{"adaa", nil, `skim: cxr "adaa": step 3 ('d'): skim.Int is not a *Cons`},
+		{"addda", nil, `skim: cxr "addda": step 4 ('d'): <nil> is not a *Cons`},
+		{"", nil, "skim: cxr: empty path"},
+		{"axd", nil, `skim: cxr "axd": invalid step 'x' at offset 1; want 'a' or 'd'`},
+	}
+	for _, c := range cases {
+		got, err := Cxr(c.path, deep)
+		switch {
+		case c.err != "" && (err == nil || err.Error() != c.err):
+			t.Errorf("Cxr(%q, ..) err = %v; want %s", c.path, err, c.err)
+		case c.err == "" && (err != nil || !reflect.DeepEqual(got, c.want)):
+			t.Errorf("Cxr(%q, ..) = %v, %v; want %v, nil", c.path, got, err, c.want)
+		}
+	}
+
+	// The Cadr family agree with Cxr.
+	if got, err := Cxr("add", deep); err != nil || got != Int(5) {
+		t.Errorf("Cxr(add) = %v, %v; want 5, nil", got, err)
+	} else if want, _ := Caddr(deep); got != want {
+		t.Errorf("Cxr(add) = %v; Caddr = %v", got, want)
+	}
+}
+
+func TestNth(t *testing.T) {
+	a, b, c := Symbol("a"), Symbol("b"), Symbol("c")
+	list := List(a, b, c)
+	vec := Vector{a, b, c}
+	for i, want := range []Atom{a, b, c} {
+		if got, err := Nth(list, i); err != nil || got != want {
+			t.Errorf("Nth(%v, %d) = %v, %v; want %v, nil", list, i, got, err, want)
+		}
+		if got, err := Nth(vec, i); err != nil || got != want {
+			t.Errorf("Nth(%v, %d) = %v, %v; want %v, nil", vec, i, got, err, want)
+		}
+	}
+	for _, in := range []Atom{list, vec, &Cons{Car: a, Cdr: b}, nil, Nil, Int(1)} {
+		for _, i := range []int{-1, 3} {
+			if got, err := Nth(in, i); err == nil {
+				t.Errorf("Nth(%v, %d) = %v; want error", in, i, got)
+			}
+		}
+	}
+
+	improper := &Cons{Car: a, Cdr: &Cons{Car: b, Cdr: c}}
+	cases := []struct {
+		in   Atom
+		n    int
+		want string
+	}{
+		{list, 0, "(a b c)"},
+		{list, 2, "(c)"},
+		{list, 3, "#nil"},
+		{improper, 2, "c"},
+		{nil, 0, "#nil"},
+	}
+	for _, tc := range cases {
+		got, err := NthCdr(tc.in, tc.n)
+		s := "#nil"
+		if got != nil {
+			s = got.String()
+		}
+		if err != nil || s != tc.want {
+			t.Errorf("NthCdr(%v, %d) = %s, %v; want %s, nil", tc.in, tc.n, s, err, tc.want)
+		}
+	}
+	for _, n := range []int{-1, 4} {
+		if got, err := NthCdr(list, n); err == nil {
+			t.Errorf("NthCdr(%v, %d) = %v; want error", list, n, got)
+		}
+	}
+	if got, err := NthCdr(improper, 3); err == nil {
+		t.Errorf("NthCdr(%v, 3) = %v; want error", improper, got)
+	}
+}
+
+func BenchmarkCxr(b *testing.B) {
+	seq := Atom(Int(0))
+	for i := 0; i < 16; i++ {
+		seq = List(seq, seq)
+	}
+	b.Run("ad", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := Cxr("ad", seq); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("adadadadadadadadadadadadadadadad", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := Cxr("adadadadadadadadadadadadadadadad", seq); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestPair(t *testing.T) {
+	a, b, c := Symbol("a"), Symbol("b"), Symbol("c")
+	cases := []struct {
+		in           Atom
+		pair, dotted string // errors, if not empty
+	}{
+		{List(a, b), "", ""},
+		{List(a, nil), "", ""},
+		{&Cons{Car: a, Cdr: b}, "skim: pair: (a . b) has no second element", ""},
+		{Int(1), "skim: pair: 1 has no first element", "skim: pair: 1 has no first element"},
+		{Nil, "skim: pair: () has no first element", "skim: pair: () has no first element"},
+		{List(a), "skim: pair: (a) has no second element", "skim: pair: (a) has no second element"},
+		{List(a, b, c), "skim: pair: (a b c) has more than two elements", "skim: pair: (a b c) has more than two elements"},
+		{&Cons{Car: a, Cdr: &Cons{Car: b, Cdr: c}}, "skim: pair: (a b . c) has a dotted tail after its second element", "skim: pair: (a b . c) has a dotted tail after its second element"},
+	}
+	for _, tc := range cases {
+		for _, fn := range []struct {
+			name string
+			fn   func(Atom) (Atom, Atom, error)
+			want string
+		}{{"Pair", Pair, tc.pair}, {"DottedPair", DottedPair, tc.dotted}} {
+			lhs, rhs, err := fn.fn(tc.in)
+			if fn.want != "" {
+				if err == nil || err.Error() != fn.want {
+					t.Errorf("%s(%v) err = %v; want %s", fn.name, tc.in, err, fn.want)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s(%v) err = %v; want nil", fn.name, tc.in, err)
+			} else if c := tc.in.(*Cons); lhs != c.Car || (rhs != c.Cdr && rhs != c.Cdr.(*Cons).Car) {
+				t.Errorf("%s(%v) = %v, %v; want its elements", fn.name, tc.in, lhs, rhs)
+			}
+		}
+	}
+}
+
+func TestConsQuoteString(t *testing.T) {
+	x := Symbol("x")
+	cases := map[string]Atom{
+		"'x":                     List(Quote, x),
+		"`x":                     List(Quasiquote, x),
+		",x":                     List(Unquote, x),
+		",@x":                    List(UnquoteSplicing, x),
+		"`(1 ,@x)":               List(Quasiquote, List(Int(1), List(UnquoteSplicing, x))),
+		"'[1 x]":                 List(Quote, Vector{Int(1), x}),
+		"`[,x ,@x]":              List(Quasiquote, Vector{List(Unquote, x), List(UnquoteSplicing, x)}),
+		"'#nil":                  List(Quote, nil),
+		"'()":                    List(Quote, Nil),
+		"(unquote @x)":           List(Unquote, Symbol("@x")),
+		"'@x":                    List(Quote, Symbol("@x")),
+		"(quote x y)":            List(Quote, x, Symbol("y")),
+		"(quote x . y)":          &Cons{Car: Quote, Cdr: &Cons{Car: x, Cdr: Symbol("y")}},
+		"(unquote-splicing x y)": List(UnquoteSplicing, x, Symbol("y")),
+	}
+
+	for want, in := range cases {
+		if got := in.String(); got != want {
+			t.Errorf("String() = %q; want %q", got, want)
+		}
+	}
+}
+
+func TestStringString(t *testing.T) {
+	cases := map[string]Atom{
+		`"hi"`:                 String("hi"),
+		`""`:                   String(""),
+		`"a \"b\"\n"`:          String("a \"b\"\n"),
+		`"\u03bb"`:             String("λ"),
+		`("hi" #\a ["x" #\y])`: List(String("hi"), Char('a'), Vector{String("x"), Char('y')}),
+	}
+
+	for want, in := range cases {
+		if got := in.String(); got != want {
+			t.Errorf("String() = %q; want %q", got, want)
+		}
+	}
+}
+
+func TestConsNilString(t *testing.T) {
+	var null *Cons
+	cases := map[string]Atom{
+		"()":      null,
+		"(a)":     &Cons{Car: Symbol("a"), Cdr: null},
+		"(())":    List(null),
+		"(quote)": &Cons{Car: Quote, Cdr: null},
+	}
+
+	for want, in := range cases {
+		if got := in.String(); got != want {
+			t.Errorf("String() = %q; want %q", got, want)
+		}
+	}
+	if got := null.GoString(); got != "()" {
+		t.Errorf("GoString() = %q; want %q", got, "()")
+	}
+}
+
+func TestFloatString(t *testing.T) {
+	cases := map[string]Float{
+		"1.5":                     1.5,
+		"-0.25":                   -0.25,
+		"+inf.0":                  Float(math.Inf(1)),
+		"-inf.0":                  Float(math.Inf(-1)),
+		"+nan.0":                  Float(math.NaN()),
+		"2.0":                     2,
+		"-0.0":                    Float(math.Copysign(0, -1)),
+		"1e+21":                   1e21,
+		"1e-10":                   1e-10,
+		"0.1":                     0.1,
+		"123456.0":                123456,
+		"1.234567e+06":            1234567,
+		"1.7976931348623157e+308": math.MaxFloat64,
+	}
+
+	for want, in := range cases {
+		if got := in.String(); got != want {
+			t.Errorf("String() = %q; want %q", got, want)
+		}
+	}
+}
+
+func TestFloatInt64(t *testing.T) {
+	cases := []struct {
+		in   Float
+		want int64
+		ok   bool
+	}{
+		{1.9, 1, true},
+		{-1.9, -1, true},
+		{math.MinInt64, math.MinInt64, true},
+		{Float(math.Nextafter(1<<63, 0)), 1<<63 - 1024, true},
+		{1 << 63, 0, false},
+		{math.MaxInt64, 0, false}, // rounds up to 2^63
+		{Float(math.Nextafter(math.MinInt64, math.Inf(-1))), 0, false},
+		{1e300, 0, false},
+		{Float(math.Inf(1)), 0, false},
+		{Float(math.Inf(-1)), 0, false},
+		{Float(math.NaN()), 0, false},
+	}
+	for _, c := range cases {
+		if got, ok := c.in.Int64(); got != c.want || ok != c.ok {
+			t.Errorf("Float(%v).Int64() = %d, %t; want %d, %t", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestCharString(t *testing.T) {
+	cases := map[Char]string{
+		'a':    `#\a`,
+		'(':    `#\(`,
+		'λ':    `#\λ`,
+		' ':    `#\space`,
+		'\n':   `#\newline`,
+		'\t':   `#\tab`,
+		0:      `#\nul`,
+		0x7f:   `#\delete`,
+		0x2028: `#\x2028`,
+		'😀':    `#\😀`,
+		0x1d:   `#\x1d`,
+	}
+
+	for c, want := range cases {
+		if got := c.String(); got != want {
+			t.Errorf("Char(%d).String() = %q; want %q", c, got, want)
+		}
+	}
+}
+
+func TestCharGoString(t *testing.T) {
+	for c, want := range map[Char]string{'a': `#\x61`, '😀': `#\x1f600`, ' ': `#\x20`} {
+		if got := c.GoString(); got != want {
+			t.Errorf("Char(%d).GoString() = %q; want %q", c, got, want)
+		}
+	}
+}
+
+func TestStringToChars(t *testing.T) {
+	cases := []struct {
+		in   String
+		want Vector
+	}{
+		{"", Vector{}},
+		{"ab", Vector{Char('a'), Char('b')}},
+		{"aλ😀", Vector{Char('a'), Char('λ'), Char('😀')}},
+		{"a\xffb", Vector{Char('a'), Char(utf8.RuneError), Char('b')}},
+	}
+	for _, c := range cases {
+		got := StringToChars(c.in)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("StringToChars(%q) = %v; want %v", c.in, got, c.want)
+		}
+		if c.in == "a\xffb" {
+			continue
+		}
+		if back, err := CharsToString(got); err != nil || back != c.in {
+			t.Errorf("CharsToString(%v) = %q, %v; want %q, nil", got, back, err, c.in)
+		}
+	}
+
+	for _, in := range []Vector{{Char('a'), String("b")}, {Char(0xd800)}, {Char(-1)}, {Char(utf8.MaxRune + 1)}} {
+		if got, err := CharsToString(in); err == nil {
+			t.Errorf("CharsToString(%v) = %q, nil; want error", in, got)
+		}
+	}
+}
+
+func TestSymbolString(t *testing.T) {
+	cases := map[Symbol]string{
+		"foo":     "foo",
+		"a-b?":    "a-b?",
+		"foo bar": "|foo bar|",
+		"(x)":     "|(x)|",
+		"a|b":     `|a\|b|`,
+		`a\b c`:   `|a\\b c|`,
+		"":        "||",
+		".":       "|.|",
+		"\n":      `|\n|`,
+		"1":       "|1|",
+		"-2x":     "|-2x|",
+		"+.5":     "|+.5|",
+		"+inf.0":  "|+inf.0|",
+		"-NaN.0":  "|-NaN.0|",
+		"#t":      "|#t|",
+		":k":      "|:k|",
+		"<<<EOF":  "|<<<EOF|",
+		"+":       "+",
+		"-":       "-",
+		"...":     "...",
+		":":       ":",
+		"<<<":     "<<<",
+		"a1":      "a1",
+	}
+
+	for sym, want := range cases {
+		if got := sym.String(); got != want {
+			t.Errorf("Symbol(%q).String() = %q; want %q", string(sym), got, want)
+		}
+	}
+}
+
+func TestConsMapString(t *testing.T) {
+	a, b := Symbol("a"), Symbol("b")
+	cases := map[string]Atom{
+		`{a 1}`:                 List(&Cons{a, Int(1)}),
+		`{a 1 "b" [2]}`:         List(&Cons{a, Int(1)}, &Cons{String("b"), Vector{Int(2)}}),
+		`((a (b . 1)))`:         List(&Cons{a, List(&Cons{b, Int(1)})}),
+		`((a 1 2))`:             List(List(a, Int(1), Int(2))),
+		`((a . 1) b)`:           List(&Cons{a, Int(1)}, b),
+		`((a . 1) (b . 2) . 3)`: &Cons{&Cons{a, Int(1)}, &Cons{&Cons{b, Int(2)}, Int(3)}},
+	}
+
+	for want, in := range cases {
+		if got := in.String(); got != want {
+			t.Errorf("String() = %q; want %q", got, want)
+		}
+	}
+}
+
+func TestNil(t *testing.T) {
+	pair := &Cons{} // (cons #nil #nil)
+	if !IsNil(Nil) || !IsNil(nil) || !IsNil((*Cons)(nil)) || IsNil(pair) {
+		t.Errorf("IsNil(Nil, nil, (*Cons)(nil), pair) = %t, %t, %t, %t; want true, true, true, false",
+			IsNil(Nil), IsNil(nil), IsNil((*Cons)(nil)), IsNil(pair))
+	}
+	if IsTrue(Nil) || !IsTrue(pair) {
+		t.Errorf("IsTrue(Nil), IsTrue(pair) = %t, %t; want false, true", IsTrue(Nil), IsTrue(pair))
+	}
+	if Equal(Nil, pair) || Compare(Nil, pair) == 0 {
+		t.Errorf("() and %v are equal", pair)
+	}
+	if got, want := pair.String(), "(#nil)"; got != want {
+		t.Errorf("String() = %s; want %s", got, want)
+	}
+	if got, want := (&Cons{Car: Int(1), Cdr: Nil}).GoString(), "(1 . ())"; got != want {
+		t.Errorf("GoString() = %s; want %s", got, want)
+	}
+	if n, err := Length(pair); n != 1 || err != nil {
+		t.Errorf("Length(%v) = %d, %v; want 1", pair, n, err)
+	}
+
+	// A leading pair of nils does not end a list.
+	var seen []Atom
+	if err := Walk(List(nil, Int(1)), func(a Atom) error { seen = append(seen, a); return nil }); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(seen, []Atom{nil, Int(1)}) {
+		t.Errorf("Walk((#nil 1)) visited %v; want [<nil> 1]", seen)
+	}
+
+	if List() != Atom(Nil) || FromSlice(nil) != Atom(Nil) || Dup(Nil) != Atom(Nil) || DupShallow(Nil) != Atom(Nil) {
+		t.Error("the empty list is not Nil")
+	}
+	l := &Cons{Car: Int(1), Cdr: Nil}
+	if d := Dup(l).(*Cons); d.Cdr != Atom(Nil) {
+		t.Errorf("Dup(%v) ends in %#v; want Nil", l, d.Cdr)
+	}
+	if m, err := Nil.Map(func(a Atom) (Atom, error) { return Int(2), nil }); err != nil || m != Atom(Nil) {
+		t.Errorf("Nil.Map() = %v, %v; want ()", m, err)
+	}
+}
+
+func TestLength(t *testing.T) {
+	a, b := Symbol("a"), Symbol("b")
+	cyclic := func(n int) *Cons {
+		head := &Cons{Car: Int(0)}
+		last := head
+		for i := 1; i < n; i++ {
+			next := &Cons{Car: Int(i)}
+			last.Cdr, last = next, next
+		}
+		last.Cdr = head
+		return head
+	}
+
+	cases := []struct {
+		in     Atom
+		n      int
+		proper bool
+		err    bool
+	}{
+		{nil, 0, true, false},
+		{(*Cons)(nil), 0, true, false},
+		{Nil, 0, true, false},
+		{List(a), 1, true, false},
+		{List(a, b, List(a, b)), 3, true, false},
+		{Vector{}, 0, true, false},
+		{Vector{a, b}, 2, true, false},
+		{&Cons{Car: a, Cdr: b}, 1, false, true},
+		{&Cons{Car: a, Cdr: &Cons{Car: b, Cdr: Int(1)}}, 2, false, true},
+		{&Cons{Car: a, Cdr: Vector{b}}, 1, false, true},
+		{cyclic(1), -1, false, true},
+		{cyclic(2), -1, false, true},
+		{cyclic(7), -1, false, true},
+		{&Cons{Car: a, Cdr: cyclic(3)}, -1, false, true},
+		{Int(1), -1, false, true},
+		{String("ab"), -1, false, true},
+	}
+	for _, c := range cases {
+		n, err := Length(c.in)
+		if c.err && err == nil {
+			t.Errorf("Length(%v) = %d, nil; want error", c.in, n)
+		} else if !c.err && (err != nil || n != c.n) {
+			t.Errorf("Length(%v) = %d, %v; want %d, nil", c.in, n, err, c.n)
+		}
+		if n, proper := LengthOK(c.in); n != c.n || proper != c.proper {
+			t.Errorf("LengthOK(%v) = %d, %t; want %d, %t", c.in, n, proper, c.n, c.proper)
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	a, b, c := Symbol("a"), Symbol("b"), Symbol("c")
+	last := List(c, Int(1))
+
+	cases := []struct {
+		in   []Atom
+		want string
+	}{
+		{nil, "#nil"},
+		{[]Atom{List(a)}, "(a)"},
+		{[]Atom{List(a, b), last}, "(a b c 1)"},
+		{[]Atom{Nil, List(a), nil, (*Cons)(nil), List(b), Nil, last}, "(a b c 1)"},
+		{[]Atom{List(a), Nil}, "(a)"},
+		{[]Atom{List(a), nil}, "(a)"},
+		{[]Atom{List(a, b), c}, "(a b . c)"},
+		{[]Atom{List(a), &Cons{Car: b, Cdr: c}}, "(a b . c)"},
+		{[]Atom{nil, Nil}, "()"},
+		{[]Atom{nil, Int(1)}, "1"},
+	}
+	for _, tc := range cases {
+		got, err := Append(tc.in...)
+		s := "#nil"
+		if got != nil {
+			s = got.String()
+		}
+		if err != nil {
+			t.Errorf("Append(%v) err = %v; want nil", tc.in, err)
+		} else if s != tc.want {
+			t.Errorf("Append(%v) = %s; want %s", tc.in, s, tc.want)
+		}
+	}
+
+	// The last list is shared and the others are copied.
+	first := List(a, b).(*Cons)
+	got, err := Append(first, last)
+	if err != nil {
+		t.Fatalf("Append(%v, %v) err = %v; want nil", first, last, err)
+	}
+	tail, _ := Cddr(got)
+	if tail != last {
+		t.Errorf("Append(%v, %v): tail %p is not the last list %p", first, last, tail, last)
+	}
+	if got == Atom(first) || got.(*Cons).Cdr == first.Cdr {
+		t.Errorf("Append(%v, %v) shares the first list", first, last)
+	}
+	got.(*Cons).Car = c
+	if first.Car != a {
+		t.Errorf("modifying Append(%v, %v) modified the first list", first, last)
+	}
+}
+
+func TestAppendError(t *testing.T) {
+	cyclic := &Cons{Car: Symbol("a")}
+	cyclic.Cdr = cyclic
+	for _, in := range [][]Atom{
+		{&Cons{Car: Symbol("a"), Cdr: Symbol("b")}, List(Symbol("c"))},
+		{List(Symbol("a")), &Cons{Car: Symbol("a"), Cdr: Int(1)}, nil},
+		{Vector{Symbol("a")}, nil},
+		{Int(1), List(Symbol("a"))},
+		{cyclic, nil},
+	} {
+		if got, err := Append(in...); err == nil {
+			t.Errorf("Append(%v) = %v; want error", in, got)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	a, b, c := Symbol("a"), Symbol("b"), Symbol("c")
+	cases := []struct {
+		in   Atom
+		want string
+	}{
+		{Nil, "()"},
+		{List(a), "(a)"},
+		{List(a, b, List(b, c)), "((b c) b a)"},
+		{&Cons{Car: a, Cdr: &Cons{Car: b, Cdr: Nil}}, "(b a)"},
+		{Vector{}, "[]"},
+		{Vector{a, b, c}, "[c b a]"},
+	}
+	for _, tc := range cases {
+		before := tc.in.String()
+		got, err := Reverse(tc.in)
+		if err != nil {
+			t.Errorf("Reverse(%v) err = %v; want nil", tc.in, err)
+		} else if got.String() != tc.want {
+			t.Errorf("Reverse(%v) = %v; want %s", tc.in, got, tc.want)
+		} else if tc.in.String() != before {
+			t.Errorf("Reverse(%s) modified its input to %v", before, tc.in)
+		}
+
+		c, ok := tc.in.(*Cons)
+		if !ok {
+			continue
+		}
+		got, err = ReverseInPlace(c)
+		if err != nil {
+			t.Errorf("ReverseInPlace(%s) err = %v; want nil", before, err)
+		} else if got.String() != tc.want {
+			t.Errorf("ReverseInPlace(%s) = %v; want %s", before, got, tc.want)
+		}
+	}
+
+	if got, err := Reverse(nil); got != nil || err != nil {
+		t.Errorf("Reverse(nil) = %v, %v; want nil, nil", got, err)
+	}
+	if got, err := ReverseInPlace(nil); got != nil || err != nil {
+		t.Errorf("ReverseInPlace(nil) = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestReverseError(t *testing.T) {
+	cyclic := &Cons{Car: Symbol("a")}
+	cyclic.Cdr = cyclic
+	improper := &Cons{Car: Symbol("a"), Cdr: &Cons{Car: Symbol("b"), Cdr: Symbol("c")}}
+	for _, in := range []*Cons{cyclic, improper} {
+		if got, err := Reverse(in); err == nil {
+			t.Errorf("Reverse(%v) = %v; want error", in, got)
+		}
+		before := in.String()
+		if got, err := ReverseInPlace(in); err == nil {
+			t.Errorf("ReverseInPlace(%v) = %v; want error", in, got)
+		} else if in.String() != before {
+			t.Errorf("ReverseInPlace(%s) modified its input to %v", before, in)
+		}
+	}
+	if got, err := Reverse(Int(1)); err == nil {
+		t.Errorf("Reverse(1) = %v; want error", got)
+	}
+}
+
+func TestReverseInPlaceAllocs(t *testing.T) {
+	list := List(Int(1), Int(2), Int(3), Int(4)).(*Cons)
+	allocs := testing.AllocsPerRun(100, func() {
+		var err error
+		if list, err = ReverseInPlace(list); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("ReverseInPlace allocated %v times; want 0", allocs)
+	}
+	if got, want := list.String(), "(4 3 2 1)"; got != want {
+		t.Errorf("ReverseInPlace 101 times = %s; want %s", got, want)
+	}
+}
+
+func TestToSlice(t *testing.T) {
+	a, b := Symbol("a"), Symbol("b")
+	cases := []struct {
+		in   Atom
+		want []Atom
+	}{
+		{nil, []Atom{}},
+		{Nil, []Atom{}},
+		{List(a, List(b), nil), []Atom{a, List(b), nil}},
+		{Vector{a, b}, []Atom{a, b}},
+	}
+	for _, c := range cases {
+		got, err := ToSlice(c.in)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ToSlice(%v) = %v, %v; want %v, nil", c.in, got, err, c.want)
+		}
+
+		dst := []Atom{Int(0)}
+		got, err = AppendToSlice(dst[:1:1], c.in)
+		if want := append([]Atom{Int(0)}, c.want...); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("AppendToSlice([0], %v) = %v, %v; want %v, nil", c.in, got, err, want)
+		}
+	}
+
+	improper := &Cons{Car: a, Cdr: b}
+	for _, in := range []Atom{improper, Int(1)} {
+		if got, err := ToSlice(in); err == nil {
+			t.Errorf("ToSlice(%v) = %v; want error", in, got)
+		}
+		dst := []Atom{a}
+		if got, err := AppendToSlice(dst, in); err == nil || len(got) != 1 {
+			t.Errorf("AppendToSlice(%v, %v) = %v, %v; want %v, error", dst, in, got, err, dst)
+		}
+	}
+}
+
+func TestFromSlice(t *testing.T) {
+	a, b := Symbol("a"), Symbol("b")
+	for _, s := range [][]Atom{nil, {a}, {a, List(b), Vector{}}} {
+		list := FromSlice(s)
+		if got, err := ToSlice(list); err != nil || len(got) != len(s) || list.String() != List(s...).String() {
+			t.Errorf("FromSlice(%v) = %v; want %v", s, list, List(s...))
+		}
+
+		vec := VectorOf(s)
+		if vec == nil || len(vec) != len(s) || vec.String() != Vector(s).String() {
+			t.Errorf("VectorOf(%v) = %#v; want %v", s, vec, s)
+		} else if len(s) > 0 && &vec[0] == &s[0] {
+			t.Errorf("VectorOf(%v) shares memory with its argument", s)
+		}
+	}
+	if allocs := testing.AllocsPerRun(10, func() { FromSlice([]Atom{a, b, a}) }); allocs != 1 {
+		t.Errorf("FromSlice allocated %v times; want 1", allocs)
+	}
+}
+
+func BenchmarkToSlice(b *testing.B) {
+	s := make([]Atom, 1000)
+	for i := range s {
+		s[i] = Int(i)
+	}
+	list := FromSlice(s)
+
+	b.Run("ToSlice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ToSlice(list); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("AppendToSlice", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]Atom, 0, len(s))
+		for i := 0; i < b.N; i++ {
+			var err error
+			if buf, err = AppendToSlice(buf[:0], list); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Walk", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var elems []Atom
+			err := Walk(list, func(a Atom) error {
+				elems = append(elems, a)
+				return nil
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestTraverseOrder(t *testing.T) {
+	a, b, c := Symbol("a"), Symbol("b"), Symbol("c")
+
+	// visitor records each atom it visits with its depth, the number of visitors returned before it,
+	// and prunes the atoms in prune.
+	var got []string
+	var visitor func(depth int) Visitor
+	prune := map[string]bool{}
+	visitor = func(depth int) Visitor {
+		return func(x Atom) (Visitor, error) {
+			got = append(got, fmt.Sprintf("%d:%v", depth, x))
+			if prune[x.String()] {
+				return nil, nil
+			}
+			return visitor(depth + 1), nil
+		}
+	}
+
+	in := List(a, List(b, Vector{c, List(a)}), &Cons{Car: b, Cdr: Vector{a}})
+	want := []string{
+		"0:(a (b [c (a)]) (b . [a]))",
+		"1:a",
+		"1:((b [c (a)]) (b . [a]))",
+		"2:(b [c (a)])",
+		"3:b",
+		"3:([c (a)])",
+		"4:[c (a)]",
+		"5:c",
+		"5:(a)",
+		"6:a",
+		"2:{b [a]}",
+		"3:(b . [a])",
+		"4:b",
+		"4:[a]",
+		"5:a",
+	}
+	if err := Traverse(in, visitor(0)); err != nil {
+		t.Fatalf("Traverse(%v) err = %v; want nil", in, err)
+	} else if !reflect.DeepEqual(got, want) {
+		t.Errorf("Traverse(%v) visited\n%q\nwant\n%q", in, got, want)
+	}
+
+	// A nil visitor prunes the atom's car and the rest of its list, but not the lists around it.
+	got = nil
+	prune["(b [c (a)])"] = true
+	prune["[a]"] = true
+	want = []string{
+		"0:(a (b [c (a)]) (b . [a]))",
+		"1:a",
+		"1:((b [c (a)]) (b . [a]))",
+		"2:(b [c (a)])",
+		"2:{b [a]}",
+		"3:(b . [a])",
+		"4:b",
+		"4:[a]",
+	}
+	if err := Traverse(in, visitor(0)); err != nil {
+		t.Fatalf("Traverse(%v) err = %v; want nil", in, err)
+	} else if !reflect.DeepEqual(got, want) {
+		t.Errorf("Traverse(%v) with pruning visited\n%q\nwant\n%q", in, got, want)
+	}
+}
+
+func TestTraverseError(t *testing.T) {
+	errStop := errors.New("stop")
+	in := List(Symbol("a"), List(Symbol("b"), Symbol("c")), Symbol("d"))
+	n := 0
+	var visitor Visitor
+	visitor = func(x Atom) (Visitor, error) {
+		n++
+		if x == Symbol("c") {
+			return nil, errStop
+		}
+		return visitor, nil
+	}
+	if err := Traverse(in, visitor); err != errStop {
+		t.Errorf("Traverse(%v) err = %v; want %v", in, err, errStop)
+	} else if n != 7 {
+		t.Errorf("Traverse(%v) visited %d atoms; want 7", in, n)
+	}
+}
+
+func TestTraverseDeep(t *testing.T) {
+	const depth = 1000000
+	var list, vec Atom = Int(0), Int(0)
+	for i := 0; i < depth; i++ {
+		list = &Cons{Car: list}
+		vec = Vector{vec}
+	}
+	for _, in := range []Atom{list, vec} {
+		n := 0
+		var visitor Visitor
+		visitor = func(Atom) (Visitor, error) {
+			n++
+			return visitor, nil
+		}
+		if err := Traverse(in, visitor); err != nil {
+			t.Fatalf("Traverse(%T) err = %v; want nil", in, err)
+		} else if n != depth+1 {
+			t.Errorf("Traverse(%T) visited %d atoms; want %d", in, n, depth+1)
+		}
+	}
+}
+
+func TestTraverseCarError(t *testing.T) {
+	a, b, c, x := Symbol("a"), Symbol("b"), Symbol("c"), Symbol("x")
+	errX := errors.New("visited x")
+
+	// x is the car of the car of the second element: (a ((x b)) c). Errors returned within a car
+	// were once dropped, ending traversal with a nil error, so the error and the atoms visited before
+	// it are both checked.
+	in := List(a, List(List(x, b)), c)
+	var got []string
+	var visitor Visitor
+	visitor = func(v Atom) (Visitor, error) {
+		got = append(got, v.String())
+		if v == x {
+			return nil, errX
+		}
+		return visitor, nil
+	}
+	want := []string{"(a ((x b)) c)", "a", "(((x b)) c)", "((x b))", "(x b)", "x"}
+	if err := Traverse(in, visitor); err != errX {
+		t.Errorf("Traverse(%v) err = %v; want %v", in, err, errX)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Traverse(%v) visited %q before its error; want %q", in, got, want)
+	}
+
+	// StopTraversal within a car also ends traversal, but is not returned.
+	got = nil
+	errX = StopTraversal
+	if err := Traverse(in, visitor); err != nil {
+		t.Errorf("Traverse(%v) err = %v; want nil", in, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Traverse(%v) visited %q before stopping; want %q", in, got, want)
+	}
+}
+
+func TestWalkMapError(t *testing.T) {
+	errX := errors.New("x")
+	fn := func(a Atom) (Atom, error) {
+		if a == Symbol("x") {
+			return nil, errX
+		}
+		return a, nil
+	}
+	for _, in := range []Atom{List(Symbol("a"), Symbol("x"), Symbol("b")), Vector{Symbol("a"), Symbol("x")}} {
+		if err := Walk(in, func(a Atom) error { _, err := fn(a); return err }); err != errX {
+			t.Errorf("Walk(%v) err = %v; want %v", in, err, errX)
+		}
+		if got, err := Map(in, fn); err != errX {
+			t.Errorf("Map(%v) = %v, %v; want nil, %v", in, got, err, errX)
+		}
+	}
+}
+
+func TestTraverseSkipStop(t *testing.T) {
+	a, b, c, x := Symbol("a"), Symbol("b"), Symbol("c"), Symbol("x")
+	in := List(a, List(b, Vector{x, c}), List(x, b), c)
+	cases := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"SkipSubtree", SkipSubtree, []string{
+			"(a (b [x c]) (x b) c)", "a", "((b [x c]) (x b) c)",
+			"(b [x c])", "b", "([x c])", "[x c]", "x", "c",
+			"((x b) c)", "(x b)", "(c)", "c",
+		}},
+		{"StopTraversal", StopTraversal, []string{
+			"(a (b [x c]) (x b) c)", "a", "((b [x c]) (x b) c)",
+			"(b [x c])", "b", "([x c])", "[x c]", "x",
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// x is skipped or stops traversal, as are the rest of the lists that begin with x.
+			var got []string
+			var visitor Visitor
+			visitor = func(v Atom) (Visitor, error) {
+				got = append(got, v.String())
+				if v == x {
+					return visitor, tc.err
+				} else if c, ok := v.(*Cons); ok && c.Car == x {
+					return visitor, tc.err
+				}
+				return visitor, nil
+			}
+			if err := Traverse(in, visitor); err != nil {
+				t.Fatalf("Traverse(%v) err = %v; want nil", in, err)
+			} else if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Traverse(%v) visited\n%q\nwant\n%q", in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTraverseCtx(t *testing.T) {
+	a, b, c := Symbol("a"), Symbol("b"), Symbol("c")
+	inner := List(b, c)
+	in := List(a, inner, Vector{List(c)})
+
+	sm := NewSourceMap()
+	sm.SetList(in.(*Cons), Pos{Line: 1, Col: 1})
+	sm.SetCar(in.(*Cons), Pos{Line: 1, Col: 2})
+	sm.SetCar(in.(*Cons).Cdr.(*Cons), Pos{Line: 2, Col: 2})
+	sm.SetList(inner.(*Cons), Pos{Line: 2, Col: 2})
+	sm.SetCar(inner.(*Cons), Pos{Line: 2, Col: 3})
+
+	var got []string
+	var visitor CtxVisitor
+	visitor = func(ctx VisitContext, v Atom) (CtxVisitor, error) {
+		s := fmt.Sprintf("%d:%v", ctx.Depth, v)
+		if ctx.HasPos {
+			s += "@" + ctx.Pos.String()
+		}
+		got = append(got, s)
+		return visitor, nil
+	}
+	want := []string{
+		"0:(a (b c) [(c)])@1:1",
+		"1:a@1:2",
+		"0:((b c) [(c)])@2:2",
+		"1:(b c)@2:2",
+		"2:b@2:3",
+		"1:(c)",
+		"2:c",
+		"0:([(c)])",
+		"1:[(c)]",
+		"2:(c)",
+		"3:c",
+	}
+	if err := TraverseCtx(in, sm, visitor); err != nil {
+		t.Fatalf("TraverseCtx(%v) err = %v; want nil", in, err)
+	} else if !reflect.DeepEqual(got, want) {
+		t.Errorf("TraverseCtx(%v) visited\n%q\nwant\n%q", in, got, want)
+	}
+
+	// Without a SourceMap, no positions are known.
+	got = nil
+	if err := TraverseCtx(in, nil, visitor); err != nil {
+		t.Fatalf("TraverseCtx(%v, nil) err = %v; want nil", in, err)
+	} else if len(got) != len(want) || got[4] != "2:b" {
+		t.Errorf("TraverseCtx(%v, nil) visited %q; want no positions", in, got)
+	}
+}
+
+func TestWalkIndexed(t *testing.T) {
+	errStop := errors.New("stop")
+	for _, in := range []Atom{List(Int(0), Int(1), Int(2)), Vector{Int(0), Int(1), Int(2)}} {
+		var got []int
+		err := WalkIndexed(in, func(i int, a Atom) error {
+			if a != Int(i) {
+				t.Errorf("WalkIndexed(%v) called fn(%d, %v); want fn(%d, %d)", in, i, a, i, i)
+			}
+			got = append(got, i)
+			if i == 1 {
+				return errStop
+			}
+			return nil
+		})
+		if err != errStop || !reflect.DeepEqual(got, []int{0, 1}) {
+			t.Errorf("WalkIndexed(%v) visited %v, err = %v; want [0 1], %v", in, got, err, errStop)
+		}
+	}
+
+	if err := WalkIndexed(&Cons{Car: Int(0), Cdr: Int(1)}, func(int, Atom) error { return nil }); err == nil {
+		t.Errorf("WalkIndexed(improper list) err = nil; want error")
+	}
+}
+
+func TestWalkTail(t *testing.T) {
+	a, b, c := Symbol("a"), Symbol("b"), Symbol("c")
+	cases := []struct {
+		in    Atom
+		elems []Atom
+		tail  Atom
+	}{
+		{nil, nil, nil},
+		{List(), nil, nil},
+		{(*Cons)(nil), nil, nil},
+		{List(a), []Atom{a}, nil},
+		{List(a, b, c), []Atom{a, b, c}, nil},
+		{&Cons{Car: a, Cdr: b}, []Atom{a}, b},
+		{&Cons{Car: a, Cdr: &Cons{Car: b, Cdr: Int(1)}}, []Atom{a, b}, Int(1)},
+		{&Cons{Car: a, Cdr: Vector{b}}, []Atom{a}, Vector{b}},
+		{Vector{a, b}, []Atom{a, b}, nil},
+		{Int(1), nil, Int(1)},
+	}
+	for _, c := range cases {
+		var elems []Atom
+		tail, err := WalkTail(c.in, func(a Atom) error {
+			elems = append(elems, a)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("WalkTail(%v) err = %v; want nil", c.in, err)
+		} else if !reflect.DeepEqual(elems, c.elems) || !reflect.DeepEqual(tail, c.tail) {
+			t.Errorf("WalkTail(%v) = %v, tail %v; want %v, tail %v", c.in, elems, tail, c.elems, c.tail)
+		}
+
+		err = Walk(c.in, func(Atom) error { return nil })
+		if c.tail == nil && err != nil {
+			t.Errorf("Walk(%v) err = %v; want nil", c.in, err)
+		} else if want := fmt.Sprintf("skim: cannot walk %T", c.tail); c.tail != nil && (err == nil || err.Error() != want) {
+			t.Errorf("Walk(%v) err = %v; want %s", c.in, err, want)
+		}
+	}
+
+	errStop := errors.New("stop")
+	in := &Cons{Car: a, Cdr: &Cons{Car: b, Cdr: c}}
+	tail, err := WalkTail(in, func(x Atom) error {
+		if x == b {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || tail != nil {
+		t.Errorf("WalkTail(%v) = %v, %v; want nil, %v", in, tail, err, errStop)
+	}
+}
+
+func TestQuoteAbbrev(t *testing.T) {
+	cases := []struct {
+		in   Atom
+		want string
+	}{
+		{List(Quote, Symbol("x")), "'"},
+		{List(Quasiquote, List(Symbol("x"))), "`"},
+		{List(Unquote, Symbol("x")), ","},
+		{List(UnquoteSplicing, Symbol("x")), ",@"},
+		{&Cons{Car: Quote, Cdr: &Cons{Car: Symbol("x"), Cdr: Nil}}, "'"},
+		{List(Quote), ""},
+		{List(Quote, Symbol("x"), Symbol("y")), ""},
+		{&Cons{Car: Quote, Cdr: &Cons{Car: Symbol("x"), Cdr: Int(1)}}, ""},
+		{List(Unquote, Symbol("@x")), ""},
+		{List(Quote, Symbol("@x")), "'"},
+		{List(Symbol("x"), Symbol("y")), ""},
+		{Nil, ""},
+	}
+	for _, c := range cases {
+		quo, rest := QuoteAbbrev(c.in.(*Cons))
+		if quo != c.want {
+			t.Errorf("QuoteAbbrev(%v) = %q; want %q", c.in, quo, c.want)
+		} else if quoted := c.in.(*Cons).Cdr; (rest != nil) != (c.want != "") || rest != nil && rest != quoted {
+			t.Errorf("QuoteAbbrev(%v) pair = %v; want %v", c.in, rest, quoted)
+		}
+	}
+}
+
+func TestIsAlist(t *testing.T) {
+	pair := &Cons{Car: Symbol("a"), Cdr: Int(1)}
+	alist := List(pair, &Cons{Car: Symbol("b"), Cdr: Int(2)}).(*Cons)
+	cases := []struct {
+		in   *Cons
+		keep func(*Cons) bool
+		want bool
+	}{
+		{alist, nil, true},
+		{Nil, nil, true},
+		{List(pair, Int(1)).(*Cons), nil, false},
+		{List(&Cons{Car: Symbol("a"), Cdr: List(Int(1))}).(*Cons), nil, false},
+		{&Cons{Car: pair, Cdr: pair}, nil, false},
+		{alist, func(c *Cons) bool { return c == pair }, false},
+		{alist, func(c *Cons) bool { return c == alist }, true}, // the first pair is not checked
+	}
+	for _, c := range cases {
+		if got := IsAlist(c.in, c.keep); got != c.want {
+			t.Errorf("IsAlist(%v) = %t; want %t", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package skim
+
+import (
+	"math"
+	"math/big"
+)
+
+// BigInt is an integer too large to be represented by an Int. A BigInt takes ownership of the
+// *big.Int it wraps, which must not be modified afterward.
+type BigInt struct{ v *big.Int }
+
+var _ Numeric = BigInt{}
+
+// NewBigInt returns v as a BigInt. The caller must not modify v after calling NewBigInt.
+func NewBigInt(v *big.Int) BigInt {
+	return BigInt{v: v}
+}
+
+// NewInteger returns v as an Int if it fits in an int64 and as a BigInt otherwise. The caller must
+// not modify v after calling NewInteger.
+func NewInteger(v *big.Int) Numeric {
+	if v.IsInt64() {
+		return Int(v.Int64())
+	}
+	return NewBigInt(v)
+}
+
+// Big returns a copy of b's value.
+func (b BigInt) Big() *big.Int {
+	if b.v == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(b.v)
+}
+
+func (BigInt) SkimAtom()     {}
+func (BigInt) IsFloat() bool { return false }
+func (BigInt) Exact() bool   { return true }
+
+func (b BigInt) String() string {
+	if b.v == nil {
+		return "0"
+	}
+	return b.v.String()
+}
+
+func (b BigInt) Int64() (int64, bool) {
+	if b.v == nil {
+		return 0, true
+	} else if !b.v.IsInt64() {
+		return 0, false
+	}
+	return b.v.Int64(), true
+}
+
+func (b BigInt) Float64() (float64, bool) {
+	if b.v == nil {
+		return 0, true
+	}
+	f, _ := new(big.Float).SetInt(b.v).Float64()
+	return f, !math.IsInf(f, 0)
+}
@@ -0,0 +1,46 @@
+package skim
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Bytes is a bytevector. It is written as #u8(...), where each element is an integer from 0 to
+// 255: #u8(0 255 31).
+type Bytes []byte
+
+// BytesOf returns a copy of b as Bytes, so that b may be modified afterward.
+func BytesOf(b []byte) Bytes {
+	return append(Bytes{}, b...)
+}
+
+func (Bytes) SkimAtom() {}
+
+func (b Bytes) String() string { return b.format(10) }
+
+// GoString returns the bytevector with each byte written in hexadecimal: #u8(#x00 #xff #x1f).
+func (b Bytes) GoString() string { return b.format(16) }
+
+func (b Bytes) format(base int) string {
+	var s strings.Builder
+	s.Grow(5 + len(b)*4)
+	s.WriteString("#u8(")
+	for i, c := range b {
+		if i > 0 {
+			s.WriteByte(' ')
+		}
+		if base == 16 {
+			s.WriteString("#x")
+			if c < 0x10 {
+				s.WriteByte('0')
+			}
+		}
+		s.WriteString(strconv.FormatUint(uint64(c), base))
+	}
+	s.WriteByte(')')
+	return s.String()
+}
+
+func (b Bytes) Dup() Atom {
+	return append(Bytes(nil), b...)
+}
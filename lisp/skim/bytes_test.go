@@ -0,0 +1,92 @@
+package skim
+
+import "testing"
+
+func TestBytesString(t *testing.T) {
+	cases := []struct {
+		in         Bytes
+		str, gostr string
+	}{
+		{Bytes{}, "#u8()", "#u8()"},
+		{Bytes{0, 255, 0x1f}, "#u8(0 255 31)", "#u8(#x00 #xff #x1f)"},
+	}
+
+	for _, c := range cases {
+		if got := c.in.String(); got != c.str {
+			t.Errorf("String() = %q; want %q", got, c.str)
+		}
+		if got := c.in.GoString(); got != c.gostr {
+			t.Errorf("GoString() = %q; want %q", got, c.gostr)
+		}
+	}
+}
+
+func TestBytesLeaf(t *testing.T) {
+	b := Bytes{1, 2, 3}
+
+	var bytes, ints int
+	var visit Visitor
+	visit = func(a Atom) (Visitor, error) {
+		switch a.(type) {
+		case Bytes:
+			bytes++
+		case Int:
+			ints++
+		}
+		return visit, nil
+	}
+	if err := Traverse(List(b, Int(4)), visit); err != nil {
+		t.Fatalf("Traverse(..) err = %v; want nil", err)
+	} else if bytes != 1 || ints != 1 {
+		t.Fatalf("Traverse(..) visited %d Bytes and %d Ints; want 1 and 1", bytes, ints)
+	}
+
+	if d := b.Dup().(Bytes); &d[0] == &b[0] || string(d) != string(b) {
+		t.Fatalf("Dup() = %v sharing storage = %t; want a copy of %v", d, &d[0] == &b[0], b)
+	}
+}
+
+func TestBytesOf(t *testing.T) {
+	src := []byte{1, 2, 3}
+	b := BytesOf(src)
+	src[0] = 9
+	if want := (Bytes{1, 2, 3}); !Equal(b, want) {
+		t.Fatalf("BytesOf(..) = %v after modifying its argument; want %v", b, want)
+	}
+	if b := BytesOf(nil); b == nil || len(b) != 0 {
+		t.Fatalf("BytesOf(nil) = %#v; want empty Bytes", b)
+	}
+}
+
+func TestBytesCopies(t *testing.T) {
+	b := Bytes{1, 2, 3}
+	in := Vector{b, List(b)}
+
+	if !Equal(in, Vector{Bytes{1, 2, 3}, List(Bytes{1, 2, 3})}) {
+		t.Errorf("Equal(%v, ..) = false for equal bytes; want true", in)
+	} else if Equal(b, Bytes{1, 2}) || Equal(b, Vector{Int(1), Int(2), Int(3)}) {
+		t.Errorf("Equal(%v, ..) = true for other atoms; want false", b)
+	}
+
+	d := Dup(in).(Vector)
+	d[0].(Bytes)[0] = 9
+	d[1].(*Cons).Car.(Bytes)[1] = 9
+	if b[0] != 1 || b[1] != 2 {
+		t.Errorf("modifying Dup(%v) modified the original, %v", in, b)
+	}
+
+	// Bytes are leaves: mapping a vector of them passes each to the MapFunc whole.
+	var n int
+	_, err := Map(in, func(a Atom) (Atom, error) {
+		if _, ok := a.(Bytes); ok {
+			n++
+		}
+		return a, nil
+	})
+	if err != nil || n != 1 {
+		t.Errorf("Map(%v) passed %d Bytes, err = %v; want 1, nil", in, n, err)
+	}
+	if _, err := Map(b, func(a Atom) (Atom, error) { return a, nil }); err == nil {
+		t.Errorf("Map(%v) err = nil; want an error, as Bytes are not mapped", b)
+	}
+}
@@ -0,0 +1,87 @@
+package skim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Char is a single Unicode code point. It is written as #\c for printable characters, #\name for
+// named characters (such as #\space and #\newline), and #\xHH for anything else.
+type Char rune
+
+var charNames = map[string]Char{
+	"alarm":     '\a',
+	"backspace": '\b',
+	"delete":    0x7f,
+	"escape":    0x1b,
+	"newline":   '\n',
+	"nul":       0,
+	"null":      0,
+	"return":    '\r',
+	"space":     ' ',
+	"tab":       '\t',
+}
+
+// charLiterals holds the canonical name of each named character.
+var charLiterals = map[Char]string{
+	'\a': "alarm",
+	'\b': "backspace",
+	0x7f: "delete",
+	0x1b: "escape",
+	'\n': "newline",
+	0:    "nul",
+	'\r': "return",
+	' ':  "space",
+	'\t': "tab",
+}
+
+// LookupCharName returns the Char for a character name, as written after #\ in a character
+// literal (e.g., "newline" or "space"). It returns false if the name is not known.
+func LookupCharName(name string) (Char, bool) {
+	c, ok := charNames[name]
+	return c, ok
+}
+
+func (Char) SkimAtom() {}
+
+func (c Char) String() string {
+	if name, ok := charLiterals[c]; ok {
+		return `#\` + name
+	} else if r := rune(c); unicode.IsPrint(r) && !unicode.IsSpace(r) {
+		return `#\` + string(r)
+	}
+	return c.GoString()
+}
+
+func (c Char) GoString() string {
+	return `#\x` + strconv.FormatInt(int64(c), 16)
+}
+
+// StringToChars returns the code points of s as a Vector of Chars. Bytes of s that are not valid
+// UTF-8 are each returned as the replacement character, U+FFFD.
+func StringToChars(s String) Vector {
+	chars := make(Vector, 0, utf8.RuneCountInString(string(s)))
+	for _, r := range string(s) {
+		chars = append(chars, Char(r))
+	}
+	return chars
+}
+
+// CharsToString returns the Chars of v as a String. It returns an error if an element of v is not a
+// Char or is not a valid code point, such as a surrogate half.
+func CharsToString(v Vector) (String, error) {
+	var b strings.Builder
+	for i, a := range v {
+		c, ok := a.(Char)
+		if !ok {
+			return "", fmt.Errorf("skim: chars: element %d is a %T, not a Char", i, a)
+		} else if !utf8.ValidRune(rune(c)) {
+			return "", fmt.Errorf("skim: chars: element %d, %#v, is not a valid code point", i, c)
+		}
+		b.WriteRune(rune(c))
+	}
+	return String(b.String()), nil
+}
@@ -0,0 +1,61 @@
+package skim
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CheckList returns an error if a is not a proper list or Vector: if it is a cyclic list, an
+// improper list, whose error gives its tail and the index at which it ends, or any other atom. If
+// elem is not nil, CheckList then calls it for each element of a and its index, from zero, and
+// returns the first error it returns. The structure of a is checked before any element is, so elem
+// may assume that a is a proper list.
+func CheckList(a Atom, elem func(i int, a Atom) error) error {
+	switch a.(type) {
+	case nil, *Cons, Vector:
+	default:
+		return fmt.Errorf("skim: expected a list, got %T", a)
+	}
+
+	n, tail, err := length(a)
+	if err != nil {
+		return errors.New("skim: list is cyclic")
+	} else if tail != nil {
+		return fmt.Errorf("skim: improper list ends in %s at index %d", fmtstring(tail), n)
+	} else if elem == nil {
+		return nil
+	}
+
+	if v, ok := a.(Vector); ok {
+		for i, e := range v {
+			if err := elem(i, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	c, _ := a.(*Cons)
+	for i := 0; !IsNil(c); i++ {
+		if err := elem(i, c.Car); err != nil {
+			return err
+		}
+		c, _ = c.Cdr.(*Cons)
+	}
+	return nil
+}
+
+// IsSymbolElem is an element check for CheckList that returns an error if a is not a Symbol.
+func IsSymbolElem(i int, a Atom) error {
+	if _, ok := a.(Symbol); !ok {
+		return fmt.Errorf("skim: element %d: expected symbol, got %T", i, a)
+	}
+	return nil
+}
+
+// IsNumericElem is an element check for CheckList that returns an error if a is not a Numeric.
+func IsNumericElem(i int, a Atom) error {
+	if _, ok := a.(Numeric); !ok {
+		return fmt.Errorf("skim: element %d: expected number, got %T", i, a)
+	}
+	return nil
+}
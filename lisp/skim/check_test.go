@@ -0,0 +1,66 @@
+package skim
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckList(t *testing.T) {
+	cyclic := &Cons{Car: Symbol("a")}
+	cyclic.Cdr = &Cons{Car: Symbol("b"), Cdr: cyclic}
+
+	cases := []struct {
+		name string
+		in   Atom
+		elem func(int, Atom) error
+		want string
+	}{
+		{name: "nil", in: nil, elem: IsSymbolElem},
+		{name: "empty", in: Nil, elem: IsSymbolElem},
+		{name: "symbols", in: List(Symbol("a"), Symbol("b")), elem: IsSymbolElem},
+		{name: "vector", in: Vector{Int(1), Float(2)}, elem: IsNumericElem},
+		{name: "no-elem", in: List(Int(1), String("a"))},
+		{name: "not-list", in: Int(1), want: "skim: expected a list, got skim.Int"},
+		{name: "cyclic", in: cyclic, elem: IsSymbolElem, want: "skim: list is cyclic"},
+		{
+			name: "improper",
+			in:   &Cons{Car: Symbol("a"), Cdr: &Cons{Car: Int(1), Cdr: String("tail")}},
+			elem: IsSymbolElem, // The structure is checked before the elements are.
+			want: `skim: improper list ends in "tail" at index 2`,
+		},
+		{
+			name: "symbol",
+			in:   List(Symbol("a"), Int(2)),
+			elem: IsSymbolElem,
+			want: "skim: element 1: expected symbol, got skim.Int",
+		},
+		{
+			name: "numeric",
+			in:   Vector{Int(1), Symbol("b")},
+			elem: IsNumericElem,
+			want: "skim: element 1: expected number, got skim.Symbol",
+		},
+		{
+			name: "elem-error",
+			in:   List(Int(1), Int(2), Int(3)),
+			elem: func(i int, a Atom) error {
+				if i == 2 {
+					return errors.New("third")
+				}
+				return nil
+			},
+			want: "third",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			err := CheckList(c.in, c.elem)
+			if c.want == "" && err != nil {
+				t.Fatalf("CheckList(%v) err = %v; want nil", c.in, err)
+			} else if c.want != "" && (err == nil || err.Error() != c.want) {
+				t.Fatalf("CheckList(%v) err = %v; want %s", c.in, err, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package skim
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Ranks of the types ordered by Compare. Numbers of all types share a rank.
+const (
+	rankNil = iota
+	rankBool
+	rankNumber
+	rankString
+	rankSymbol
+	rankCons
+	rankVector
+	rankMap
+	rankSet
+	rankOther
+)
+
+// Compare returns -1 if a is ordered before b, 1 if a is ordered after b, and 0 if they are equal.
+// Compare defines a total order over atoms:
+//
+//   - Atoms are first ordered by type: nil and the empty list, then Bools, numbers, Strings,
+//     Symbols, lists, Vectors, HashMaps, Sets, and then atoms of any other type ordered by the
+//     names of their types.
+//   - Numbers of any type are ordered by value, so 1 is before 1.5 and 2. Numbers with the same
+//     value are ordered by type, Int, BigInt, Rational, then Float, so 1 is before 1.0. The Float
+//     NaN is ordered before all other numbers and -0.0 is equal to 0.0.
+//   - #f is before #t. Strings, Symbols, Keywords, and Bytes are ordered by their bytes, and Chars
+//     by their code points. Errors are ordered by their messages and then by their irritants.
+//     Records are ordered by their types, as RecordTypes are, and then by their values.
+//   - Lists are ordered by their cars and then by their cdrs, so (a) is before (a b) and (b).
+//     Vectors are ordered by their elements and then by their lengths, so [a] is before [a b].
+//     HashMaps are ordered as vectors of their keys and values, sorted by their keys, so {a 1} is
+//     before {a 2} and {a 1 b 2}. Sets are ordered as vectors of their sorted elements.
+//   - Atoms of other types that have the same type are ordered by their values if they have a basic
+//     kind, such as a string, and otherwise by their String methods. Pointers with the same String
+//     are then ordered by their addresses, so that distinct pointers are never equal.
+//
+// For atoms of the types defined by this package, Compare(a, b) is 0 exactly when Equal(a, b),
+// except that NaN is equal to itself. Like Equal, Compare keeps its own stack and is safe to call
+// on cyclic atoms: a pair of conses, vectors, maps, sets, or records already being compared is
+// assumed to be equal, so cyclic atoms are ordered consistently with Equal, though not always by
+// the first difference in their unfoldings.
+func Compare(a, b Atom) int {
+	type item struct {
+		a, b Atom
+		lens bool // if true, compare the lengths of the vectors a and b
+	}
+	type pair struct{ a, b interface{} }
+	var (
+		stack = []item{{a: a, b: b}}
+		seen  map[pair]struct{}
+	)
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		a, b := top.a, top.b
+		if top.lens {
+			if c := compareInt(int64(len(a.(Vector))), int64(len(b.(Vector)))); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		ra, rb := compareRank(a), compareRank(b)
+		if ra != rb {
+			return compareInt(int64(ra), int64(rb))
+		}
+
+		if ka, kb := identity(a), identity(b); ka != nil && kb != nil {
+			key := pair{ka, kb}
+			if _, ok := seen[key]; ok {
+				continue
+			} else if seen == nil {
+				seen = make(map[pair]struct{})
+			}
+			seen[key] = struct{}{}
+		}
+
+		switch ra {
+		case rankNil:
+		case rankCons:
+			a, b := a.(*Cons), b.(*Cons)
+			stack = append(stack, item{a: a.Cdr, b: b.Cdr}, item{a: a.Car, b: b.Car})
+		case rankVector:
+			a, b := a.(Vector), b.(Vector)
+			stack = append(stack, item{a: a, b: b, lens: true})
+			for i := min(len(a), len(b)) - 1; i >= 0; i-- {
+				stack = append(stack, item{a: a[i], b: b[i]})
+			}
+		case rankMap:
+			stack = append(stack, item{a: mapVector(a.(*HashMap)), b: mapVector(b.(*HashMap))})
+		case rankSet:
+			stack = append(stack, item{a: Vector(a.(*Set).Elems()), b: Vector(b.(*Set).Elems())})
+		default:
+			if ea, ok := a.(*Error); ok && ea != nil {
+				if eb, ok := b.(*Error); ok && eb != nil {
+					if c := strings.Compare(ea.Msg, eb.Msg); c != 0 {
+						return c
+					}
+					stack = append(stack, item{a: ea.Irritants, b: eb.Irritants})
+					continue
+				}
+			}
+			if ra, ok := a.(*Record); ok && ra != nil {
+				if rb, ok := b.(*Record); ok && rb != nil {
+					if c := compareValues(ra.Type, rb.Type); c != 0 {
+						return c
+					}
+					stack = append(stack, item{a: Vector(ra.Values), b: Vector(rb.Values)})
+					continue
+				}
+			}
+			if c := compareValues(a, b); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func compareRank(a Atom) int {
+	if IsNil(a) {
+		return rankNil
+	}
+	switch a.(type) {
+	case Bool:
+		return rankBool
+	case Int, Float, BigInt, Rational:
+		return rankNumber
+	case String:
+		return rankString
+	case Symbol:
+		return rankSymbol
+	case *Cons:
+		return rankCons
+	case Vector:
+		return rankVector
+	case *HashMap:
+		return rankMap
+	case *Set:
+		return rankSet
+	}
+	return rankOther
+}
+
+// mapVector returns the keys and values of m as a Vector, [key value ...], sorted by their keys.
+func mapVector(m *HashMap) Vector {
+	entries := m.sorted()
+	v := make(Vector, 0, 2*len(entries))
+	for _, e := range entries {
+		v = append(v, e.Key, e.Value)
+	}
+	return v
+}
+
+// compareValues compares two atoms of the same rank that are neither lists nor vectors.
+func compareValues(a, b Atom) int {
+	switch a := a.(type) {
+	case Bool:
+		b := b.(Bool)
+		switch {
+		case a == b:
+			return 0
+		case !bool(a):
+			return -1
+		}
+		return 1
+	case Int, Float, BigInt, Rational:
+		if c := compareNumbers(a, b); c != 0 {
+			return c
+		}
+		return compareInt(int64(numberRank(a)), int64(numberRank(b)))
+	case String:
+		return strings.Compare(string(a), string(b.(String)))
+	case Symbol:
+		return strings.Compare(string(a), string(b.(Symbol)))
+	}
+
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb {
+		if c := strings.Compare(ta.PkgPath(), tb.PkgPath()); c != 0 {
+			return c
+		}
+		return strings.Compare(ta.String(), tb.String())
+	}
+	switch a := a.(type) {
+	case Keyword:
+		return strings.Compare(string(a), string(b.(Keyword)))
+	case Char:
+		return compareInt(int64(a), int64(b.(Char)))
+	case Bytes:
+		return bytes.Compare(a, b.(Bytes))
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareInt(va.Int(), vb.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch x, y := va.Uint(), vb.Uint(); {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(va.Float(), vb.Float())
+	case reflect.String:
+		return strings.Compare(va.String(), vb.String())
+	case reflect.Bool:
+		return compareValues(Bool(va.Bool()), Bool(vb.Bool()))
+	}
+	if c := strings.Compare(a.String(), b.String()); c != 0 || va.Kind() != reflect.Ptr {
+		return c
+	}
+	switch pa, pb := va.Pointer(), vb.Pointer(); {
+	case pa < pb:
+		return -1
+	case pa > pb:
+		return 1
+	}
+	return 0
+}
+
+// numberRank orders numbers of different types that have the same value.
+func numberRank(a Atom) int {
+	switch a.(type) {
+	case Int:
+		return 0
+	case BigInt:
+		return 1
+	case Rational:
+		return 2
+	}
+	return 3
+}
+
+// compareNumbers compares the values of two numbers exactly.
+func compareNumbers(a, b Atom) int {
+	switch a := a.(type) {
+	case Int:
+		if b, ok := b.(Int); ok {
+			return compareInt(int64(a), int64(b))
+		}
+	case Float:
+		if b, ok := b.(Float); ok {
+			return compareFloat(float64(a), float64(b))
+		}
+	}
+
+	// Only finite Floats can be compared as big.Rats.
+	fa, aIsFloat := a.(Float)
+	fb, bIsFloat := b.(Float)
+	switch {
+	case aIsFloat && (math.IsNaN(float64(fa)) || math.IsInf(float64(fa), 0)):
+		return compareFloat(float64(fa), 0)
+	case bIsFloat && (math.IsNaN(float64(fb)) || math.IsInf(float64(fb), 0)):
+		return -compareFloat(float64(fb), 0)
+	}
+	return bigRat(a).Cmp(bigRat(b))
+}
+
+// bigRat returns the value of the finite number a.
+func bigRat(a Atom) *big.Rat {
+	switch a := a.(type) {
+	case Int:
+		return new(big.Rat).SetInt64(int64(a))
+	case Float:
+		return new(big.Rat).SetFloat64(float64(a))
+	case BigInt:
+		return new(big.Rat).SetInt(a.Big())
+	case Rational:
+		return a.Big()
+	}
+	panic("skim: not a number")
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareFloat compares two floats, ordering NaN before all other values.
+func compareFloat(a, b float64) int {
+	switch an, bn := math.IsNaN(a), math.IsNaN(b); {
+	case an && bn:
+		return 0
+	case an:
+		return -1
+	case bn:
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,130 @@
+package skim
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestCompareOrder(t *testing.T) {
+	huge := NewBigInt(new(big.Int).Lsh(big.NewInt(1), 70))
+	// Each atom is ordered before those after it.
+	order := []Atom{
+		nil,
+		Bool(false),
+		Bool(true),
+		Float(math.NaN()),
+		Float(math.Inf(-1)),
+		NewBigInt(new(big.Int).Neg(huge.Big())),
+		Int(-1),
+		Float(-0.5),
+		Int(0),
+		Float(0),
+		NewRational(big.NewRat(1, 3)),
+		Float(0.5),
+		Int(1),
+		NewBigInt(big.NewInt(1)),
+		Float(1),
+		huge,
+		Float(math.Inf(1)),
+		String(""),
+		String("a"),
+		String("ab"),
+		String("b"),
+		Symbol("a"),
+		Symbol("b"),
+		List(Int(1)),
+		&Cons{Car: Int(1), Cdr: Int(2)},
+		List(Int(1), Int(1)),
+		List(Int(1), Int(2)),
+		List(Int(2)),
+		List(List(Int(1))),
+		Vector{},
+		Vector{Int(1)},
+		Vector{Int(1), Int(1)},
+		Vector{Int(2)},
+		Bytes("a"),
+		Bytes("b"),
+		Char('a'),
+		Char('b'),
+		Keyword("a"),
+		Keyword("b"),
+	}
+	for i, a := range order {
+		for j, b := range order {
+			want := compareInt(int64(i), int64(j))
+			if got := Compare(a, b); got != want {
+				t.Errorf("Compare(%v, %v) = %d; want %d", a, b, got, want)
+			}
+		}
+	}
+
+	equal := [][2]Atom{
+		{nil, List()},
+		{nil, (*Cons)(nil)},
+		{(*Cons)(nil), List()},
+		{Float(0), Float(math.Copysign(0, -1))},
+		{Float(math.NaN()), Float(math.NaN())},
+		{NewBigInt(big.NewInt(1)), NewBigInt(big.NewInt(1))},
+		{List(Symbol("a"), Vector{Int(1)}), List(Symbol("a"), Vector{Int(1)})},
+	}
+	for _, c := range equal {
+		if got := Compare(c[0], c[1]); got != 0 {
+			t.Errorf("Compare(%v, %v) = %d; want 0", c[0], c[1], got)
+		}
+	}
+}
+
+func TestCompareCyclic(t *testing.T) {
+	one := &Cons{Car: Symbol("a")}
+	one.Cdr = one
+	two := &Cons{Car: Symbol("a"), Cdr: &Cons{Car: Symbol("a")}}
+	two.Cdr.(*Cons).Cdr = two
+	other := &Cons{Car: Symbol("a"), Cdr: &Cons{Car: Symbol("b")}}
+	other.Cdr.(*Cons).Cdr = other
+
+	if got := Compare(one, two); got != 0 {
+		t.Errorf("Compare(%v, %v) = %d; want 0", one, two, got)
+	}
+	if got := Compare(one, other); got != -1 {
+		t.Errorf("Compare(%v, %v) = %d; want -1", one, other, got)
+	}
+	if got := Compare(other, two); got != 1 {
+		t.Errorf("Compare(%v, %v) = %d; want 1", other, two, got)
+	}
+}
+
+func TestCompareTotal(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	atoms := make([]Atom, 120)
+	for i := range atoms {
+		atoms[i] = randAtom(r, 3)
+	}
+
+	for _, a := range atoms {
+		for _, b := range atoms {
+			ab, ba := Compare(a, b), Compare(b, a)
+			if ab != -ba {
+				t.Fatalf("Compare(%v, %v) = %d, but Compare(%v, %v) = %d", a, b, ab, b, a, ba)
+			} else if (ab == 0) != Equal(a, b) {
+				t.Fatalf("Compare(%v, %v) = %d, but Equal() = %t", a, b, ab, Equal(a, b))
+			}
+			for _, c := range atoms {
+				if bc := Compare(b, c); ab <= 0 && bc <= 0 {
+					if ac := Compare(a, c); ac > 0 || (ab < 0 || bc < 0) && ac == 0 {
+						t.Fatalf("Compare(%v, %v) = %d and Compare(%v, %v) = %d, but Compare(%v, %v) = %d", a, b, ab, b, c, bc, a, c, ac)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(atoms, func(i, j int) bool { return Compare(atoms[i], atoms[j]) < 0 })
+	for i := 1; i < len(atoms); i++ {
+		if Compare(atoms[i-1], atoms[i]) > 0 {
+			t.Fatalf("sorted atoms %v and %v are out of order", atoms[i-1], atoms[i])
+		}
+	}
+}
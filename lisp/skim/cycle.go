@@ -0,0 +1,359 @@
+package skim
+
+import "strconv"
+
+// smallAtom is the number of cons pairs, vector elements, map keys and values, set elements, and
+// record values an atom may hold before it is checked for cycles when written or copied. Smaller
+// atoms cannot be cyclic.
+const smallAtom = 1024
+
+// bounded returns whether a holds no more than *n cons pairs, vector elements, map keys and values,
+// set elements, and record values, decrementing *n by the number it holds. An atom that is bounded
+// is not cyclic.
+func bounded(a Atom, n *int) bool {
+	for *n >= 0 {
+		switch v := a.(type) {
+		case *Cons:
+			if v == nil {
+				return true
+			}
+			*n--
+			if !bounded(v.Car, n) {
+				return false
+			}
+			a = v.Cdr
+		case Vector:
+			*n -= len(v)
+			for _, elem := range v {
+				if *n < 0 || !bounded(elem, n) {
+					return false
+				}
+			}
+			return *n >= 0
+		case *HashMap:
+			*n -= 2 * v.Len()
+			if v == nil || *n < 0 {
+				return *n >= 0
+			}
+			for _, bucket := range v.buckets {
+				for _, e := range bucket {
+					if !bounded(e.Key, n) || !bounded(e.Value, n) {
+						return false
+					}
+				}
+			}
+			return true
+		case *Set:
+			if v == nil {
+				return true
+			}
+			a = &v.m // elements are keys mapped to nil, which are counted alike
+		case *Record:
+			if v == nil {
+				return true
+			}
+			a = Vector(v.Values)
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Cyclic returns whether a holds a cons pair, vector, map, set, or record that is reachable from
+// itself, such as a list whose last cdr is its first cons pair. Cyclic atoms are written with datum
+// labels.
+func Cyclic(a Atom) bool {
+	n := smallAtom
+	return !bounded(a, &n) && cycles(a) != nil
+}
+
+// Labels assigns datum labels, as in #0=(a . #0#), to the cons pairs, vectors, maps, sets, and
+// records of an atom that are reachable from themselves, numbered in the order they are written. A
+// nil *Labels labels nothing. It is used by String and by printers writing cyclic atoms.
+type Labels struct {
+	labels map[interface{}]int // labels of cyclic atoms; -1 until written
+	next   int                 // next label
+}
+
+// NewLabels returns the Labels of a, or nil if a is not cyclic.
+func NewLabels(a Atom) *Labels {
+	n := smallAtom
+	if bounded(a, &n) {
+		return nil
+	}
+	labels := cycles(a)
+	if labels == nil {
+		return nil
+	}
+	return &Labels{labels: labels}
+}
+
+// Label returns the datum label written in place of a, or before it, and whether a has been written
+// already. The first time a labeled atom is passed to Label, it returns its definition, #N=, and
+// false, after which the atom is written. After that, it returns its reference, #N#, and true, and
+// the atom is not written again. If a is not labeled, Label returns the empty string and false.
+func (l *Labels) Label(a Atom) (label string, written bool) {
+	if l == nil {
+		return "", false
+	}
+	key := identity(a)
+	n, ok := l.labels[key]
+	if !ok {
+		return "", false
+	} else if n >= 0 {
+		return "#" + strconv.Itoa(n) + "#", true
+	}
+	n, l.next = l.next, l.next+1
+	l.labels[key] = n
+	return "#" + strconv.Itoa(n) + "=", false
+}
+
+// Labeled returns whether a has a datum label.
+func (l *Labels) Labeled(a Atom) bool {
+	if l == nil {
+		return false
+	}
+	_, ok := l.labels[identity(a)]
+	return ok
+}
+
+// LabeledPair returns whether the cons pair c has a datum label. It may be passed to IsAlist.
+func (l *Labels) LabeledPair(c *Cons) bool {
+	return l.Labeled(c)
+}
+
+// identity returns a key identifying a cons pair other than Nil, non-empty vector, map, set, or
+// record, or nil if a is none of these.
+func identity(a Atom) interface{} {
+	switch v := a.(type) {
+	case *Cons:
+		if v != nil && v != Nil {
+			return v
+		}
+	case Vector:
+		if len(v) > 0 {
+			return &v[0]
+		}
+	case *HashMap:
+		if v != nil {
+			return v
+		}
+	case *Set:
+		if v != nil {
+			return v
+		}
+	case *Record:
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// cycles returns the set of cons pairs, vectors, maps, sets, and records in a that are reachable
+// from themselves, each mapped to -1. It returns nil if a is not cyclic.
+func cycles(a Atom) map[interface{}]int {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	var (
+		state  = make(map[interface{}]int)
+		labels map[interface{}]int
+		visit  func(Atom)
+	)
+	visit = func(a Atom) {
+		var chain []interface{} // cons pairs of the list being visited, by cdr
+		defer func() {
+			for _, key := range chain {
+				state[key] = visited
+			}
+		}()
+		for {
+			key := identity(a)
+			switch state[key] {
+			case visiting:
+				if labels == nil {
+					labels = make(map[interface{}]int)
+				}
+				labels[key] = -1
+				return
+			case visited:
+				return
+			}
+			if key == nil {
+				return
+			}
+			state[key] = visiting
+			chain = append(chain, key)
+			switch v := a.(type) {
+			case Vector:
+				for _, elem := range v {
+					visit(elem)
+				}
+				return
+			case *HashMap:
+				for _, bucket := range v.buckets {
+					for _, e := range bucket {
+						visit(e.Key)
+						visit(e.Value)
+					}
+				}
+				return
+			case *Set:
+				for _, bucket := range v.m.buckets {
+					for _, e := range bucket {
+						visit(e.Key)
+					}
+				}
+				return
+			case *Record:
+				for _, value := range v.Values {
+					visit(value)
+				}
+				return
+			}
+			c := a.(*Cons)
+			visit(c.Car)
+			a = c.Cdr
+		}
+	}
+	visit(a)
+	return labels
+}
+
+// dupAtom returns a deep copy of a. If dups is nil, a must not be cyclic, and the cons pairs of
+// each list in a are allocated together. Otherwise, a may be cyclic: cons pairs, vectors, maps,
+// sets, and records already copied are held in dups by their identity, so each is copied once.
+// dupAtom keeps its own stack rather than recursing, so the depth of a is limited only by memory.
+func dupAtom(a Atom, dups map[interface{}]Atom) Atom {
+	type task struct {
+		src Atom
+		dst *Atom
+	}
+	var result Atom
+	stack := []task{{a, &result}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		src, dst := top.src, top.dst
+
+		switch v := src.(type) {
+		case *Cons:
+			if v == nil {
+				continue
+			} else if v == Nil {
+				*dst = v
+				continue
+			}
+		case Vector:
+			if len(v) == 0 {
+				*dst = Vector{}
+				continue
+			}
+		case *HashMap:
+			if v == nil {
+				*dst = v
+				continue
+			}
+		case *Set:
+			if v == nil {
+				*dst = v
+				continue
+			}
+		case *Record:
+			if v == nil {
+				*dst = v
+				continue
+			}
+		default:
+			*dst = Dup(src)
+			continue
+		}
+
+		key := identity(src)
+		if dups != nil {
+			if d, ok := dups[key]; ok {
+				*dst = d
+				continue
+			}
+		}
+
+		if r, ok := src.(*Record); ok {
+			d := &Record{Type: r.Type, Values: make([]Atom, len(r.Values))}
+			*dst = d
+			if dups != nil {
+				dups[key] = d
+			}
+			for i := len(r.Values) - 1; i >= 0; i-- {
+				stack = append(stack, task{r.Values[i], &d.Values[i]})
+			}
+			continue
+		}
+
+		var m, d *HashMap
+		switch v := src.(type) {
+		case *HashMap:
+			m, d = v, new(HashMap)
+			*dst = d
+		case *Set:
+			s := new(Set)
+			m, d = &v.m, &s.m
+			*dst = s
+		}
+		if m != nil {
+			// Copied keys are Equal to the originals, so they keep their hashes under the same seed.
+			d.seed, d.buckets, d.n = m.seed, make(map[uint64][]mapEntry, len(m.buckets)), m.n
+			if dups != nil {
+				dups[key] = *dst
+			}
+			for h, bucket := range m.buckets {
+				entries := make([]mapEntry, len(bucket))
+				d.buckets[h] = entries
+				for i, e := range bucket {
+					stack = append(stack, task{e.Value, &entries[i].Value}, task{e.Key, &entries[i].Key})
+				}
+			}
+			continue
+		}
+
+		if v, ok := src.(Vector); ok {
+			d := make(Vector, len(v))
+			*dst = d
+			if dups != nil {
+				dups[key] = d
+			}
+			for i := len(v) - 1; i >= 0; i-- {
+				stack = append(stack, task{v[i], &d[i]})
+			}
+			continue
+		}
+
+		c := src.(*Cons)
+		if dups != nil {
+			d := new(Cons)
+			*dst, dups[key] = d, d
+			stack = append(stack, task{c.Cdr, &d.Cdr}, task{c.Car, &d.Car})
+			continue
+		}
+
+		n := 1
+		for next, ok := c.Cdr.(*Cons); ok && next != nil && next != Nil; next, ok = next.Cdr.(*Cons) {
+			n++
+		}
+		pairs := make([]Cons, n)
+		*dst = &pairs[0]
+		for i := range pairs {
+			d := &pairs[i]
+			stack = append(stack, task{c.Car, &d.Car})
+			if i == n-1 {
+				stack = append(stack, task{c.Cdr, &d.Cdr})
+				break
+			}
+			d.Cdr = &pairs[i+1]
+			c = c.Cdr.(*Cons)
+		}
+	}
+	return result
+}
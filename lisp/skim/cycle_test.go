@@ -0,0 +1,90 @@
+package skim
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCycleString(t *testing.T) {
+	a, b := Symbol("a"), Symbol("b")
+
+	cdr := &Cons{Car: Int(1)}
+	cdr.Cdr = cdr
+
+	long := &Cons{Car: a, Cdr: &Cons{Car: b}}
+	long.Cdr.(*Cons).Cdr = long
+
+	car := &Cons{Car: nil, Cdr: &Cons{Car: b}}
+	car.Car = car
+
+	vec := Vector{a, nil}
+	vec[1] = List(b, vec)
+
+	shared := List(a)
+	inner := &Cons{Car: a}
+	inner.Cdr = inner
+
+	cases := []struct {
+		in         Atom
+		str, gostr string
+	}{
+		{cdr, "#0=(1 . #0#)", "#0=(1 . #0#)"},
+		{long, "#0=(a b . #0#)", "#0=(a . (b . #0#))"},
+		{car, "#0=(#0# b)", "#0=(#0# . (b . #nil))"},
+		{vec, "#0=[a (b #0#)]", "#0=[a (b . (#0# . #nil))]"},
+		{List(inner, inner, shared, shared), "(#0=(a . #0#) #0# (a) (a))", "(#0=(a . #0#) . (#0# . ((a . #nil) . ((a . #nil) . #nil))))"},
+		{List(Quote, cdr), "'#0=(1 . #0#)", "(quote . (#0=(1 . #0#) . #nil))"},
+	}
+	for _, c := range cases {
+		if got := c.in.String(); got != c.str {
+			t.Errorf("String() = %q; want %q", got, c.str)
+		}
+		if got := c.in.(goStringer).GoString(); got != c.gostr {
+			t.Errorf("GoString() = %q; want %q", got, c.gostr)
+		}
+	}
+}
+
+func TestCycleStringLarge(t *testing.T) {
+	elems := make([]Atom, smallAtom*2)
+	for i := range elems {
+		elems[i] = Int(i)
+	}
+	list := List(elems...).(*Cons)
+	want := list.String()
+	if strings.Contains(want, "#0") {
+		t.Fatalf("String() of acyclic list contains a datum label")
+	}
+
+	// Make the list cyclic at its end, past the point where it is checked for cycles.
+	last := list
+	for ; last.Cdr != nil; last = last.Cdr.(*Cons) {
+	}
+	last.Cdr = list
+	got := list.String()
+	if want := "#0=(" + want[1:len(want)-1] + " . #0#)"; got != want {
+		t.Fatalf("String() = %.40q...; want %.40q...", got, want)
+	}
+}
+
+func TestCycleDup(t *testing.T) {
+	c := &Cons{Car: Vector{Int(1), nil}}
+	c.Car.(Vector)[1] = c
+	c.Cdr = c
+
+	d, ok := c.Dup().(*Cons)
+	if !ok || d == c {
+		t.Fatalf("Dup() = %#v; want a new *Cons", d)
+	} else if d.Cdr != d || d.Car.(Vector)[1] != d {
+		t.Fatalf("Dup() = %v; want cycles through the copy", d)
+	} else if got, want := d.String(), c.String(); got != want {
+		t.Fatalf("Dup() = %v; want %v", got, want)
+	}
+
+	v := Vector{nil}
+	v[0] = v
+	w := v.Dup().(Vector)
+	if &w[0] == &v[0] || &w[0].(Vector)[0] != &w[0] {
+		t.Fatalf("Dup() = %v; want a vector holding itself", w)
+	}
+}
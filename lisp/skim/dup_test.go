@@ -0,0 +1,120 @@
+package skim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDup(t *testing.T) {
+	shared := List(Symbol("s"))
+	in := List(
+		String("str"),
+		Bytes("bytes"),
+		Vector{Int(1), List(Int(2))},
+		Vector{},
+		shared,
+		shared,
+		&Cons{Car: Int(1), Cdr: Int(2)},
+		List(),
+	).(*Cons)
+
+	out, ok := Dup(in).(*Cons)
+	if !ok || out == in {
+		t.Fatalf("Dup(%v) = %#v; want a new *Cons", in, out)
+	} else if !reflect.DeepEqual(out, in) {
+		t.Fatalf("Dup(%v) = %v; want an equal list", in, out)
+	}
+
+	elems, err := ToSlice(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, _ := ToSlice(in)
+	if b := elems[1].(Bytes); &b[0] == &orig[1].(Bytes)[0] {
+		t.Errorf("Dup(%v) shares the storage of %v", in, b)
+	}
+	if v := elems[2].(Vector); &v[0] == &orig[2].(Vector)[0] || v[1] == orig[2].(Vector)[1] {
+		t.Errorf("Dup(%v) shares the vector %v", in, v)
+	}
+	if elems[3] == nil || len(elems[3].(Vector)) != 0 {
+		t.Errorf("Dup(%v) copied [] as %#v; want []", in, elems[3])
+	}
+	if elems[4] == shared || elems[5] == shared {
+		t.Errorf("Dup(%v) shares the list %v", in, shared)
+	} else if elems[4] == elems[5] {
+		t.Errorf("Dup(%v) copied an acyclic list held twice once; want twice", in)
+	}
+
+	for _, a := range []Atom{nil, (*Cons)(nil), Int(1), String("s"), Symbol("a")} {
+		if got := Dup(a); got != a && !(a == Atom((*Cons)(nil)) && got == nil) {
+			t.Errorf("Dup(%#v) = %#v; want it returned as is", a, got)
+		}
+	}
+}
+
+func TestDupDeep(t *testing.T) {
+	const depth = 1 << 16
+	var in Atom = Int(0)
+	for i := 0; i < depth; i++ {
+		in = List(in, Vector{Int(i)})
+	}
+	out := Dup(in)
+	for i := depth - 1; i >= 0; i-- {
+		c := out.(*Cons)
+		if v := c.Cdr.(*Cons).Car.(Vector); v[0] != Int(i) {
+			t.Fatalf("Dup() at depth %d holds %v; want [%d]", depth-i, v, i)
+		}
+		out = c.Car
+	}
+	if out != Int(0) {
+		t.Fatalf("Dup() innermost atom = %v; want 0", out)
+	}
+}
+
+func TestDupShallow(t *testing.T) {
+	a, b := List(Symbol("a")), Vector{Int(1)}
+	cases := []Atom{
+		List(a, b),
+		&Cons{Car: a, Cdr: &Cons{Car: b, Cdr: Int(3)}},
+		&Cons{Car: a},
+		Vector{a, b},
+	}
+	for _, in := range cases {
+		out := DupShallow(in)
+		if !reflect.DeepEqual(out, in) {
+			t.Errorf("DupShallow(%v) = %v; want an equal copy", in, out)
+		}
+		if c, ok := in.(*Cons); ok && out == Atom(c) {
+			t.Errorf("DupShallow(%v) returned the same list", in)
+		} else if v, ok := in.(Vector); ok && &out.(Vector)[0] == &v[0] {
+			t.Errorf("DupShallow(%v) returned the same vector", in)
+		}
+	}
+
+	for _, in := range []Atom{nil, (*Cons)(nil), Vector(nil), Int(1)} {
+		if out := DupShallow(in); !reflect.DeepEqual(out, in) && !(in == Atom((*Cons)(nil)) && out == nil) {
+			t.Errorf("DupShallow(%#v) = %#v; want it returned as is", in, out)
+		}
+	}
+
+	// The elements of a list are shared, but not its pairs.
+	in := List(a, b).(*Cons)
+	out := DupShallow(in).(*Cons)
+	if out == in || out.Car != in.Car || &out.Cdr.(*Cons).Car.(Vector)[0] != &b[0] {
+		t.Errorf("DupShallow(%v) = %v; want new pairs holding the same elements", in, out)
+	}
+	out.Cdr = nil
+	if in.Cdr == nil {
+		t.Errorf("DupShallow(%v) shares the cdr of its first pair", in)
+	}
+
+	// A circular list is copied with the same cycle.
+	c := List(Int(1), Int(2), Int(3)).(*Cons)
+	c.Cdr.(*Cons).Cdr.(*Cons).Cdr = c.Cdr
+	d := DupShallow(c).(*Cons)
+	if d == c || d.Cdr == c.Cdr {
+		t.Errorf("DupShallow(%v) shares pairs", c)
+	} else if loop := d.Cdr.(*Cons).Cdr.(*Cons).Cdr; loop != d.Cdr {
+		t.Errorf("DupShallow(%v) = %v; want the same cycle", c, d)
+	}
+}
@@ -0,0 +1,159 @@
+package skim
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// Equal returns whether a and b are structurally equal:
+//
+//   - Lists are equal if their cars and cdrs are equal, so improper lists are equal if their tails
+//     are, and vectors are equal if they have the same length and equal elements.
+//   - nil, a nil *Cons, and the empty list, Nil, are equal to one another, as reported by IsNil.
+//     A cons pair holding two nils is the list (#nil), which is not equal to ().
+//   - Ints, Floats, Strings, Symbols, Keywords, Chars, and Bools are equal if they have the same
+//     type and value, as by ==. Numbers of different types are never equal, so the Int 1 is not
+//     equal to the Float 1.0, and the Float NaN is not equal to itself.
+//   - BigInts, Rationals, and Bytes are equal if they have the same value.
+//   - Errors are equal if they have the same message and equal irritants. The errors they wrap are
+//     not compared.
+//   - HashMaps are equal if they have the same keys and the values of equal keys are equal. A nil
+//     *HashMap is equal to an empty one. Sets are equal if they hold the same elements.
+//   - Records are equal if they have the same RecordType, by identity, and equal values.
+//   - Any other atoms are equal if they are comparable and equal by ==.
+//
+// Shared and copied structure are not distinguished. Equal is safe to call on cyclic atoms: a pair
+// of conses, vectors, maps, sets, or records already being compared is assumed to be equal, so
+// cyclic atoms are equal if they unfold to the same structure.
+func Equal(a, b Atom) bool {
+	type pair struct{ a, b interface{} }
+	var (
+		stack = []Atom{a, b}
+		seen  map[pair]struct{}
+	)
+	for len(stack) > 0 {
+		a, b := stack[len(stack)-2], stack[len(stack)-1]
+		stack = stack[:len(stack)-2]
+
+		if na, nb := IsNil(a), IsNil(b); na || nb {
+			if na != nb {
+				return false
+			}
+			continue
+		}
+
+		if ka, kb := identity(a), identity(b); ka != nil && kb != nil {
+			key := pair{ka, kb}
+			if _, ok := seen[key]; ok {
+				continue
+			} else if seen == nil {
+				seen = make(map[pair]struct{})
+			}
+			seen[key] = struct{}{}
+		}
+
+		switch a := a.(type) {
+		case *Cons:
+			b, ok := b.(*Cons)
+			if !ok {
+				return false
+			}
+			stack = append(stack, a.Cdr, b.Cdr, a.Car, b.Car)
+		case Vector:
+			b, ok := b.(Vector)
+			if !ok || len(a) != len(b) {
+				return false
+			}
+			for i := len(a) - 1; i >= 0; i-- {
+				stack = append(stack, a[i], b[i])
+			}
+		case BigInt:
+			b, ok := b.(BigInt)
+			if !ok || a.Big().Cmp(b.Big()) != 0 {
+				return false
+			}
+		case Rational:
+			b, ok := b.(Rational)
+			if !ok || a.Big().Cmp(b.Big()) != 0 {
+				return false
+			}
+		case Bytes:
+			b, ok := b.(Bytes)
+			if !ok || !bytes.Equal(a, b) {
+				return false
+			}
+		case *Error:
+			b, ok := b.(*Error)
+			if !ok || a == nil || b == nil {
+				if !ok || a != b {
+					return false
+				}
+				continue
+			} else if a.Msg != b.Msg {
+				return false
+			}
+			stack = append(stack, a.Irritants, b.Irritants)
+		case *HashMap:
+			b, ok := b.(*HashMap)
+			if !ok || a.Len() != b.Len() {
+				return false
+			}
+			for _, e := range a.sorted() {
+				v, ok := b.Get(e.Key)
+				if !ok {
+					return false
+				}
+				stack = append(stack, e.Value, v)
+			}
+		case *Set:
+			b, ok := b.(*Set)
+			if !ok || a.Len() != b.Len() {
+				return false
+			}
+			for _, elem := range a.Elems() {
+				if !b.Contains(elem) {
+					return false
+				}
+			}
+		case *Record:
+			b, ok := b.(*Record)
+			if !ok || a == nil || b == nil {
+				if !ok || a != b {
+					return false
+				}
+				continue
+			} else if a.Type != b.Type {
+				return false
+			}
+			stack = append(stack, Vector(a.Values), Vector(b.Values))
+		default:
+			if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() || a != b {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Eqv returns whether a and b are the same atom, as Scheme's eqv? decides. Unlike Equal, it does
+// not compare the contents of cons pairs, vectors, bytes, maps, sets, records, or errors: they are
+// eqv only if they are the same one, such as the same *Cons or vectors sharing their first element.
+// nil, a nil *Cons, and Nil are eqv to one another, as are empty vectors. BigInts and Rationals are
+// eqv if they have the same value, and any other atoms if they have the same type and are equal by
+// ==.
+func Eqv(a, b Atom) bool {
+	if na, nb := IsNil(a), IsNil(b); na || nb {
+		return na == nb
+	}
+	switch a := a.(type) {
+	case BigInt, Rational:
+		return Equal(a, b)
+	case Vector:
+		b, ok := b.(Vector)
+		return ok && len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+	case Bytes:
+		b, ok := b.(Bytes)
+		return ok && len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+	}
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b
+}
@@ -0,0 +1,104 @@
+package skim
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	a, b := Symbol("a"), Symbol("b")
+
+	ring := func(elems ...Atom) *Cons {
+		c := List(elems...).(*Cons)
+		last := c
+		for next, ok := last.Cdr.(*Cons); ok && next != nil; next, ok = last.Cdr.(*Cons) {
+			last = next
+		}
+		last.Cdr = c
+		return c
+	}
+	selfVec := func() Vector {
+		v := Vector{a, nil}
+		v[1] = List(b, v)
+		return v
+	}
+	shared := List(a, b)
+	proc := struct{ Atom }{Int(1)}
+	fn := funcAtom(func() {})
+
+	cases := []struct {
+		a, b Atom
+		want bool
+	}{
+		{nil, nil, true},
+		{nil, Nil, true},
+		{(*Cons)(nil), Nil, true},
+		{nil, List(nil), false}, // (#nil) has one element
+		{List(nil), &Cons{}, true},
+		{nil, Vector{}, false},
+		{Int(1), Int(1), true},
+		{Int(1), Float(1), false},
+		{Int(1), NewBigInt(big.NewInt(1)), false},
+		{Float(math.NaN()), Float(math.NaN()), false},
+		{String("a"), Symbol("a"), false},
+		{Symbol("a"), Keyword("a"), false},
+		{Bool(true), Bool(true), true},
+		{Char('a'), Char('a'), true},
+		{NewBigInt(new(big.Int).Lsh(big.NewInt(1), 100)), NewBigInt(new(big.Int).Lsh(big.NewInt(1), 100)), true},
+		{NewRational(big.NewRat(1, 3)), NewRational(big.NewRat(2, 6)), true},
+		{Bytes{1, 2}, Bytes{1, 2}, true},
+		{Bytes{1, 2}, Bytes{1}, false},
+		{List(a, List(b, List(Int(1), String("x")))), List(a, List(b, List(Int(1), String("x")))), true},
+		{List(a, List(b, List(Int(1)))), List(a, List(b, List(Int(2)))), false},
+		{List(a, b), List(a, b, nil), false},
+		{List(a, b), List(a, b, Int(0)), false},
+		{List(a, b), List(a), false},
+		{&Cons{Car: a, Cdr: b}, &Cons{Car: a, Cdr: b}, true},
+		{&Cons{Car: a, Cdr: b}, List(a, b), false},
+		{&Cons{Car: a, Cdr: Nil}, List(a), true},
+		{Vector{List(a), List(b, Vector{})}, Vector{List(a), List(b, Vector{})}, true},
+		{Vector{List(a)}, Vector{List(b)}, false},
+		{Vector{a}, Vector{a, b}, false},
+		{Vector{a}, List(a), false},
+		{List(shared, shared), List(List(a, b), List(a, b)), true},
+		{ring(Int(1)), ring(Int(1)), true},
+		{ring(Int(1)), ring(Int(1), Int(1)), true},
+		{ring(Int(1)), ring(Int(1), Int(2)), false},
+		{ring(a, b), List(a, b), false},
+		{selfVec(), selfVec(), true},
+		{proc, proc, true},
+		{proc, struct{ Atom }{Int(2)}, false},
+		{fn, fn, false},
+	}
+	for _, c := range cases {
+		if got := Equal(c.a, c.b); got != c.want {
+			t.Errorf("Equal(%v, %v) = %t; want %t", c.a, c.b, got, c.want)
+		}
+		if got := Equal(c.b, c.a); got != c.want {
+			t.Errorf("Equal(%v, %v) = %t; want %t", c.b, c.a, got, c.want)
+		}
+	}
+}
+
+func TestEqualDeep(t *testing.T) {
+	// Long and deeply nested lists are compared without recursion.
+	const n = 1 << 16
+	long, deep := Atom(nil), Atom(nil)
+	for i := 0; i < n; i++ {
+		long = &Cons{Car: Int(i), Cdr: long}
+		deep = List(deep)
+	}
+	if !Equal(long, Dup(long)) {
+		t.Errorf("Equal(long, Dup(long)) = false; want true")
+	}
+	if !Equal(deep, Dup(deep)) {
+		t.Errorf("Equal(deep, Dup(deep)) = false; want true")
+	}
+}
+
+// funcAtom is an atom that is not comparable.
+type funcAtom func()
+
+func (funcAtom) SkimAtom()      {}
+func (funcAtom) String() string { return "#<func>" }
@@ -0,0 +1,58 @@
+package skim
+
+import "strings"
+
+// Error is an error object: an atom describing a failure, with a message and the atoms involved in
+// it, its irritants. It is also a Go error, so an Error raised while evaluating a program can be
+// recovered from the error returned by evaluation with errors.As. An Error may wrap the Go error
+// that caused it, which is returned by its Unwrap method.
+type Error struct {
+	Msg       string
+	Irritants Vector
+	Wrapped   error
+}
+
+func (*Error) SkimAtom() {}
+
+// String returns the Error as #<error "msg" irritant ...>, with each irritant written by its String
+// method.
+func (e *Error) String() string {
+	if e == nil {
+		return "#nil"
+	}
+	var b strings.Builder
+	b.WriteString("#<error ")
+	b.WriteString(String(e.Msg).String())
+	for _, a := range e.Irritants {
+		b.WriteByte(' ')
+		b.WriteString(fmtstring(a))
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// Error returns the message of e followed by its irritants, separated by spaces, and the message of
+// the error it wraps, if any.
+func (e *Error) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	var b strings.Builder
+	b.WriteString(e.Msg)
+	for _, a := range e.Irritants {
+		b.WriteByte(' ')
+		b.WriteString(fmtstring(a))
+	}
+	if e.Wrapped != nil {
+		if b.Len() > 0 {
+			b.WriteString(": ")
+		}
+		b.WriteString(e.Wrapped.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the error wrapped by e, allowing use of errors.Is and errors.As.
+func (e *Error) Unwrap() error {
+	return e.Wrapped
+}
@@ -0,0 +1,62 @@
+package skim
+
+import (
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	e := &Error{Msg: "bad value", Irritants: Vector{Int(1), String("x"), nil}, Wrapped: io.EOF}
+	if got, want := e.String(), `#<error "bad value" 1 "x" #nil>`; got != want {
+		t.Errorf("String() = %q; want %q", got, want)
+	}
+	if got, want := e.Error(), `bad value 1 "x" #nil: EOF`; got != want {
+		t.Errorf("Error() = %q; want %q", got, want)
+	}
+	if got, want := (&Error{Msg: "m"}).Error(), "m"; got != want {
+		t.Errorf("Error() = %q; want %q", got, want)
+	}
+
+	var err error = fmt.Errorf("eval: %w", e)
+	var got *Error
+	if !errors.As(err, &got) || got != e {
+		t.Errorf("errors.As(%v) = %v; want %v", err, got, e)
+	} else if !errors.Is(err, io.EOF) {
+		t.Errorf("errors.Is(%v, io.EOF) = false; want true", err)
+	}
+
+	if !IsTrue(e) {
+		t.Errorf("IsTrue(%v) = false; want true", e)
+	}
+}
+
+func TestErrorEqual(t *testing.T) {
+	seed := maphash.MakeSeed()
+	a := &Error{Msg: "m", Irritants: Vector{List(Int(1))}, Wrapped: io.EOF}
+	cases := []struct {
+		b    Atom
+		want bool
+	}{
+		{a, true},
+		{&Error{Msg: "m", Irritants: Vector{List(Int(1))}}, true},
+		{&Error{Msg: "n", Irritants: Vector{List(Int(1))}}, false},
+		{&Error{Msg: "m", Irritants: Vector{List(Int(2))}}, false},
+		{&Error{Msg: "m"}, false},
+		{String("m"), false},
+	}
+	for _, c := range cases {
+		if got := Equal(a, c.b); got != c.want {
+			t.Errorf("Equal(%v, %v) = %t; want %t", a, c.b, got, c.want)
+		} else if got && Hash(a, seed) != Hash(c.b, seed) {
+			t.Errorf("Hash(%v) != Hash(%v) for equal errors", a, c.b)
+		} else if cmp := Compare(a, c.b); (cmp == 0) != c.want {
+			t.Errorf("Compare(%v, %v) = %d; want 0 = %t", a, c.b, cmp, c.want)
+		}
+	}
+	if !Equal(&Error{Msg: "m"}, &Error{Msg: "m", Irritants: Vector{}}) {
+		t.Errorf("Equal(..) = false for errors without irritants; want true")
+	}
+}
@@ -0,0 +1,38 @@
+package skim
+
+// Foreign is an atom holding an opaque Go value, such as a database connection or logger, so that
+// it can be passed through a program without being converted to atoms. A Foreign evaluates to
+// itself and is written as #<foreign Name>.
+//
+// Foreign atoms are compared by identity, as pointers, regardless of the values they hold. They are
+// not copied by Dup: a copy of a list holding a Foreign holds the same Foreign, and so shares its
+// value.
+type Foreign struct {
+	Name  string
+	Value interface{}
+}
+
+// NewForeign returns a new Foreign named name holding v.
+func NewForeign(name string, v interface{}) *Foreign {
+	return &Foreign{Name: name, Value: v}
+}
+
+func (*Foreign) SkimAtom() {}
+
+func (f *Foreign) String() string {
+	if f == nil {
+		return "#nil"
+	}
+	return "#<foreign " + f.Name + ">"
+}
+
+// ForeignValue returns the value held by a, if a is a *Foreign holding a value of type T.
+func ForeignValue[T any](a Atom) (T, bool) {
+	var zero T
+	f, ok := a.(*Foreign)
+	if !ok || f == nil {
+		return zero, false
+	}
+	v, ok := f.Value.(T)
+	return v, ok
+}
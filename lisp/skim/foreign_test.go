@@ -0,0 +1,64 @@
+package skim
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestForeign(t *testing.T) {
+	var buf bytes.Buffer
+	a, b := NewForeign("buffer", &buf), NewForeign("buffer", &buf)
+	if got, want := a.String(), "#<foreign buffer>"; got != want {
+		t.Errorf("String() = %s; want %s", got, want)
+	}
+	if !Equal(a, a) || Equal(a, b) || Compare(a, b) == 0 || Compare(a, a) != 0 {
+		t.Errorf("foreign atoms %p and %p are not compared by identity", a, b)
+	}
+
+	// Dup shares foreign atoms, and so their values.
+	l := List(a, Int(1))
+	if d := Dup(l).(*Cons); d == l || d.Car != Atom(a) {
+		t.Errorf("Dup(%v) = %v; want a new list holding the same foreign atom", l, d)
+	}
+
+	if got, ok := ForeignValue[*bytes.Buffer](a); !ok || got != &buf {
+		t.Errorf("ForeignValue[*bytes.Buffer](%v) = %p, %t; want %p, true", a, got, ok, &buf)
+	}
+	if _, ok := ForeignValue[string](a); ok {
+		t.Errorf("ForeignValue[string](%v) = true; want false", a)
+	}
+	if _, ok := ForeignValue[*bytes.Buffer](Int(1)); ok {
+		t.Error("ForeignValue[*bytes.Buffer](1) = true; want false")
+	}
+}
+
+func TestForeignMarshal(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Marshal(func() {}); err == nil {
+		t.Error("Marshal(func) err = nil; want an error")
+	}
+	a, err := MarshalOptions{Foreign: true}.Marshal(struct{ Buf *bytes.Buffer }{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.String(), "{Buf #<foreign *bytes.Buffer>}"; got != want {
+		t.Errorf("Marshal(Foreign) = %s; want %s", got, want)
+	}
+
+	var v struct{ Buf *bytes.Buffer }
+	if err := Unmarshal(a, &v); err != nil {
+		t.Fatal(err)
+	} else if v.Buf != &buf {
+		t.Errorf("Unmarshal(%v) set Buf to %p; want %p", a, v.Buf, &buf)
+	}
+
+	var s string
+	if err := Unmarshal(NewForeign("buffer", &buf), &s); err == nil {
+		t.Error("Unmarshal(foreign, *string) err = nil; want an error")
+	}
+	var atom Atom
+	f := NewForeign("buffer", &buf)
+	if err := Unmarshal(f, &atom); err != nil || atom != Atom(f) {
+		t.Errorf("Unmarshal(foreign, *Atom) = %v, %v; want the foreign atom", atom, err)
+	}
+}
@@ -0,0 +1,66 @@
+package skim
+
+import "fmt"
+
+// formatAtom implements fmt.Formatter for a. The %v and %s verbs write a's String form and %#v
+// its GoString form, padded to the width given and aligned left by the - flag. A precision
+// truncates the text to that many runes, as it does for strings. %q writes the String form as a
+// quoted Go string.
+func formatAtom(f fmt.State, verb rune, a Atom) {
+	switch verb {
+	case 'v', 's', 'q':
+		s := fmtstring(a)
+		if verb == 'v' && f.Flag('#') {
+			s = fmtgostring(a)
+		}
+		if verb == 'v' {
+			verb = 's'
+		}
+		fmt.Fprintf(f, fmt.FormatString(f, verb), s)
+	default:
+		fmt.Fprintf(f, "%%!%c(%T=%s)", verb, a, fmtstring(a))
+	}
+}
+
+// Format implements fmt.Formatter. Integer verbs, such as %d, %x, and %c, format i as an int64;
+// other verbs are handled as they are for any atom.
+func (i Int) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'b', 'c', 'd', 'o', 'O', 'x', 'X', 'U':
+		fmt.Fprintf(f, fmt.FormatString(f, verb), int64(i))
+	default:
+		formatAtom(f, verb, i)
+	}
+}
+
+// Format implements fmt.Formatter. Floating-point verbs, such as %f, %e, and %g, format fl as a
+// float64 and honor its precision; other verbs are handled as they are for any atom.
+func (fl Float) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'b', 'e', 'E', 'f', 'F', 'g', 'G', 'x', 'X':
+		fmt.Fprintf(f, fmt.FormatString(f, verb), float64(fl))
+	default:
+		formatAtom(f, verb, fl)
+	}
+}
+
+// Format implements fmt.Formatter, writing s as it is written by String or GoString.
+func (s Symbol) Format(f fmt.State, verb rune) { formatAtom(f, verb, s) }
+
+// Format implements fmt.Formatter, writing s as it is written by String or GoString.
+func (s String) Format(f fmt.State, verb rune) { formatAtom(f, verb, s) }
+
+// Format implements fmt.Formatter, writing c as it is written by String or GoString.
+func (c *Cons) Format(f fmt.State, verb rune) { formatAtom(f, verb, c) }
+
+// Format implements fmt.Formatter, writing v as it is written by String or GoString.
+func (v Vector) Format(f fmt.State, verb rune) { formatAtom(f, verb, v) }
+
+// Format implements fmt.Formatter, writing m as it is written by String or GoString.
+func (m *HashMap) Format(f fmt.State, verb rune) { formatAtom(f, verb, m) }
+
+// Format implements fmt.Formatter, writing s as it is written by String or GoString.
+func (s *Set) Format(f fmt.State, verb rune) { formatAtom(f, verb, s) }
+
+// Format implements fmt.Formatter, writing r as it is written by String or GoString.
+func (r *Record) Format(f fmt.State, verb rune) { formatAtom(f, verb, r) }
@@ -0,0 +1,67 @@
+package skim
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		format string
+		arg    Atom
+		want   string
+	}{
+		{"%v", Symbol("a b"), "|a b|"},
+		{"%#v", Symbol("a b"), "a b"},
+		{"%6s|", Symbol("foo"), "   foo|"},
+		{"%-6s|", Symbol("foo"), "foo   |"},
+		{"%-8v|", Symbol("λx"), "λx      |"},
+		{"%.2s", Symbol("foo"), "fo"},
+		{"%q", Symbol("foo"), `"foo"`},
+		{"%8v|", String("a"), `     "a"|`},
+		{"%#v", String("a"), `"a"`},
+		{"%v", Int(42), "42"},
+		{"%5d", Int(42), "   42"},
+		{"%-5v|", Int(42), "42   |"},
+		{"%x", Int(255), "ff"},
+		{"%v", Float(2), "2.0"},
+		{"%.2f", Float(3.14159), "3.14"},
+		{"%8.3f", Float(-1.5), "  -1.500"},
+		{"%g", Float(1e21), "1e+21"},
+		{"%-6v|", Float(0.5), "0.5   |"},
+		{"%v", List(Int(1), Symbol("a")), "(1 a)"},
+		{"%#v", List(Int(1), Symbol("a")), "(1 . (a . #nil))"},
+		{"%9v|", List(Int(1), Symbol("a")), "    (1 a)|"},
+		{"%v", (*Cons)(nil), "()"},
+		{"%#v", (*Cons)(nil), "()"},
+		{"%-7v|", Vector{Int(1), String("b")}, `[1 "b"]|`},
+		{"%d", Symbol("a"), "%!d(skim.Symbol=a)"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprintf(c.format, c.arg); got != c.want {
+			t.Errorf("Sprintf(%q, %#v) = %q; want %q", c.format, c.arg, got, c.want)
+		}
+	}
+}
+
+func TestFormatTable(t *testing.T) {
+	rows := []struct {
+		name  Symbol
+		value Atom
+	}{
+		{"pi", Float(3.14159)},
+		{"answer", Int(42)},
+		{"greeting", String("hi")},
+	}
+	var got string
+	for _, r := range rows {
+		got += fmt.Sprintf("%-10v%6v\n", r.name, r.value)
+	}
+	const want = "" +
+		"pi        3.14159\n" +
+		"answer        42\n" +
+		"greeting    \"hi\"\n"
+	if got != want {
+		t.Errorf("table =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,198 @@
+package skim
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+// cyclicHashLimit is the number of atoms of a cyclic atom that Hash writes.
+const cyclicHashLimit = 4096
+
+// Type tags written by Hash before each atom, so that atoms of different types holding the same
+// value, such as the Int 1 and the Float 1.0, hash differently.
+const (
+	hashNil byte = iota
+	hashCons
+	hashVector
+	hashInt
+	hashFloat
+	hashString
+	hashSymbol
+	hashKeyword
+	hashChar
+	hashBool
+	hashBigInt
+	hashRational
+	hashBytes
+	hashError
+	hashMap
+	hashSet
+	hashRecord
+	hashOther
+)
+
+// Hash returns a hash of a for the given seed that is consistent with Equal: if Equal(a, b), then
+// Hash(a, seed) == Hash(b, seed). Hashes are only comparable if they use the same seed.
+//
+// Lists, vectors, maps, sets, and records are hashed by their structure and the atoms they hold,
+// and other atoms by their types and values, so the Int 1 and the Float 1.0 hash differently, as
+// they are not equal. Atoms of other types are hashed by their values if they are pointers, which
+// are hashed by identity, or have a basic kind, such as a string; otherwise, only their types are
+// hashed. Since atoms that are not comparable, such as procedures implemented as funcs, are never
+// equal, any hash is consistent for them.
+//
+// Hash keeps its own stack rather than recursing, and is safe to call on cyclic atoms: since a
+// cyclic atom is equal to any atom that unfolds to the same structure, only the first atoms of its
+// unfolding are hashed.
+func Hash(a Atom, seed maphash.Seed) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+
+	limit := -1
+	if Cyclic(a) {
+		limit = cyclicHashLimit
+	}
+
+	var buf [8]byte
+	writeUint64 := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+
+	stack := []Atom{a}
+	for len(stack) > 0 && limit != 0 {
+		a := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		limit--
+
+		if IsNil(a) {
+			h.WriteByte(hashNil)
+			continue
+		}
+
+		switch v := a.(type) {
+		case *Cons:
+			h.WriteByte(hashCons)
+			stack = append(stack, v.Cdr, v.Car)
+		case Vector:
+			h.WriteByte(hashVector)
+			writeUint64(uint64(len(v)))
+			for i := len(v) - 1; i >= 0; i-- {
+				stack = append(stack, v[i])
+			}
+		case Int:
+			h.WriteByte(hashInt)
+			writeUint64(uint64(v))
+		case Float:
+			h.WriteByte(hashFloat)
+			if v == 0 {
+				v = 0 // -0 is equal to 0
+			}
+			writeUint64(math.Float64bits(float64(v)))
+		case String:
+			h.WriteByte(hashString)
+			writeUint64(uint64(len(v)))
+			h.WriteString(string(v))
+		case Symbol:
+			h.WriteByte(hashSymbol)
+			writeUint64(uint64(len(v)))
+			h.WriteString(string(v))
+		case Keyword:
+			h.WriteByte(hashKeyword)
+			writeUint64(uint64(len(v)))
+			h.WriteString(string(v))
+		case Char:
+			h.WriteByte(hashChar)
+			writeUint64(uint64(v))
+		case Bool:
+			h.WriteByte(hashBool)
+			if v {
+				h.WriteByte(1)
+			} else {
+				h.WriteByte(0)
+			}
+		case BigInt:
+			h.WriteByte(hashBigInt)
+			if v.v != nil {
+				h.Write(v.v.Append(buf[:0], 16))
+			}
+		case Rational:
+			h.WriteByte(hashRational)
+			if v.v != nil {
+				h.Write(v.v.Num().Append(buf[:0], 16))
+				h.WriteByte('/')
+				h.Write(v.v.Denom().Append(buf[:0], 16))
+			}
+		case Bytes:
+			h.WriteByte(hashBytes)
+			writeUint64(uint64(len(v)))
+			h.Write(v)
+		case *Error:
+			h.WriteByte(hashError)
+			if v != nil {
+				writeUint64(uint64(len(v.Msg)))
+				h.WriteString(v.Msg)
+				stack = append(stack, v.Irritants)
+			}
+		case *HashMap:
+			// Entries are hashed in the order of their keys, which is the same for equal maps.
+			h.WriteByte(hashMap)
+			entries := v.sorted()
+			writeUint64(uint64(len(entries)))
+			for i := len(entries) - 1; i >= 0; i-- {
+				stack = append(stack, entries[i].Value, entries[i].Key)
+			}
+		case *Set:
+			h.WriteByte(hashSet)
+			elems := v.Elems()
+			writeUint64(uint64(len(elems)))
+			for i := len(elems) - 1; i >= 0; i-- {
+				stack = append(stack, elems[i])
+			}
+		case *Record:
+			h.WriteByte(hashRecord)
+			if v != nil {
+				writeUint64(uint64(reflect.ValueOf(v.Type).Pointer()))
+				stack = append(stack, Vector(v.Values))
+			}
+		default:
+			h.WriteByte(hashOther)
+			hashOtherAtom(&h, a, writeUint64)
+		}
+	}
+	return h.Sum64()
+}
+
+// hashOtherAtom writes the type of a, an atom of a type not defined by this package, and its value
+// if it is a pointer or has a basic kind.
+func hashOtherAtom(h *maphash.Hash, a Atom, writeUint64 func(uint64)) {
+	t := reflect.TypeOf(a)
+	h.WriteString(t.PkgPath())
+	h.WriteByte('.')
+	h.WriteString(t.String())
+	if !t.Comparable() {
+		return
+	}
+
+	v := reflect.ValueOf(a)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Chan:
+		writeUint64(uint64(v.Pointer()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); f != 0 {
+			writeUint64(math.Float64bits(f))
+		}
+	case reflect.String:
+		h.WriteString(v.String())
+	case reflect.Bool:
+		if v.Bool() {
+			h.WriteByte(1)
+		}
+	}
+}
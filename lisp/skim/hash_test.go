@@ -0,0 +1,154 @@
+package skim
+
+import (
+	"hash/maphash"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// randAtom returns a random atom of at most depth levels, drawn from few enough values that
+// equal atoms are often generated separately.
+func randAtom(r *rand.Rand, depth int) Atom {
+	n := 12
+	if depth <= 0 {
+		n = 9
+	}
+	switch r.Intn(n) {
+	case 0:
+		return Int(r.Intn(3))
+	case 1:
+		return Float(r.Intn(3))
+	case 2:
+		return Symbol("ab"[r.Intn(2):][:1])
+	case 3:
+		return String("ab"[r.Intn(2):][:1])
+	case 4:
+		return Bool(r.Intn(2) == 0)
+	case 5:
+		return NewBigInt(new(big.Int).Lsh(big.NewInt(int64(r.Intn(2))), 70))
+	case 6:
+		return Bytes("ab"[r.Intn(2):])
+	case 7:
+		return nil
+	case 8:
+		return List()
+	case 9:
+		v := make(Vector, r.Intn(3))
+		for i := range v {
+			v[i] = randAtom(r, depth-1)
+		}
+		return v
+	case 10:
+		return &Cons{Car: randAtom(r, depth-1), Cdr: randAtom(r, depth-1)}
+	default:
+		elems := make([]Atom, r.Intn(3))
+		for i := range elems {
+			elems[i] = randAtom(r, depth-1)
+		}
+		return List(elems...)
+	}
+}
+
+func TestHashEqual(t *testing.T) {
+	seed := maphash.MakeSeed()
+	r := rand.New(rand.NewSource(1))
+
+	corpus := []Atom{
+		Float(0), Float(math.Copysign(0, -1)),
+		List(nil), List(), nil, (*Cons)(nil),
+		List(Symbol("a"), nil), List(Symbol("a")),
+		NewBigInt(big.NewInt(1)), NewBigInt(big.NewInt(1)),
+		NewRational(big.NewRat(1, 2)), NewRational(big.NewRat(2, 4)),
+		Int(1), Float(1), Char('a'), Keyword("a"), Symbol("a"), String("a"),
+	}
+	for i := 0; i < 2000; i++ {
+		a := randAtom(r, 3)
+		corpus = append(corpus, a, Dup(a))
+	}
+
+	// Cyclic atoms that unfold to the same structure are equal.
+	one := &Cons{Car: Symbol("a")}
+	one.Cdr = one
+	two := &Cons{Car: Symbol("a"), Cdr: &Cons{Car: Symbol("a")}}
+	two.Cdr.(*Cons).Cdr = two
+	vec := Vector{Int(1), nil}
+	vec[1] = vec
+	corpus = append(corpus, one, two, Dup(one), vec, Dup(vec))
+
+	hashes := make([]uint64, len(corpus))
+	for i, a := range corpus {
+		hashes[i] = Hash(a, seed)
+		if again := Hash(a, seed); again != hashes[i] {
+			t.Fatalf("Hash(%v) = %x, then %x", a, hashes[i], again)
+		}
+	}
+
+	equal, distinct := 0, map[uint64]bool{}
+	for i, a := range corpus {
+		distinct[hashes[i]] = true
+		for j, b := range corpus[:i] {
+			if !Equal(a, b) {
+				continue
+			}
+			equal++
+			if hashes[i] != hashes[j] {
+				t.Errorf("Equal(%v, %v), but Hash() = %x and %x", a, b, hashes[i], hashes[j])
+			}
+		}
+	}
+	if equal < len(corpus)/2 {
+		t.Errorf("corpus holds %d equal pairs; want at least %d", equal, len(corpus)/2)
+	}
+	if len(distinct) < 100 {
+		t.Errorf("corpus of %d atoms has %d distinct hashes; want at least 100", len(corpus), len(distinct))
+	}
+}
+
+func TestHashDistinct(t *testing.T) {
+	seed := maphash.MakeSeed()
+	cases := [][2]Atom{
+		{Int(1), Float(1)},
+		{Symbol("a"), String("a")},
+		{Symbol("a"), Keyword("a")},
+		{List(Int(1), Int(2)), Vector{Int(1), Int(2)}},
+		{List(Int(1), Int(2)), &Cons{Car: Int(1), Cdr: Int(2)}},
+		{Vector{String("ab"), String("c")}, Vector{String("a"), String("bc")}},
+		{List(List(Int(1)), Int(2)), List(Int(1), Int(2))},
+		{NewRational(big.NewRat(1, 2)), NewRational(big.NewRat(1, 3))},
+	}
+	for _, c := range cases {
+		if Hash(c[0], seed) == Hash(c[1], seed) {
+			t.Errorf("Hash(%v) == Hash(%v); want distinct", c[0], c[1])
+		}
+	}
+
+	// Atoms of other types hash by identity, if they are pointers, and never panic.
+	p, q := &ptrAtom{1}, &ptrAtom{1}
+	if Hash(p, seed) != Hash(p, seed) || Hash(p, seed) == Hash(q, seed) {
+		t.Errorf("Hash(%p) and Hash(%p) do not hash by identity", p, q)
+	}
+	_ = Hash(funcAtom(func() {}), seed)
+}
+
+// ptrAtom is an atom of a type not defined by this package that is compared by identity.
+type ptrAtom struct{ n int }
+
+func (*ptrAtom) SkimAtom()      {}
+func (*ptrAtom) String() string { return "#<ptr>" }
+
+func TestHashDeep(t *testing.T) {
+	const depth = 1 << 16
+	var list, vec Atom = Int(0), Int(0)
+	for i := 0; i < depth; i++ {
+		list = &Cons{Car: list}
+		vec = Vector{vec}
+	}
+	seed := maphash.MakeSeed()
+	for _, a := range []Atom{list, vec} {
+		if Hash(a, seed) != Hash(Dup(a), seed) {
+			t.Errorf("Hash(%.20s...) != Hash(Dup(%.20s...))", a, a)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package skim
+
+import (
+	"fmt"
+	"hash/maphash"
+	"sort"
+)
+
+// HashMap is a mutable map of atoms to atoms. Keys are matched by Equal and hashed by Hash, so any
+// two keys that are Equal, such as two lists holding the same elements, are the same key. Keys that
+// hash alike but are not Equal are kept apart.
+//
+// A HashMap is written as {key value ...}, with its entries sorted by their keys, as ordered by
+// Compare, so that equal maps are always written alike. Keys held by a HashMap must not be changed
+// while they are held. The zero HashMap is empty and ready to use. A HashMap is not safe for
+// concurrent use.
+type HashMap struct {
+	seed    maphash.Seed
+	buckets map[uint64][]mapEntry // entries by the Hash of their keys
+	n       int
+}
+
+// mapEntry is an entry of a HashMap.
+type mapEntry struct {
+	Key, Value Atom
+}
+
+// NewHashMap returns a new, empty HashMap.
+func NewHashMap() *HashMap {
+	return new(HashMap)
+}
+
+func (*HashMap) SkimAtom() {}
+
+func (m *HashMap) String() string {
+	w := newWriter(m, false)
+	w.hashMap(m)
+	return w.String()
+}
+
+// GoString returns the map written as {key value ...}, with its keys and values written by their
+// GoString methods.
+func (m *HashMap) GoString() string {
+	w := newWriter(m, true)
+	w.hashMap(m)
+	return w.String()
+}
+
+// Len returns the number of entries in m.
+func (m *HashMap) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.n
+}
+
+// Get returns the value of key in m and whether m holds key.
+func (m *HashMap) Get(key Atom) (value Atom, ok bool) {
+	if m.Len() == 0 {
+		return nil, false
+	}
+	bucket := m.buckets[Hash(key, m.seed)]
+	if i := findEntry(bucket, key); i >= 0 {
+		return bucket[i].Value, true
+	}
+	return nil, false
+}
+
+// Set sets the value of key in m, replacing any value it already has. It returns an error if key
+// cannot be hashed: if key is cyclic, or if it is not equal to itself, such as a procedure, the Float
+// NaN, or a list holding either, since such a key could never be found again.
+func (m *HashMap) Set(key, value Atom) error {
+	if err := checkHashable(key, "map key"); err != nil {
+		return err
+	}
+	m.set(key, value)
+	return nil
+}
+
+// checkHashable returns an error if a cannot be hashed for use as a map key or set element, named
+// by what.
+func checkHashable(a Atom, what string) error {
+	if Cyclic(a) {
+		return fmt.Errorf("skim: cannot use cyclic %T as a %s", a, what)
+	} else if !Equal(a, a) {
+		return fmt.Errorf("skim: cannot use %T as a %s; it is not equal to itself", a, what)
+	}
+	return nil
+}
+
+// set sets the value of key in m, which must be hashable.
+func (m *HashMap) set(key, value Atom) {
+	if m.buckets == nil {
+		m.seed = maphash.MakeSeed()
+		m.buckets = make(map[uint64][]mapEntry)
+	}
+	h := Hash(key, m.seed)
+	bucket := m.buckets[h]
+	if i := findEntry(bucket, key); i >= 0 {
+		bucket[i].Value = value
+		return
+	}
+	m.buckets[h] = append(bucket, mapEntry{key, value})
+	m.n++
+}
+
+// Delete removes key from m, returning whether m held it.
+func (m *HashMap) Delete(key Atom) bool {
+	if m.Len() == 0 {
+		return false
+	}
+	h := Hash(key, m.seed)
+	bucket := m.buckets[h]
+	i := findEntry(bucket, key)
+	if i < 0 {
+		return false
+	}
+	if len(bucket) == 1 {
+		delete(m.buckets, h)
+	} else {
+		last := len(bucket) - 1
+		bucket[i], bucket[last] = bucket[last], mapEntry{}
+		m.buckets[h] = bucket[:last]
+	}
+	m.n--
+	return true
+}
+
+// Keys returns the keys of m, sorted by Compare.
+func (m *HashMap) Keys() []Atom {
+	entries := m.sorted()
+	keys := make([]Atom, len(entries))
+	for i, e := range entries {
+		keys[i] = e.Key
+	}
+	return keys
+}
+
+// Dup returns a deep copy of m, as (*Cons).Dup does for lists: its keys and values are copied, and
+// if m is cyclic, so is the copy.
+func (m *HashMap) Dup() Atom {
+	if m == nil {
+		return m
+	} else if Cyclic(m) {
+		return dupAtom(m, make(map[interface{}]Atom))
+	}
+	return dupAtom(m, nil)
+}
+
+// sorted returns the entries of m, sorted by their keys. Since no two keys are Equal, no two keys
+// are ordered alike by Compare.
+func (m *HashMap) sorted() []mapEntry {
+	entries := make([]mapEntry, 0, m.Len())
+	if m == nil {
+		return entries
+	}
+	for _, bucket := range m.buckets {
+		entries = append(entries, bucket...)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return Compare(entries[i].Key, entries[j].Key) < 0
+	})
+	return entries
+}
+
+// findEntry returns the index of the entry in bucket whose key is Equal to key, or -1 if there is
+// none.
+func findEntry(bucket []mapEntry, key Atom) int {
+	for i, e := range bucket {
+		if Equal(e.Key, key) {
+			return i
+		}
+	}
+	return -1
+}
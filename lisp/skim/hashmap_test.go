@@ -0,0 +1,302 @@
+package skim
+
+import (
+	"errors"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// mapOf returns a HashMap holding the keys and values kvs, [key value ...].
+func mapOf(t *testing.T, kvs ...Atom) *HashMap {
+	t.Helper()
+	m := NewHashMap()
+	for i := 0; i < len(kvs); i += 2 {
+		if err := m.Set(kvs[i], kvs[i+1]); err != nil {
+			t.Fatalf("Set(%v, %v) = %v", kvs[i], kvs[i+1], err)
+		}
+	}
+	return m
+}
+
+func TestHashMap(t *testing.T) {
+	var m HashMap
+	if m.Len() != 0 || m.String() != "{}" {
+		t.Fatalf("zero HashMap = %v with Len() = %d; want {}", &m, m.Len())
+	}
+	if _, ok := m.Get(Int(1)); ok {
+		t.Fatal("zero HashMap holds 1")
+	}
+
+	for _, kv := range [][2]Atom{
+		{Int(1), Symbol("int")},
+		{Float(1), Symbol("float")},
+		{String("a"), Symbol("string")},
+		{Symbol("a"), Symbol("symbol")},
+		{List(Int(1), Int(2)), Symbol("list")},
+		{nil, Symbol("nil")},
+	} {
+		if err := m.Set(kv[0], kv[1]); err != nil {
+			t.Fatalf("Set(%v, %v) = %v", kv[0], kv[1], err)
+		}
+	}
+
+	cases := []struct {
+		key  Atom
+		want Atom
+		ok   bool
+	}{
+		{Int(1), Symbol("int"), true},
+		{Float(1), Symbol("float"), true},
+		{String("a"), Symbol("string"), true},
+		{Symbol("a"), Symbol("symbol"), true},
+		{List(Int(1), Int(2)), Symbol("list"), true}, // Equal, not identical
+		{List(), Symbol("nil"), true},
+		{Keyword("a"), nil, false},
+		{Vector{Int(1), Int(2)}, nil, false},
+		{Int(2), nil, false},
+	}
+	for _, c := range cases {
+		if got, ok := m.Get(c.key); ok != c.ok || !Equal(got, c.want) {
+			t.Errorf("Get(%v) = %v, %t; want %v, %t", c.key, got, ok, c.want, c.ok)
+		}
+	}
+	if m.Len() != 6 {
+		t.Errorf("Len() = %d; want 6", m.Len())
+	}
+
+	if err := m.Set(List(Int(1), Int(2)), Symbol("replaced")); err != nil {
+		t.Fatal(err)
+	} else if got, _ := m.Get(List(Int(1), Int(2))); got != Symbol("replaced") || m.Len() != 6 {
+		t.Errorf("after replacing (1 2), Get((1 2)) = %v and Len() = %d; want replaced, 6", got, m.Len())
+	}
+
+	if !m.Delete(Float(1)) || m.Delete(Float(1)) {
+		t.Error("Delete(1.0) did not delete 1.0 once")
+	}
+	if _, ok := m.Get(Float(1)); ok || m.Len() != 5 {
+		t.Errorf("after Delete(1.0), Get(1.0) = %t and Len() = %d; want false, 5", ok, m.Len())
+	}
+
+	want := []Atom{nil, Int(1), String("a"), Symbol("a"), List(Int(1), Int(2))}
+	if got := m.Keys(); !Equal(VectorOf(got), VectorOf(want)) {
+		t.Errorf("Keys() = %v; want %v", got, want)
+	}
+}
+
+func TestHashMapCollisions(t *testing.T) {
+	// Place an entry whose key is not Equal to a in the bucket of a, as if their hashes collided.
+	a, b := Symbol("a"), Symbol("b")
+	m := mapOf(t, a, Int(1))
+	h := Hash(a, m.seed)
+	m.buckets[h] = append([]mapEntry{{b, Int(0)}}, m.buckets[h]...)
+	m.n++
+
+	if got, ok := m.Get(a); !ok || got != Int(1) {
+		t.Errorf("Get(a) = %v, %t; want 1, true", got, ok)
+	}
+	if err := m.Set(a, Int(2)); err != nil {
+		t.Fatal(err)
+	} else if got, _ := m.Get(a); got != Int(2) || m.Len() != 2 || len(m.buckets[h]) != 2 {
+		t.Errorf("after Set(a, 2), Get(a) = %v and Len() = %d; want 2, 2", got, m.Len())
+	}
+	if !m.Delete(a) {
+		t.Fatal("Delete(a) = false")
+	} else if want := []mapEntry{{b, Int(0)}}; !reflect.DeepEqual(m.buckets[h], want) {
+		t.Errorf("after Delete(a), bucket = %v; want %v", m.buckets[h], want)
+	}
+	if _, ok := m.Get(a); ok || m.Len() != 1 {
+		t.Errorf("after Delete(a), Get(a) = %t and Len() = %d; want false, 1", ok, m.Len())
+	}
+}
+
+func TestHashMapUnhashable(t *testing.T) {
+	cyclic := &Cons{Car: Int(1)}
+	cyclic.Cdr = cyclic
+	fn := funcAtom(func() {})
+
+	cases := []struct {
+		name string
+		key  Atom
+	}{
+		{"cyclic", cyclic},
+		{"func", fn},
+		{"nan", Float(math.NaN())},
+		{"list-of-func", List(Int(1), fn)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := NewHashMap()
+			if err := m.Set(c.key, Int(1)); err == nil {
+				t.Errorf("Set(%v, 1) = nil; want an error", c.key)
+			} else if m.Len() != 0 {
+				t.Errorf("Set(%v, 1) failed, but Len() = %d", c.key, m.Len())
+			}
+		})
+	}
+
+	// Cyclic values are allowed.
+	m := NewHashMap()
+	if err := m.Set(Symbol("self"), cyclic); err != nil {
+		t.Errorf("Set(self, %v) = %v", cyclic, err)
+	}
+}
+
+func TestHashMapString(t *testing.T) {
+	cases := []struct {
+		name string
+		in   *HashMap
+		want string
+		gos  string
+	}{
+		{"nil", nil, "{}", "{}"},
+		{"empty", NewHashMap(), "{}", "{}"},
+		{
+			"sorted",
+			mapOf(t, Symbol("b"), Int(2), String("a"), Vector{Int(1)}, Int(3), List(Symbol("x"))),
+			`{3 (x) "a" [1] b 2}`,
+			`{3 (x . #nil) "a" [1] b 2}`,
+		},
+		{"nested", mapOf(t, Keyword("k"), mapOf(t, Int(1), Int(2))), "{:k {1 2}}", "{:k {1 2}}"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.in.String(); got != c.want {
+				t.Errorf("String() = %s; want %s", got, c.want)
+			}
+			if got := c.in.GoString(); got != c.gos {
+				t.Errorf("GoString() = %s; want %s", got, c.gos)
+			}
+		})
+	}
+
+	cyclic := NewHashMap()
+	if err := cyclic.Set(Symbol("self"), List(cyclic)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cyclic.String(), "#0={self (#0#)}"; got != want {
+		t.Errorf("String() = %s; want %s", got, want)
+	}
+}
+
+func TestHashMapWalk(t *testing.T) {
+	m := mapOf(t, Symbol("b"), List(Int(2)), Symbol("a"), Int(1))
+
+	var walked []Atom
+	if err := Walk(m, func(a Atom) error { walked = append(walked, a); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Vector{Symbol("a"), Int(1), Symbol("b"), List(Int(2))}); !Equal(VectorOf(walked), want) {
+		t.Errorf("Walk(%v) visited %v; want %v", m, walked, want)
+	}
+
+	stop := errors.New("stop")
+	if err := Walk(m, func(Atom) error { return stop }); err != stop {
+		t.Errorf("Walk(%v) = %v; want %v", m, err, stop)
+	}
+
+	type visit struct {
+		depth int
+		atom  string
+	}
+	var traversed []visit
+	var visitor CtxVisitor
+	visitor = func(ctx VisitContext, a Atom) (CtxVisitor, error) {
+		traversed = append(traversed, visit{ctx.Depth, fmtstring(a)})
+		return visitor, nil
+	}
+	if err := TraverseCtx(m, nil, visitor); err != nil {
+		t.Fatal(err)
+	}
+	want := []visit{{0, "{a 1 b (2)}"}, {1, "a"}, {1, "1"}, {1, "b"}, {1, "(2)"}, {2, "2"}}
+	if !reflect.DeepEqual(traversed, want) {
+		t.Errorf("TraverseCtx(%v) visited %v; want %v", m, traversed, want)
+	}
+}
+
+func TestHashMapDup(t *testing.T) {
+	key := List(Int(1))
+	m := mapOf(t, key, Vector{String("v")}, Symbol("m"), mapOf(t, Int(1), Int(2)))
+	d, ok := Dup(m).(*HashMap)
+	if !ok || d == m {
+		t.Fatalf("Dup(%v) = %#v; want a new *HashMap", m, d)
+	} else if !Equal(d, m) {
+		t.Fatalf("Dup(%v) = %v; want an equal map", m, d)
+	}
+
+	if got := d.Keys()[1]; got == key {
+		t.Errorf("Dup(%v) shares the key %v", m, key)
+	}
+	v, _ := d.Get(List(Int(1)))
+	v.(Vector)[0] = String("changed")
+	inner, _ := d.Get(Symbol("m"))
+	if err := inner.(*HashMap).Set(Int(3), Int(4)); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{m {1 2} (1) ["v"]}`; m.String() != want {
+		t.Errorf("changing the copy changed the original to %v; want %s", m, want)
+	}
+
+	cyclic := NewHashMap()
+	if err := cyclic.Set(Symbol("self"), Vector{cyclic}); err != nil {
+		t.Fatal(err)
+	}
+	dc := Dup(cyclic).(*HashMap)
+	if self, _ := dc.Get(Symbol("self")); self.(Vector)[0] != dc {
+		t.Errorf("Dup(%v) = %v; want a map holding itself", cyclic, dc)
+	}
+	if (*HashMap)(nil).Dup() != (*HashMap)(nil) {
+		t.Error("Dup of a nil *HashMap is not nil")
+	}
+}
+
+func TestHashMapEqual(t *testing.T) {
+	a := mapOf(t, Int(1), String("one"), List(Int(2)), Vector{Int(3)})
+	b := mapOf(t, List(Int(2)), Vector{Int(3)}, Int(1), String("one")) // same entries, set in reverse
+	cases := []struct {
+		name string
+		a, b Atom
+		want bool
+	}{
+		{"same", a, a, true},
+		{"reordered", a, b, true},
+		{"dup", a, Dup(a), true},
+		{"empty", NewHashMap(), NewHashMap(), true},
+		{"nil-empty", (*HashMap)(nil), NewHashMap(), true},
+		{"value", a, mapOf(t, Int(1), String("uno"), List(Int(2)), Vector{Int(3)}), false},
+		{"key", a, mapOf(t, Float(1), String("one"), List(Int(2)), Vector{Int(3)}), false},
+		{"fewer", a, mapOf(t, Int(1), String("one")), false},
+		{"vector", mapOf(t, Int(1), Int(2)), Vector{Int(1), Int(2)}, false},
+		{"alist", mapOf(t, Int(1), Int(2)), List(&Cons{Car: Int(1), Cdr: Int(2)}), false},
+	}
+	seed := maphash.MakeSeed()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Equal(c.a, c.b); got != c.want {
+				t.Errorf("Equal(%v, %v) = %t; want %t", c.a, c.b, got, c.want)
+			}
+			if got := Compare(c.a, c.b) == 0; got != c.want {
+				t.Errorf("Compare(%v, %v) = %d; want 0 = %t", c.a, c.b, Compare(c.a, c.b), c.want)
+			}
+			if c.want && Hash(c.a, seed) != Hash(c.b, seed) {
+				t.Errorf("Hash(%v) != Hash(%v)", c.a, c.b)
+			}
+		})
+	}
+
+	ordered := []Atom{
+		Vector{Int(9)},
+		NewHashMap(),
+		mapOf(t, Symbol("a"), Int(1)),
+		mapOf(t, Symbol("a"), Int(1), Symbol("b"), Int(0)),
+		mapOf(t, Symbol("a"), Int(2)),
+		mapOf(t, Symbol("b"), Int(0)),
+		&ptrAtom{},
+	}
+	for i := 1; i < len(ordered); i++ {
+		if c := Compare(ordered[i-1], ordered[i]); c != -1 {
+			t.Errorf("Compare(%v, %v) = %d; want -1", ordered[i-1], ordered[i], c)
+		}
+	}
+}
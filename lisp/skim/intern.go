@@ -0,0 +1,21 @@
+package skim
+
+import "unique"
+
+// Intern returns the symbol s, sharing its text with other symbols interned with the same text, so
+// that programs holding many copies of a symbol hold its text only once. Interned symbols are
+// ordinary Symbols: an interned symbol is equal to a Symbol converted from the same text, such as a
+// literal.
+//
+// Symbols are interned with the unique package, so the table of interned text is not kept forever:
+// once the garbage collector finds that text is no longer being interned, it may drop it, and the
+// next symbol interned with that text holds a new copy. Intern is safe for concurrent use.
+func Intern(s string) Symbol {
+	return Symbol(unique.Make(s).Value())
+}
+
+// InternBytes is Intern for text held in a byte slice. It does not allocate if the symbol is
+// already interned, and does not retain b.
+func InternBytes(b []byte) Symbol {
+	return Symbol(unique.Make(string(b)).Value())
+}
@@ -0,0 +1,75 @@
+package skim
+
+import (
+	"hash/maphash"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"unique"
+	"unsafe"
+)
+
+func TestIntern(t *testing.T) {
+	// Hold the interned text so that it is not dropped by a collection during the test.
+	defer runtime.KeepAlive(unique.Make("abab"))
+	a := Intern(strings.Repeat("ab", 2))
+	b := InternBytes([]byte("abab"))
+	if a != Symbol("abab") || b != Symbol("abab") {
+		t.Errorf("Intern(abab), InternBytes(abab) = %v, %v; want abab", a, b)
+	}
+	seed := maphash.MakeSeed()
+	if !Equal(a, Symbol("abab")) || Hash(a, seed) != Hash(Symbol("abab"), seed) {
+		t.Errorf("interned %v is not equal to the literal symbol", a)
+	}
+	if unsafe.StringData(string(a)) != unsafe.StringData(string(b)) {
+		t.Error("Intern and InternBytes returned symbols with distinct text")
+	}
+
+	// InternBytes does not retain its argument.
+	buf := []byte("scratch")
+	sym := InternBytes(buf)
+	copy(buf, "changed")
+	if sym != "scratch" || Intern("scratch") != "scratch" {
+		t.Errorf("InternBytes(scratch) = %v after changing its argument; want scratch", sym)
+	}
+}
+
+func TestInternConcurrent(t *testing.T) {
+	const n = 8
+	defer runtime.KeepAlive(unique.Make("concurrent-symbol"))
+	syms := make([]Symbol, n)
+	var wg sync.WaitGroup
+	for i := range syms {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			syms[i] = InternBytes([]byte("concurrent-symbol"))
+		}()
+	}
+	wg.Wait()
+	for _, sym := range syms[1:] {
+		if unsafe.StringData(string(sym)) != unsafe.StringData(string(syms[0])) {
+			t.Fatalf("concurrently interned symbols %v do not share their text", syms)
+		}
+	}
+}
+
+var internSink Symbol
+
+func BenchmarkIntern(b *testing.B) {
+	text := []byte("symbol-to-intern")
+	Intern(string(text))
+	b.Run("bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			InternBytes(text)
+		}
+	})
+	b.Run("convert", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			internSink = Symbol(text)
+		}
+	})
+}
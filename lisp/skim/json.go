@@ -0,0 +1,350 @@
+package skim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// JSONOptions configures how atoms are encoded as and decoded from JSON. The MarshalJSON methods of
+// atoms and FromJSON use the zero JSONOptions.
+type JSONOptions struct {
+	// SymbolKey is the key of the object a Symbol is encoded as, such as {"sym":"name"}. An object
+	// whose only member has this key and a string value is decoded as a Symbol. If empty, "sym" is
+	// used.
+	SymbolKey string
+}
+
+func (o JSONOptions) symbolKey() string {
+	if o.SymbolKey == "" {
+		return "sym"
+	}
+	return o.SymbolKey
+}
+
+// The keys of the object that a cons pair of an improper list is encoded as in JSON.
+const (
+	jsonCarKey = "car"
+	jsonCdrKey = "cdr"
+)
+
+// jsonKeyEscape begins each key of an object that would otherwise be decoded as a Symbol or cons
+// pair, so that it is decoded as an association list. It is removed from the keys of such objects
+// only, and other keys are decoded as they are written.
+const jsonKeyEscape = "~"
+
+// jsonItem is an item of the stack used by appendJSON: either an atom to encode or, if lit is not
+// empty, literal text to write.
+type jsonItem struct {
+	lit  string
+	atom Atom
+}
+
+// Marshal returns the JSON encoding of a. Int, BigInt, and Float are encoded as numbers, Bool as a
+// boolean, String as a string, nil as null, and vectors and proper lists as arrays. A Symbol is
+// encoded as an object whose only key is o.SymbolKey, and each cons pair of an improper list as an
+// object with car and cdr keys. Other atoms are encoded by their MarshalJSON method, if they have
+// one.
+func (o JSONOptions) Marshal(a Atom) ([]byte, error) {
+	return o.appendJSON(nil, a)
+}
+
+// appendJSON appends the JSON encoding of a to b, as described by Marshal. Lists and vectors are
+// encoded with a stack of their own, rather than by recursion, so that deeply nested atoms can be
+// encoded.
+func (o JSONOptions) appendJSON(b []byte, a Atom) ([]byte, error) {
+	if Cyclic(a) {
+		return nil, fmt.Errorf("skim: cannot marshal cyclic %T as JSON", a)
+	}
+	stack := []jsonItem{{atom: a}}
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if item.lit != "" {
+			b = append(b, item.lit...)
+			continue
+		}
+
+		switch a := item.atom.(type) {
+		case nil:
+			b = append(b, "null"...)
+		case Int:
+			b = strconv.AppendInt(b, int64(a), 10)
+		case BigInt:
+			b = append(b, a.String()...)
+		case Float:
+			f := float64(a)
+			if math.IsInf(f, 0) || math.IsNaN(f) {
+				return nil, fmt.Errorf("skim: cannot marshal %v as JSON", a)
+			}
+			b = appendJSONFloat(b, f)
+		case Bool:
+			b = strconv.AppendBool(b, bool(a))
+		case String:
+			b = appendJSONString(b, string(a))
+		case Symbol:
+			b = append(b, '{')
+			b = appendJSONString(b, o.symbolKey())
+			b = append(b, ':')
+			b = appendJSONString(b, string(a))
+			b = append(b, '}')
+		case Vector:
+			b = append(b, '[')
+			stack = append(stack, jsonItem{lit: "]"})
+			for i := len(a) - 1; i >= 0; i-- {
+				stack = append(stack, jsonItem{atom: a[i]})
+				if i > 0 {
+					stack = append(stack, jsonItem{lit: ","})
+				}
+			}
+		case *Cons:
+			if IsNil(a) {
+				b = append(b, "[]"...)
+			} else if isProperList(a) {
+				b = append(b, '[')
+				n := len(stack)
+				for c := a; !IsNil(c); c, _ = c.Cdr.(*Cons) {
+					if len(stack) > n {
+						stack = append(stack, jsonItem{lit: ","})
+					}
+					stack = append(stack, jsonItem{atom: c.Car})
+				}
+				stack = append(stack, jsonItem{lit: "]"})
+				reverseJSONItems(stack[n:])
+			} else {
+				stack = append(stack,
+					jsonItem{lit: "}"},
+					jsonItem{atom: a.Cdr},
+					jsonItem{lit: `,"` + jsonCdrKey + `":`},
+					jsonItem{atom: a.Car},
+				)
+				b = append(b, `{"`+jsonCarKey+`":`...)
+			}
+		case json.Marshaler:
+			p, err := a.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, p...)
+		default:
+			return nil, fmt.Errorf("skim: cannot marshal %T as JSON", a)
+		}
+	}
+	return b, nil
+}
+
+// isProperList returns whether c ends in an empty list.
+func isProperList(c *Cons) bool {
+	for {
+		switch cdr := c.Cdr.(type) {
+		case nil:
+			return true
+		case *Cons:
+			if IsNil(cdr) {
+				return true
+			}
+			c = cdr
+		default:
+			return false
+		}
+	}
+}
+
+func reverseJSONItems(items []jsonItem) {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+}
+
+// appendJSONFloat appends f as a JSON number. Integral floats keep a decimal point so that they
+// are decoded as floats.
+func appendJSONFloat(b []byte, f float64) []byte {
+	n := len(b)
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		b = strconv.AppendFloat(b, f, 'e', -1, 64)
+	} else {
+		b = strconv.AppendFloat(b, f, 'f', -1, 64)
+	}
+	if bytes.IndexAny(b[n:], ".e") == -1 {
+		b = append(b, ".0"...)
+	}
+	return b
+}
+
+func appendJSONString(b []byte, s string) []byte {
+	p, _ := json.Marshal(s) // Marshaling a string cannot fail.
+	return append(b, p...)
+}
+
+func (i Int) MarshalJSON() ([]byte, error)    { return JSONOptions{}.Marshal(i) }
+func (b BigInt) MarshalJSON() ([]byte, error) { return JSONOptions{}.Marshal(b) }
+func (f Float) MarshalJSON() ([]byte, error)  { return JSONOptions{}.Marshal(f) }
+func (b Bool) MarshalJSON() ([]byte, error)   { return JSONOptions{}.Marshal(b) }
+func (s String) MarshalJSON() ([]byte, error) { return JSONOptions{}.Marshal(s) }
+func (s Symbol) MarshalJSON() ([]byte, error) { return JSONOptions{}.Marshal(s) }
+func (v Vector) MarshalJSON() ([]byte, error) { return JSONOptions{}.Marshal(v) }
+
+// MarshalJSON encodes c as a JSON array if it is a proper list. A nil *Cons is encoded as an empty
+// array, and each cons pair of an improper list as an object, {"car":...,"cdr":...}.
+func (c *Cons) MarshalJSON() ([]byte, error) { return JSONOptions{}.Marshal(c) }
+
+// jsonFrame is an array or object being decoded by FromJSON.
+type jsonFrame struct {
+	object bool
+	keys   []string
+	elems  []Atom
+}
+
+// FromJSON decodes data, which must hold a single JSON value, as an atom, using the zero
+// JSONOptions.
+func FromJSON(data []byte) (Atom, error) {
+	return JSONOptions{}.FromJSON(data)
+}
+
+// FromJSON decodes data, which must hold a single JSON value, as an atom. It is the reverse of
+// Marshal: numbers are decoded as Int, or as BigInt or Float if they do not fit an Int or have a
+// fraction or exponent, arrays as lists, and null as nil.
+//
+// Objects are decoded as a Symbol if their only key is o.SymbolKey and its value is a string, and
+// as a cons pair if their keys are car and cdr. Other objects are decoded as association lists
+// whose keys are strings, (("key" . value) ...), with their members in order. An object that would
+// be decoded as a Symbol or cons pair, such as {"sym":"a"}, is decoded as an association list if
+// each of its keys begins with ~, as in {"~sym":"a"}, which is decoded as (("sym" . "a")). The ~ is
+// removed only from such objects, and only once, so {"~~sym":"a"} is decoded as (("~sym" . "a"))
+// and {"~home":1} as (("~home" . 1)).
+//
+// Association lists are encoded by Marshal as lists of cons pairs, not objects, so an object such
+// as {"a":1} is decoded as (("a" . 1)) and marshaled again as [{"car":"a","cdr":1}], which decodes
+// as the same atom. An empty object is decoded as the empty list, which is marshaled as [].
+func (o JSONOptions) FromJSON(data []byte) (Atom, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var (
+		stack  []*jsonFrame
+		result Atom
+		done   bool
+	)
+	for !done {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+
+		var a Atom
+		switch tok := tok.(type) {
+		case json.Delim:
+			switch tok {
+			case '[', '{':
+				stack = append(stack, &jsonFrame{object: tok == '{'})
+				continue
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if top.object {
+				a = top.atom(o.symbolKey())
+			} else {
+				a = List(top.elems...)
+			}
+		case json.Number:
+			a = jsonNumber(tok)
+		case string:
+			if top := len(stack) - 1; top >= 0 && stack[top].object && len(stack[top].keys) == len(stack[top].elems) {
+				stack[top].keys = append(stack[top].keys, tok)
+				continue
+			}
+			a = String(tok)
+		case bool:
+			a = Bool(tok)
+		case nil:
+			a = nil
+		}
+
+		if len(stack) == 0 {
+			result, done = a, true
+		} else {
+			top := stack[len(stack)-1]
+			top.elems = append(top.elems, a)
+		}
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("skim: invalid JSON: more than one value")
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// atom returns the atom that the object f is decoded as, where symKey is the key of a Symbol.
+func (f *jsonFrame) atom(symKey string) Atom {
+	if a, ok := jsonTagged(f.keys, f.elems, symKey); ok {
+		return a
+	}
+	escaped := jsonEscaped(f.keys, f.elems, symKey)
+	pairs := make([]Atom, len(f.keys))
+	for i, key := range f.keys {
+		if escaped {
+			key = key[len(jsonKeyEscape):]
+		}
+		pairs[i] = &Cons{Car: String(key), Cdr: f.elems[i]}
+	}
+	return List(pairs...)
+}
+
+// jsonTagged returns the Symbol or cons pair that an object with the given keys and values is
+// decoded as, if any, where symKey is the key of a Symbol.
+func jsonTagged(keys []string, elems []Atom, symKey string) (Atom, bool) {
+	switch {
+	case len(keys) == 1 && keys[0] == symKey:
+		if s, ok := elems[0].(String); ok {
+			return Symbol(s), true
+		}
+	case len(keys) == 2 && keys[0] == jsonCarKey && keys[1] == jsonCdrKey:
+		return &Cons{Car: elems[0], Cdr: elems[1]}, true
+	case len(keys) == 2 && keys[0] == jsonCdrKey && keys[1] == jsonCarKey:
+		return &Cons{Car: elems[1], Cdr: elems[0]}, true
+	}
+	return nil, false
+}
+
+// jsonEscaped returns whether each of keys begins with jsonKeyEscape and, with it removed, the
+// object would be decoded as a Symbol or cons pair or its keys would be escaped in turn.
+func jsonEscaped(keys []string, elems []Atom, symKey string) bool {
+	for len(keys) == 1 || len(keys) == 2 {
+		unescaped := make([]string, len(keys))
+		for i, key := range keys {
+			if !strings.HasPrefix(key, jsonKeyEscape) {
+				return false
+			}
+			unescaped[i] = key[len(jsonKeyEscape):]
+		}
+		if _, ok := jsonTagged(unescaped, elems, symKey); ok {
+			return true
+		}
+		keys = unescaped
+	}
+	return false
+}
+
+// jsonNumber returns the atom that the JSON number n is decoded as.
+func jsonNumber(n json.Number) Atom {
+	if !strings.ContainsAny(string(n), ".eE") {
+		if i, err := n.Int64(); err == nil {
+			return Int(i)
+		} else if v, ok := new(big.Int).SetString(string(n), 10); ok {
+			return NewBigInt(v)
+		}
+	}
+	f, _ := n.Float64() // Numbers too large for a float64 are decoded as infinities.
+	return Float(f)
+}
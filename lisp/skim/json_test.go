@@ -0,0 +1,228 @@
+package skim
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	cases := []struct {
+		in   Atom
+		want string
+	}{
+		{Int(-1), `-1`},
+		{NewBigInt(n), `123456789012345678901234567890`},
+		{Float(1.5), `1.5`},
+		{Float(2), `2.0`},
+		{Float(1e21), `1e+21`},
+		{Bool(true), `true`},
+		{String("a\"b<"), `"a\"b\u003c"`},
+		{Symbol("foo"), `{"sym":"foo"}`},
+		{Symbol("sym"), `{"sym":"sym"}`},
+		{Vector{}, `[]`},
+		{Vector{Int(1), nil, String("x")}, `[1,null,"x"]`},
+		{Nil, `[]`},
+		{List(Int(1), Vector{Int(2)}, List(Int(3))), `[1,[2],[3]]`},
+		{&Cons{Car: Int(1), Cdr: Int(2)}, `{"car":1,"cdr":2}`},
+		{&Cons{Car: Int(1), Cdr: &Cons{Car: Int(2), Cdr: Int(3)}}, `{"car":1,"cdr":{"car":2,"cdr":3}}`},
+		{List(&Cons{Car: Symbol("a"), Cdr: Int(1)}), `[{"car":{"sym":"a"},"cdr":1}]`},
+	}
+	for _, c := range cases {
+		got, err := json.Marshal(c.in)
+		if err != nil {
+			t.Errorf("Marshal(%v) err = %v; want nil", c.in, err)
+		} else if string(got) != c.want {
+			t.Errorf("Marshal(%v) = %s; want %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestMarshalJSONError(t *testing.T) {
+	cyclic := &Cons{Car: Int(1)}
+	cyclic.Cdr = cyclic
+	cyclic2 := make([]Atom, smallAtom*2)
+	for i := range cyclic2 {
+		cyclic2[i] = Int(i)
+	}
+	last := List(cyclic2...).(*Cons)
+	for last.Cdr != nil {
+		last = last.Cdr.(*Cons)
+	}
+	last.Cdr = cyclic
+
+	cases := []Atom{
+		Float(math.NaN()),
+		Float(math.Inf(1)),
+		List(Int(1), Float(math.Inf(-1))),
+		List(Keyword("k")),
+		cyclic,
+		last,
+	}
+	for _, a := range cases {
+		if got, err := json.Marshal(a); err == nil {
+			t.Errorf("Marshal(%#v) = %s; want error", a, got)
+		}
+	}
+}
+
+func TestMarshalJSONDeep(t *testing.T) {
+	const depth = 100000
+	var a Atom = Int(1)
+	for i := 0; i < depth; i++ {
+		a = &Cons{Car: a}
+	}
+	got, err := a.(*Cons).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() err = %v; want nil", err)
+	}
+	if want := 2*depth + 1; len(got) != want {
+		t.Fatalf("len(MarshalJSON()) = %d; want %d", len(got), want)
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Atom
+	}{
+		{`null`, nil},
+		{`1`, Int(1)},
+		{`-1.0`, Float(-1)},
+		{`1e2`, Float(100)},
+		{`true`, Bool(true)},
+		{`"x"`, String("x")},
+		{`[]`, Nil},
+		{`[1, [2]]`, List(Int(1), List(Int(2)))},
+		{`{"sym": "a"}`, Symbol("a")},
+		{`{"sym": 1}`, List(&Cons{Car: String("sym"), Cdr: Int(1)})},
+		{`{"sym": "a", "b": 1}`, List(&Cons{Car: String("sym"), Cdr: String("a")}, &Cons{Car: String("b"), Cdr: Int(1)})},
+		{`{"cdr": 2, "car": 1}`, &Cons{Car: Int(1), Cdr: Int(2)}},
+		{`{"b": 1, "a": [true]}`, List(&Cons{Car: String("b"), Cdr: Int(1)}, &Cons{Car: String("a"), Cdr: List(Bool(true))})},
+		{`{"~sym": "a"}`, List(&Cons{Car: String("sym"), Cdr: String("a")})},
+		{`{"~car": 1, "~cdr": 2}`, List(&Cons{Car: String("car"), Cdr: Int(1)}, &Cons{Car: String("cdr"), Cdr: Int(2)})},
+		{`{"~~sym": "a"}`, List(&Cons{Car: String("~sym"), Cdr: String("a")})},
+		{`{"~~~car": 1, "~~~cdr": 2}`, List(&Cons{Car: String("~~car"), Cdr: Int(1)}, &Cons{Car: String("~~cdr"), Cdr: Int(2)})},
+		{`{"~sym": 1}`, List(&Cons{Car: String("~sym"), Cdr: Int(1)})},
+		{`{"~car": 1, "cdr": 2}`, List(&Cons{Car: String("~car"), Cdr: Int(1)}, &Cons{Car: String("cdr"), Cdr: Int(2)})},
+		{`{"~~a": 1}`, List(&Cons{Car: String("~~a"), Cdr: Int(1)})},
+		{`{"~home": "/root"}`, List(&Cons{Car: String("~home"), Cdr: String("/root")})},
+		{`{"~": 1}`, List(&Cons{Car: String("~"), Cdr: Int(1)})},
+		{`{}`, Nil},
+	}
+	for _, c := range cases {
+		got, err := FromJSON([]byte(c.in))
+		if err != nil {
+			t.Errorf("FromJSON(%s) err = %v; want nil", c.in, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("FromJSON(%s) = %#v; want %#v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestFromJSONBigInt(t *testing.T) {
+	const n = "123456789012345678901234567890"
+	got, err := FromJSON([]byte(n))
+	if err != nil {
+		t.Fatalf("FromJSON(%s) err = %v; want nil", n, err)
+	}
+	if b, ok := got.(BigInt); !ok || b.String() != n {
+		t.Fatalf("FromJSON(%s) = %#v; want BigInt %s", n, got, n)
+	}
+}
+
+func TestFromJSONError(t *testing.T) {
+	for _, in := range []string{``, `[1`, `{"a"}`, `1 2`, `[1,]`} {
+		if got, err := FromJSON([]byte(in)); err == nil {
+			t.Errorf("FromJSON(%q) = %v; want error", in, got)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	doc := List(
+		List(Symbol("define"), Symbol("server"),
+			List(Symbol("quote"), List(
+				&Cons{Car: Symbol("host"), Cdr: String("example.com")},
+				&Cons{Car: Symbol("port"), Cdr: Int(8080)},
+				&Cons{Car: Symbol("ratio"), Cdr: Float(0.75)},
+				&Cons{Car: Symbol("weight"), Cdr: Float(3)},
+				&Cons{Car: Symbol("tls"), Cdr: Bool(false)},
+			))),
+		List(Symbol("sym"), nil, Nil),
+		&Cons{Car: Int(1), Cdr: &Cons{Car: Int(2), Cdr: Symbol("rest")}},
+	)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal() err = %v; want nil", err)
+	}
+	got, err := FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON(%s) err = %v; want nil", data, err)
+	}
+	if got.String() != doc.String() {
+		t.Fatalf("FromJSON(Marshal(doc)) = %v; want %v\nJSON: %s", got, doc, data)
+	}
+	again, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal(FromJSON()) err = %v; want nil", err)
+	} else if string(again) != string(data) {
+		t.Fatalf("Marshal(FromJSON()) = %s; want %s", again, data)
+	}
+}
+
+// TestJSONObjectRoundTrip checks that objects decode as association lists that marshal, as lists
+// of cons pairs, to JSON decoding as the same atoms.
+func TestJSONObjectRoundTrip(t *testing.T) {
+	cases := []struct {
+		in, marshaled string
+	}{
+		{`{"a":1}`, `[{"car":"a","cdr":1}]`},
+		{`{"~home":"/root","b":[true]}`, `[{"car":"~home","cdr":"/root"},["b",true]]`},
+		{`{"~sym":"a"}`, `[{"car":"sym","cdr":"a"}]`},
+		{`{"~car":1,"~cdr":{"x":null}}`, `[{"car":"car","cdr":1},["cdr",["x"]]]`},
+	}
+	for _, c := range cases {
+		want, err := FromJSON([]byte(c.in))
+		if err != nil {
+			t.Fatalf("FromJSON(%s) err = %v; want nil", c.in, err)
+		}
+		data, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("Marshal(%v) err = %v; want nil", want, err)
+		} else if string(data) != c.marshaled {
+			t.Errorf("Marshal(FromJSON(%s)) = %s; want %s", c.in, data, c.marshaled)
+		}
+		if got, err := FromJSON(data); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("FromJSON(Marshal(FromJSON(%s))) = %v, %v; want %v, nil", c.in, got, err, want)
+		}
+	}
+}
+
+func TestJSONSymbolKey(t *testing.T) {
+	opts := JSONOptions{SymbolKey: "$sym"}
+	data, err := opts.Marshal(List(Symbol("a"), String("b")))
+	if err != nil {
+		t.Fatalf("Marshal() err = %v; want nil", err)
+	} else if want := `[{"$sym":"a"},"b"]`; string(data) != want {
+		t.Fatalf("Marshal() = %s; want %s", data, want)
+	}
+
+	cases := []struct {
+		opts JSONOptions
+		want Atom
+	}{
+		{opts, List(Symbol("a"), String("b"))},
+		{JSONOptions{}, List(List(&Cons{Car: String("$sym"), Cdr: String("a")}), String("b"))},
+	}
+	for _, c := range cases {
+		if got, err := c.opts.FromJSON(data); err != nil {
+			t.Errorf("%+v.FromJSON(%s) err = %v; want nil", c.opts, data, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%+v.FromJSON(%s) = %v; want %v", c.opts, data, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package skim
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Keyword is a self-evaluating symbol-like atom, written with a leading colon (e.g., :port). The
+// Keyword's value does not include the colon. Keywords that are empty or hold whitespace or
+// delimiters are quoted by pipes after the colon, as symbols are (e.g., :|first name|).
+type Keyword string
+
+func (Keyword) SkimAtom() {}
+
+func (k Keyword) String() string {
+	if k.isBare() {
+		return ":" + string(k)
+	}
+	return ":" + Symbol(k).quote()
+}
+
+func (k Keyword) GoString() string { return k.String() }
+
+// isBare returns whether k may be written without pipes.
+func (k Keyword) isBare() bool {
+	if k == "" {
+		return false
+	}
+	for _, r := range k {
+		if unicode.IsSpace(r) || strings.ContainsRune(symbolSentinels, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// PlistGet returns the first value of the keyword k in the property list, list, and whether it
+// occurs. The whole list is checked, so PlistGet returns the same errors as PlistToMap.
+func PlistGet(list Atom, k Keyword) (value Atom, ok bool, err error) {
+	err = walkPlist(list, func(key Keyword, v Atom) {
+		if key == k && !ok {
+			value, ok = v, true
+		}
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return value, ok, nil
+}
+
+// PlistToMap converts a property list, a list of alternating keywords and values such as
+// (:host "x" :port 8080), to a map of keywords to values. If a keyword occurs more than once, its
+// first value is kept. It returns an error if the list is not a proper list, has an odd number of
+// elements, or has a key that is not a Keyword.
+func PlistToMap(list Atom) (map[Keyword]Atom, error) {
+	plist := make(map[Keyword]Atom)
+	err := walkPlist(list, func(key Keyword, v Atom) {
+		if _, dup := plist[key]; !dup {
+			plist[key] = v
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plist, nil
+}
+
+// walkPlist calls fn for each keyword and value of the property list, list, in order.
+func walkPlist(list Atom, fn func(Keyword, Atom)) error {
+	var (
+		key Keyword
+		i   int
+	)
+	err := Walk(list, func(a Atom) error {
+		defer func() { i++ }()
+		if i%2 == 1 {
+			fn(key, a)
+			return nil
+		}
+
+		k, ok := a.(Keyword)
+		if !ok {
+			return fmt.Errorf("skim: plist: element %d is a %T, not a Keyword", i, a)
+		}
+		key = k
+		return nil
+	})
+	if err != nil {
+		return err
+	} else if i%2 == 1 {
+		return fmt.Errorf("skim: plist: keyword %v at element %d has no value", key, i-1)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package skim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlistToMap(t *testing.T) {
+	type testcase struct {
+		in      Atom
+		want    map[Keyword]Atom
+		wanterr bool
+	}
+
+	cases := map[string]testcase{
+		"nil":   {in: nil, want: map[Keyword]Atom{}},
+		"empty": {in: List(), want: map[Keyword]Atom{}},
+		"list": {
+			in:   List(Keyword("host"), String("x"), Keyword("port"), Int(8080)),
+			want: map[Keyword]Atom{"host": String("x"), "port": Int(8080)},
+		},
+		"vector": {
+			in:   Vector{Keyword("port"), Int(8080)},
+			want: map[Keyword]Atom{"port": Int(8080)},
+		},
+		"duplicate-first-wins": {
+			in:   List(Keyword("a"), Int(1), Keyword("a"), Int(2)),
+			want: map[Keyword]Atom{"a": Int(1)},
+		},
+		"odd-length":  {in: List(Keyword("a"), Int(1), Keyword("b")), wanterr: true},
+		"non-keyword": {in: List(Keyword("a"), Int(1), Symbol("b"), Int(2)), wanterr: true},
+		"improper":    {in: &Cons{Keyword("a"), Int(1)}, wanterr: true},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			got, err := PlistToMap(c.in)
+			if (err != nil) != c.wanterr {
+				t.Fatalf("PlistToMap(%v) err = %v; want error = %t", c.in, err, c.wanterr)
+			} else if !c.wanterr && !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("PlistToMap(%v) = %v; want %v", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestPlistGet(t *testing.T) {
+	plist := List(Keyword("host"), String("x"), Keyword("port"), Int(8080), Keyword("host"), String("y"))
+	cases := []struct {
+		in      Atom
+		key     Keyword
+		want    Atom
+		wantok  bool
+		wanterr string
+	}{
+		{in: plist, key: "host", want: String("x"), wantok: true},
+		{in: plist, key: "port", want: Int(8080), wantok: true},
+		{in: plist, key: "user"},
+		{in: nil, key: "host"},
+		{in: List(), key: "host"},
+		{in: Vector{Keyword("port"), nil}, key: "port", want: nil, wantok: true},
+		{
+			in:      List(Keyword("a"), Int(1), Keyword("b")),
+			key:     "a",
+			wanterr: "skim: plist: keyword :b at element 2 has no value",
+		},
+		{
+			in:      List(Keyword("a"), Int(1), Symbol("b"), Int(2)),
+			key:     "a",
+			wanterr: "skim: plist: element 2 is a skim.Symbol, not a Keyword",
+		},
+	}
+	for _, c := range cases {
+		got, ok, err := PlistGet(c.in, c.key)
+		if c.wanterr != "" {
+			if err == nil || err.Error() != c.wanterr {
+				t.Errorf("PlistGet(%v, %v) err = %v; want %s", c.in, c.key, err, c.wanterr)
+			}
+		} else if err != nil || ok != c.wantok || got != c.want {
+			t.Errorf("PlistGet(%v, %v) = %v, %t, %v; want %v, %t, nil", c.in, c.key, got, ok, err, c.want, c.wantok)
+		}
+	}
+}
+
+func TestKeywordString(t *testing.T) {
+	cases := map[Keyword]string{
+		"port":       ":port",
+		":":          "::",
+		"1":          ":1",
+		"":           ":||",
+		"first name": ":|first name|",
+		"a)":         ":|a)|",
+		"x|y\n":      `:|x\|y\n|`,
+		"tab\t":      `:|tab\t|`,
+	}
+	for k, want := range cases {
+		if got := k.String(); got != want {
+			t.Errorf("Keyword(%q).String() = %q; want %q", string(k), got, want)
+		} else if got := k.GoString(); got != want {
+			t.Errorf("Keyword(%q).GoString() = %q; want %q", string(k), got, want)
+		}
+	}
+}
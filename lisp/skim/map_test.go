@@ -32,6 +32,14 @@ func TestMap(t *testing.T) {
 			fn:      requireNoCall,
 		},
 
+		{
+			name:    "not-mapper",
+			in:      Int(1),
+			want:    nil,
+			wanterr: errors.New("skim: cannot map skim.Int; does not implement Mapper"),
+			fn:      addOne,
+		},
+
 		// cons
 		{
 			name:    "cons/mapfn-error",
@@ -61,6 +69,20 @@ func TestMap(t *testing.T) {
 			wanterr: nil,
 			fn:      addOne,
 		},
+		{
+			name:    "cons/dotted-add-1",
+			in:      &Cons{Car: Int(1), Cdr: &Cons{Car: Int(2), Cdr: Int(3)}},
+			want:    &Cons{Car: Int(2), Cdr: &Cons{Car: Int(3), Cdr: Int(3)}},
+			wanterr: nil,
+			fn:      addOne,
+		},
+		{
+			name:    "cons/pair-add-1",
+			in:      &Cons{Car: Int(1), Cdr: Int(3)},
+			want:    &Cons{Car: Int(2), Cdr: Int(3)},
+			wanterr: nil,
+			fn:      addOne,
+		},
 
 		// vector
 		{
@@ -108,3 +130,151 @@ func TestMap(t *testing.T) {
 		})
 	}
 }
+
+func TestConsMapAllocs(t *testing.T) {
+	elems := make(Vector, 1000)
+	for i := range elems {
+		elems[i] = Int(i)
+	}
+	list := List(elems...).(*Cons)
+	var identity MapFunc = func(a Atom) (Atom, error) { return a, nil }
+
+	var got Atom
+	allocs := testing.AllocsPerRun(10, func() {
+		got, _ = list.Map(identity)
+	})
+	if allocs != 1 {
+		t.Errorf("Map(list of %d) allocated %v times; want 1", len(elems), allocs)
+	}
+	if n, err := Length(got); err != nil || n != len(elems) {
+		t.Errorf("Length(Map(list of %d)) = %d, %v; want %d, nil", len(elems), n, err, len(elems))
+	}
+}
+
+func TestFilter(t *testing.T) {
+	type testCase struct {
+		name    string
+		in      Atom
+		want    Atom
+		wanterr error
+		fn      func(Atom) (bool, error)
+	}
+
+	requireNoCall := func(Atom) (bool, error) {
+		return false, errors.New("filter was called")
+	}
+
+	odd := func(a Atom) (bool, error) {
+		return a.(Int)%2 == 1, nil
+	}
+
+	cases := []testCase{
+		{name: "nil", in: nil, want: nil, fn: requireNoCall},
+		{name: "empty", in: Nil, want: Nil, fn: requireNoCall},
+		{name: "not-list", in: Int(1), wanterr: errors.New("skim: length: skim.Int is not a list"), fn: odd},
+		{name: "fn-nil", in: List(Int(1)), wanterr: errors.New("skim: filter: keep function is nil")},
+
+		// cons
+		{name: "cons/fn-error", in: List(Int(1), Int(2)), wanterr: errors.New("filter was called"), fn: requireNoCall},
+		{name: "cons/odd", in: List(Int(1), Int(2), Int(3), Int(4)), want: List(Int(1), Int(3)), fn: odd},
+		{name: "cons/none", in: List(Int(2), Int(4)), want: Nil, fn: odd},
+		{
+			name:    "cons/dotted",
+			in:      &Cons{Car: Int(1), Cdr: &Cons{Car: Int(2), Cdr: Int(3)}},
+			wanterr: errors.New("skim: length: improper list of 2 elements ends in skim.Int"),
+			fn:      requireNoCall,
+		},
+
+		// vector
+		{name: "vector/fn-error", in: Vector{Int(1)}, wanterr: errors.New("filter was called"), fn: requireNoCall},
+		{name: "vector/odd", in: Vector{Int(1), Int(2), Int(3)}, want: Vector{Int(1), Int(3)}, fn: odd},
+		{name: "vector/none", in: Vector{Int(2)}, want: Vector{}, fn: odd},
+		{name: "vector/nil", in: Vector(nil), want: Vector(nil), fn: requireNoCall},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			switch got, goterr := Filter(c.in, c.fn); {
+			case (goterr == nil) != (c.wanterr == nil) || (goterr != nil && goterr.Error() != c.wanterr.Error()):
+				t.Fatalf("Filter( %v ) err = %v; want %v", c.in, goterr, c.wanterr)
+
+			case !Equal(got, c.want) || reflect.TypeOf(got) != reflect.TypeOf(c.want):
+				t.Fatalf("Filter( %v ) = %v; want %v", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestFilterAllocs(t *testing.T) {
+	elems := make(Vector, 1000)
+	for i := range elems {
+		elems[i] = Int(i)
+	}
+	list := List(elems...)
+	even := func(a Atom) (bool, error) { return a.(Int)%2 == 0, nil }
+
+	var got Atom
+	allocs := testing.AllocsPerRun(10, func() {
+		got, _ = Filter(list, even)
+	})
+	if allocs != 1 {
+		t.Errorf("Filter(list of %d) allocated %v times; want 1", len(elems), allocs)
+	}
+	if n, err := Length(got); err != nil || n != len(elems)/2 {
+		t.Errorf("Length(Filter(list of %d)) = %d, %v; want %d, nil", len(elems), n, err, len(elems)/2)
+	}
+}
+
+func TestFold(t *testing.T) {
+	type testCase struct {
+		name    string
+		in      Atom
+		want    Atom
+		wanterr error
+		fn      func(acc, elem Atom) (Atom, error)
+	}
+
+	requireNoCall := func(Atom, Atom) (Atom, error) {
+		return nil, errors.New("fold was called")
+	}
+
+	// cons onto the accumulator to show that elements are folded from first to last.
+	push := func(acc, elem Atom) (Atom, error) {
+		return &Cons{Car: elem, Cdr: acc}, nil
+	}
+
+	cases := []testCase{
+		{name: "nil", in: nil, want: Nil, fn: requireNoCall},
+		{name: "empty", in: Nil, want: Nil, fn: requireNoCall},
+		{name: "not-list", in: Int(1), wanterr: errors.New("skim: length: skim.Int is not a list"), fn: push},
+		{name: "fn-nil", in: List(Int(1)), wanterr: errors.New("skim: fold: fold function is nil")},
+
+		// cons
+		{name: "cons/fn-error", in: List(Int(1), Int(2)), wanterr: errors.New("fold was called"), fn: requireNoCall},
+		{name: "cons/push", in: List(Int(1), Int(2), Int(3)), want: List(Int(3), Int(2), Int(1)), fn: push},
+		{
+			name:    "cons/dotted",
+			in:      &Cons{Car: Int(1), Cdr: Int(2)},
+			wanterr: errors.New("skim: length: improper list of 1 elements ends in skim.Int"),
+			fn:      requireNoCall,
+		},
+
+		// vector
+		{name: "vector/fn-error", in: Vector{Int(1)}, wanterr: errors.New("fold was called"), fn: requireNoCall},
+		{name: "vector/push", in: Vector{Int(1), Int(2)}, want: List(Int(2), Int(1)), fn: push},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			switch got, goterr := Fold(c.in, Nil, c.fn); {
+			case (goterr == nil) != (c.wanterr == nil) || (goterr != nil && goterr.Error() != c.wanterr.Error()):
+				t.Fatalf("Fold( %v ) err = %v; want %v", c.in, goterr, c.wanterr)
+
+			case !Equal(got, c.want):
+				t.Fatalf("Fold( %v ) = %v; want %v", c.in, got, c.want)
+			}
+		})
+	}
+}
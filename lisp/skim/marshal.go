@@ -0,0 +1,331 @@
+package skim
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SkimMarshaler is implemented by types that convert themselves to an atom when passed to Marshal.
+type SkimMarshaler interface {
+	MarshalSkim() (Atom, error)
+}
+
+// MarshalOptions configures Marshal. The zero MarshalOptions marshals maps and structs as
+// association lists.
+type MarshalOptions struct {
+	// Plists, if true, marshals maps and structs as property lists, (:key value ...), rather than
+	// association lists, ((key . value) ...).
+	Plists bool
+
+	// Foreign, if true, marshals non-nil pointers, channels, functions, and unsafe pointers as
+	// *Foreign atoms holding them, named by their types, rather than as the values they point to
+	// or an error. Values implementing Atom or SkimMarshaler are still converted as they are.
+	Foreign bool
+}
+
+// Marshal converts v to an atom using the zero MarshalOptions.
+func Marshal(v interface{}) (Atom, error) {
+	return MarshalOptions{}.Marshal(v)
+}
+
+// Marshal converts v to an atom using reflection.
+//
+// Values implementing SkimMarshaler are converted by their MarshalSkim method, and atoms are
+// returned as they are. Otherwise, bools are converted to Bool, integers to Int, or BigInt if they
+// do not fit an Int, floats to Float, strings to String, and []byte to Bytes. Other slices and
+// arrays are converted to vectors. Pointers and interfaces are converted to the value they hold,
+// and nil pointers, interfaces, slices, and maps to nil.
+//
+// Maps and structs are converted to association lists, or property lists whose keys are keywords
+// if o.Plists is set. Map keys must be strings, which are converted to symbols, or numbers, and are
+// sorted. Struct fields are written in the order they are declared, and only exported fields
+// are converted. The skim struct tag names a field, as in `skim:"name"`; a tag of "-" skips the
+// field, and the omitempty option skips it if it is false, zero, nil, or empty. The fields of
+// embedded structs without a name in their tag are converted as if they were fields of the outer
+// struct.
+//
+// Channels, functions, complex numbers, and unsafe pointers cannot be converted, unless o.Foreign
+// is set, and values that contain themselves are cyclic; both are errors that name the path to the
+// value, such as .Server.Handlers[1].
+func (o MarshalOptions) Marshal(v interface{}) (Atom, error) {
+	m := &marshaler{opts: o, visiting: make(map[visitKey]bool)}
+	return m.marshal(reflect.ValueOf(v), "")
+}
+
+type marshaler struct {
+	opts     MarshalOptions
+	visiting map[visitKey]bool // pointers, maps, and slices being marshaled
+}
+
+// visitKey identifies a pointer, map, or slice being marshaled.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+var (
+	atomType          = reflect.TypeOf((*Atom)(nil)).Elem()
+	skimMarshalerType = reflect.TypeOf((*SkimMarshaler)(nil)).Elem()
+)
+
+func (m *marshaler) errorf(path, format string, args ...interface{}) error {
+	if path == "" {
+		path = "."
+	}
+	return fmt.Errorf("skim: cannot marshal %s at %s", fmt.Sprintf(format, args...), path)
+}
+
+func (m *marshaler) marshal(v reflect.Value, path string) (Atom, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	if v.CanInterface() {
+		if v.Type().Implements(skimMarshalerType) {
+			return v.Interface().(SkimMarshaler).MarshalSkim()
+		} else if v.CanAddr() && v.Addr().Type().Implements(skimMarshalerType) {
+			return v.Addr().Interface().(SkimMarshaler).MarshalSkim()
+		} else if v.Type().Implements(atomType) {
+			return v.Interface().(Atom), nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if m.opts.Foreign && v.CanInterface() {
+			return NewForeign(v.Type().String(), v.Interface()), nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return Bool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u > math.MaxInt64 {
+			return NewBigInt(new(big.Int).SetUint64(u)), nil
+		}
+		return Int(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return Float(v.Float()), nil
+	case reflect.String:
+		return String(v.String()), nil
+	case reflect.Interface:
+		return m.marshal(v.Elem(), path)
+	case reflect.Ptr:
+		if err := m.enter(v, path); err != nil {
+			return nil, err
+		}
+		defer m.leave(v)
+		return m.marshal(v.Elem(), path)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return Bytes(v.Bytes()).Dup(), nil
+		}
+		if err := m.enter(v, path); err != nil {
+			return nil, err
+		}
+		defer m.leave(v)
+		return m.vector(v, path)
+	case reflect.Array:
+		return m.vector(v, path)
+	case reflect.Map:
+		if err := m.enter(v, path); err != nil {
+			return nil, err
+		}
+		defer m.leave(v)
+		return m.mapList(v, path)
+	case reflect.Struct:
+		var keys, values []Atom
+		if err := m.fields(v, path, &keys, &values); err != nil {
+			return nil, err
+		}
+		return m.object(keys, values), nil
+	}
+	return nil, m.errorf(path, "%v", v.Type())
+}
+
+// enter records that the pointer, map, or slice v is being marshaled, returning an error if it is
+// already being marshaled.
+func (m *marshaler) enter(v reflect.Value, path string) error {
+	key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	if m.visiting[key] {
+		return m.errorf(path, "cyclic %v", v.Type())
+	}
+	m.visiting[key] = true
+	return nil
+}
+
+func (m *marshaler) leave(v reflect.Value) {
+	key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	delete(m.visiting, key)
+}
+
+func (m *marshaler) vector(v reflect.Value, path string) (Atom, error) {
+	vec := make(Vector, v.Len())
+	for i := range vec {
+		a, err := m.marshal(v.Index(i), path+"["+strconv.Itoa(i)+"]")
+		if err != nil {
+			return nil, err
+		}
+		vec[i] = a
+	}
+	return vec, nil
+}
+
+func (m *marshaler) mapList(v reflect.Value, path string) (Atom, error) {
+	keys := v.MapKeys()
+	names := make([]Atom, len(keys))
+	for i, key := range keys {
+		switch key.Kind() {
+		case reflect.String:
+			names[i] = Symbol(key.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			names[i], _ = m.marshal(key, path)
+		default:
+			return nil, m.errorf(path, "map key type %v", key.Type())
+		}
+	}
+
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := keys[order[i]], keys[order[j]]
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		}
+		return a.String() < b.String()
+	})
+
+	sortedNames := make([]Atom, len(keys))
+	values := make([]Atom, len(keys))
+	for i, k := range order {
+		a, err := m.marshal(v.MapIndex(keys[k]), path+"["+mapKeyPath(names[k])+"]")
+		if err != nil {
+			return nil, err
+		}
+		sortedNames[i], values[i] = names[k], a
+	}
+	return m.object(sortedNames, values), nil
+}
+
+// mapKeyPath returns the map key name as it is written in the path to a map value.
+func mapKeyPath(name Atom) string {
+	if sym, ok := name.(Symbol); ok {
+		return strconv.Quote(string(sym))
+	}
+	return name.String()
+}
+
+// fields appends the names and values of the fields of the struct v to keys and values.
+func (m *marshaler) fields(v reflect.Value, path string, keys *[]Atom, values *[]Atom) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("skim")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if fv.Kind() == reflect.Ptr {
+					if fv.IsNil() {
+						continue
+					}
+					fv = fv.Elem()
+				}
+				if err := m.fields(fv, path, keys, values); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if hasOption(opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+		a, err := m.marshal(fv, path+"."+f.Name)
+		if err != nil {
+			return err
+		}
+		*keys, *values = append(*keys, Symbol(name)), append(*values, a)
+	}
+	return nil
+}
+
+func hasOption(opts, opt string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// object returns the association or property list of keys and values. Keys are symbols or
+// numbers, which are written as keywords in property lists.
+func (m *marshaler) object(keys []Atom, values []Atom) Atom {
+	elems := make([]Atom, 0, len(keys)*2)
+	for i, key := range keys {
+		if !m.opts.Plists {
+			elems = append(elems, &Cons{Car: key, Cdr: values[i]})
+		} else if sym, ok := key.(Symbol); ok {
+			elems = append(elems, Keyword(sym), values[i])
+		} else {
+			elems = append(elems, Keyword(key.String()), values[i])
+		}
+	}
+	return List(elems...)
+}
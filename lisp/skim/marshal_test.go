@@ -0,0 +1,138 @@
+package skim
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+type marshalPort struct {
+	Number   int    `skim:"number"`
+	Protocol string `skim:"protocol,omitempty"`
+}
+
+type marshalBase struct {
+	Name string `skim:"name"`
+}
+
+type marshalDuration int64
+
+func (d marshalDuration) MarshalSkim() (Atom, error) {
+	return List(Symbol("seconds"), Int(d)), nil
+}
+
+type marshalService struct {
+	marshalBase
+	Ports   []marshalPort          `skim:"ports"`
+	Limits  map[string][]int       `skim:"limits"`
+	Extra   interface{}            `skim:"extra"`
+	Timeout marshalDuration        `skim:"timeout"`
+	Labels  map[string]string      `skim:"labels,omitempty"`
+	Parent  *marshalService        `skim:"parent,omitempty"`
+	Script  Atom                   `skim:"script"`
+	Skipped bool                   `skim:"-"`
+	Weights map[int]float64        `skim:"weights"`
+	Raw     []byte                 `skim:"raw"`
+	Meta    map[string]interface{} `skim:"meta,omitempty"`
+	hidden  int
+}
+
+func TestMarshal(t *testing.T) {
+	svc := marshalService{
+		marshalBase: marshalBase{Name: "api"},
+		Ports:       []marshalPort{{Number: 80, Protocol: "http"}, {Number: 443}},
+		Limits:      map[string][]int{"mem": {512, 1024}, "cpu": {1}},
+		Extra:       []interface{}{"x", 1.5, nil, true},
+		Timeout:     30,
+		Script:      List(Symbol("display"), String("hi")),
+		Skipped:     true,
+		Weights:     map[int]float64{10: 0.5, 2: 1},
+		Raw:         []byte("hi"),
+		hidden:      1,
+	}
+	cases := []struct {
+		name string
+		opts MarshalOptions
+		in   interface{}
+		want string
+	}{
+		{"nil", MarshalOptions{}, nil, "#nil"},
+		{"scalars", MarshalOptions{}, []interface{}{uint8(1), int16(-2), float32(0.5), "s", false, uint64(math.MaxUint64)},
+			`[1 -2 0.5 "s" #f 18446744073709551615]`},
+		{"array", MarshalOptions{}, [2]bool{true, false}, "[#t #f]"},
+		{"map of slices", MarshalOptions{}, map[string][]int{"b": {1, 2}, "a": nil, "c": {}},
+			"((a) (b . [1 2]) (c . []))"},
+		{"pointer", MarshalOptions{}, &marshalPort{Number: 1}, "{number 1}"},
+		{"atom", MarshalOptions{}, Symbol("x"), "x"},
+		{"struct", MarshalOptions{}, svc,
+			`((name . "api") (ports . [{number 80 protocol "http"} {number 443}]) ` +
+				`(limits (cpu . [1]) (mem . [512 1024])) (extra . ["x" 1.5 #nil #t]) (timeout seconds 30) ` +
+				`(script display "hi") (weights (2 . 1.0) (10 . 0.5)) (raw . #u8(104 105)))`},
+		{"plist", MarshalOptions{Plists: true}, svc,
+			`(:name "api" :ports [(:number 80 :protocol "http") (:number 443)] ` +
+				`:limits (:cpu [1] :mem [512 1024]) :extra ["x" 1.5 #nil #t] :timeout (seconds 30) ` +
+				`:script (display "hi") :weights (:2 1.0 :10 0.5) :raw #u8(104 105))`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := c.opts.Marshal(c.in)
+			if err != nil {
+				t.Fatalf("Marshal(%#v) err = %v; want nil", c.in, err)
+			} else if got := fmtstring(a); got != c.want {
+				t.Fatalf("Marshal(%#v) =\n%s\nwant\n%s", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestMarshalShared(t *testing.T) {
+	port := &marshalPort{Number: 1}
+	a, err := Marshal([]*marshalPort{port, port})
+	if err != nil {
+		t.Fatalf("Marshal() err = %v; want nil", err)
+	} else if got, want := a.String(), "[{number 1} {number 1}]"; got != want {
+		t.Fatalf("Marshal() = %s; want %s", got, want)
+	}
+}
+
+type marshalError struct{}
+
+func (marshalError) MarshalSkim() (Atom, error) { return nil, errors.New("marshal error") }
+
+func TestMarshalError(t *testing.T) {
+	type handlers struct {
+		Handlers []interface{}
+	}
+	type server struct {
+		Server handlers
+	}
+
+	cyclic := &marshalService{}
+	cyclic.Parent = cyclic
+	cyclicMap := map[string]interface{}{}
+	cyclicMap["self"] = cyclicMap
+	cyclicSlice := []interface{}{nil}
+	cyclicSlice[0] = cyclicSlice
+
+	cases := []struct {
+		in   interface{}
+		want string
+	}{
+		{make(chan int), "cannot marshal chan int at ."},
+		{server{handlers{[]interface{}{1, func() {}}}}, "cannot marshal func() at .Server.Handlers[1]"},
+		{map[string]complex128{"c": 1}, `cannot marshal complex128 at ["c"]`},
+		{map[bool]int{true: 1}, "cannot marshal map key type bool at ."},
+		{cyclic, "cannot marshal cyclic *skim.marshalService at .Parent"},
+		{cyclicMap, `cannot marshal cyclic map[string]interface {} at ["self"]`},
+		{cyclicSlice, "cannot marshal cyclic []interface {} at [0]"},
+		{[]marshalError{{}}, "marshal error"},
+	}
+	for _, c := range cases {
+		if a, err := Marshal(c.in); err == nil {
+			t.Errorf("Marshal(%T) = %v; want error", c.in, a)
+		} else if !strings.Contains(err.Error(), c.want) {
+			t.Errorf("Marshal(%T) err = %v; want %q", c.in, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package skim
+
+import "math/big"
+
+// NumericKind is the representation of a number in the numeric tower. Kinds are ordered from
+// narrowest to widest, so that any number may be converted to a kind at least as wide as its own:
+// an Int to a BigInt, either to a Rational, and any of them to a Float.
+type NumericKind int
+
+const (
+	// InvalidKind is the kind of a Numeric that cannot be converted to any other kind.
+	InvalidKind NumericKind = iota
+	// IntKind is the kind of an Int.
+	IntKind
+	// BigIntKind is the kind of a BigInt.
+	BigIntKind
+	// RationalKind is the kind of a Rational.
+	RationalKind
+	// FloatKind is the kind of a Float. It is the only inexact kind.
+	FloatKind
+)
+
+func (k NumericKind) String() string {
+	switch k {
+	case IntKind:
+		return "int"
+	case BigIntKind:
+		return "bigint"
+	case RationalKind:
+		return "rational"
+	case FloatKind:
+		return "float"
+	}
+	return "invalid"
+}
+
+// KindOf returns the kind of n. A Numeric of another type is of FloatKind if it is a float, of
+// IntKind if its value fits in an int64, and of InvalidKind otherwise.
+func KindOf(n Numeric) NumericKind {
+	switch n.(type) {
+	case Int:
+		return IntKind
+	case BigInt:
+		return BigIntKind
+	case Rational:
+		return RationalKind
+	case Float:
+		return FloatKind
+	case nil:
+		return InvalidKind
+	}
+	if n.IsFloat() {
+		return FloatKind
+	} else if _, ok := n.Int64(); ok {
+		return IntKind
+	}
+	return InvalidKind
+}
+
+// NumericPair returns l and r converted to the wider of their kinds, and that kind, so that an
+// arithmetic operation need only switch on the kind to find the type of both operands: Int for
+// IntKind, BigInt for BigIntKind, Rational for RationalKind, and Float for FloatKind. Converted
+// operands are not normalized, so a Rational operand may be an integer; results should be made with
+// NewInteger or NewRational. If either operand is of InvalidKind or cannot be converted, such as a
+// BigInt too large for a Float, NumericPair returns l, r, and InvalidKind.
+func NumericPair(l, r Numeric) (Numeric, Numeric, NumericKind) {
+	lk, rk := KindOf(l), KindOf(r)
+	if lk == InvalidKind || rk == InvalidKind {
+		return l, r, InvalidKind
+	}
+	kind := max(lk, rk)
+	lc, lok := toKind(l, kind)
+	rc, rok := toKind(r, kind)
+	if !lok || !rok {
+		return l, r, InvalidKind
+	}
+	return lc, rc, kind
+}
+
+// toKind converts n to kind, which must be at least as wide as the kind of n.
+func toKind(n Numeric, kind NumericKind) (Numeric, bool) {
+	switch kind {
+	case IntKind:
+		i, ok := n.Int64()
+		return Int(i), ok
+	case BigIntKind:
+		if b, ok := n.(BigInt); ok {
+			return b, true
+		}
+		i, ok := n.Int64()
+		return NewBigInt(big.NewInt(i)), ok
+	case RationalKind:
+		switch n := n.(type) {
+		case Rational:
+			return n, true
+		case BigInt:
+			return Rational{v: new(big.Rat).SetInt(n.Big())}, true
+		}
+		i, ok := n.Int64()
+		return Rational{v: new(big.Rat).SetInt64(i)}, ok
+	case FloatKind:
+		f, ok := n.Float64()
+		return Float(f), ok
+	}
+	return nil, false
+}
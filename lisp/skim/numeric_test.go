@@ -0,0 +1,65 @@
+package skim
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestNumericPair(t *testing.T) {
+	huge := NewBigInt(new(big.Int).Lsh(big.NewInt(1), 1100)) // too large for a Float
+	half := NewRational(big.NewRat(1, 2))
+	rat := func(a, b int64) Rational { return Rational{v: big.NewRat(a, b)} }
+	bigi := func(i int64) BigInt { return NewBigInt(big.NewInt(i)) }
+
+	cases := []struct {
+		l, r         Numeric
+		wantl, wantr Numeric
+		kind         NumericKind
+	}{
+		{Int(1), Int(2), Int(1), Int(2), IntKind},
+		{Int(1), bigi(2), bigi(1), bigi(2), BigIntKind},
+		{bigi(1), Int(2), bigi(1), bigi(2), BigIntKind},
+		{Int(1), half, rat(1, 1), half, RationalKind},
+		{half, bigi(3), half, rat(3, 1), RationalKind},
+		{Int(1), Float(0.5), Float(1), Float(0.5), FloatKind},
+		{Float(0.5), bigi(3), Float(0.5), Float(3), FloatKind},
+		{half, Float(2), Float(0.5), Float(2), FloatKind},
+		{Float(math.Inf(1)), Int(math.MaxInt64), Float(math.Inf(1)), Float(math.MaxInt64), FloatKind},
+		{huge, Int(1), huge, bigi(1), BigIntKind},
+		{huge, Float(1), huge, Float(1), InvalidKind},
+		{Int(1), nil, Int(1), nil, InvalidKind},
+	}
+	for _, c := range cases {
+		l, r, kind := NumericPair(c.l, c.r)
+		if kind != c.kind || !reflect.DeepEqual(l, c.wantl) || !reflect.DeepEqual(r, c.wantr) {
+			t.Errorf("NumericPair(%v, %v) = (%T) %v, (%T) %v, %v; want (%T) %v, (%T) %v, %v",
+				c.l, c.r, l, l, r, r, kind, c.wantl, c.wantl, c.wantr, c.wantr, c.kind)
+		}
+		// The kind of a pair must not depend on the order of its operands.
+		if _, _, kind := NumericPair(c.r, c.l); kind != c.kind {
+			t.Errorf("NumericPair(%v, %v) kind = %v; want %v", c.r, c.l, kind, c.kind)
+		}
+	}
+}
+
+func TestNumericExact(t *testing.T) {
+	cases := []struct {
+		n    Numeric
+		kind NumericKind
+	}{
+		{Int(1), IntKind},
+		{NewBigInt(big.NewInt(1)), BigIntKind},
+		{Rational{v: big.NewRat(1, 2)}, RationalKind},
+		{Float(1), FloatKind},
+	}
+	for _, c := range cases {
+		if got := KindOf(c.n); got != c.kind {
+			t.Errorf("KindOf(%v) = %v; want %v", c.n, got, c.kind)
+		}
+		if got, want := c.n.Exact(), c.kind != FloatKind; got != want {
+			t.Errorf("(%T).Exact() = %t; want %t", c.n, got, want)
+		}
+	}
+}
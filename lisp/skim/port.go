@@ -0,0 +1,173 @@
+package skim
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// ErrPortClosed is wrapped by the errors returned by a Port once it is closed.
+var ErrPortClosed = errors.New("skim: port is closed")
+
+// Port is a source of input, a sink of output, or both: an io.Reader or io.Writer, or both, that
+// programs read from or write to. Ports are compared by identity and written as #<port name>.
+// A Port is safe for concurrent use.
+//
+// The errors returned by a Port when it is used in a way it does not allow, such as after it is
+// closed, are *Errors holding the port as their irritant.
+type Port struct {
+	name  string
+	mu    sync.Mutex
+	r     io.RuneReader
+	w     io.Writer
+	flags portFlags
+
+	closers []io.Closer // the reader and writer, if they are io.Closers
+}
+
+type portFlags uint8
+
+const (
+	portInput portFlags = 1 << iota
+	portOutput
+	portClosed
+)
+
+// NewPort returns a new Port named name that reads from r, if r is not nil, and writes to w, if w
+// is not nil. If r is not an io.RuneReader, it is buffered.
+func NewPort(name string, r io.Reader, w io.Writer) *Port {
+	p := &Port{name: name, w: w}
+	if r != nil {
+		p.flags |= portInput
+		if rr, ok := r.(io.RuneReader); ok {
+			p.r = rr
+		} else {
+			p.r = bufio.NewReader(r)
+		}
+		if c, ok := r.(io.Closer); ok {
+			p.closers = append(p.closers, c)
+		}
+	}
+	if w != nil {
+		p.flags |= portOutput
+		if c, ok := w.(io.Closer); ok && !sameCloser(r, c) {
+			p.closers = append(p.closers, c)
+		}
+	}
+	return p
+}
+
+// sameCloser returns whether r is the io.Closer c, so that it is only closed once.
+func sameCloser(r io.Reader, c io.Closer) bool {
+	rc, ok := r.(io.Closer)
+	return ok && reflect.TypeOf(rc) == reflect.TypeOf(c) && reflect.TypeOf(c).Comparable() && rc == c
+}
+
+// NewInputPort returns a new input Port named name that reads from r.
+func NewInputPort(name string, r io.Reader) *Port {
+	return NewPort(name, r, nil)
+}
+
+// NewOutputPort returns a new output Port named name that writes to w.
+func NewOutputPort(name string, w io.Writer) *Port {
+	return NewPort(name, nil, w)
+}
+
+func (*Port) SkimAtom() {}
+
+func (p *Port) String() string {
+	if p == nil {
+		return "#nil"
+	}
+	return "#<port " + p.name + ">"
+}
+
+// Name returns the name of p.
+func (p *Port) Name() string {
+	return p.name
+}
+
+// IsInput returns whether p can be read from, if it is not closed.
+func (p *Port) IsInput() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.flags&portInput != 0
+}
+
+// IsOutput returns whether p can be written to, if it is not closed.
+func (p *Port) IsOutput() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.flags&portOutput != 0
+}
+
+// IsClosed returns whether p is closed.
+func (p *Port) IsClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.flags&portClosed != 0
+}
+
+// ReadRune reads a single rune from p. It returns an error if p is closed or is not an input port.
+func (p *Port) ReadRune() (r rune, size int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.check(portInput, "input port"); err != nil {
+		return 0, 0, err
+	}
+	return p.r.ReadRune()
+}
+
+// Write writes b to p. It returns an error if p is closed or is not an output port.
+func (p *Port) Write(b []byte) (n int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.check(portOutput, "output port"); err != nil {
+		return 0, err
+	}
+	return p.w.Write(b)
+}
+
+// WriteString writes s to p. It returns an error if p is closed or is not an output port.
+func (p *Port) WriteString(s string) (n int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.check(portOutput, "output port"); err != nil {
+		return 0, err
+	}
+	return io.WriteString(p.w, s)
+}
+
+// Close closes p and the reader and writer it wraps, if they are io.Closers. Once closed, p can be
+// neither read from nor written to, and closing it again returns an error.
+func (p *Port) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.flags&portClosed != 0 {
+		return p.closedError()
+	}
+	p.flags |= portClosed
+	var err error
+	for _, c := range p.closers {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// check returns an error if p is closed or does not have the flag dir, described by what.
+func (p *Port) check(dir portFlags, what string) error {
+	if p.flags&portClosed != 0 {
+		return p.closedError()
+	} else if p.flags&dir == 0 {
+		return &Error{Msg: "not an " + what, Irritants: Vector{p}}
+	}
+	return nil
+}
+
+func (p *Port) closedError() error {
+	return &Error{Msg: "closed port", Irritants: Vector{p}, Wrapped: ErrPortClosed}
+}
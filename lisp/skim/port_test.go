@@ -0,0 +1,110 @@
+package skim
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// closeCounter is an io.ReadWriteCloser that counts the times it is closed.
+type closeCounter struct {
+	strings.Builder
+	io.Reader
+	closed int
+}
+
+func (c *closeCounter) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestPortReadWrite(t *testing.T) {
+	in := NewInputPort("in", strings.NewReader("hé"))
+	var out strings.Builder
+	outp := NewOutputPort("out", &out)
+
+	if !in.IsInput() || in.IsOutput() || !outp.IsOutput() || outp.IsInput() {
+		t.Fatalf("ports have the wrong directions: %v input=%t, %v output=%t", in, in.IsInput(), outp, outp.IsOutput())
+	}
+	if got, want := in.String(), "#<port in>"; got != want || in.Name() != "in" {
+		t.Errorf("String() = %s; want %s", got, want)
+	}
+
+	var runes []rune
+	for {
+		r, _, err := in.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("ReadRune() err = %v", err)
+		}
+		runes = append(runes, r)
+	}
+	if string(runes) != "hé" {
+		t.Errorf("read %q; want %q", string(runes), "hé")
+	}
+
+	if _, err := outp.WriteString("a "); err != nil {
+		t.Fatal(err)
+	} else if _, err := outp.Write([]byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "a b" {
+		t.Errorf("wrote %q; want %q", out.String(), "a b")
+	}
+
+	var e *Error
+	if _, _, err := outp.ReadRune(); !errors.As(err, &e) || e.Msg != "not an input port" || e.Irritants[0] != outp {
+		t.Errorf("ReadRune() on an output port err = %v; want not an input port", err)
+	}
+	if _, err := in.WriteString("x"); !errors.As(err, &e) || e.Msg != "not an output port" {
+		t.Errorf("WriteString() on an input port err = %v; want not an output port", err)
+	}
+}
+
+func TestPortClose(t *testing.T) {
+	rw := &closeCounter{Reader: strings.NewReader("abc")}
+	p := NewPort("rw", rw, rw)
+	if !p.IsInput() || !p.IsOutput() || p.IsClosed() {
+		t.Fatalf("NewPort(rw, rw) is not an open input and output port")
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	} else if !p.IsClosed() || rw.closed != 1 {
+		t.Fatalf("after Close(), IsClosed() = %t and the reader and writer were closed %d times; want true, 1", p.IsClosed(), rw.closed)
+	}
+
+	uses := map[string]func() error{
+		"Close":       p.Close,
+		"ReadRune":    func() error { _, _, err := p.ReadRune(); return err },
+		"WriteString": func() error { _, err := p.WriteString("x"); return err },
+		"Write":       func() error { _, err := p.Write([]byte("x")); return err },
+	}
+	for name, use := range uses {
+		err := use()
+		var e *Error
+		if !errors.As(err, &e) || !errors.Is(err, ErrPortClosed) {
+			t.Errorf("%s() after Close err = (%T) %v; want an *Error wrapping ErrPortClosed", name, err, err)
+		} else if len(e.Irritants) != 1 || e.Irritants[0] != p {
+			t.Errorf("%s() after Close err irritants = %v; want [%v]", name, e.Irritants, p)
+		}
+	}
+	if rw.closed != 1 || rw.Len() != 0 {
+		t.Errorf("closed port closed its writer %d times and wrote %q; want 1 and nothing", rw.closed, rw.String())
+	}
+}
+
+func TestPortAtom(t *testing.T) {
+	a, b := NewInputPort("p", strings.NewReader("")), NewInputPort("p", strings.NewReader(""))
+	if !IsTrue(a) {
+		t.Errorf("IsTrue(%v) = false; want true", a)
+	}
+	if !Equal(a, a) || Equal(a, b) || Compare(a, b) == 0 || Compare(a, a) != 0 {
+		t.Errorf("ports %p and %p are not compared by identity", a, b)
+	}
+	if Dup(a) != Atom(a) {
+		t.Errorf("Dup(%v) copied the port", a)
+	}
+}
@@ -0,0 +1,57 @@
+package skim
+
+import (
+	"math"
+	"math/big"
+)
+
+// Rational is an exact ratio of two integers, such as 3/2. Like BigInt, a Rational takes ownership
+// of the *big.Rat it wraps, which must not be modified afterward.
+type Rational struct{ v *big.Rat }
+
+var _ Numeric = Rational{}
+
+// NewRational returns v as a Numeric: an Int or BigInt if v is an integer and a Rational otherwise.
+// The caller must not modify v after calling NewRational.
+func NewRational(v *big.Rat) Numeric {
+	if v.IsInt() {
+		return NewInteger(new(big.Int).Set(v.Num()))
+	}
+	return Rational{v: v}
+}
+
+// Big returns a copy of r's value.
+func (r Rational) Big() *big.Rat {
+	if r.v == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(r.v)
+}
+
+func (Rational) SkimAtom()     {}
+func (Rational) IsFloat() bool { return false }
+func (Rational) Exact() bool   { return true }
+
+func (r Rational) String() string {
+	if r.v == nil {
+		return "0"
+	}
+	return r.v.RatString()
+}
+
+func (r Rational) Int64() (int64, bool) {
+	if r.v == nil {
+		return 0, true
+	} else if !r.v.IsInt() || !r.v.Num().IsInt64() {
+		return 0, false
+	}
+	return r.v.Num().Int64(), true
+}
+
+func (r Rational) Float64() (float64, bool) {
+	if r.v == nil {
+		return 0, true
+	}
+	f, _ := r.v.Float64()
+	return f, !math.IsInf(f, 0)
+}
@@ -0,0 +1,116 @@
+package skim
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RecordType describes a kind of Record: its name and the names of its fields, in order. Record
+// types are compared by identity, so two types with the same name and fields are distinct.
+type RecordType struct {
+	Name   Symbol
+	Fields []Symbol
+}
+
+// NewRecordType returns a new RecordType with the given name and fields. It returns an error if a
+// field is named more than once.
+func NewRecordType(name Symbol, fields ...Symbol) (*RecordType, error) {
+	for i, field := range fields {
+		for _, prev := range fields[:i] {
+			if prev == field {
+				return nil, fmt.Errorf("skim: record type %v has duplicate field %v", name, field)
+			}
+		}
+	}
+	return &RecordType{Name: name, Fields: append([]Symbol(nil), fields...)}, nil
+}
+
+func (*RecordType) SkimAtom() {}
+
+// String returns the type as #<record-type name>.
+func (t *RecordType) String() string {
+	if t == nil {
+		return "#nil"
+	}
+	return "#<record-type " + t.Name.String() + ">"
+}
+
+// FieldIndex returns the index of the field name in records of type t, and whether t has that field.
+func (t *RecordType) FieldIndex(name Symbol) (int, bool) {
+	for i, field := range t.Fields {
+		if field == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// New returns a new Record of type t holding values, one for each field of t in order.
+func (t *RecordType) New(values ...Atom) (*Record, error) {
+	if len(values) != len(t.Fields) {
+		return nil, fmt.Errorf("skim: record type %v has %d fields; got %d values", t.Name, len(t.Fields), len(values))
+	}
+	return &Record{Type: t, Values: append([]Atom(nil), values...)}, nil
+}
+
+// Record is an instance of a RecordType, holding a value for each of the type's fields. A Record is
+// written as #<name field: value ...>.
+type Record struct {
+	Type   *RecordType
+	Values []Atom // values of Type.Fields, in order
+}
+
+func (*Record) SkimAtom() {}
+
+func (r *Record) String() string {
+	w := newWriter(r, false)
+	w.record(r)
+	return w.String()
+}
+
+// GoString returns the record written as #<name field: value ...>, with its values written by their
+// GoString methods.
+func (r *Record) GoString() string {
+	w := newWriter(r, true)
+	w.record(r)
+	return w.String()
+}
+
+// Get returns the value of the field name, and whether r has that field.
+func (r *Record) Get(name Symbol) (Atom, bool) {
+	i, ok := r.Type.FieldIndex(name)
+	if !ok {
+		return nil, false
+	}
+	return r.Values[i], true
+}
+
+// Set sets the value of the field name. It returns an error if r has no such field.
+func (r *Record) Set(name Symbol, value Atom) error {
+	i, ok := r.Type.FieldIndex(name)
+	if !ok {
+		return fmt.Errorf("skim: record type %v has no field %v", r.Type.Name, name)
+	}
+	r.Values[i] = value
+	return nil
+}
+
+// Dup returns a deep copy of r of the same type, copying each of its values.
+func (r *Record) Dup() Atom {
+	if r == nil {
+		return r
+	} else if Cyclic(r) {
+		return dupAtom(r, make(map[interface{}]Atom))
+	}
+	return dupAtom(r, nil)
+}
+
+// recordName returns the name r is written with: the name of its type, without the angle brackets
+// conventionally written around the names of record types, as in <point>.
+func recordName(r *Record) string {
+	name := string(r.Type.Name)
+	if len(name) > 2 && strings.HasPrefix(name, "<") && strings.HasSuffix(name, ">") {
+		name = name[1 : len(name)-1]
+	}
+	return Symbol(name).String()
+}
@@ -0,0 +1,105 @@
+package skim
+
+import (
+	"hash/maphash"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	if _, err := NewRecordType("<bad>", "x", "y", "x"); err == nil {
+		t.Error("NewRecordType with duplicate fields err = nil; want an error")
+	}
+
+	point, err := NewRecordType("<point>", "x", "y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := point.New(Int(1)); err == nil {
+		t.Error("New with too few values err = nil; want an error")
+	}
+	p, err := point.New(Int(1), List(String("y")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := p.String(), `#<point x: 1 y: ("y")>`; got != want {
+		t.Errorf("String() = %s; want %s", got, want)
+	}
+	if got, want := p.GoString(), `#<point x: 1 y: ("y" . #nil)>`; got != want {
+		t.Errorf("GoString() = %s; want %s", got, want)
+	}
+	if got, ok := p.Get("x"); !ok || got != Int(1) {
+		t.Errorf("Get(x) = %v, %t; want 1, true", got, ok)
+	}
+	if _, ok := p.Get("z"); ok {
+		t.Error("Get(z) = true; want false")
+	}
+	if err := p.Set("x", Int(2)); err != nil {
+		t.Errorf("Set(x, 2) = %v", err)
+	} else if got, _ := p.Get("x"); got != Int(2) {
+		t.Errorf("after Set(x, 2), Get(x) = %v; want 2", got)
+	}
+	if err := p.Set("z", Int(2)); err == nil {
+		t.Error("Set(z, 2) = nil; want an error")
+	}
+
+	// Records refer to themselves through their fields.
+	if err := p.Set("y", List(p)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.String(), "#0=#<point x: 2 y: (#0#)>"; got != want {
+		t.Errorf("String() = %s; want %s", got, want)
+	}
+	d := Dup(p).(*Record)
+	if y, _ := d.Get("y"); d == p || y.(*Cons).Car != d {
+		t.Errorf("Dup(%v) = %v; want a new record holding itself", p, d)
+	}
+}
+
+func TestRecordEqual(t *testing.T) {
+	point, _ := NewRecordType("<point>", "x", "y")
+	other, _ := NewRecordType("<point>", "x", "y") // same name and fields, but a distinct type
+	mk := func(rt *RecordType, values ...Atom) *Record {
+		r, err := rt.New(values...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	a := mk(point, Int(1), Vector{String("v")})
+	cases := []struct {
+		name string
+		a, b Atom
+		want bool
+	}{
+		{"same", a, a, true},
+		{"equal", a, mk(point, Int(1), Vector{String("v")}), true},
+		{"dup", a, Dup(a), true},
+		{"value", a, mk(point, Int(1), Vector{String("w")}), false},
+		{"type", a, mk(other, Int(1), Vector{String("v")}), false},
+		{"vector", a, Vector{Int(1), Vector{String("v")}}, false},
+		{"types", point, other, false},
+	}
+	seed := maphash.MakeSeed()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Equal(c.a, c.b); got != c.want {
+				t.Errorf("Equal(%v, %v) = %t; want %t", c.a, c.b, got, c.want)
+			}
+			if got := Compare(c.a, c.b) == 0; got != c.want {
+				t.Errorf("Compare(%v, %v) = %d; want 0 = %t", c.a, c.b, Compare(c.a, c.b), c.want)
+			}
+			if c.want && Hash(c.a, seed) != Hash(c.b, seed) {
+				t.Errorf("Hash(%v) != Hash(%v)", c.a, c.b)
+			}
+		})
+	}
+
+	// Dup copies the values of a record, but not its type.
+	d := Dup(a).(*Record)
+	d.Values[1].(Vector)[0] = String("changed")
+	if d.Type != a.Type || a.Values[1].(Vector)[0] != String("v") {
+		t.Errorf("Dup(%v) = %v; want a copy of the same type", a, d)
+	}
+}
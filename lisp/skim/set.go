@@ -0,0 +1,89 @@
+package skim
+
+// Set is a mutable set of atoms. Like the keys of a HashMap, elements are matched by Equal, so a set
+// holds at most one of any atoms that are Equal, and elements must not be changed while they are
+// held.
+//
+// A Set is written as #{elem ...}, with its elements sorted by Compare. The zero Set is empty and
+// ready to use. A Set is not safe for concurrent use.
+type Set struct {
+	m HashMap // elements, each mapped to nil
+}
+
+// NewSet returns a new Set holding elems. It returns an error if any of elems cannot be held by a
+// Set, as described by Add.
+func NewSet(elems ...Atom) (*Set, error) {
+	s := new(Set)
+	for _, elem := range elems {
+		if err := s.Add(elem); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (*Set) SkimAtom() {}
+
+func (s *Set) String() string {
+	w := newWriter(s, false)
+	w.set(s)
+	return w.String()
+}
+
+// GoString returns the set written as #{elem ...}, with its elements written by their GoString
+// methods.
+func (s *Set) GoString() string {
+	w := newWriter(s, true)
+	w.set(s)
+	return w.String()
+}
+
+// Len returns the number of elements in s.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return s.m.Len()
+}
+
+// Contains returns whether s holds an element Equal to elem.
+func (s *Set) Contains(elem Atom) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.m.Get(elem)
+	return ok
+}
+
+// Add adds elem to s, if s does not already hold it. As with the keys of a HashMap, it returns an
+// error if elem is cyclic or not equal to itself, such as a procedure or the Float NaN.
+func (s *Set) Add(elem Atom) error {
+	if err := checkHashable(elem, "set element"); err != nil {
+		return err
+	}
+	s.m.set(elem, nil)
+	return nil
+}
+
+// Delete removes elem from s, returning whether s held it.
+func (s *Set) Delete(elem Atom) bool {
+	return s != nil && s.m.Delete(elem)
+}
+
+// Elems returns the elements of s, sorted by Compare.
+func (s *Set) Elems() []Atom {
+	if s == nil {
+		return []Atom{}
+	}
+	return s.m.Keys()
+}
+
+// Dup returns a deep copy of s, copying each of its elements.
+func (s *Set) Dup() Atom {
+	if s == nil {
+		return s
+	} else if Cyclic(s) {
+		return dupAtom(s, make(map[interface{}]Atom))
+	}
+	return dupAtom(s, nil)
+}
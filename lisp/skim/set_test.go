@@ -0,0 +1,221 @@
+package skim
+
+import (
+	"hash/maphash"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// setOf returns a Set holding elems.
+func setOf(t *testing.T, elems ...Atom) *Set {
+	t.Helper()
+	s, err := NewSet(elems...)
+	if err != nil {
+		t.Fatalf("NewSet(%v) = %v", elems, err)
+	}
+	return s
+}
+
+func TestSet(t *testing.T) {
+	var s Set
+	if s.Len() != 0 || s.String() != "#{}" || s.Contains(nil) {
+		t.Fatalf("zero Set = %v with Len() = %d; want #{}", &s, s.Len())
+	}
+
+	for _, elem := range []Atom{Int(1), Float(1), String("a"), List(Int(1)), Int(1), List(Int(1))} {
+		if err := s.Add(elem); err != nil {
+			t.Fatalf("Add(%v) = %v", elem, err)
+		}
+	}
+	if s.Len() != 4 {
+		t.Errorf("Len() = %d; want 4", s.Len())
+	}
+
+	cases := []struct {
+		elem Atom
+		want bool
+	}{
+		{Int(1), true},
+		{Float(1), true},
+		{String("a"), true},
+		{List(Int(1)), true},
+		{Symbol("a"), false},
+		{Vector{Int(1)}, false},
+		{nil, false},
+	}
+	for _, c := range cases {
+		if got := s.Contains(c.elem); got != c.want {
+			t.Errorf("Contains(%v) = %t; want %t", c.elem, got, c.want)
+		}
+	}
+
+	if !s.Delete(Float(1)) || s.Delete(Float(1)) || s.Contains(Float(1)) || s.Len() != 3 {
+		t.Errorf("Delete(1.0) did not delete 1.0 once; set is %v", &s)
+	}
+	want := []Atom{Int(1), String("a"), List(Int(1))}
+	if got := s.Elems(); !Equal(VectorOf(got), VectorOf(want)) {
+		t.Errorf("Elems() = %v; want %v", got, want)
+	}
+
+	var nilSet *Set
+	if nilSet.Len() != 0 || nilSet.Contains(Int(1)) || nilSet.Delete(Int(1)) || len(nilSet.Elems()) != 0 {
+		t.Error("nil *Set is not empty")
+	}
+}
+
+func TestSetUnhashable(t *testing.T) {
+	cyclic := Vector{nil}
+	cyclic[0] = cyclic
+	fn := funcAtom(func() {})
+	setOfFunc := new(Set)
+	setOfFunc.m.set(fn, nil) // bypasses the check made by Add
+
+	cases := []struct {
+		name string
+		elem Atom
+	}{
+		{"cyclic", cyclic},
+		{"func", fn},
+		{"nan", Float(math.NaN())},
+		{"set-of-func", setOfFunc},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := setOf(t, Int(1))
+			if err := s.Add(c.elem); err == nil {
+				t.Errorf("Add(%v) = nil; want an error", c.elem)
+			} else if s.Len() != 1 {
+				t.Errorf("Add(%v) failed, but Len() = %d", c.elem, s.Len())
+			}
+			if _, err := NewSet(Int(1), c.elem); err == nil {
+				t.Errorf("NewSet(1, %v) = nil; want an error", c.elem)
+			}
+		})
+	}
+}
+
+func TestSetString(t *testing.T) {
+	cases := []struct {
+		name string
+		in   *Set
+		want string
+	}{
+		{"nil", nil, "#{}"},
+		{"empty", setOf(t), "#{}"},
+		{"sorted", setOf(t, Symbol("c"), String("b"), Int(2), Int(1), List(Symbol("a"))), `#{1 2 "b" c (a)}`},
+		{"nested", setOf(t, setOf(t, Int(2)), setOf(t, Int(1), Int(2)), setOf(t)), "#{#{} #{1 2} #{2}}"},
+		{"map", setOf(t, mapOf(t, Keyword("k"), Int(1))), "#{{:k 1}}"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.in.String(); got != c.want {
+				t.Errorf("String() = %s; want %s", got, c.want)
+			}
+		})
+	}
+
+	// A set held by a map that it holds is written with a datum label.
+	s, m := setOf(t), NewHashMap()
+	if err := s.Add(m); err != nil {
+		t.Fatal(err)
+	} else if err := m.Set(Symbol("s"), s); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.String(), "#0=#{{s #0#}}"; got != want {
+		t.Errorf("String() = %s; want %s", got, want)
+	}
+}
+
+func TestSetWalk(t *testing.T) {
+	s := setOf(t, Symbol("b"), List(Int(1)), Symbol("a"))
+
+	var walked []Atom
+	if err := Walk(s, func(a Atom) error { walked = append(walked, a); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Vector{Symbol("a"), Symbol("b"), List(Int(1))}); !Equal(VectorOf(walked), want) {
+		t.Errorf("Walk(%v) visited %v; want %v", s, walked, want)
+	}
+
+	var traversed []string
+	var visitor Visitor
+	visitor = func(a Atom) (Visitor, error) {
+		traversed = append(traversed, fmtstring(a))
+		return visitor, nil
+	}
+	if err := Traverse(s, visitor); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"#{a b (1)}", "a", "b", "(1)", "1"}; !reflect.DeepEqual(traversed, want) {
+		t.Errorf("Traverse(%v) visited %q; want %q", s, traversed, want)
+	}
+}
+
+func TestSetDup(t *testing.T) {
+	inner := setOf(t, Int(1))
+	s := setOf(t, inner, Vector{String("v")})
+	d, ok := Dup(s).(*Set)
+	if !ok || d == s {
+		t.Fatalf("Dup(%v) = %#v; want a new *Set", s, d)
+	} else if !Equal(d, s) {
+		t.Fatalf("Dup(%v) = %v; want an equal set", s, d)
+	}
+	for _, elem := range d.Elems() {
+		if elem == Atom(inner) {
+			t.Errorf("Dup(%v) shares the set %v", s, inner)
+		}
+	}
+	if (*Set)(nil).Dup() != (*Set)(nil) {
+		t.Error("Dup of a nil *Set is not nil")
+	}
+}
+
+func TestSetEqual(t *testing.T) {
+	a := setOf(t, Int(1), List(Int(2)), setOf(t, Symbol("x")))
+	b := setOf(t, setOf(t, Symbol("x")), List(Int(2)), Int(1)) // same elements, added in reverse
+	cases := []struct {
+		name string
+		a, b Atom
+		want bool
+	}{
+		{"same", a, a, true},
+		{"reordered", a, b, true},
+		{"dup", a, Dup(a), true},
+		{"empty", setOf(t), setOf(t), true},
+		{"nil-empty", (*Set)(nil), setOf(t), true},
+		{"elem", a, setOf(t, Int(1), List(Int(2)), setOf(t, Symbol("y"))), false},
+		{"fewer", a, setOf(t, Int(1), List(Int(2))), false},
+		{"number-type", setOf(t, Int(1)), setOf(t, Float(1)), false},
+		{"map", setOf(t, Int(1)), mapOf(t, Int(1), nil), false},
+		{"vector", setOf(t, Int(1)), Vector{Int(1)}, false},
+	}
+	seed := maphash.MakeSeed()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Equal(c.a, c.b); got != c.want {
+				t.Errorf("Equal(%v, %v) = %t; want %t", c.a, c.b, got, c.want)
+			}
+			if got := Compare(c.a, c.b) == 0; got != c.want {
+				t.Errorf("Compare(%v, %v) = %d; want 0 = %t", c.a, c.b, Compare(c.a, c.b), c.want)
+			}
+			if c.want && Hash(c.a, seed) != Hash(c.b, seed) {
+				t.Errorf("Hash(%v) != Hash(%v)", c.a, c.b)
+			}
+		})
+	}
+
+	ordered := []Atom{
+		mapOf(t, Int(9), Int(9)),
+		setOf(t),
+		setOf(t, Int(1)),
+		setOf(t, Int(1), Int(2)),
+		setOf(t, Int(2)),
+		&ptrAtom{},
+	}
+	for i := 1; i < len(ordered); i++ {
+		if c := Compare(ordered[i-1], ordered[i]); c != -1 {
+			t.Errorf("Compare(%v, %v) = %d; want -1", ordered[i-1], ordered[i], c)
+		}
+	}
+}
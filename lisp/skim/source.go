@@ -0,0 +1,140 @@
+package skim
+
+import "fmt"
+
+// Pos is a position in source text.
+type Pos struct {
+	Name      string // Name of the source, such as a file path; empty if unnamed
+	Line, Col int
+	Offset    int // Byte offset
+}
+
+func (p Pos) String() string {
+	if p.Name == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Col)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Name, p.Line, p.Col)
+}
+
+// SourceMap records the source positions of parsed lists and their elements, keyed by cons pair,
+// and of the elements of vectors. Atoms are not modified to hold positions, so a SourceMap must be
+// passed alongside the atoms it describes. It also records where the text of each atom other than a
+// list or vector ends, so that the text an atom was read from can be recovered from its position.
+//
+// Positions are not recorded for Nil, which is shared by every empty list.
+//
+// A SourceMap is not safe for concurrent use while it is being written to.
+type SourceMap struct {
+	lists map[*Cons]Pos // position of a list whose first pair is the key
+	ends  map[*Cons]Pos // position just past the end of a list whose first pair is the key
+	cars  map[*Cons]Pos // position of the key's car
+	cdrs  map[*Cons]Pos // position of the key's cdr, if read after a dot or as a map value
+	elems map[*Atom]Pos // position of a vector element, keyed by its address
+	spans map[int]Pos   // position just past the text of an atom, keyed by the offset of its start
+}
+
+func NewSourceMap() *SourceMap {
+	return &SourceMap{
+		lists: make(map[*Cons]Pos),
+		ends:  make(map[*Cons]Pos),
+		cars:  make(map[*Cons]Pos),
+		cdrs:  make(map[*Cons]Pos),
+		elems: make(map[*Atom]Pos),
+		spans: make(map[int]Pos),
+	}
+}
+
+// SetList records the position of the list beginning with the pair c.
+func (m *SourceMap) SetList(c *Cons, pos Pos) {
+	if c != Nil {
+		m.lists[c] = pos
+	}
+}
+
+// SetEnd records the position just past the closing bracket of the list beginning with the pair c.
+func (m *SourceMap) SetEnd(c *Cons, pos Pos) {
+	if c != Nil {
+		m.ends[c] = pos
+	}
+}
+
+// SetCar records the position of the car of the pair c.
+func (m *SourceMap) SetCar(c *Cons, pos Pos) {
+	if c != Nil {
+		m.cars[c] = pos
+	}
+}
+
+// List returns the position of the list beginning with the pair c, if known.
+func (m *SourceMap) List(c *Cons) (pos Pos, ok bool) {
+	if m == nil || c == nil {
+		return pos, false
+	}
+	pos, ok = m.lists[c]
+	return pos, ok
+}
+
+// End returns the position just past the closing bracket of the list beginning with the pair c, if
+// known.
+func (m *SourceMap) End(c *Cons) (pos Pos, ok bool) {
+	if m == nil || c == nil {
+		return pos, false
+	}
+	pos, ok = m.ends[c]
+	return pos, ok
+}
+
+// Car returns the position of the car of the pair c, if known.
+func (m *SourceMap) Car(c *Cons) (pos Pos, ok bool) {
+	if m == nil || c == nil {
+		return pos, false
+	}
+	pos, ok = m.cars[c]
+	return pos, ok
+}
+
+// SetCdr records the position of the cdr of the pair c, such as the tail of a dotted pair.
+func (m *SourceMap) SetCdr(c *Cons, pos Pos) {
+	if c != Nil {
+		m.cdrs[c] = pos
+	}
+}
+
+// Cdr returns the position of the cdr of the pair c, if known. The parser records it only for the
+// tails of dotted pairs and for the values of maps.
+func (m *SourceMap) Cdr(c *Cons) (pos Pos, ok bool) {
+	if m == nil || c == nil {
+		return pos, false
+	}
+	pos, ok = m.cdrs[c]
+	return pos, ok
+}
+
+// SetElem records the position of the element of v at index i.
+func (m *SourceMap) SetElem(v Vector, i int, pos Pos) {
+	m.elems[&v[i]] = pos
+}
+
+// Elem returns the position of the element of v at index i, if known.
+func (m *SourceMap) Elem(v Vector, i int) (pos Pos, ok bool) {
+	if m == nil || i < 0 || i >= len(v) {
+		return pos, false
+	}
+	pos, ok = m.elems[&v[i]]
+	return pos, ok
+}
+
+// SetSpan records that the text of the atom beginning at pos ends just before end. Spans are
+// recorded for atoms other than lists and vectors, whose ends are recorded by SetEnd or not at all.
+func (m *SourceMap) SetSpan(pos, end Pos) {
+	m.spans[pos.Offset] = end
+}
+
+// Span returns the position just past the text of the atom beginning at offset, if known.
+func (m *SourceMap) Span(offset int) (end Pos, ok bool) {
+	if m == nil {
+		return end, false
+	}
+	end, ok = m.spans[offset]
+	return end, ok
+}
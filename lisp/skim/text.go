@@ -0,0 +1,21 @@
+package skim
+
+// MarshalText implements encoding.TextMarshaler. The text of an atom is the same as its String
+// method: the atom as it is written in source, which parser.ReadAtom reads back.
+func (i Int) MarshalText() ([]byte, error) { return []byte(i.String()), nil }
+
+// MarshalText implements encoding.TextMarshaler.
+func (f Float) MarshalText() ([]byte, error) { return []byte(f.String()), nil }
+
+// MarshalText implements encoding.TextMarshaler.
+func (b Bool) MarshalText() ([]byte, error) { return []byte(b.String()), nil }
+
+// MarshalText implements encoding.TextMarshaler.
+func (s String) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Symbol) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// MarshalText implements encoding.TextMarshaler. Cyclic vectors are written with labels, as by
+// String.
+func (v Vector) MarshalText() ([]byte, error) { return []byte(v.String()), nil }
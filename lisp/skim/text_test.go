@@ -0,0 +1,40 @@
+package skim_test
+
+import (
+	"encoding"
+	"testing"
+
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestMarshalText(t *testing.T) {
+	cases := []struct {
+		in   encoding.TextMarshaler
+		want string
+	}{
+		{skim.Int(-1), "-1"},
+		{skim.Float(2), "2.0"},
+		{skim.Bool(true), "#t"},
+		{skim.String("a \"b\""), `"a \"b\""`},
+		{skim.Symbol("x"), "x"},
+		{skim.Symbol("1"), "|1|"},
+		{skim.Vector{skim.Int(1), skim.Symbol("y")}, "[1 y]"},
+	}
+	for _, c := range cases {
+		text, err := c.in.MarshalText()
+		if err != nil {
+			t.Errorf("%#v.MarshalText() err = %v; want nil", c.in, err)
+			continue
+		} else if string(text) != c.want {
+			t.Errorf("%#v.MarshalText() = %q; want %q", c.in, text, c.want)
+		}
+
+		a, err := parser.ReadAtom(text)
+		if err != nil {
+			t.Errorf("ReadAtom(%q) err = %v; want nil", text, err)
+		} else if a.String() != c.want {
+			t.Errorf("ReadAtom(%q) = %v; want %s", text, a, c.want)
+		}
+	}
+}
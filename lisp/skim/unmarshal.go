@@ -0,0 +1,520 @@
+package skim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SkimUnmarshaler is implemented by types that decode themselves from an atom when passed to
+// Unmarshal.
+type SkimUnmarshaler interface {
+	UnmarshalSkim(Atom) error
+}
+
+// KeyKind is a set of the kinds of atoms that Unmarshal accepts as keys of association and
+// property lists.
+type KeyKind int
+
+const (
+	SymbolKeys KeyKind = 1 << iota
+	KeywordKeys
+	StringKeys
+
+	// AllKeys accepts symbols, keywords, and strings as keys.
+	AllKeys = SymbolKeys | KeywordKeys | StringKeys
+)
+
+// UnmarshalOptions configures Unmarshal. The zero UnmarshalOptions accepts symbols, keywords, and
+// strings as keys and ignores keys that do not name a struct field.
+type UnmarshalOptions struct {
+	// Keys is the set of kinds of atoms accepted as keys of association and property lists. If
+	// zero, AllKeys is used.
+	Keys KeyKind
+
+	// DisallowUnknownFields, if true, makes a key that does not name a field of the struct being
+	// decoded into an error.
+	DisallowUnknownFields bool
+}
+
+// UnmarshalError is an error returned by Unmarshal. Path is the path to the atom that could not be
+// decoded, written as keys separated by dots and indices in brackets, such as servers[2].port.
+type UnmarshalError struct {
+	Path string
+	Err  error
+}
+
+func (e *UnmarshalError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("skim: %v", e.Err)
+	}
+	return fmt.Sprintf("skim: %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the error wrapped by the UnmarshalError.
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// Unmarshal decodes a into the value pointed to by v using the zero UnmarshalOptions.
+func Unmarshal(a Atom, v interface{}) error {
+	return UnmarshalOptions{}.Unmarshal(a, v)
+}
+
+// Unmarshal decodes a into the value pointed to by v, which must be a non-nil pointer. It is the
+// reverse of Marshal.
+//
+// Values implementing SkimUnmarshaler decode themselves, and values of interface types that a
+// implements, such as Atom, are set to a. Otherwise, Bool is decoded into bools, Int and BigInt
+// into integers and floats, Float into floats, and strings, symbols, and keywords into strings.
+// Integers that do not fit the value they are decoded into are errors. Vectors and lists are
+// decoded into slices and arrays, and Bytes and strings into byte slices. Pointers are allocated
+// as needed, and nil sets a value to its zero value. A Foreign is decoded into a value to which its
+// value can be assigned, as the value it holds.
+//
+// Association lists, ((key . value) ...), and property lists, (:key value ...), are decoded into
+// maps and structs. A list whose elements are all cons pairs is an association list; any other
+// list is a property list. An element of an association list may also be written as a binding,
+// (key value), as in a let form, so that ((port 8080)) and ((port . 8080)) are decoded alike. A
+// key names a struct field by the name in its skim tag, or otherwise by the field's name ignoring
+// case, hyphens, and underscores, so that max-conns names a field MaxConns. If a key occurs more
+// than once, its first value is used.
+func (o UnmarshalOptions) Unmarshal(a Atom, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &UnmarshalError{Err: fmt.Errorf("cannot unmarshal into %T, not a non-nil pointer", v)}
+	}
+	if o.Keys == 0 {
+		o.Keys = AllKeys
+	}
+	return o.unmarshal(a, rv.Elem(), "")
+}
+
+var skimUnmarshalerType = reflect.TypeOf((*SkimUnmarshaler)(nil)).Elem()
+
+// typeName returns the name of the type of a for use in errors, such as string or cons.
+func typeName(a Atom) string {
+	if a == nil {
+		return "nil"
+	}
+	t := reflect.TypeOf(a)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.PkgPath() != reflect.TypeOf(Int(0)).PkgPath() {
+		return t.String()
+	}
+	return strings.ToLower(t.Name())
+}
+
+func (o UnmarshalOptions) unmarshal(a Atom, v reflect.Value, path string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(skimUnmarshalerType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if err := v.Addr().Interface().(SkimUnmarshaler).UnmarshalSkim(a); err != nil {
+			return &UnmarshalError{Path: path, Err: err}
+		}
+		return nil
+	}
+
+	cannot := func() error {
+		return &UnmarshalError{Path: path, Err: fmt.Errorf("cannot convert %s to %v", typeName(a), v.Type())}
+	}
+
+	if f, ok := a.(*Foreign); ok && f != nil && f.Value != nil {
+		// The value held by a Foreign is set as it is, if it can be.
+		if fv := reflect.ValueOf(f.Value); fv.Type().AssignableTo(v.Type()) {
+			v.Set(fv)
+			return nil
+		}
+	}
+
+	if v.Kind() == reflect.Interface {
+		if a == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else if reflect.TypeOf(a).Implements(v.Type()) {
+			v.Set(reflect.ValueOf(a))
+		} else {
+			return cannot()
+		}
+		return nil
+	} else if a == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return o.unmarshal(a, v.Elem(), path)
+	case reflect.Bool:
+		b, ok := a.(Bool)
+		if !ok {
+			return cannot()
+		}
+		v.SetBool(bool(b))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var (
+			n  int64
+			ok bool
+		)
+		switch i := a.(type) {
+		case Int:
+			n, ok = int64(i), true
+		case BigInt:
+			b := i.Big()
+			n, ok = b.Int64(), b.IsInt64()
+		default:
+			return cannot()
+		}
+		if !ok || v.OverflowInt(n) {
+			return &UnmarshalError{Path: path, Err: fmt.Errorf("%v overflows %v", a, v.Type())}
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var (
+			n  uint64
+			ok bool
+		)
+		switch i := a.(type) {
+		case Int:
+			n, ok = uint64(i), i >= 0
+		case BigInt:
+			b := i.Big()
+			n, ok = b.Uint64(), b.IsUint64()
+		default:
+			return cannot()
+		}
+		if !ok || v.OverflowUint(n) {
+			return &UnmarshalError{Path: path, Err: fmt.Errorf("%v overflows %v", a, v.Type())}
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		num, ok := a.(Numeric)
+		if !ok {
+			return cannot()
+		}
+		f, ok := num.Float64()
+		if !ok || (v.OverflowFloat(f) && !math.IsInf(f, 0)) {
+			return &UnmarshalError{Path: path, Err: fmt.Errorf("%v overflows %v", a, v.Type())}
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		switch s := a.(type) {
+		case String:
+			v.SetString(string(s))
+		case Symbol:
+			v.SetString(string(s))
+		case Keyword:
+			v.SetString(string(s))
+		default:
+			return cannot()
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			switch b := a.(type) {
+			case Bytes:
+				v.SetBytes(append([]byte(nil), b...))
+				return nil
+			case String:
+				v.SetBytes([]byte(b))
+				return nil
+			}
+		}
+		elems, ok := listElements(a)
+		if !ok {
+			return cannot()
+		}
+		return o.sequence(elems, v, path)
+	case reflect.Map, reflect.Struct:
+		obj, err := o.members(a, path)
+		if err == errNotObject {
+			return cannot()
+		} else if err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Map {
+			return o.mapMembers(obj, v, path)
+		}
+		return o.structMembers(obj, v, path)
+	default:
+		return cannot()
+	}
+	return nil
+}
+
+// listElements returns the elements of a if it is a vector or proper list.
+func listElements(a Atom) ([]Atom, bool) {
+	switch a := a.(type) {
+	case Vector:
+		return a, true
+	case *Cons:
+		if IsNil(a) {
+			return nil, true
+		}
+		var elems []Atom
+		for c := a; c != nil; {
+			elems = append(elems, c.Car)
+			switch cdr := c.Cdr.(type) {
+			case nil:
+				return elems, true
+			case *Cons:
+				c = cdr
+			default:
+				return nil, false
+			}
+		}
+		return elems, true
+	}
+	return nil, false
+}
+
+func (o UnmarshalOptions) sequence(elems []Atom, v reflect.Value, path string) error {
+	if v.Kind() == reflect.Array {
+		if len(elems) > v.Len() {
+			return &UnmarshalError{Path: path, Err: fmt.Errorf("cannot convert %d elements to %v", len(elems), v.Type())}
+		}
+		for i := len(elems); i < v.Len(); i++ {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+		}
+	} else {
+		v.Set(reflect.MakeSlice(v.Type(), len(elems), len(elems)))
+	}
+	for i, elem := range elems {
+		if err := o.unmarshal(elem, v.Index(i), path+"["+strconv.Itoa(i)+"]"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errNotObject = errors.New("not an association or property list")
+
+// object holds the members of an association or property list.
+type object struct {
+	keys   []Atom
+	values []Atom
+	alist  bool
+}
+
+// members returns the members of the association or property list a. The values of an
+// association list are the cdrs of its elements.
+func (o UnmarshalOptions) members(a Atom, path string) (*object, error) {
+	elems, ok := listElements(a)
+	if _, isVec := a.(Vector); isVec || !ok {
+		return nil, errNotObject
+	}
+
+	obj := &object{alist: true}
+	for _, elem := range elems {
+		if c, ok := elem.(*Cons); !ok || c == nil {
+			obj.alist = false
+			break
+		}
+	}
+	if obj.alist {
+		for _, elem := range elems {
+			pair := elem.(*Cons)
+			obj.keys, obj.values = append(obj.keys, pair.Car), append(obj.values, pair.Cdr)
+		}
+	} else {
+		if len(elems)%2 != 0 {
+			return nil, &UnmarshalError{Path: path, Err: errors.New("property list has a key with no value")}
+		}
+		for i := 0; i < len(elems); i += 2 {
+			obj.keys, obj.values = append(obj.keys, elems[i]), append(obj.values, elems[i+1])
+		}
+	}
+	for _, key := range obj.keys {
+		if _, ok := o.keyName(key); !ok {
+			return nil, &UnmarshalError{Path: path, Err: fmt.Errorf("cannot use %s %v as a key", typeName(key), key)}
+		}
+	}
+	return obj, nil
+}
+
+// value returns the ith value of obj to decode into a value of type t. If obj is an association
+// list whose ith element is written as a binding, (key value), the value is the binding's value
+// rather than the list holding it.
+func (o UnmarshalOptions) value(obj *object, i int, t reflect.Type) Atom {
+	cdr := obj.values[i]
+	c, ok := cdr.(*Cons)
+	if !obj.alist || !ok || IsNil(c) || c.Cdr != nil {
+		return cdr
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		switch c.Car.(type) {
+		case Vector, Bytes:
+		default:
+			return cdr
+		}
+	case reflect.Map, reflect.Struct:
+		if _, err := o.members(cdr, ""); err == nil {
+			return cdr
+		}
+	}
+	return c.Car
+}
+
+// keyName returns the name of the key a, if it is of a kind accepted by o.Keys.
+func (o UnmarshalOptions) keyName(a Atom) (string, bool) {
+	switch a := a.(type) {
+	case Symbol:
+		return string(a), o.Keys&SymbolKeys != 0
+	case Keyword:
+		return string(a), o.Keys&KeywordKeys != 0
+	case String:
+		return string(a), o.Keys&StringKeys != 0
+	}
+	return "", false
+}
+
+func keyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func (o UnmarshalOptions) mapMembers(obj *object, v reflect.Value, path string) error {
+	t := v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(obj.keys)))
+	}
+	seen := make(map[string]bool, len(obj.keys))
+	for i, key := range obj.keys {
+		name, _ := o.keyName(key)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		kv := reflect.New(t.Key()).Elem()
+		if t.Key().Kind() == reflect.String {
+			kv.SetString(name)
+		} else if err := o.unmarshal(String(name), kv, keyPath(path, name)); err != nil {
+			return err
+		}
+		ev := reflect.New(t.Elem()).Elem()
+		if err := o.unmarshal(o.value(obj, i, t.Elem()), ev, keyPath(path, name)); err != nil {
+			return err
+		}
+		v.SetMapIndex(kv, ev)
+	}
+	return nil
+}
+
+func (o UnmarshalOptions) structMembers(obj *object, v reflect.Value, path string) error {
+	fields := structFields(v.Type(), nil)
+	seen := make(map[string]bool, len(obj.keys))
+	for i, key := range obj.keys {
+		name, _ := o.keyName(key)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		f := findField(fields, name)
+		if f == nil {
+			if o.DisallowUnknownFields {
+				return &UnmarshalError{Path: keyPath(path, name), Err: fmt.Errorf("no field named %s in %v", name, v.Type())}
+			}
+			continue
+		}
+		fv, err := fieldByIndex(v, f.index)
+		if err != nil {
+			return &UnmarshalError{Path: keyPath(path, name), Err: err}
+		}
+		if err := o.unmarshal(o.value(obj, i, fv.Type()), fv, keyPath(path, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field is a struct field that may be decoded into.
+type field struct {
+	name   string
+	tagged bool
+	index  []int
+}
+
+// structFields returns the fields of t that may be decoded into, including those of embedded
+// structs, in the order they are declared.
+func structFields(t reflect.Type, index []int) (fields []field) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("skim")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		fi := append(index[:len(index):len(index)], i)
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, structFields(ft, fi)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			fields = append(fields, field{name: f.Name, index: fi})
+		} else {
+			fields = append(fields, field{name: name, tagged: true, index: fi})
+		}
+	}
+	return fields
+}
+
+// findField returns the field named by key: a field whose tag names key, or otherwise a field whose
+// name equals key ignoring case, hyphens, and underscores.
+func findField(fields []field, key string) *field {
+	for i := range fields {
+		if fields[i].tagged && fields[i].name == key {
+			return &fields[i]
+		}
+	}
+	norm := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(s))
+	}
+	key = norm(key)
+	for i := range fields {
+		if !fields[i].tagged && norm(fields[i].name) == key {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns the field of v at index, allocating embedded struct pointers as needed.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
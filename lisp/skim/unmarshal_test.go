@@ -0,0 +1,190 @@
+package skim_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+const configText = `
+((name "api")                          ; Let-style bindings.
+ (replicas 3)
+ (max-conns . 1024)                    ; Dotted pairs work too.
+ (ratio 0.75)
+ (debug #t)
+ (tags ["blue" green :red])
+ (servers
+  [(:host "a.example.com" :port 80)
+   ((host . "b.example.com") (port . 8080) (weight 2))
+   ("host" "c.example.com" "port" 443)])
+ (limits (cpu 2) (mem 512))
+ (ports 80 443)
+ (hook (display "deployed " name))
+ (key #u8(1 2 3))
+ (owner (name "ops"))
+ (name "ignored"))
+`
+
+type unmarshalServer struct {
+	Host   string `skim:"host"`
+	Port   uint16 `skim:"port"`
+	Weight *int
+}
+
+type unmarshalOwner struct {
+	Name string `skim:"name"`
+}
+
+type unmarshalConfig struct {
+	unmarshalOwner
+	Replicas int
+	MaxConns int64
+	Ratio    float32
+	Debug    bool
+	Tags     []string
+	Servers  []unmarshalServer `skim:"servers"`
+	Limits   map[string]int
+	Ports    [3]int
+	Hook     skim.Atom
+	Key      []byte
+	Owner    *unmarshalOwner
+	Ignored  string `skim:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	a, err := parser.ReadString(configText)
+	if err != nil {
+		t.Fatalf("ReadString() err = %v; want nil", err)
+	}
+
+	var got unmarshalConfig
+	if err := skim.Unmarshal(a[0], &got); err != nil {
+		t.Fatalf("Unmarshal() err = %v; want nil", err)
+	}
+	two := 2
+	want := unmarshalConfig{
+		unmarshalOwner: unmarshalOwner{Name: "api"},
+		Replicas:       3,
+		MaxConns:       1024,
+		Ratio:          0.75,
+		Debug:          true,
+		Tags:           []string{"blue", "green", "red"},
+		Servers: []unmarshalServer{
+			{Host: "a.example.com", Port: 80},
+			{Host: "b.example.com", Port: 8080, Weight: &two},
+			{Host: "c.example.com", Port: 443},
+		},
+		Limits: map[string]int{"cpu": 2, "mem": 512},
+		Ports:  [3]int{80, 443},
+		Hook:   skim.List(skim.Symbol("display"), skim.String("deployed "), skim.Symbol("name")),
+		Key:    []byte{1, 2, 3},
+		Owner:  &unmarshalOwner{Name: "ops"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unmarshal() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestUnmarshalMarshal(t *testing.T) {
+	type doc struct {
+		Name  string            `skim:"name"`
+		Sizes []int             `skim:"sizes"`
+		Attrs map[string]string `skim:"attrs"`
+		Any   interface{}       `skim:"any"`
+	}
+	in := doc{Name: "x", Sizes: []int{1, 2}, Attrs: map[string]string{"k": "v"}, Any: "s"}
+	for _, opts := range []skim.MarshalOptions{{}, {Plists: true}} {
+		a, err := opts.Marshal(in)
+		if err != nil {
+			t.Fatalf("Marshal() err = %v; want nil", err)
+		}
+		var out doc
+		if err := skim.Unmarshal(a, &out); err != nil {
+			t.Fatalf("Unmarshal(%v) err = %v; want nil", a, err)
+		}
+		out.Any = string(out.Any.(skim.String))
+		if !reflect.DeepEqual(out, in) {
+			t.Fatalf("Unmarshal(%v) = %+v; want %+v", a, out, in)
+		}
+	}
+}
+
+type unmarshalLevel int
+
+func (l *unmarshalLevel) UnmarshalSkim(a skim.Atom) error {
+	switch a {
+	case skim.Symbol("low"):
+		*l = 1
+	case skim.Symbol("high"):
+		*l = 2
+	default:
+		return errors.New("invalid level")
+	}
+	return nil
+}
+
+func TestUnmarshalUnmarshaler(t *testing.T) {
+	var levels []unmarshalLevel
+	if err := skim.Unmarshal(skim.Vector{skim.Symbol("high"), skim.Symbol("low")}, &levels); err != nil {
+		t.Fatalf("Unmarshal() err = %v; want nil", err)
+	} else if want := []unmarshalLevel{2, 1}; !reflect.DeepEqual(levels, want) {
+		t.Fatalf("Unmarshal() = %v; want %v", levels, want)
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	type port struct {
+		Port int `skim:"port"`
+	}
+	type config struct {
+		Servers []port          `skim:"servers"`
+		Small   int8            `skim:"small"`
+		Count   uint            `skim:"count"`
+		Pair    [1]int          `skim:"pair"`
+		Level   unmarshalLevel  `skim:"level"`
+		Extra   map[string]bool `skim:"extra"`
+	}
+	cases := []struct {
+		name string
+		opts skim.UnmarshalOptions
+		in   string
+		want string
+	}{
+		{"path", skim.UnmarshalOptions{}, `((servers [((port 1)) ((port 2)) ((port "x"))]))`,
+			"skim: servers[2].port: cannot convert string to int"},
+		{"int8", skim.UnmarshalOptions{}, `(:small 128)`, "skim: small: 128 overflows int8"},
+		{"uint", skim.UnmarshalOptions{}, `(:count -1)`, "skim: count: -1 overflows uint"},
+		{"array", skim.UnmarshalOptions{}, `(:pair [1 2])`, "skim: pair: cannot convert 2 elements to [1]int"},
+		{"unmarshaler", skim.UnmarshalOptions{}, `(:level medium)`, "skim: level: invalid level"},
+		{"map", skim.UnmarshalOptions{}, `(:extra (:a 1))`, "skim: extra.a: cannot convert int to bool"},
+		{"not object", skim.UnmarshalOptions{}, `[1 2]`, "skim: cannot convert vector to skim_test.config"},
+		{"odd plist", skim.UnmarshalOptions{}, `(:small)`, "skim: property list has a key with no value"},
+		{"key kind", skim.UnmarshalOptions{Keys: skim.SymbolKeys}, `(:small 1)`,
+			"skim: cannot use keyword :small as a key"},
+		{"unknown", skim.UnmarshalOptions{DisallowUnknownFields: true}, `((small 1) (big 2))`,
+			"skim: big: no field named big in skim_test.config"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := parser.ReadString(c.in)
+			if err != nil {
+				t.Fatalf("ReadString(%q) err = %v; want nil", c.in, err)
+			}
+			var v config
+			err = c.opts.Unmarshal(a[0], &v)
+			if err == nil {
+				t.Fatalf("Unmarshal(%v) = %+v; want error", a[0], v)
+			} else if got := err.Error(); !strings.Contains(got, c.want) {
+				t.Fatalf("Unmarshal(%v) err = %v; want %q", a[0], got, c.want)
+			}
+		})
+	}
+
+	if err := skim.Unmarshal(skim.Int(1), config{}); err == nil {
+		t.Fatal("Unmarshal(1, config{}) err = nil; want error")
+	}
+}
@@ -0,0 +1,236 @@
+package skim
+
+import "strings"
+
+// writer writes the String or GoString form of cons pairs, vectors, maps, sets, and records. Those
+// that are part of a cycle are written with datum labels, as in #0=(a . #0#), so that
+// writing a cyclic structure ends.
+type writer struct {
+	strings.Builder
+	gostring bool
+	labels   *Labels
+}
+
+func newWriter(a Atom, gostring bool) *writer {
+	return &writer{gostring: gostring, labels: NewLabels(a)}
+}
+
+// label writes the datum label of a, if it has one, and returns whether a has already been
+// written, in which case only its reference, #N#, is written.
+func (w *writer) label(a Atom) (written bool) {
+	label, written := w.labels.Label(a)
+	w.WriteString(label)
+	return written
+}
+
+func (w *writer) atom(a Atom) {
+	switch v := a.(type) {
+	case *Cons:
+		w.cons(v)
+	case Vector:
+		w.vector(v)
+	case *HashMap:
+		w.hashMap(v)
+	case *Set:
+		w.set(v)
+	case *Record:
+		w.record(v)
+	default:
+		if w.gostring {
+			w.WriteString(fmtgostring(a))
+		} else {
+			w.WriteString(fmtstring(a))
+		}
+	}
+}
+
+func (w *writer) vector(v Vector) {
+	if w.label(v) {
+		return
+	}
+	w.WriteByte('[')
+	for i, a := range v {
+		if i > 0 {
+			w.WriteByte(' ')
+		}
+		w.atom(a)
+	}
+	w.WriteByte(']')
+}
+
+// hashMap writes m as {key value ...}, with its entries sorted by their keys.
+func (w *writer) hashMap(m *HashMap) {
+	if w.label(m) {
+		return
+	}
+	w.WriteByte('{')
+	for i, e := range m.sorted() {
+		if i > 0 {
+			w.WriteByte(' ')
+		}
+		w.atom(e.Key)
+		w.WriteByte(' ')
+		w.atom(e.Value)
+	}
+	w.WriteByte('}')
+}
+
+// set writes s as #{elem ...}, with its elements sorted.
+func (w *writer) set(s *Set) {
+	if w.label(s) {
+		return
+	}
+	w.WriteString("#{")
+	for i, elem := range s.Elems() {
+		if i > 0 {
+			w.WriteByte(' ')
+		}
+		w.atom(elem)
+	}
+	w.WriteByte('}')
+}
+
+// record writes r as #<name field: value ...>.
+func (w *writer) record(r *Record) {
+	if r == nil {
+		w.WriteString("#nil")
+		return
+	} else if w.label(r) {
+		return
+	}
+	w.WriteString("#<")
+	w.WriteString(recordName(r))
+	for i, field := range r.Type.Fields {
+		w.WriteByte(' ')
+		w.WriteString(field.String())
+		w.WriteString(": ")
+		w.atom(r.Values[i])
+	}
+	w.WriteByte('>')
+}
+
+func (w *writer) cons(c *Cons) {
+	if w.gostring {
+		w.goCons(c)
+		return
+	}
+	if IsNil(c) {
+		w.WriteString("()")
+		return
+	} else if w.label(c) {
+		return
+	}
+
+	if quo, rest := QuoteAbbrev(c); rest != nil && !w.labels.Labeled(rest) {
+		w.WriteString(quo)
+		w.atom(rest.Car)
+		return
+	}
+
+	if IsAlist(c, w.labels.LabeledPair) {
+		w.WriteByte('{')
+		for i := c; !IsNil(i); i, _ = i.Cdr.(*Cons) {
+			if i != c {
+				w.WriteByte(' ')
+			}
+			pair := i.Car.(*Cons)
+			w.atom(pair.Car)
+			w.WriteByte(' ')
+			w.atom(pair.Cdr)
+		}
+		w.WriteByte('}')
+		return
+	}
+
+	ch := byte('(')
+	for a := Atom(c); a != nil; {
+		cons, ok := a.(*Cons)
+		if ok && IsNil(cons) {
+			break
+		}
+		first := ch == '('
+		w.WriteByte(ch)
+		ch = ' '
+
+		if !ok || (!first && w.labels.Labeled(cons)) {
+			w.WriteString(". ")
+			w.atom(a)
+			break
+		}
+
+		w.atom(cons.Car)
+		a = cons.Cdr
+	}
+	w.WriteByte(')')
+}
+
+// goCons writes c as a dotted pair, (car . cdr). A nil *Cons and Nil are written as (), as they are
+// by String.
+func (w *writer) goCons(c *Cons) {
+	if IsNil(c) {
+		w.WriteString("()")
+		return
+	} else if w.label(c) {
+		return
+	}
+	w.WriteByte('(')
+	w.atom(c.Car)
+	w.WriteString(" . ")
+	w.atom(c.Cdr)
+	w.WriteByte(')')
+}
+
+// QuoteAbbrev returns the abbreviation, such as ', with which the quote form c, such as (quote x),
+// is written, and the pair holding the quoted atom. Only two-element forms are abbreviated, and
+// (unquote @x) is not, since ,@x would be read as unquote-splicing. If c is not abbreviated,
+// QuoteAbbrev returns the empty string and nil.
+func QuoteAbbrev(c *Cons) (string, *Cons) {
+	if IsNil(c) {
+		return "", nil
+	}
+	var quo string
+	switch c.Car {
+	case Quote:
+		quo = "'"
+	case Quasiquote:
+		quo = "`"
+	case Unquote:
+		quo = ","
+	case UnquoteSplicing:
+		quo = ",@"
+	default:
+		return "", nil
+	}
+	rest, ok := c.Cdr.(*Cons)
+	if !ok || IsNil(rest) {
+		return "", nil
+	} else if tail, ok := rest.Cdr.(*Cons); rest.Cdr != nil && (!ok || !IsNil(tail)) {
+		return "", nil
+	} else if sym, ok := rest.Car.(Symbol); ok && quo == "," && strings.HasPrefix(string(sym), "@") {
+		return "", nil
+	}
+	return quo, rest
+}
+
+// IsAlist returns whether c is a proper list of dotted pairs, each of whose tail is not a list,
+// such that it can be written as a map: {key value ...}. If keep is not nil, c is not an alist if
+// keep returns true for any pair of c after the first or any dotted pair it holds. String uses it
+// to write lists holding labeled cons pairs as lists, so that their labels are kept.
+func IsAlist(c *Cons, keep func(*Cons) bool) bool {
+	for i := c; !IsNil(i); {
+		pair, ok := i.Car.(*Cons)
+		if !ok || pair == nil || keep != nil && keep(pair) {
+			return false
+		}
+		switch pair.Cdr.(type) {
+		case *Cons, nil:
+			return false
+		}
+		next, ok := i.Cdr.(*Cons)
+		if (!ok && i.Cdr != nil) || (next != nil && keep != nil && keep(next)) {
+			return false
+		}
+		i = next
+	}
+	return true
+}
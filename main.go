@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -12,19 +14,55 @@ import (
 	"go.spiff.io/skim/lisp/skim"
 )
 
+// Limits on input read from stdin.
+const (
+	maxDepth       = 10000   // nesting depth
+	maxTokenBytes  = 1 << 16 // length of a symbol or number
+	maxStringBytes = 1 << 24 // length of a string or heredoc
+)
+
+// errCyclic is returned by eval for forms that are reachable from themselves.
+var errCyclic = errors.New("skim: cannot evaluate a cyclic form")
+
+// eval evaluates a in ctx. Cyclic forms, which may be read from stdin with datum labels, are
+// rejected rather than evaluated, since evaluating them may never return.
+func eval(ctx *interp.Context, a skim.Atom) (skim.Atom, error) {
+	if skim.Cyclic(a) {
+		return nil, errCyclic
+	}
+	return ctx.Eval(a)
+}
+
 func main() {
+	var initForm parser.Value
+	flag.Var(&initForm, "init", "evaluate the datum `form` after reading stdin and before evaluating it")
+	flag.Parse()
+
 	log.SetFlags(0)
 	debug.SetLogger(log.Print)
-	roots, err := parser.Read(os.Stdin)
+	src := skim.NewSourceMap()
+	roots, err := parser.Options{
+		MaxDepth:       maxDepth,
+		MaxTokenBytes:  maxTokenBytes,
+		MaxStringBytes: maxStringBytes,
+		SourceMap:      src,
+	}.Read(os.Stdin)
 	if err != nil {
 		log.Fatal("decode: ", err)
 	}
 
-	ctx := interp.NewContext()
+	ctx := interp.NewContext().SetSourceMap(src)
 	builtins.BindCore(ctx)
 	builtins.BindDisplay(ctx)
 	builtins.BindArithmetic(ctx)
 	builtins.BindMutative(ctx)
+	builtins.BindErrors(ctx)
+	builtins.BindRecords(ctx)
+	if initForm.Atom != nil {
+		if _, err := eval(ctx, initForm.Atom); err != nil {
+			log.Fatal("init: ", err)
+		}
+	}
 	first := true
 	skim.Walk(roots, func(a skim.Atom) error {
 		if !first {
@@ -32,10 +70,14 @@ func main() {
 		}
 		first = false
 		fmt.Printf("; %#v\n%v\n", a, a)
-		v, err := ctx.Eval(a)
+		v, err := eval(ctx, a)
 		var next interface{} = v
-		if err != nil {
+		if _, ok := err.(fmt.GoStringer); ok {
 			next = err
+		} else if err != nil {
+			// The debug representation of most errors holds their address, which differs between
+			// runs, so only their message is written.
+			next = err.Error()
 		}
 		fmt.Printf("; => %v\n; [D] => %#v\n", next, next)
 		return nil
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"go.spiff.io/skim/lisp/builtins"
+	"go.spiff.io/skim/lisp/interp"
+	"go.spiff.io/skim/lisp/parser"
+	"go.spiff.io/skim/lisp/skim"
+)
+
+func TestEvalCyclic(t *testing.T) {
+	ctx := interp.NewContext()
+	builtins.BindCore(ctx)
+	cases := []struct {
+		in   string
+		want string // the result, or "" if the form is cyclic
+	}{
+		{"(list . #0=(1 . #0#))", ""},
+		{"#0=(list #0#)", ""},
+		{"(list #0=(quote 1) #0#)", "(1 1)"},
+	}
+	for _, c := range cases {
+		forms, err := parser.ReadString(c.in)
+		if err != nil {
+			t.Fatalf("ReadString(%q) err = %v; want nil", c.in, err)
+		}
+		got, err := eval(ctx, forms[0])
+		if c.want == "" {
+			if err != errCyclic {
+				t.Errorf("eval(%s) = %v, %v; want nil, %v", c.in, got, err, errCyclic)
+			}
+		} else if err != nil || skim.Atom(got) == nil || got.String() != c.want {
+			t.Errorf("eval(%s) = %v, %v; want %s, nil", c.in, got, err, c.want)
+		}
+	}
+}